@@ -1,9 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -27,6 +30,61 @@ func main() {
 	flag.StringVar(&cfg.Token.AccessExpires, "jwt-access-expires", os.Getenv("JWT_ACCESS_EXPIRES"), "Jwt Access")
 	flag.StringVar(&cfg.Token.RefreshExpires, "jwt-refresh-expires", os.Getenv("JWT_REFRESH_EXPIRES"), "Jwt Access")
 
+	cfg.Cors.TrustedOrigins = strings.Fields(os.Getenv("CORS_TRUSTED_ORIGINS"))
+	flag.Func("cors-trusted-origins", "Trusted CORS origins separated by space (exact, https://*.domain or regex:pattern)", func(val string) error {
+		cfg.Cors.TrustedOrigins = strings.Fields(val)
+		return nil
+	})
+	flag.BoolVar(&cfg.Cors.AllowCredentials, "cors-allow-credentials", true, "Allow credentialed CORS request, could not be used with the * trusted origin")
+	flag.DurationVar(&cfg.Cors.MaxAge, "cors-max-age", 10*time.Minute, "CORS preflight cache duration")
+
+	cfg.Admin.UserIds = strings.Fields(os.Getenv("ADMIN_USER_IDS"))
+	flag.Func("admin-user-ids", "User ids promoted to admin on start separated by space", func(val string) error {
+		cfg.Admin.UserIds = strings.Fields(val)
+		return nil
+	})
+
+	flag.StringVar(&cfg.Attachment.Driver, "attachment-driver", os.Getenv("ATTACHMENT_DRIVER"), "Attachment storage (s3 | local | memory), default to local on development and s3 otherwise")
+	flag.DurationVar(&cfg.Attachment.UrlExpires, "attachment-url-expires", 5*time.Minute, "Lifetime of the signed attachment url")
+	flag.StringVar(&cfg.Attachment.S3.Bucket, "attachment-s3-bucket", os.Getenv("S3_BUCKET_NAME"), "S3 bucket name")
+	flag.StringVar(&cfg.Attachment.S3.Region, "attachment-s3-region", "ap-southeast-1", "S3 region")
+	flag.StringVar(&cfg.Attachment.S3.Endpoint, "attachment-s3-endpoint", os.Getenv("S3_ENDPOINT"), "S3 compatible endpoint, eg: http://localhost:9000 for minio")
+	flag.BoolVar(&cfg.Attachment.S3.PathStyle, "attachment-s3-path-style", false, "Address the S3 bucket in the path, required by minio")
+	flag.StringVar(&cfg.Attachment.Local.Dir, "attachment-local-dir", "data/blobs", "Directory of the local attachment driver")
+	flag.StringVar(&cfg.Attachment.Local.BaseUrl, "attachment-local-base-url", os.Getenv("ATTACHMENT_BASE_URL"), "Public url of the blob route, default to http://localhost:<port>/api/v1/blobs")
+	flag.StringVar(&cfg.Attachment.SigningSecret, "attachment-signing-secret", os.Getenv("ATTACHMENT_SIGNING_SECRET"), "Secret to sign the url of local and memory attachment driver")
+
+	flag.DurationVar(&cfg.BlobGc.Interval, "blob-gc-interval", 6*time.Hour, "Orphaned blob collector interval, 0 to disable")
+	flag.DurationVar(&cfg.BlobGc.Grace, "blob-gc-grace", 24*time.Hour, "Keep the unreferenced blob younger than this duration")
+	flag.BoolVar(&cfg.BlobGc.DryRun, "blob-gc-dry-run", false, "Only log the orphaned blob instead of deleting it")
+	flag.BoolVar(&cfg.BlobGc.Report, "blob-gc-report", false, "Print the orphaned blob report as json and exit")
+
+	flag.DurationVar(&cfg.Account.DeletionGrace, "account-deletion-grace", 30*24*time.Hour, "Delay before the account requested for deletion is purged")
+	flag.DurationVar(&cfg.Account.PurgeInterval, "account-purge-interval", time.Hour, "Account purger interval, 0 to disable")
+
+	flag.DurationVar(&cfg.Export.Interval, "export-interval", 30*time.Second, "Personal data exporter interval, 0 to disable")
+	flag.DurationVar(&cfg.Export.Ttl, "export-ttl", 7*24*time.Hour, "Lifetime of the personal data archive before it is deleted")
+
+	flag.DurationVar(&cfg.Reveal.Ttl, "reveal-ttl", 72*time.Hour, "Lifetime of the reveal request before it expires, 0 to never expire it")
+	flag.BoolVar(&cfg.Reveal.Mutual, "reveal-mutual", false, "Require both participants to request the reveal instead of accepting it")
+	flag.DurationVar(&cfg.Reveal.ExpiryInterval, "reveal-expiry-interval", time.Minute, "Reveal expirer interval, 0 to disable")
+
+	flag.Int64Var(&cfg.Media.MaxAudioBytes, "media-max-audio-bytes", 8<<20, "Max byte of voice note chat attachment")
+	flag.Int64Var(&cfg.Media.MaxImageBytes, "media-max-image-bytes", 8<<20, "Max byte of image chat attachment")
+	flag.Int64Var(&cfg.Media.MaxVideoBytes, "media-max-video-bytes", 32<<20, "Max byte of video chat attachment")
+	flag.IntVar(&cfg.Media.MaxDimension, "media-max-dimension", 4096, "Max width or height in pixel of image and video chat attachment")
+	flag.DurationVar(&cfg.Media.MaxVoiceDuration, "media-max-voice-duration", 5*time.Minute, "Max duration of voice note chat attachment")
+	flag.DurationVar(&cfg.Media.MaxVideoDuration, "media-max-video-duration", time.Minute, "Max duration of video chat attachment")
+	flag.IntVar(&cfg.Media.ThumbnailSize, "media-thumbnail-size", 320, "Longest side in pixel of the generated thumbnail")
+	flag.IntVar(&cfg.Media.ProfileFullSize, "media-profile-full-size", 1080, "Longest side in pixel of the full profile picture")
+	flag.IntVar(&cfg.Media.ProfileMediumSize, "media-profile-medium-size", 480, "Longest side in pixel of the medium profile picture")
+	flag.IntVar(&cfg.Media.ProfileThumbnailSize, "media-profile-thumbnail-size", 160, "Longest side in pixel of the profile picture thumbnail")
+
+	flag.DurationVar(&cfg.Presence.HeartbeatTimeout, "presence-heartbeat-timeout", 45*time.Second, "Mark user offline after no heartbeat for this duration")
+	flag.DurationVar(&cfg.Presence.SweepInterval, "presence-sweep-interval", 30*time.Second, "Stale online sweeper interval, 0 to disable")
+	flag.DurationVar(&cfg.Ws.HistoryTtl, "ws-history-ttl", 10*time.Minute, "Keep the event for resume this long after the user disconnect")
+	flag.DurationVar(&cfg.Ws.HistorySweepInterval, "ws-history-sweep-interval", time.Minute, "Event history sweeper interval, 0 to disable")
+
 	flag.Parse()
 
 	db, err := cfg.OpenPgDb()
@@ -40,14 +98,54 @@ func main() {
 			log.Panic(err)
 		}
 	}(db)
-	routes, eventDeps, wsDeps := cfg.Container(db)
+	routes, eventDeps, wsDeps, err := cfg.Container(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if cfg.BlobGc.Report {
+		report, err := eventDeps.BlobGc.Collect(true)
+		if err != nil {
+			log.Fatal(err)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
 	event.ProfileUpdated.Register(&eventDeps)
 	event.MatchRevealed.Register(&eventDeps)
 	event.ChatSeen.Register(&eventDeps)
+	event.ChatDelivered.Register(&eventDeps)
 	event.ChatCreated.Register(&eventDeps)
+	event.ChatEdited.Register(&eventDeps)
+	event.ChatDeleted.Register(&eventDeps)
+	event.ReactionChanged.Register(&eventDeps)
+	event.PresenceChanged.Register(&eventDeps)
+	event.UnreadChanged.Register(&eventDeps)
 
 	go wsDeps.ListenToWsChan()
+	if cfg.Presence.SweepInterval > 0 {
+		go eventDeps.Online.RunStaleSweeper(cfg.Presence.SweepInterval, cfg.Presence.HeartbeatTimeout)
+	}
+	if cfg.Ws.HistorySweepInterval > 0 {
+		go eventDeps.Ws.RunHistorySweeper(cfg.Ws.HistorySweepInterval, cfg.Ws.HistoryTtl)
+	}
+	if cfg.BlobGc.Interval > 0 {
+		go eventDeps.BlobGc.RunCollector(cfg.BlobGc.Interval, cfg.BlobGc.DryRun)
+	}
+	if cfg.Account.PurgeInterval > 0 {
+		go eventDeps.Account.RunPurger(cfg.Account.PurgeInterval)
+	}
+	if cfg.Export.Interval > 0 {
+		go eventDeps.Export.RunExporter(cfg.Export.Interval)
+	}
+	if cfg.Reveal.ExpiryInterval > 0 {
+		go eventDeps.MatchSvc.RunRevealExpirer(cfg.Reveal.ExpiryInterval)
+	}
 	err = cfg.NewServer(routes)
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,284 @@
+package media
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+type Audio struct {
+	Duration time.Duration
+	// Intensity is the loudness estimation of each frame in order, it is not decoded so only the relative value is meaningful
+	Intensity []int
+}
+
+// ProbeOgg read the opus or vorbis stream of the first logical bitstream.
+// the duration come from the last granule position and the intensity is the size of each audio packet
+func ProbeOgg(src []byte) (Audio, error) {
+	packets, lastGranule, err := oggPackets(src)
+	if err != nil {
+		return Audio{}, err
+	}
+	if len(packets) == 0 || lastGranule < 0 {
+		return Audio{}, ErrMalformed
+	}
+	var samples int64
+	var sampleRate int64
+	var audioPackets [][]byte
+	head := packets[0]
+	switch {
+	case len(head) >= 19 && string(head[:8]) == "OpusHead":
+		// opus granule always count at 48kHz, minus the pre-skip of the decoder
+		sampleRate = 48000
+		samples = lastGranule - int64(binary.LittleEndian.Uint16(head[10:]))
+		if len(packets) > 2 {
+			audioPackets = packets[2:]
+		}
+	case len(head) >= 30 && string(head[:7]) == "\x01vorbis":
+		sampleRate = int64(binary.LittleEndian.Uint32(head[12:]))
+		samples = lastGranule
+		if len(packets) > 3 {
+			audioPackets = packets[3:]
+		}
+	default:
+		return Audio{}, ErrMalformed
+	}
+	if sampleRate <= 0 || samples < 0 {
+		return Audio{}, ErrMalformed
+	}
+	// the granule is taken from the upload, the stream longer than time.Duration could hold is not real audio
+	seconds := samples / sampleRate
+	if seconds > int64(math.MaxInt64/time.Second)-1 {
+		return Audio{}, ErrMalformed
+	}
+	intensity := make([]int, 0, len(audioPackets))
+	for _, packet := range audioPackets {
+		intensity = append(intensity, len(packet))
+	}
+	return Audio{
+		Duration:  time.Duration(seconds)*time.Second + time.Duration(samples%sampleRate)*time.Second/time.Duration(sampleRate),
+		Intensity: intensity,
+	}, nil
+}
+
+// oggPackets assemble the packets of the first logical bitstream, the page checksum is not verified
+func oggPackets(src []byte) (packets [][]byte, lastGranule int64, err error) {
+	lastGranule = -1
+	var serial uint32
+	var packet []byte
+	for i, page := 0, 0; i < len(src); page++ {
+		if i+27 > len(src) || string(src[i:i+4]) != "OggS" || src[i+4] != 0 {
+			return nil, 0, ErrMalformed
+		}
+		granule := int64(binary.LittleEndian.Uint64(src[i+6:]))
+		pageSerial := binary.LittleEndian.Uint32(src[i+14:])
+		segments := int(src[i+26])
+		if i+27+segments > len(src) {
+			return nil, 0, ErrMalformed
+		}
+		table := src[i+27 : i+27+segments]
+		body := i + 27 + segments
+		end := body
+		for _, lacing := range table {
+			end += int(lacing)
+		}
+		if end > len(src) {
+			return nil, 0, ErrMalformed
+		}
+		if page == 0 {
+			serial = pageSerial
+		}
+		if pageSerial == serial {
+			for _, lacing := range table {
+				packet = append(packet, src[body:body+int(lacing)]...)
+				body += int(lacing)
+				// lacing less than 255 finish the packet, otherwise it continue on the next segment
+				if lacing < 255 {
+					packets = append(packets, packet)
+					packet = nil
+				}
+			}
+			if granule >= 0 {
+				lastGranule = granule
+			}
+		}
+		i = end
+	}
+	return packets, lastGranule, nil
+}
+
+var (
+	mp3Bitrates = [2][16]int{
+		// mpeg 2 and 2.5
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+		// mpeg 1
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+	}
+	mp3SampleRates = map[uint32][3]int{
+		0: {11025, 12000, 8000},
+		2: {22050, 24000, 16000},
+		3: {44100, 48000, 32000},
+	}
+)
+
+// ProbeMP3 walk the layer III frames after the ID3v2 tag. the duration is the sum of the frame samples
+// and the intensity is the global gain of the first granule, zero when the granule is silent
+func ProbeMP3(src []byte) (Audio, error) {
+	i := 0
+	if len(src) >= 10 && string(src[:3]) == "ID3" {
+		size := int(src[6]&0x7f)<<21 | int(src[7]&0x7f)<<14 | int(src[8]&0x7f)<<7 | int(src[9]&0x7f)
+		i = 10 + size
+		if src[5]&0x10 != 0 {
+			i += 10
+		}
+	}
+	var duration time.Duration
+	var intensity []int
+	for frames := 0; i+4 <= len(src); frames++ {
+		header := binary.BigEndian.Uint32(src[i:])
+		version := (header >> 19) & 3
+		layer := (header >> 17) & 3
+		bitrateIdx := (header >> 12) & 0xF
+		sampleRateIdx := (header >> 10) & 3
+		sampleRates, validVersion := mp3SampleRates[version]
+		if header>>21 != 0x7FF || !validVersion || layer != 1 || bitrateIdx == 0 || bitrateIdx == 15 || sampleRateIdx == 3 {
+			// the trailing ID3v1 or APE tag
+			if frames == 0 {
+				return Audio{}, ErrMalformed
+			}
+			break
+		}
+		mpeg1 := version == 3
+		mono := (header>>6)&3 == 3
+		sampleRate := sampleRates[sampleRateIdx]
+		var bitrate int
+		frameLen, samples := 0, 576
+		if mpeg1 {
+			bitrate = mp3Bitrates[1][bitrateIdx]
+			frameLen, samples = 144000*bitrate/sampleRate, 1152
+		} else {
+			bitrate = mp3Bitrates[0][bitrateIdx]
+			frameLen = 72000 * bitrate / sampleRate
+		}
+		frameLen += int((header >> 9) & 1)
+		if i+frameLen > len(src) {
+			break
+		}
+		frame := src[i : i+frameLen]
+		i += frameLen
+
+		sideInfo := 4
+		if (header>>16)&1 == 0 {
+			// protected by crc
+			sideInfo += 2
+		}
+		if frames == 0 && isXingFrame(frame, sideInfo, mpeg1, mono) {
+			continue
+		}
+		gain, err := mp3GlobalGain(frame[sideInfo:], mpeg1, mono)
+		if err != nil {
+			return Audio{}, err
+		}
+		intensity = append(intensity, gain)
+		duration += time.Duration(samples) * time.Second / time.Duration(sampleRate)
+	}
+	if len(intensity) == 0 {
+		return Audio{}, ErrMalformed
+	}
+	return Audio{
+		Duration:  duration,
+		Intensity: intensity,
+	}, nil
+}
+
+func mp3SideInfoLen(mpeg1, mono bool) int {
+	switch {
+	case mpeg1 && mono:
+		return 17
+	case mpeg1:
+		return 32
+	case mono:
+		return 9
+	default:
+		return 17
+	}
+}
+
+// isXingFrame tell whether the frame is the vbr info frame, it hold no audio
+func isXingFrame(frame []byte, sideInfo int, mpeg1, mono bool) bool {
+	at := sideInfo + mp3SideInfoLen(mpeg1, mono)
+	if at+4 > len(frame) {
+		return false
+	}
+	tag := string(frame[at : at+4])
+	return tag == "Xing" || tag == "Info"
+}
+
+func mp3GlobalGain(sideInfo []byte, mpeg1, mono bool) (int, error) {
+	if len(sideInfo) < mp3SideInfoLen(mpeg1, mono) {
+		return 0, ErrMalformed
+	}
+	// skip main_data_begin, private bits and scfsi to the first granule of the first channel
+	var granule int
+	switch {
+	case mpeg1 && mono:
+		granule = 9 + 5 + 4
+	case mpeg1:
+		granule = 9 + 3 + 8
+	case mono:
+		granule = 8 + 1
+	default:
+		granule = 8 + 2
+	}
+	bigValues := readBits(sideInfo, granule+12, 9)
+	if bigValues == 0 {
+		return 0, nil
+	}
+	return readBits(sideInfo, granule+21, 8), nil
+}
+
+func readBits(src []byte, offset, n int) int {
+	var v int
+	for k := offset; k < offset+n; k++ {
+		v = v<<1 | int(src[k/8]>>(7-k%8)&1)
+	}
+	return v
+}
+
+// Waveform reduce the intensity to the peak of each bar, scaled between 0 and 100
+func Waveform(intensity []int, bars int) []int {
+	if len(intensity) == 0 || bars <= 0 {
+		return nil
+	}
+	if len(intensity) < bars {
+		bars = len(intensity)
+	}
+	peaks := make([]int, bars)
+	for b := range peaks {
+		for _, v := range intensity[b*len(intensity)/bars : (b+1)*len(intensity)/bars] {
+			if v > peaks[b] {
+				peaks[b] = v
+			}
+		}
+	}
+	lo, hi := peaks[0], peaks[0]
+	for _, p := range peaks {
+		if p < lo {
+			lo = p
+		}
+		if p > hi {
+			hi = p
+		}
+	}
+	for b, p := range peaks {
+		switch {
+		case hi == lo && hi == 0:
+			peaks[b] = 0
+		case hi == lo:
+			peaks[b] = 100
+		default:
+			peaks[b] = (p - lo) * 100 / (hi - lo)
+		}
+	}
+	return peaks
+}
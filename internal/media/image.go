@@ -0,0 +1,364 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	// registered for image.Decode and image.DecodeConfig
+	_ "image/gif"
+	_ "image/png"
+)
+
+var ErrMalformed = errors.New("media is malformed")
+
+// SanitizeImage drop the metadata (exif, gps, xmp, comment) of jpeg, png and gif.
+// jpeg with non default exif orientation is re-encoded upright since the orientation tag is dropped
+func SanitizeImage(content []byte, format string) ([]byte, error) {
+	switch format {
+	case "jpeg":
+		if orientation := jpegOrientation(content); orientation > 1 && orientation <= 8 {
+			img, err := jpeg.Decode(bytes.NewReader(content))
+			if err != nil {
+				return nil, ErrMalformed
+			}
+			var buf bytes.Buffer
+			err = jpeg.Encode(&buf, orient(img, orientation), &jpeg.Options{Quality: 90})
+			if err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		}
+		return stripJPEG(content)
+	case "png":
+		return stripPNG(content)
+	case "gif":
+		return stripGIF(content)
+	}
+	return nil, ErrMalformed
+}
+
+// Thumbnail downscale the image to fit maxSide and encode it as jpeg, transparency is flattened to white
+func Thumbnail(content []byte, maxSide int) ([]byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	return EncodeJPEG(img, maxSide, 80)
+}
+
+// Upright decode the image with the jpeg exif orientation applied, gif is decoded to its first frame
+func Upright(content []byte) (image.Image, error) {
+	img, format, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if format == "jpeg" {
+		if orientation := jpegOrientation(content); orientation > 1 && orientation <= 8 {
+			return orient(img, orientation), nil
+		}
+	}
+	return img, nil
+}
+
+// EncodeJPEG downscale img to fit maxSide and encode it as jpeg, the metadata is never written
+// and transparency is flattened to white
+func EncodeJPEG(img image.Image, maxSide, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, downscale(img, maxSide), &jpeg.Options{Quality: quality})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// downscale average every source pixel covered by the destination pixel
+func downscale(src image.Image, maxSide int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			dw, dh = maxSide, h*maxSide/w
+		} else {
+			dw, dh = w*maxSide/h, maxSide
+		}
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, (y+1)*h/dh
+		if y1 == y0 {
+			y1++
+		}
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, (x+1)*w/dw
+			if x1 == x0 {
+				x1++
+			}
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(b.Min.X+sx, b.Min.Y+sy).RGBA()
+					r, g, bl, a = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			// the color is alpha premultiplied, so flatten over white by adding the missing coverage
+			white := 0xffff - a/n
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((r/n + white) >> 8),
+				G: uint8((g/n + white) >> 8),
+				B: uint8((bl/n + white) >> 8),
+				A: 0xff,
+			})
+		}
+	}
+	return dst
+}
+
+// orient apply the exif orientation so the image is upright
+func orient(src image.Image, orientation int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			dst.Set(x, y, src.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
+
+// stripJPEG drop APP1 (exif, xmp), APP3-APP13 (iptc included), APP15 and comment segment.
+// APP0 (jfif), APP2 (icc profile) and APP14 (adobe color transform) is kept since the decoder need it
+func stripJPEG(src []byte) ([]byte, error) {
+	if len(src) < 4 || src[0] != 0xFF || src[1] != 0xD8 {
+		return nil, ErrMalformed
+	}
+	out := make([]byte, 0, len(src))
+	out = append(out, 0xFF, 0xD8)
+	for i := 2; i < len(src); {
+		if src[i] != 0xFF {
+			return nil, ErrMalformed
+		}
+		for i < len(src) && src[i] == 0xFF {
+			i++
+		}
+		if i >= len(src) {
+			return nil, ErrMalformed
+		}
+		marker := src[i]
+		i++
+		if marker == 0xD9 {
+			return append(out, 0xFF, 0xD9), nil
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			out = append(out, 0xFF, marker)
+			continue
+		}
+		if i+2 > len(src) {
+			return nil, ErrMalformed
+		}
+		length := int(binary.BigEndian.Uint16(src[i:]))
+		if length < 2 || i+length > len(src) {
+			return nil, ErrMalformed
+		}
+		if marker == 0xDA {
+			// the entropy coded data follow the start of scan until the end of image
+			out = append(out, 0xFF, marker)
+			return append(out, src[i:]...), nil
+		}
+		drop := marker == 0xE1 || (marker >= 0xE3 && marker <= 0xED) || marker == 0xEF || marker == 0xFE
+		if !drop {
+			out = append(out, 0xFF, marker)
+			out = append(out, src[i:i+length]...)
+		}
+		i += length
+	}
+	return nil, ErrMalformed
+}
+
+// jpegOrientation read the orientation tag of the exif IFD0, 0 when there is none
+func jpegOrientation(src []byte) int {
+	if len(src) < 4 || src[0] != 0xFF || src[1] != 0xD8 {
+		return 0
+	}
+	for i := 2; i+4 <= len(src); {
+		if src[i] != 0xFF {
+			return 0
+		}
+		marker := src[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return 0
+		}
+		length := int(binary.BigEndian.Uint16(src[i+2:]))
+		if length < 2 || i+2+length > len(src) {
+			return 0
+		}
+		segment := src[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 0
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// stripPNG drop the exif, text and time chunk
+func stripPNG(src []byte) ([]byte, error) {
+	if !bytes.HasPrefix(src, pngSignature) {
+		return nil, ErrMalformed
+	}
+	out := make([]byte, 0, len(src))
+	out = append(out, pngSignature...)
+	for i := len(pngSignature); i+12 <= len(src); {
+		length := int(binary.BigEndian.Uint32(src[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(src) {
+			return nil, ErrMalformed
+		}
+		switch typ := string(src[i+4 : i+8]); typ {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out = append(out, src[i:end]...)
+			if typ == "IEND" {
+				return out, nil
+			}
+		}
+		i = end
+	}
+	return nil, ErrMalformed
+}
+
+// stripGIF drop the comment and every application extension other than the animation loop
+func stripGIF(src []byte) ([]byte, error) {
+	if len(src) < 13 || !(bytes.HasPrefix(src, []byte("GIF87a")) || bytes.HasPrefix(src, []byte("GIF89a"))) {
+		return nil, ErrMalformed
+	}
+	i := 13
+	if flags := src[10]; flags&0x80 != 0 {
+		i += 3 << ((flags & 0x07) + 1)
+	}
+	if i > len(src) {
+		return nil, ErrMalformed
+	}
+	out := make([]byte, 0, len(src))
+	out = append(out, src[:i]...)
+	for i < len(src) {
+		switch src[i] {
+		case 0x3B:
+			return append(out, 0x3B), nil
+		case 0x21:
+			if i+2 > len(src) {
+				return nil, ErrMalformed
+			}
+			end, err := skipGIFSubBlocks(src, i+2)
+			if err != nil {
+				return nil, err
+			}
+			label := src[i+1]
+			isLoop := label == 0xFF && end-i >= 14 &&
+				(string(src[i+3:i+14]) == "NETSCAPE2.0" || string(src[i+3:i+14]) == "ANIMEXTS1.0")
+			if label != 0xFE && (label != 0xFF || isLoop) {
+				out = append(out, src[i:end]...)
+			}
+			i = end
+		case 0x2C:
+			if i+10 > len(src) {
+				return nil, ErrMalformed
+			}
+			j := i + 10
+			if flags := src[i+9]; flags&0x80 != 0 {
+				j += 3 << ((flags & 0x07) + 1)
+			}
+			// skip the lzw minimum code size
+			end, err := skipGIFSubBlocks(src, j+1)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, src[i:end]...)
+			i = end
+		default:
+			return nil, ErrMalformed
+		}
+	}
+	return nil, ErrMalformed
+}
+
+func skipGIFSubBlocks(src []byte, i int) (int, error) {
+	for {
+		if i >= len(src) {
+			return 0, ErrMalformed
+		}
+		n := int(src[i])
+		i++
+		if n == 0 {
+			return i, nil
+		}
+		i += n
+	}
+}
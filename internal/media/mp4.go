@@ -0,0 +1,182 @@
+package media
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+var ErrNoVideoTrack = errors.New("video track not found")
+
+type Video struct {
+	Width    int
+	Height   int
+	Duration time.Duration
+}
+
+// ProbeMP4 read the duration from the movie header and the display size of the first video track
+func ProbeMP4(src []byte) (Video, error) {
+	var video Video
+	var hasFtyp, hasMoov, hasVideo bool
+	err := walkBoxes(src, 0, len(src), func(b box) error {
+		switch b.typ {
+		case "ftyp":
+			hasFtyp = true
+		case "moov":
+			hasMoov = true
+			return walkBoxes(src, b.payload, b.end, func(b box) error {
+				switch b.typ {
+				case "mvhd":
+					duration, err := mvhdDuration(src[b.payload:b.end])
+					if err != nil {
+						return err
+					}
+					video.Duration = duration
+				case "trak":
+					if hasVideo {
+						return nil
+					}
+					width, height, isVideo, err := probeTrak(src, b)
+					if err != nil {
+						return err
+					}
+					if isVideo {
+						hasVideo = true
+						video.Width, video.Height = width, height
+					}
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return Video{}, err
+	}
+	if !hasFtyp || !hasMoov {
+		return Video{}, ErrMalformed
+	}
+	if !hasVideo {
+		return Video{}, ErrNoVideoTrack
+	}
+	return video, nil
+}
+
+// StripMP4Metadata blank the user data, metadata and uuid (xmp) box of the file, movie and track.
+// the box is renamed to free instead of removed so the sample offsets is still valid
+func StripMP4Metadata(src []byte) ([]byte, error) {
+	out := make([]byte, len(src))
+	copy(out, src)
+	var blank func(start, end int) error
+	blank = func(start, end int) error {
+		return walkBoxes(out, start, end, func(b box) error {
+			switch b.typ {
+			case "udta", "meta", "uuid":
+				copy(out[b.start+4:b.start+8], "free")
+				for i := b.payload; i < b.end; i++ {
+					out[i] = 0
+				}
+			case "moov", "trak":
+				return blank(b.payload, b.end)
+			}
+			return nil
+		})
+	}
+	if err := blank(0, len(out)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type box struct {
+	typ     string
+	start   int
+	payload int
+	end     int
+}
+
+// walkBoxes call fn with every box inside start and end
+func walkBoxes(src []byte, start, end int, fn func(b box) error) error {
+	for i := start; i < end; {
+		if i+8 > end {
+			return ErrMalformed
+		}
+		size := uint64(binary.BigEndian.Uint32(src[i:]))
+		header := 8
+		switch size {
+		case 0:
+			size = uint64(end - i)
+		case 1:
+			if i+16 > end {
+				return ErrMalformed
+			}
+			size = binary.BigEndian.Uint64(src[i+8:])
+			header = 16
+		}
+		if size < uint64(header) || size > uint64(end-i) {
+			return ErrMalformed
+		}
+		err := fn(box{
+			typ:     string(src[i+4 : i+8]),
+			start:   i,
+			payload: i + header,
+			end:     i + int(size),
+		})
+		if err != nil {
+			return err
+		}
+		i += int(size)
+	}
+	return nil
+}
+
+func mvhdDuration(payload []byte) (time.Duration, error) {
+	var timescale, duration uint64
+	switch {
+	case len(payload) >= 20 && payload[0] == 0:
+		timescale = uint64(binary.BigEndian.Uint32(payload[12:]))
+		duration = uint64(binary.BigEndian.Uint32(payload[16:]))
+	case len(payload) >= 32 && payload[0] == 1:
+		timescale = uint64(binary.BigEndian.Uint32(payload[20:]))
+		duration = binary.BigEndian.Uint64(payload[24:])
+	default:
+		return 0, ErrMalformed
+	}
+	if timescale == 0 {
+		return 0, ErrMalformed
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second)), nil
+}
+
+// probeTrak return the tkhd display size, swapped when the track is rotated by 90 degree
+func probeTrak(src []byte, trak box) (width, height int, isVideo bool, err error) {
+	err = walkBoxes(src, trak.payload, trak.end, func(b box) error {
+		switch b.typ {
+		case "tkhd":
+			payload := src[b.payload:b.end]
+			matrix, size := 40, 76
+			if len(payload) > 0 && payload[0] == 1 {
+				matrix, size = 52, 88
+			}
+			if len(payload) < size+8 {
+				return ErrMalformed
+			}
+			width = int(binary.BigEndian.Uint32(payload[size:]) >> 16)
+			height = int(binary.BigEndian.Uint32(payload[size+4:]) >> 16)
+			a := int32(binary.BigEndian.Uint32(payload[matrix:]))
+			c := int32(binary.BigEndian.Uint32(payload[matrix+4:]))
+			if a == 0 && (c == 0x10000 || c == -0x10000) {
+				width, height = height, width
+			}
+		case "mdia":
+			return walkBoxes(src, b.payload, b.end, func(b box) error {
+				if b.typ == "hdlr" && b.end-b.payload >= 12 {
+					isVideo = string(src[b.payload+8:b.payload+12]) == "vide"
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return width, height, isVideo, err
+}
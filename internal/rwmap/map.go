@@ -33,3 +33,18 @@ func (rw *RwMap[K, V]) Delete(key K) {
 	delete(rw.m, key)
 	rw.mu.Unlock()
 }
+
+// Range call f on the snapshot of the entries until f return false, so f could modify the map
+func (rw *RwMap[K, V]) Range(f func(key K, value V) bool) {
+	rw.mu.RLock()
+	snapshot := make(map[K]V, len(rw.m))
+	for key, value := range rw.m {
+		snapshot[key] = value
+	}
+	rw.mu.RUnlock()
+	for key, value := range snapshot {
+		if !f(key, value) {
+			return
+		}
+	}
+}
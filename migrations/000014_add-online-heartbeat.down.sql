@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS onlines_stale_heartbeat_idx;
+
+ALTER TABLE onlines DROP COLUMN IF EXISTS last_heartbeat;
\ No newline at end of file
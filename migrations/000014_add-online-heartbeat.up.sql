@@ -0,0 +1,3 @@
+ALTER TABLE onlines ADD COLUMN last_heartbeat TIMESTAMPTZ;
+
+CREATE INDEX onlines_stale_heartbeat_idx ON onlines(last_heartbeat) WHERE is_online;
\ No newline at end of file
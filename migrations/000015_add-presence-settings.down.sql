@@ -0,0 +1,7 @@
+ALTER TABLE onlines
+  DROP COLUMN IF EXISTS visibility,
+  DROP COLUMN IF EXISTS last_seen_precision;
+
+DROP TABLE IF EXISTS valid_last_seen_precision;
+
+DROP TABLE IF EXISTS valid_presence_visibility;
@@ -0,0 +1,20 @@
+CREATE TABLE valid_presence_visibility (visibility VARCHAR(25) PRIMARY KEY);
+
+INSERT INTO
+  valid_presence_visibility(visibility)
+VALUES
+  ('everyone'),
+  ('matches'),
+  ('nobody');
+
+CREATE TABLE valid_last_seen_precision (precision VARCHAR(25) PRIMARY KEY);
+
+INSERT INTO
+  valid_last_seen_precision(precision)
+VALUES
+  ('exact'),
+  ('coarse');
+
+ALTER TABLE onlines
+  ADD COLUMN visibility VARCHAR(25) NOT NULL DEFAULT 'everyone' REFERENCES valid_presence_visibility(visibility) ON UPDATE CASCADE,
+  ADD COLUMN last_seen_precision VARCHAR(25) NOT NULL DEFAULT 'exact' REFERENCES valid_last_seen_precision(precision) ON UPDATE CASCADE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS chats_unseen_idx;
+
+ALTER TABLE chats DROP COLUMN IF EXISTS delivered_at;
@@ -0,0 +1,3 @@
+ALTER TABLE chats ADD COLUMN delivered_at TIMESTAMPTZ;
+
+CREATE INDEX chats_unseen_idx ON chats(conversation_id, sent_at) WHERE seen_at IS NULL;
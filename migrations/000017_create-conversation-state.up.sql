@@ -0,0 +1,30 @@
+CREATE TABLE conversation_states (
+  conversation_id UUID NOT NULL REFERENCES conversations(match_id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  unread_count INTEGER NOT NULL DEFAULT 0 CHECK (unread_count >= 0),
+  muted_until TIMESTAMPTZ,
+  pinned BOOLEAN NOT NULL DEFAULT FALSE,
+  archived BOOLEAN NOT NULL DEFAULT FALSE,
+  PRIMARY KEY (conversation_id, user_id)
+);
+
+CREATE INDEX conversation_states_user_idx ON conversation_states(user_id);
+
+INSERT INTO
+  conversation_states(conversation_id, user_id, unread_count)
+SELECT
+  conv.match_id,
+  participant.user_id,
+  (
+    SELECT
+      COUNT(*)
+    FROM chats
+    WHERE chats.conversation_id = conv.match_id
+      AND chats.author != participant.user_id
+      AND chats.seen_at IS NULL
+  )
+FROM conversations AS conv
+JOIN match ON match.id = conv.match_id
+CROSS JOIN LATERAL (
+  VALUES (match.request_from), (match.request_to)
+) AS participant(user_id);
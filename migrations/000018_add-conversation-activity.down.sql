@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS chats_conversation_last_idx;
+
+DROP INDEX IF EXISTS conversation_states_inbox_idx;
+
+CREATE INDEX conversation_states_user_idx ON conversation_states(user_id);
+
+ALTER TABLE conversation_states DROP COLUMN IF EXISTS last_activity_at;
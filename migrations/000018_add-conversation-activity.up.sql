@@ -0,0 +1,21 @@
+ALTER TABLE conversation_states ADD COLUMN last_activity_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE conversation_states SET
+  last_activity_at = COALESCE(
+    (
+      SELECT
+        MAX(chats.sent_at)
+      FROM chats
+      WHERE chats.conversation_id = conversation_states.conversation_id
+    ),
+    match.accepted_at,
+    match.created_at
+  )
+FROM match
+WHERE match.id = conversation_states.conversation_id;
+
+DROP INDEX IF EXISTS conversation_states_user_idx;
+
+CREATE INDEX conversation_states_inbox_idx ON conversation_states(user_id, archived, pinned DESC, last_activity_at DESC, conversation_id DESC);
+
+CREATE INDEX chats_conversation_last_idx ON chats(conversation_id, sent_at DESC, id DESC);
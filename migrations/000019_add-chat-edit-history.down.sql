@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS chat_edits;
+
+ALTER TABLE chats DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE chats DROP COLUMN IF EXISTS edited_at;
@@ -0,0 +1,11 @@
+ALTER TABLE chats ADD COLUMN edited_at TIMESTAMPTZ;
+ALTER TABLE chats ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE TABLE chat_edits (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  chat_id UUID NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+  messages TEXT NOT NULL,
+  edited_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX chat_edits_chat_idx ON chat_edits(chat_id, edited_at DESC);
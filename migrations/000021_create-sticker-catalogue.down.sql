@@ -0,0 +1,6 @@
+ALTER TABLE chats DROP COLUMN IF EXISTS sticker_id;
+
+DROP TABLE IF EXISTS stickers;
+DROP TABLE IF EXISTS sticker_packs;
+
+DELETE FROM valid_media_type WHERE media_type IN ('image/png', 'image/webp', 'image/gif');
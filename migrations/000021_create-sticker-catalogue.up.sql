@@ -0,0 +1,27 @@
+INSERT INTO
+  valid_media_type(media_type)
+VALUES
+  ('image/png'),
+  ('image/webp'),
+  ('image/gif') ON CONFLICT DO NOTHING;
+
+CREATE TABLE sticker_packs (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  name VARCHAR(50) NOT NULL UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  archived_at TIMESTAMPTZ
+);
+
+CREATE TABLE stickers (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  pack_id UUID NOT NULL REFERENCES sticker_packs(id) ON DELETE CASCADE,
+  emoji VARCHAR(64),
+  blob_link CITEXT NOT NULL,
+  media_type VARCHAR(25) NOT NULL REFERENCES valid_media_type(media_type) ON UPDATE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  archived_at TIMESTAMPTZ
+);
+
+CREATE INDEX stickers_pack_idx ON stickers(pack_id, created_at);
+
+ALTER TABLE chats ADD COLUMN sticker_id UUID REFERENCES stickers(id);
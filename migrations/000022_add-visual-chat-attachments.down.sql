@@ -0,0 +1,9 @@
+ALTER TABLE media
+  DROP COLUMN IF EXISTS width,
+  DROP COLUMN IF EXISTS height,
+  DROP COLUMN IF EXISTS duration_ms,
+  DROP COLUMN IF EXISTS thumbnail_link;
+
+DELETE FROM media WHERE media_type IN ('image/jpeg', 'image/png', 'image/gif', 'video/mp4');
+
+DELETE FROM valid_media_type WHERE media_type IN ('image/jpeg', 'video/mp4');
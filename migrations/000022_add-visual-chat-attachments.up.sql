@@ -0,0 +1,13 @@
+INSERT INTO
+  valid_media_type(media_type)
+VALUES
+  ('image/jpeg'),
+  ('image/png'),
+  ('image/gif'),
+  ('video/mp4') ON CONFLICT DO NOTHING;
+
+ALTER TABLE media
+  ADD COLUMN width INT,
+  ADD COLUMN height INT,
+  ADD COLUMN duration_ms INT,
+  ADD COLUMN thumbnail_link CITEXT;
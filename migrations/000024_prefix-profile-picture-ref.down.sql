@@ -0,0 +1,2 @@
+UPDATE profile_picture SET picture_ref = substring(picture_ref FROM length('profile-picture/') + 1)
+WHERE picture_ref LIKE 'profile-picture/%';
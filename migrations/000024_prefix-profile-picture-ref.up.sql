@@ -0,0 +1,2 @@
+UPDATE profile_picture SET picture_ref = 'profile-picture/' || picture_ref
+WHERE picture_ref NOT LIKE '%/%';
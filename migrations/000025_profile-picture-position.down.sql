@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS profile_picture_selected_idx;
+ALTER TABLE profile_picture DROP CONSTRAINT IF EXISTS profile_picture_position_key;
+ALTER TABLE profile_picture DROP COLUMN IF EXISTS position;
@@ -0,0 +1,22 @@
+ALTER TABLE profile_picture ADD COLUMN position INT;
+
+UPDATE profile_picture AS p SET position = ordered.position
+FROM (
+  SELECT id, row_number() OVER (PARTITION BY user_id ORDER BY id) - 1 AS position
+  FROM profile_picture
+) AS ordered
+WHERE p.id = ordered.id;
+
+ALTER TABLE profile_picture ALTER COLUMN position SET NOT NULL;
+
+-- checked at the end of the statement so the reorder could swap the position
+ALTER TABLE profile_picture ADD CONSTRAINT profile_picture_position_key
+  UNIQUE (user_id, position) DEFERRABLE INITIALLY IMMEDIATE;
+
+-- keep the latest selected picture of every user before the selected is made unique
+UPDATE profile_picture SET selected = FALSE
+WHERE selected AND id NOT IN (
+  SELECT MAX(id) FROM profile_picture WHERE selected GROUP BY user_id
+);
+
+CREATE UNIQUE INDEX profile_picture_selected_idx ON profile_picture(user_id) WHERE selected;
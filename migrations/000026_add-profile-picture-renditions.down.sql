@@ -0,0 +1,3 @@
+ALTER TABLE profile_picture
+  DROP COLUMN IF EXISTS thumbnail_ref,
+  DROP COLUMN IF EXISTS medium_ref;
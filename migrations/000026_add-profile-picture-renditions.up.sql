@@ -0,0 +1,4 @@
+-- picture_ref stay the full rendition, the row uploaded before the renditions keep them NULL
+ALTER TABLE profile_picture
+  ADD COLUMN medium_ref CITEXT,
+  ADD COLUMN thumbnail_ref CITEXT;
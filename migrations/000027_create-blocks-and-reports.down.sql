@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reports;
+DROP TABLE IF EXISTS valid_report_reason;
+DROP TABLE IF EXISTS blocks;
@@ -0,0 +1,34 @@
+CREATE TABLE blocks (
+  blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (blocker_id, blocked_id),
+  CONSTRAINT blocks_not_self CHECK (blocker_id != blocked_id)
+);
+
+-- the block is checked from both side of the pair
+CREATE INDEX blocks_blocked_idx ON blocks(blocked_id, blocker_id);
+
+CREATE TABLE valid_report_reason (reason VARCHAR(25) PRIMARY KEY);
+
+INSERT INTO
+  valid_report_reason(reason)
+VALUES
+  ('spam'),
+  ('harassment'),
+  ('inappropriate'),
+  ('fake_profile'),
+  ('underage'),
+  ('other');
+
+CREATE TABLE reports (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  reported_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  reason VARCHAR(25) NOT NULL REFERENCES valid_report_reason(reason) ON UPDATE CASCADE,
+  chat_id UUID REFERENCES chats(id) ON DELETE SET NULL,
+  details TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX reports_reported_idx ON reports(reported_id, created_at);
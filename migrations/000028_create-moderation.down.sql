@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS moderation_audit_logs;
+DROP TABLE IF EXISTS suspensions;
+
+DROP INDEX IF EXISTS reports_queue_idx;
+ALTER TABLE reports
+  DROP COLUMN IF EXISTS resolution,
+  DROP COLUMN IF EXISTS reviewed_at,
+  DROP COLUMN IF EXISTS reviewed_by,
+  DROP COLUMN IF EXISTS status;
+DROP TABLE IF EXISTS valid_report_status;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+DROP TABLE IF EXISTS valid_user_role;
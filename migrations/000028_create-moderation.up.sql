@@ -0,0 +1,55 @@
+CREATE TABLE valid_user_role (role VARCHAR(25) PRIMARY KEY);
+
+INSERT INTO
+  valid_user_role(role)
+VALUES
+  ('user'),
+  ('moderator'),
+  ('admin');
+
+ALTER TABLE users
+  ADD COLUMN role VARCHAR(25) NOT NULL DEFAULT 'user' REFERENCES valid_user_role(role) ON UPDATE CASCADE;
+
+CREATE TABLE valid_report_status (status VARCHAR(25) PRIMARY KEY);
+
+INSERT INTO
+  valid_report_status(status)
+VALUES
+  ('open'),
+  ('resolved'),
+  ('dismissed');
+
+ALTER TABLE reports
+  ADD COLUMN status VARCHAR(25) NOT NULL DEFAULT 'open' REFERENCES valid_report_status(status) ON UPDATE CASCADE,
+  ADD COLUMN reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  ADD COLUMN reviewed_at TIMESTAMPTZ,
+  ADD COLUMN resolution TEXT;
+
+CREATE INDEX reports_queue_idx ON reports(status, created_at);
+
+-- ends_at NULL is a permanent ban, the lifted row is kept as history
+CREATE TABLE suspensions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  moderator_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  reason TEXT NOT NULL,
+  ends_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  lifted_at TIMESTAMPTZ,
+  lifted_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX suspensions_active_idx ON suspensions(user_id) WHERE lifted_at IS NULL;
+
+-- moderator_id NULL is an action taken by the system
+CREATE TABLE moderation_audit_logs (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  moderator_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  action VARCHAR(50) NOT NULL,
+  target_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  report_id UUID REFERENCES reports(id) ON DELETE SET NULL,
+  details TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX moderation_audit_logs_created_idx ON moderation_audit_logs(created_at DESC, id DESC);
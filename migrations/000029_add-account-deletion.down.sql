@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS users_deletion_requested_idx;
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS deletion_requested_at,
+  DROP COLUMN IF EXISTS purged_at,
+  DROP COLUMN IF EXISTS snoozed_at;
@@ -0,0 +1,7 @@
+-- deletion_requested_at start the grace period, the purged row is kept anonymized so the partner history stay intact
+ALTER TABLE users
+  ADD COLUMN deletion_requested_at TIMESTAMPTZ,
+  ADD COLUMN purged_at TIMESTAMPTZ,
+  ADD COLUMN snoozed_at TIMESTAMPTZ;
+
+CREATE INDEX users_deletion_requested_idx ON users(deletion_requested_at) WHERE deletion_requested_at IS NOT NULL AND purged_at IS NULL;
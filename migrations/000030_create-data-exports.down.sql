@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS data_exports;
+
+DROP TABLE IF EXISTS valid_export_status;
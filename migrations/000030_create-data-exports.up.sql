@@ -0,0 +1,30 @@
+CREATE TABLE valid_export_status (status VARCHAR(25) PRIMARY KEY);
+
+INSERT INTO
+  valid_export_status(status)
+VALUES
+  ('pending'),
+  ('processing'),
+  ('ready'),
+  ('failed'),
+  ('expired');
+
+-- blob_key is the uploaded archive, it is deleted once expires_at has passed
+CREATE TABLE data_exports (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status VARCHAR(25) NOT NULL DEFAULT 'pending' REFERENCES valid_export_status(status) ON UPDATE CASCADE,
+  blob_key CITEXT,
+  size_bytes BIGINT,
+  error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  started_at TIMESTAMPTZ,
+  completed_at TIMESTAMPTZ,
+  expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX data_exports_user_idx ON data_exports(user_id, created_at DESC);
+
+-- at most one export in progress per user, it is also the queue of the exporter
+CREATE UNIQUE INDEX data_exports_in_progress_idx ON data_exports(user_id) WHERE status IN ('pending', 'processing');
+CREATE INDEX data_exports_queue_idx ON data_exports(created_at) WHERE status IN ('pending', 'processing');
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS match_request_to_idx;
+
+UPDATE match SET request_status = 'declined' WHERE request_status = 'withdrawn';
+DELETE FROM valid_match_request WHERE match_value = 'withdrawn';
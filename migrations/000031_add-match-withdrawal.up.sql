@@ -0,0 +1,7 @@
+INSERT INTO
+  valid_match_request(match_value)
+VALUES
+  ('withdrawn');
+
+-- the sent list is served by the request_from, request_to unique index
+CREATE INDEX match_request_to_idx ON match(request_to, request_status, created_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS match_events;
+
+DROP TABLE IF EXISTS valid_match_event_kind;
@@ -0,0 +1,33 @@
+CREATE TABLE valid_match_event_kind (kind VARCHAR(25) PRIMARY KEY);
+
+INSERT INTO
+  valid_match_event_kind(kind)
+VALUES
+  ('request'),
+  ('reveal');
+
+-- actor_id is null when the transition is made by the server, eg: expiry
+CREATE TABLE match_events (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  match_id UUID NOT NULL REFERENCES match(id) ON DELETE CASCADE,
+  actor_id UUID REFERENCES users(id) ON DELETE CASCADE,
+  kind VARCHAR(25) NOT NULL REFERENCES valid_match_event_kind(kind) ON UPDATE CASCADE,
+  from_status VARCHAR(25) NOT NULL REFERENCES valid_match_request(match_value) ON UPDATE CASCADE,
+  to_status VARCHAR(25) NOT NULL REFERENCES valid_match_request(match_value) ON UPDATE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX match_events_match_idx ON match_events(match_id, created_at);
+
+-- the match created before the history only has its current status
+INSERT INTO
+  match_events(match_id, actor_id, kind, from_status, to_status, created_at)
+SELECT id, NULL, 'request', 'unknown', request_status, created_at
+FROM match
+WHERE request_status <> 'unknown';
+
+INSERT INTO
+  match_events(match_id, actor_id, kind, from_status, to_status, created_at)
+SELECT id, NULL, 'reveal', 'unknown', reveal_status, COALESCE(revealed_at, accepted_at, created_at)
+FROM match
+WHERE reveal_status <> 'unknown';
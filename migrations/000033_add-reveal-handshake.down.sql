@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS match_reveal_expiry_idx;
+
+ALTER TABLE match
+  DROP COLUMN IF EXISTS reveal_requested_by,
+  DROP COLUMN IF EXISTS reveal_requested_at,
+  DROP COLUMN IF EXISTS reveal_expires_at;
+
+UPDATE match SET reveal_status = 'unknown' WHERE reveal_status IN ('cancelled', 'expired');
+DELETE FROM match_events WHERE from_status IN ('cancelled', 'expired') OR to_status IN ('cancelled', 'expired');
+DELETE FROM valid_match_request WHERE match_value IN ('cancelled', 'expired');
@@ -0,0 +1,13 @@
+INSERT INTO
+  valid_match_request(match_value)
+VALUES
+  ('cancelled'),
+  ('expired');
+
+-- the reveal requested before the handshake has no requester, either participant could answer it
+ALTER TABLE match
+  ADD COLUMN reveal_requested_by UUID REFERENCES users(id) ON DELETE CASCADE,
+  ADD COLUMN reveal_requested_at TIMESTAMPTZ,
+  ADD COLUMN reveal_expires_at TIMESTAMPTZ;
+
+CREATE INDEX match_reveal_expiry_idx ON match(reveal_expires_at) WHERE reveal_status = 'requested';
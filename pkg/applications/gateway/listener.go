@@ -2,7 +2,9 @@ package gateway
 
 import (
 	"log"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/xyedo/blindate/pkg/applications/service"
 	websocketEntity "github.com/xyedo/blindate/pkg/domain/ws"
 )
@@ -30,6 +32,8 @@ func (d *Deps) ListenToWsChan() {
 			d.OnSimpleAction(event, "onChoosingStickerStart")
 		case "onChoosingStickerStop":
 			d.OnSimpleAction(event, "onChoosingStickerStop")
+		case "onMessageDelivered":
+			d.OnMessageDelivered(event)
 		case "onLeaving":
 			d.OnLeaving(event)
 		}
@@ -37,21 +41,34 @@ func (d *Deps) ListenToWsChan() {
 
 }
 func (d *Deps) OnLeaving(event websocketEntity.Payload) {
-	userId, ok := d.Ws.Clients.Get(event.Conn)
+	_ = event.Conn.Close()
+	d.Ws.Unregister(event.Conn)
+}
+
+// OnMessageDelivered acknowledge OnMessage frame, payload is the received chat ids separated by comma
+func (d *Deps) OnMessageDelivered(event websocketEntity.Payload) {
+	userId, ok := d.Ws.Clients.Get(event.Conn.Key())
 	if !ok {
 		return
 	}
-	d.removingUser(event.Conn, userId)
-
+	chatIds := make([]string, 0)
+	for _, chatId := range strings.Split(event.Payload, ",") {
+		chatId = strings.TrimSpace(chatId)
+		if _, err := uuid.Parse(chatId); err != nil {
+			log.Println("invalid delivered chatId", chatId)
+			return
+		}
+		chatIds = append(chatIds, chatId)
+	}
+	err := d.ChatSvc.UpdateDeliveredChat(userId, chatIds)
+	if err != nil {
+		log.Println(err)
+	}
 }
 
 func (d *Deps) OnSimpleAction(event websocketEntity.Payload, action string) {
 	sendToConversation := func(toUserId, convId string) {
-		socket, ok := d.Ws.ReverseClient.Get(toUserId)
-		if !ok {
-			return
-		}
-		err := socket.WriteJSON(websocketEntity.Response{
+		err := d.Ws.SendTransient(toUserId, websocketEntity.Response{
 			Action: action,
 			Data: map[string]any{
 				"convId": convId,
@@ -59,7 +76,6 @@ func (d *Deps) OnSimpleAction(event websocketEntity.Payload, action string) {
 		})
 		if err != nil {
 			log.Println("websocket Err", err)
-			d.removingUser(socket, toUserId)
 		}
 	}
 	convId := event.Payload
@@ -71,9 +87,3 @@ func (d *Deps) OnSimpleAction(event websocketEntity.Payload, action string) {
 	sendToConversation(match.RequestFrom, convId)
 	sendToConversation(match.RequestTo, convId)
 }
-func (d *Deps) removingUser(socket websocketEntity.Conn, userId string) {
-	_ = socket.Close()
-	d.Ws.Clients.Delete(socket)
-	d.Ws.ReverseClient.Delete(userId)
-	d.OnlinceSvc.PutOnline(userId, false)
-}
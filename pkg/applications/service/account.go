@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/xyedo/blindate/pkg/common"
+	"github.com/xyedo/blindate/pkg/domain/user"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// purgeBatchSize bound the account purged in one run
+const purgeBatchSize = 100
+
+// NewAccount manage the account lifecycle, grace is the delay between the deletion request and the purge
+func NewAccount(userRepo user.Repository, attachment Attachment, grace time.Duration) *Account {
+	return &Account{
+		userRepo:   userRepo,
+		attachment: attachment,
+		grace:      grace,
+	}
+}
+
+type Account struct {
+	userRepo   user.Repository
+	attachment Attachment
+	grace      time.Duration
+}
+
+// RequestDeletion schedule the purge after the grace period, logging in before it cancel the deletion.
+// requesting it twice keep the first schedule
+func (a *Account) RequestDeletion(userId string, input userEntity.DeleteAccount) (time.Time, error) {
+	found, err := a.userRepo.GetUserById(userId)
+	if err != nil {
+		return time.Time{}, err
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(found.Password), []byte(input.Password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return time.Time{}, common.WrapError(err, common.ErrNotMatchCredential)
+		}
+		return time.Time{}, err
+	}
+	if found.DeletionRequestedAt != nil {
+		return found.DeletionRequestedAt.Add(a.grace), nil
+	}
+	requestedAt := time.Now()
+	err = a.userRepo.UpdateDeletionRequest(userId, &requestedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return requestedAt.Add(a.grace), nil
+}
+
+// Snooze hide the user from discovery, the match and conversation is kept
+func (a *Account) Snooze(userId string) (time.Time, error) {
+	snoozedAt := time.Now()
+	err := a.userRepo.UpdateSnooze(userId, &snoozedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return snoozedAt, nil
+}
+
+func (a *Account) Unsnooze(userId string) error {
+	return a.userRepo.UpdateSnooze(userId, nil)
+}
+
+// Purge erase every account which grace period has passed, the blob is deleted once the row is committed.
+// the blob failed to be deleted is left to the orphaned blob collector
+func (a *Account) Purge() (userEntity.PurgeReport, error) {
+	var report userEntity.PurgeReport
+	userIds, err := a.userRepo.SelectPurgeable(time.Now().Add(-a.grace), purgeBatchSize)
+	if err != nil {
+		return userEntity.PurgeReport{}, err
+	}
+	for _, userId := range userIds {
+		blobKeys, err := a.userRepo.PurgeUser(userId, time.Now())
+		if err != nil {
+			// restored in between is not a failure
+			if !errors.Is(err, common.ErrResourceNotFound) {
+				log.Println("account purge err", userId, err)
+				report.Failed++
+			}
+			continue
+		}
+		report.Purged++
+		for _, key := range blobKeys {
+			if err := a.attachment.DeleteBlob(key); err != nil {
+				log.Println("account purge delete blob err", key, err)
+				report.BlobFailed++
+				continue
+			}
+			report.BlobDeleted++
+		}
+	}
+	return report, nil
+}
+
+func (a *Account) RunPurger(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		report, err := a.Purge()
+		if err != nil {
+			log.Println("account purge err", err)
+			continue
+		}
+		if report.Purged != 0 || report.Failed != 0 {
+			log.Printf("account purge purged %d account, failed %d, deleted %d blob, failed %d blob", report.Purged, report.Failed, report.BlobDeleted, report.BlobFailed)
+		}
+	}
+}
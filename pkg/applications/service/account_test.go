@@ -0,0 +1,66 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/common"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+)
+
+func Test_AccountPurge(t *testing.T) {
+	grace := 30 * 24 * time.Hour
+	upload := func(t *testing.T, m *memoryAttachment, prefix string) string {
+		key, err := m.UploadBlob(strings.NewReader(prefix), attachmentEntity.Uploader{Prefix: prefix, Ext: ".jpg"})
+		require.NoError(t, err)
+		return key
+	}
+
+	t.Run("purge the account and its blob", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		m := NewMemoryAttachment(NewHmacUrl("https://api.test/blobs/", []byte("secret"), time.Minute))
+		picture := upload(t, m, "profile-picture")
+		media := upload(t, m, "chat-attachment")
+		kept := upload(t, m, "profile-picture")
+
+		userRepo := mockrepo.NewMockUser(ctrl)
+		userRepo.EXPECT().SelectPurgeable(gomock.Any(), gomock.Eq(purgeBatchSize)).Times(1).
+			DoAndReturn(func(requestedBefore time.Time, limit int) ([]string, error) {
+				assert.WithinDuration(t, time.Now().Add(-grace), requestedBefore, time.Minute)
+				return []string{"purged", "restored", "broken"}, nil
+			})
+		userRepo.EXPECT().PurgeUser(gomock.Eq("purged"), gomock.Any()).Times(1).Return([]string{picture, media, "missing-blob"}, nil)
+		userRepo.EXPECT().PurgeUser(gomock.Eq("restored"), gomock.Any()).Times(1).
+			Return(nil, common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "user is not pending deletion"))
+		userRepo.EXPECT().PurgeUser(gomock.Eq("broken"), gomock.Any()).Times(1).Return(nil, errors.New("connection reset"))
+
+		report, err := NewAccount(userRepo, m, grace).Purge()
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Purged)
+		assert.Equal(t, 1, report.Failed, "restored account is not a failure")
+		assert.Equal(t, 3, report.BlobDeleted, "deleting missing blob is not an error")
+
+		assert.NotContains(t, m.blobs, picture)
+		assert.NotContains(t, m.blobs, media)
+		assert.Contains(t, m.blobs, kept)
+	})
+	t.Run("nothing to purge", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		userRepo := mockrepo.NewMockUser(ctrl)
+		userRepo.EXPECT().SelectPurgeable(gomock.Any(), gomock.Any()).Times(1).Return([]string{}, nil)
+		userRepo.EXPECT().PurgeUser(gomock.Any(), gomock.Any()).Times(0)
+
+		report, err := NewAccount(userRepo, nil, grace).Purge()
+		require.NoError(t, err)
+		assert.Zero(t, report.Purged)
+	})
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/xyedo/blindate/pkg/common"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// NewLocalAttachment store the blob under dir, the download url is signed by urls and served by the app
+func NewLocalAttachment(dir string, urls *HmacUrl) (*localAttachment, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, err
+	}
+	return &localAttachment{
+		dir:  dir,
+		urls: urls,
+	}, nil
+}
+
+type localAttachment struct {
+	dir  string
+	urls *HmacUrl
+}
+
+// UploadBlob write to a temporary file first so the partial upload is never served
+func (l *localAttachment) UploadBlob(file io.Reader, attach attachmentEntity.Uploader) (string, error) {
+	key := attach.Prefix + "/" + util.RandomUUID() + attach.Ext
+	blobPath, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(filepath.Dir(blobPath), 0o750)
+	if err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(blobPath), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, file)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	err = os.Rename(tmp.Name(), blobPath)
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (l *localAttachment) DeleteBlob(key string) error {
+	blobPath, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(blobPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *localAttachment) GetPresignedUrl(key string) (string, error) {
+	return l.urls.Sign(key), nil
+}
+
+func (l *localAttachment) VerifyUrl(key, expires, signature string) error {
+	return l.urls.Verify(key, expires, signature)
+}
+
+func (l *localAttachment) OpenBlob(key string) (io.ReadSeekCloser, error) {
+	blobPath, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(blobPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, common.WrapErrorWithMsg(ErrBlobNotFound, common.ErrResourceNotFound, "blob not found")
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+func (l *localAttachment) ReadBlob(key string) (io.ReadCloser, error) {
+	return l.OpenBlob(key)
+}
+
+// ListBlobs skip the temporary file of the upload in progress
+func (l *localAttachment) ListBlobs(prefix string) ([]attachmentEntity.Blob, error) {
+	root, err := l.path(prefix)
+	if err != nil {
+		return nil, err
+	}
+	blobs := make([]attachmentEntity.Blob, 0)
+	err = filepath.WalkDir(root, func(blobPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(l.dir, blobPath)
+		if err != nil {
+			return err
+		}
+		blobs = append(blobs, attachmentEntity.Blob{
+			Key:          filepath.ToSlash(rel),
+			LastModified: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blobs, nil
+}
+
+// path reject the key escaping the dir
+func (l *localAttachment) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return "", common.WrapErrorWithMsg(ErrBlobNotFound, common.ErrResourceNotFound, "blob not found")
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
@@ -2,14 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
 	"io"
-	"log"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/xyedo/blindate/pkg/common"
 	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
 	"github.com/xyedo/blindate/pkg/util"
 )
@@ -18,24 +20,45 @@ type Attachment interface {
 	UploadBlob(file io.Reader, attach attachmentEntity.Uploader) (string, error)
 	DeleteBlob(key string) error
 	GetPresignedUrl(key string) (string, error)
+	// ReadBlob stream the blob content, the missing blob is ErrResourceNotFound
+	ReadBlob(key string) (io.ReadCloser, error)
+	// ListBlobs list every blob uploaded under prefix
+	ListBlobs(prefix string) ([]attachmentEntity.Blob, error)
 }
 
-func NewS3(bucketName string) *attachment {
+type S3Options struct {
+	Bucket string
+	Region string
+	// Endpoint override the aws endpoint for s3 compatible storage, eg: minio
+	Endpoint string
+	// PathStyle address the bucket in the path instead of the host
+	PathStyle  bool
+	UrlExpires time.Duration
+}
+
+// NewS3 load the credentials from the aws default chain, eg: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+func NewS3(opts S3Options) (*attachment, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("ap-southeast-1"))
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(opts.Region))
 	if err != nil {
-		log.Panicln(err)
+		return nil, err
 	}
-	client := s3.NewFromConfig(cfg)
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if opts.Endpoint != "" {
+			o.EndpointResolver = s3.EndpointResolverFromURL(opts.Endpoint)
+		}
+		o.UsePathStyle = opts.PathStyle
+	})
 	return &attachment{
 		uploader: manager.NewUploader(client, func(u *manager.Uploader) {
 			u.PartSize = 10 << 20
 		}),
 		presignClient: s3.NewPresignClient(client),
 		s3client:      client,
-		bucketName:    bucketName,
-	}
+		bucketName:    opts.Bucket,
+		urlExpires:    opts.UrlExpires,
+	}, nil
 }
 
 type attachment struct {
@@ -43,6 +66,7 @@ type attachment struct {
 	presignClient *s3.PresignClient
 	s3client      *s3.Client
 	bucketName    string
+	urlExpires    time.Duration
 }
 
 func (a *attachment) UploadBlob(file io.Reader, attach attachmentEntity.Uploader) (string, error) {
@@ -84,10 +108,61 @@ func (a *attachment) GetPresignedUrl(key string) (string, error) {
 		Bucket: aws.String(a.bucketName),
 		Key:    aws.String(key),
 	}, func(po *s3.PresignOptions) {
-		po.Expires = 5 * time.Minute
+		po.Expires = a.urlExpires
 	})
 	if err != nil {
 		return "", err
 	}
 	return presignRes.URL, nil
 }
+
+// ReadBlob keep the request context alive until the body is closed, so the large blob is not cut by a timeout
+func (a *attachment) ReadBlob(key string) (io.ReadCloser, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	object, err := a.s3client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(a.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		cancel()
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, common.WrapErrorWithMsg(ErrBlobNotFound, common.ErrResourceNotFound, "blob not found")
+		}
+		return nil, err
+	}
+	return cancelReadCloser{ReadCloser: object.Body, cancel: cancel}, nil
+}
+
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelReadCloser) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+func (a *attachment) ListBlobs(prefix string) ([]attachmentEntity.Blob, error) {
+	blobs := make([]attachmentEntity.Blob, 0)
+	paginator := s3.NewListObjectsV2Paginator(a.s3client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(a.bucketName),
+		Prefix: aws.String(prefix + "/"),
+	})
+	for paginator.HasMorePages() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		page, err := paginator.NextPage(ctx)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			blobs = append(blobs, attachmentEntity.Blob{
+				Key:          aws.ToString(object.Key),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+	return blobs, nil
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/common"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
+)
+
+type blobDriver interface {
+	Attachment
+	VerifyUrl(key, expires, signature string) error
+	OpenBlob(key string) (io.ReadSeekCloser, error)
+}
+
+func Test_MemoryAttachment(t *testing.T) {
+	testSignedDriver(t, NewMemoryAttachment(NewHmacUrl("https://api.test/blobs/", []byte("secret"), time.Minute)))
+}
+
+func Test_LocalAttachment(t *testing.T) {
+	dir := t.TempDir()
+	local, err := NewLocalAttachment(dir, NewHmacUrl("https://api.test/blobs/", []byte("secret"), time.Minute))
+	require.NoError(t, err)
+	testSignedDriver(t, local)
+
+	t.Run("no temporary file is left", func(t *testing.T) {
+		_, err := local.UploadBlob(strings.NewReader("hello"), attachmentEntity.Uploader{Prefix: "chat-attachment", Ext: ".txt"})
+		require.NoError(t, err)
+		entries, err := os.ReadDir(filepath.Join(dir, "chat-attachment"))
+		require.NoError(t, err)
+		for _, entry := range entries {
+			assert.False(t, strings.HasPrefix(entry.Name(), ".upload-"), entry.Name())
+		}
+	})
+	t.Run("key escaping the dir", func(t *testing.T) {
+		for _, key := range []string{"../secret", "/etc/passwd", "a/../../b", ""} {
+			_, err := local.OpenBlob(key)
+			assert.ErrorIs(t, err, common.ErrResourceNotFound, key)
+			assert.Error(t, local.DeleteBlob(key), key)
+		}
+	})
+}
+
+// testSignedDriver cover the driver signing its url with HmacUrl
+func testSignedDriver(t *testing.T, driver blobDriver) {
+	var key string
+	t.Run("upload", func(t *testing.T) {
+		var err error
+		key, err = driver.UploadBlob(strings.NewReader("voice note"), attachmentEntity.Uploader{
+			Length:      10,
+			ContentType: "application/ogg",
+			Prefix:      "chat-attachment",
+			Ext:         ".ogg",
+		})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(key, "chat-attachment/"))
+		assert.True(t, strings.HasSuffix(key, ".ogg"))
+
+		blob, err := driver.OpenBlob(key)
+		require.NoError(t, err)
+		defer blob.Close()
+		content, err := io.ReadAll(blob)
+		require.NoError(t, err)
+		assert.Equal(t, "voice note", string(content))
+	})
+	t.Run("list", func(t *testing.T) {
+		blobs, err := driver.ListBlobs("chat-attachment")
+		require.NoError(t, err)
+		require.Len(t, blobs, 1)
+		assert.Equal(t, key, blobs[0].Key)
+		assert.WithinDuration(t, time.Now(), blobs[0].LastModified, time.Minute)
+
+		blobs, err = driver.ListBlobs("profile-picture")
+		require.NoError(t, err)
+		assert.Empty(t, blobs)
+	})
+	t.Run("sign", func(t *testing.T) {
+		signed, err := driver.GetPresignedUrl(key)
+		require.NoError(t, err)
+		u, err := url.Parse(signed)
+		require.NoError(t, err)
+		assert.Equal(t, "api.test", u.Host)
+		assert.Equal(t, "/blobs/"+key, u.Path)
+		expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+		assert.NoError(t, driver.VerifyUrl(key, expires, signature))
+
+		err = driver.VerifyUrl(key, expires, strings.Repeat("0", len(signature)))
+		assertAPIError(t, err, http.StatusForbidden, "blob url is invalid")
+		err = driver.VerifyUrl("chat-attachment/other.ogg", expires, signature)
+		assertAPIError(t, err, http.StatusForbidden, "blob url is invalid")
+	})
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, driver.DeleteBlob(key))
+		_, err := driver.OpenBlob(key)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+		assertAPIError(t, err, http.StatusNotFound, "blob not found")
+		// deleting twice is not an error, the same as s3
+		assert.NoError(t, driver.DeleteBlob(key))
+	})
+}
+
+func Test_HmacUrlExpired(t *testing.T) {
+	urls := NewHmacUrl("https://api.test/blobs", []byte("secret"), -time.Minute)
+	u, err := url.Parse(urls.Sign("sticker/wave.png"))
+	require.NoError(t, err)
+	err = urls.Verify("sticker/wave.png", u.Query().Get("expires"), u.Query().Get("signature"))
+	assertAPIError(t, err, http.StatusForbidden, "blob url is expired")
+
+	err = urls.Verify("sticker/wave.png", "not-a-number", u.Query().Get("signature"))
+	assertAPIError(t, err, http.StatusForbidden, "blob url is invalid")
+}
+
+func assertAPIError(t *testing.T, err error, wantStatus int, wantMsg string) {
+	t.Helper()
+	var apiErr common.APIError
+	require.True(t, errors.As(err, &apiErr), err)
+	status, msg := apiErr.APIError()
+	assert.Equal(t, wantStatus, status)
+	assert.Equal(t, wantMsg, msg)
+}
+
+// fakeS3 keep the object of the path style request, the signature is not verified
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = content
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodGet:
+		if r.URL.Query().Get("list-type") == "2" {
+			f.list(w, r)
+			return
+		}
+		content, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// list answer ListObjectsV2 in a single page
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	bucket := strings.TrimSuffix(r.URL.Path, "/")
+	prefix := bucket + "/" + r.URL.Query().Get("prefix")
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult><IsTruncated>false</IsTruncated>`)
+	for path := range f.objects {
+		if strings.HasPrefix(path, prefix) {
+			fmt.Fprintf(&body, "<Contents><Key>%s</Key><LastModified>%s</LastModified></Contents>",
+				strings.TrimPrefix(path, bucket+"/"), time.Now().UTC().Format(time.RFC3339))
+		}
+	}
+	body.WriteString("</ListBucketResult>")
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = io.WriteString(w, body.String())
+}
+
+func Test_S3Attachment(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "minio")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "minio-secret")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	fake := &fakeS3{objects: make(map[string][]byte), types: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s3Attachment, err := NewS3(S3Options{
+		Bucket:     "blindate",
+		Region:     "us-east-1",
+		Endpoint:   server.URL,
+		PathStyle:  true,
+		UrlExpires: 5 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	key, err := s3Attachment.UploadBlob(bytes.NewReader([]byte("sticker")), attachmentEntity.Uploader{
+		Length:      7,
+		ContentType: "image/png",
+		Prefix:      "sticker",
+		Ext:         ".png",
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "sticker/"))
+	assert.Equal(t, "sticker", string(fake.objects["/blindate/"+key]))
+	assert.Equal(t, "image/png", fake.types["/blindate/"+key])
+
+	signed, err := s3Attachment.GetPresignedUrl(key)
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimPrefix(server.URL, "http://"), u.Host)
+	assert.Equal(t, "/blindate/"+key, u.Path)
+	assert.Equal(t, "300", u.Query().Get("X-Amz-Expires"))
+	assert.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+	res, err := http.Get(signed)
+	require.NoError(t, err)
+	content, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, "sticker", string(content))
+
+	blobs, err := s3Attachment.ListBlobs("sticker")
+	require.NoError(t, err)
+	require.Len(t, blobs, 1)
+	assert.Equal(t, key, blobs[0].Key)
+	assert.WithinDuration(t, time.Now(), blobs[0].LastModified, time.Minute)
+	blobs, err = s3Attachment.ListBlobs("profile-picture")
+	require.NoError(t, err)
+	assert.Empty(t, blobs)
+
+	require.NoError(t, s3Attachment.DeleteBlob(key))
+	assert.NotContains(t, fake.objects, "/blindate/"+key)
+}
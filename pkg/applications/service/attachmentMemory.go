@@ -0,0 +1,100 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xyedo/blindate/pkg/common"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+// NewMemoryAttachment keep the blob in memory, it is meant for test and ephemeral environment
+func NewMemoryAttachment(urls *HmacUrl) *memoryAttachment {
+	return &memoryAttachment{
+		urls:  urls,
+		blobs: make(map[string]memoryBlob),
+	}
+}
+
+type memoryAttachment struct {
+	urls *HmacUrl
+
+	mu    sync.RWMutex
+	blobs map[string]memoryBlob
+}
+
+type memoryBlob struct {
+	content      []byte
+	lastModified time.Time
+}
+
+func (m *memoryAttachment) UploadBlob(file io.Reader, attach attachmentEntity.Uploader) (string, error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	key := attach.Prefix + "/" + util.RandomUUID() + attach.Ext
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blobs[key] = memoryBlob{
+		content:      content,
+		lastModified: time.Now(),
+	}
+	return key, nil
+}
+
+func (m *memoryAttachment) DeleteBlob(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.blobs, key)
+	return nil
+}
+
+func (m *memoryAttachment) GetPresignedUrl(key string) (string, error) {
+	return m.urls.Sign(key), nil
+}
+
+func (m *memoryAttachment) VerifyUrl(key, expires, signature string) error {
+	return m.urls.Verify(key, expires, signature)
+}
+
+func (m *memoryAttachment) OpenBlob(key string) (io.ReadSeekCloser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	blob, ok := m.blobs[key]
+	if !ok {
+		return nil, common.WrapErrorWithMsg(ErrBlobNotFound, common.ErrResourceNotFound, "blob not found")
+	}
+	return nopSeekCloser{bytes.NewReader(blob.content)}, nil
+}
+
+func (m *memoryAttachment) ReadBlob(key string) (io.ReadCloser, error) {
+	return m.OpenBlob(key)
+}
+
+func (m *memoryAttachment) ListBlobs(prefix string) ([]attachmentEntity.Blob, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	blobs := make([]attachmentEntity.Blob, 0)
+	for key, blob := range m.blobs {
+		if strings.HasPrefix(key, prefix+"/") {
+			blobs = append(blobs, attachmentEntity.Blob{
+				Key:          key,
+				LastModified: blob.lastModified,
+			})
+		}
+	}
+	return blobs, nil
+}
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
@@ -2,13 +2,19 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/xyedo/blindate/pkg/common"
 	"github.com/xyedo/blindate/pkg/domain/authentication"
 	"github.com/xyedo/blindate/pkg/domain/user"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var ErrUserRestricted = errors.New("user is restricted")
+
 func NewAuth(authR authentication.Repository, userR user.Repository, tokenSvc *Jwt) *Auth {
 	return &Auth{
 		authRepo: authR,
@@ -35,7 +41,18 @@ func (a *Auth) Login(email, password string) (accessToken string, refreshToken s
 		}
 		return
 	}
-	accessToken, err = a.tokenSvc.GenerateAccessToken(user.ID)
+	err = checkRestriction(user)
+	if err != nil {
+		return
+	}
+	// login within the grace period restore the account
+	if user.DeletionRequestedAt != nil {
+		err = a.userRepo.UpdateDeletionRequest(user.ID, nil)
+		if err != nil {
+			return
+		}
+	}
+	accessToken, err = a.tokenSvc.GenerateAccessToken(user.ID, user.Role)
 	if err != nil {
 		panic(err)
 	}
@@ -58,7 +75,19 @@ func (a *Auth) RevalidateRefreshToken(refreshToken string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	accessToken, err := a.tokenSvc.GenerateAccessToken(id)
+	// the role and the restriction could be changed by the moderator since the last auth
+	user, err := a.userRepo.GetUserById(id)
+	if err != nil {
+		return "", err
+	}
+	err = checkRestriction(user)
+	if err != nil {
+		return "", err
+	}
+	if user.DeletionRequestedAt != nil {
+		return "", common.WrapWithNewError(ErrUserRestricted, http.StatusForbidden, "account is scheduled for deletion, login to restore it")
+	}
+	accessToken, err := a.tokenSvc.GenerateAccessToken(id, user.Role)
 	if err != nil {
 		panic(err)
 	}
@@ -76,3 +105,32 @@ func (a *Auth) Logout(refreshToken string) error {
 	}
 	return nil
 }
+
+// CheckAccess return the current role of userId, the restricted user is rejected.
+// unlike the access token claim, it reflect the moderation made after the token is issued
+func (a *Auth) CheckAccess(userId string) (userEntity.Role, error) {
+	user, err := a.userRepo.GetUserById(userId)
+	if err != nil {
+		if errors.Is(err, common.ErrResourceNotFound) {
+			return "", common.WrapWithNewError(err, http.StatusUnauthorized, "user is no longer exist")
+		}
+		return "", err
+	}
+	err = checkRestriction(user)
+	if err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
+
+// checkRestriction reject the banned or suspended user
+func checkRestriction(user userEntity.FullDTO) error {
+	if user.Banned {
+		return common.WrapWithNewError(ErrUserRestricted, http.StatusForbidden, "account is banned")
+	}
+	if user.SuspendedUntil != nil {
+		return common.WrapWithNewError(ErrUserRestricted, http.StatusForbidden,
+			fmt.Sprintf("account is suspended until %s", user.SuspendedUntil.UTC().Format(time.RFC3339)))
+	}
+	return nil
+}
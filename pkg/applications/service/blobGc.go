@@ -0,0 +1,101 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
+)
+
+// gcPrefixes is every prefix which blob is referenced by media or profile_picture
+var gcPrefixes = []string{"chat-attachment", "chat-thumbnail", "profile-picture"}
+
+// gcBatchSize bound the keys checked against the database in one query
+const gcBatchSize = 500
+
+// NewBlobGc collect the blob no longer referenced, grace protect the blob which row is not inserted yet
+func NewBlobGc(attachment Attachment, blobRepo attachmentEntity.Repository, grace time.Duration) *BlobGc {
+	return &BlobGc{
+		attachment: attachment,
+		blobRepo:   blobRepo,
+		grace:      grace,
+	}
+}
+
+type BlobGc struct {
+	attachment Attachment
+	blobRepo   attachmentEntity.Repository
+	grace      time.Duration
+}
+
+// Collect delete every orphaned blob older than the grace period, dryRun only report them
+func (g *BlobGc) Collect(dryRun bool) (attachmentEntity.GcReport, error) {
+	report := attachmentEntity.GcReport{
+		DryRun:  dryRun,
+		Orphans: make([]string, 0),
+	}
+	staleBefore := time.Now().Add(-g.grace)
+	for _, prefix := range gcPrefixes {
+		blobs, err := g.attachment.ListBlobs(prefix)
+		if err != nil {
+			return attachmentEntity.GcReport{}, err
+		}
+		report.Scanned += len(blobs)
+
+		candidates := make([]string, 0, len(blobs))
+		for _, blob := range blobs {
+			if blob.LastModified.Before(staleBefore) {
+				candidates = append(candidates, blob.Key)
+			}
+		}
+		for start := 0; start < len(candidates); start += gcBatchSize {
+			end := start + gcBatchSize
+			if end > len(candidates) {
+				end = len(candidates)
+			}
+			batch := candidates[start:end]
+			referenced, err := g.blobRepo.SelectReferencedBlob(batch)
+			if err != nil {
+				return attachmentEntity.GcReport{}, err
+			}
+			isReferenced := make(map[string]bool, len(referenced))
+			for _, key := range referenced {
+				isReferenced[key] = true
+			}
+			for _, key := range batch {
+				if !isReferenced[key] {
+					report.Orphans = append(report.Orphans, key)
+				}
+			}
+		}
+	}
+	if dryRun {
+		return report, nil
+	}
+	for _, key := range report.Orphans {
+		if err := g.attachment.DeleteBlob(key); err != nil {
+			log.Println("blob gc delete err", key, err)
+			report.Failed++
+			continue
+		}
+		report.Deleted++
+	}
+	return report, nil
+}
+
+func (g *BlobGc) RunCollector(interval time.Duration, dryRun bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		report, err := g.Collect(dryRun)
+		if err != nil {
+			log.Println("blob gc err", err)
+			continue
+		}
+		if dryRun {
+			log.Printf("blob gc dry-run scanned %d blob, found %d orphan: %v", report.Scanned, len(report.Orphans), report.Orphans)
+			continue
+		}
+		log.Printf("blob gc scanned %d blob, deleted %d orphan, failed %d", report.Scanned, report.Deleted, report.Failed)
+	}
+}
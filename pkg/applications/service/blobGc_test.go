@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+)
+
+func Test_BlobGcCollect(t *testing.T) {
+	upload := func(t *testing.T, m *memoryAttachment, prefix string, age time.Duration) string {
+		key, err := m.UploadBlob(strings.NewReader(prefix), attachmentEntity.Uploader{Prefix: prefix, Ext: ".png"})
+		require.NoError(t, err)
+		blob := m.blobs[key]
+		blob.lastModified = time.Now().Add(-age)
+		m.blobs[key] = blob
+		return key
+	}
+	setupFunc := func(t *testing.T) (*memoryAttachment, map[string]string) {
+		m := NewMemoryAttachment(NewHmacUrl("https://api.test/blobs/", []byte("secret"), time.Minute))
+		keys := map[string]string{
+			"referenced":     upload(t, m, "chat-attachment", 48*time.Hour),
+			"orphan":         upload(t, m, "chat-attachment", 48*time.Hour),
+			"orphanThumb":    upload(t, m, "chat-thumbnail", 48*time.Hour),
+			"orphanPicture":  upload(t, m, "profile-picture", 48*time.Hour),
+			"young":          upload(t, m, "profile-picture", time.Minute),
+			"unknownPrefix":  upload(t, m, "sticker", 48*time.Hour),
+			"referencedPict": upload(t, m, "profile-picture", 48*time.Hour),
+		}
+		return m, keys
+	}
+	referencedOnly := func(keys map[string]string) func([]string) ([]string, error) {
+		return func(batch []string) ([]string, error) {
+			referenced := make([]string, 0)
+			for _, key := range batch {
+				if key == keys["referenced"] || key == keys["referencedPict"] {
+					referenced = append(referenced, key)
+				}
+			}
+			return referenced, nil
+		}
+	}
+
+	t.Run("dry-run only report", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		m, keys := setupFunc(t)
+		blobRepo := mockrepo.NewMockAttachment(ctrl)
+		blobRepo.EXPECT().SelectReferencedBlob(gomock.Any()).DoAndReturn(referencedOnly(keys)).AnyTimes()
+
+		report, err := NewBlobGc(m, blobRepo, 24*time.Hour).Collect(true)
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 6, report.Scanned)
+		sort.Strings(report.Orphans)
+		want := []string{keys["orphan"], keys["orphanThumb"], keys["orphanPicture"]}
+		sort.Strings(want)
+		assert.Equal(t, want, report.Orphans)
+		assert.Zero(t, report.Deleted)
+		assert.Len(t, m.blobs, 7)
+	})
+	t.Run("delete the orphan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		m, keys := setupFunc(t)
+		blobRepo := mockrepo.NewMockAttachment(ctrl)
+		blobRepo.EXPECT().SelectReferencedBlob(gomock.Any()).DoAndReturn(referencedOnly(keys)).AnyTimes()
+
+		report, err := NewBlobGc(m, blobRepo, 24*time.Hour).Collect(false)
+		require.NoError(t, err)
+		assert.False(t, report.DryRun)
+		assert.Equal(t, 3, report.Deleted)
+		assert.Zero(t, report.Failed)
+		for _, name := range []string{"referenced", "referencedPict", "young", "unknownPrefix"} {
+			assert.Contains(t, m.blobs, keys[name], name)
+		}
+		for _, name := range []string{"orphan", "orphanThumb", "orphanPicture"} {
+			assert.NotContains(t, m.blobs, keys[name], name)
+		}
+	})
+	t.Run("database err keep every blob", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		m, _ := setupFunc(t)
+		blobRepo := mockrepo.NewMockAttachment(ctrl)
+		blobRepo.EXPECT().SelectReferencedBlob(gomock.Any()).Return(nil, errors.New("db down")).Times(1)
+
+		_, err := NewBlobGc(m, blobRepo, 24*time.Hour).Collect(false)
+		require.Error(t, err)
+		assert.Len(t, m.blobs, 7)
+	})
+	t.Run("checked in batch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		m := NewMemoryAttachment(NewHmacUrl("https://api.test/blobs/", []byte("secret"), time.Minute))
+		for i := 0; i < gcBatchSize+1; i++ {
+			upload(t, m, "chat-attachment", 48*time.Hour)
+		}
+		blobRepo := mockrepo.NewMockAttachment(ctrl)
+		gomock.InOrder(
+			blobRepo.EXPECT().SelectReferencedBlob(gomock.Len(gcBatchSize)).Return(nil, nil),
+			blobRepo.EXPECT().SelectReferencedBlob(gomock.Len(1)).Return(nil, nil),
+		)
+
+		report, err := NewBlobGc(m, blobRepo, 24*time.Hour).Collect(true)
+		require.NoError(t, err)
+		assert.Len(t, report.Orphans, gcBatchSize+1)
+	})
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCachedUrl bound the memory of the cache, the expired url is swept once it is full
+const maxCachedUrl = 4096
+
+type urlPresigner interface {
+	GetPresignedUrl(key string) (string, error)
+}
+
+type cachedUrl struct {
+	url      string
+	signedAt time.Time
+}
+
+// NewBlobUrl resolve the blob key to the url signed by presigner, expires is the lifetime of the signed url
+func NewBlobUrl(presigner urlPresigner, expires time.Duration) *BlobUrl {
+	return &BlobUrl{
+		presigner: presigner,
+		expires:   expires,
+		cache:     make(map[string]cachedUrl),
+	}
+}
+
+type BlobUrl struct {
+	presigner urlPresigner
+	expires   time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedUrl
+}
+
+// Resolve return the signed url of key, empty key stay empty.
+// the url is reused until half of its lifetime so every response still hand out an url valid for a while
+func (b *BlobUrl) Resolve(key string) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+	now := time.Now()
+	b.mu.Lock()
+	cached, ok := b.cache[key]
+	b.mu.Unlock()
+	if ok && now.Sub(cached.signedAt) < b.expires/2 {
+		return cached.url, nil
+	}
+
+	url, err := b.presigner.GetPresignedUrl(key)
+	if err != nil {
+		return "", err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.cache) >= maxCachedUrl {
+		for k, v := range b.cache {
+			if now.Sub(v.signedAt) >= b.expires/2 {
+				delete(b.cache, k)
+			}
+		}
+		if len(b.cache) >= maxCachedUrl {
+			b.cache = make(map[string]cachedUrl)
+		}
+	}
+	b.cache[key] = cachedUrl{
+		url:      url,
+		signedAt: now,
+	}
+	return url, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/xyedo/blindate/pkg/common"
+	"github.com/xyedo/blindate/pkg/domain/block"
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+	"github.com/xyedo/blindate/pkg/domain/chat"
+	"github.com/xyedo/blindate/pkg/domain/event"
+	"github.com/xyedo/blindate/pkg/domain/match"
+)
+
+var (
+	ErrUserBlocked   = errors.New("user is blocked")
+	ErrInvalidReport = errors.New("invalid report")
+)
+
+func NewBlock(blockRepo block.Repository, chatRepo chat.Repository, matchRepo match.Repository) *Block {
+	return &Block{
+		blockRepo: blockRepo,
+		chatRepo:  chatRepo,
+		matchRepo: matchRepo,
+	}
+}
+
+type Block struct {
+	blockRepo block.Repository
+	chatRepo  chat.Repository
+	matchRepo match.Repository
+}
+
+// BlockUser hide the pair from each other, their conversation is hidden until unblocked
+func (b *Block) BlockUser(blockerId, blockedId string) error {
+	if blockerId == blockedId {
+		return common.WrapWithNewError(ErrUserBlocked, http.StatusUnprocessableEntity, "could not block yourself")
+	}
+	err := b.blockRepo.InsertBlock(blockerId, blockedId)
+	if err != nil {
+		return err
+	}
+	// the hidden conversation is no longer counted on the badge
+	event.UnreadChanged.Trigger(event.UnreadChangedPayload{UserId: blockerId})
+	event.UnreadChanged.Trigger(event.UnreadChangedPayload{UserId: blockedId})
+	return nil
+}
+
+func (b *Block) UnblockUser(blockerId, blockedId string) error {
+	err := b.blockRepo.DeleteBlock(blockerId, blockedId)
+	if err != nil {
+		return err
+	}
+	event.UnreadChanged.Trigger(event.UnreadChangedPayload{UserId: blockerId})
+	event.UnreadChanged.Trigger(event.UnreadChangedPayload{UserId: blockedId})
+	return nil
+}
+
+func (b *Block) GetBlockedUsers(blockerId string) ([]blockEntity.Block, error) {
+	blocks, err := b.blockRepo.SelectBlocked(blockerId)
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// Report the abuse of reportedId, the referenced chat must come from their conversation
+func (b *Block) Report(reporterId string, newReport blockEntity.NewReport) (blockEntity.Report, error) {
+	if reporterId == newReport.ReportedId {
+		return blockEntity.Report{}, common.WrapWithNewError(ErrInvalidReport, http.StatusUnprocessableEntity, "could not report yourself")
+	}
+	if newReport.ChatId != nil {
+		chatDAO, err := b.chatRepo.SelectChatById(*newReport.ChatId)
+		if err != nil {
+			if errors.Is(err, common.ErrResourceNotFound) {
+				return blockEntity.Report{}, common.WrapWithNewError(ErrInvalidReport, http.StatusUnprocessableEntity, "chat is not found")
+			}
+			return blockEntity.Report{}, err
+		}
+		matchDAO, err := b.matchRepo.GetMatchById(chatDAO.ConversationId)
+		if err != nil {
+			return blockEntity.Report{}, err
+		}
+		isPair := (matchDAO.RequestFrom == reporterId && matchDAO.RequestTo == newReport.ReportedId) ||
+			(matchDAO.RequestTo == reporterId && matchDAO.RequestFrom == newReport.ReportedId)
+		if !isPair {
+			return blockEntity.Report{}, common.WrapWithNewError(ErrInvalidReport, http.StatusUnprocessableEntity, "chat is not part of your conversation with the reported user")
+		}
+	}
+	report := blockEntity.Report{
+		ReporterId: reporterId,
+		ReportedId: newReport.ReportedId,
+		Reason:     blockEntity.Reason(newReport.Reason),
+		ChatId:     newReport.ChatId,
+	}
+	if newReport.Details != nil {
+		if details := strings.TrimSpace(*newReport.Details); details != "" {
+			report.Details = &details
+		}
+	}
+	err := b.blockRepo.InsertReport(&report)
+	if err != nil {
+		return blockEntity.Report{}, err
+	}
+	return report, nil
+}
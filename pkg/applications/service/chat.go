@@ -2,10 +2,13 @@ package service
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/xyedo/blindate/pkg/common"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
 	"github.com/xyedo/blindate/pkg/domain/chat"
 	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
 	"github.com/xyedo/blindate/pkg/domain/event"
@@ -13,16 +16,21 @@ import (
 	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
 )
 
-func NewChat(chatRepo chat.Repository, matchRepo match.Repository) *Chat {
+var ErrChatNotEditable = errors.New("chat is not editable")
+var ErrNotRevealed = errors.New("match is not revealed")
+
+func NewChat(chatRepo chat.Repository, matchRepo match.Repository, blobUrl *BlobUrl) *Chat {
 	return &Chat{
 		chatRepo:  chatRepo,
 		matchRepo: matchRepo,
+		blobUrl:   blobUrl,
 	}
 }
 
 type Chat struct {
 	chatRepo  chat.Repository
 	matchRepo match.Repository
+	blobUrl   *BlobUrl
 }
 
 func (c *Chat) CreateNewChat(content *chatEntity.DTO) error {
@@ -36,6 +44,14 @@ func (c *Chat) CreateNewChat(content *chatEntity.DTO) error {
 	if matchDAO.RequestStatus != string(matchEntity.Accepted) {
 		return ErrInvalidMatchStatus
 	}
+	if matchDAO.Blocked {
+		return common.WrapWithNewError(ErrUserBlocked, http.StatusForbidden, "could not send chat to this user")
+	}
+	// the match is blind until both user reveal, no picture of the sender could be shared before
+	if content.Attachment != nil && attachmentEntity.IsVisual(content.Attachment.MediaType) &&
+		matchDAO.RevealStatus != string(matchEntity.Accepted) {
+		return common.WrapWithNewError(ErrNotRevealed, http.StatusForbidden, "image and video could only be shared after reveal")
+	}
 	chatDAO := c.convertToDAO(*content)
 	cleanChats := c.sanitizeChat(chatDAO)
 	for _, cleanChat := range cleanChats {
@@ -46,15 +62,24 @@ func (c *Chat) CreateNewChat(content *chatEntity.DTO) error {
 	}
 	cleanChatDTO := make([]chatEntity.DTO, 0, len(cleanChats))
 	for _, cleanChat := range cleanChats {
-		cleanChatDTO = append(cleanChatDTO, c.convertToDTO(cleanChat))
+		chatDTO := c.convertToDTO(cleanChat)
+		if err := c.resolveUrls(&chatDTO); err != nil {
+			return err
+		}
+		cleanChatDTO = append(cleanChatDTO, chatDTO)
 	}
 	event.ChatCreated.Trigger(event.ChatCreatedPayload{
 		Chat:   cleanChatDTO,
 		ConvId: content.ConversationId,
 	})
+	recipientId := matchDAO.RequestTo
+	if content.Author == matchDAO.RequestTo {
+		recipientId = matchDAO.RequestFrom
+	}
+	event.UnreadChanged.Trigger(event.UnreadChangedPayload{UserId: recipientId})
 	return nil
 }
-func (c *Chat) UpdateSeenChat(convId, userId string) error {
+func (c *Chat) UpdateSeenChat(convId, userId, upToChatId string) error {
 	matchEntity, err := c.matchRepo.GetMatchById(convId)
 	if err != nil {
 		return err
@@ -62,38 +87,253 @@ func (c *Chat) UpdateSeenChat(convId, userId string) error {
 	if !(matchEntity.RequestFrom == userId || matchEntity.RequestTo == userId) {
 		return common.WrapWithNewError(common.ErrAuthorNotValid, http.StatusForbidden, "users not in this conversation")
 	}
-	changedChatIds, err := c.chatRepo.UpdateSeenChat(convId, userId)
+	seenAt := time.Now()
+	changedChatIds, err := c.chatRepo.UpdateSeenChat(convId, userId, upToChatId, seenAt)
 	if err != nil {
 		return err
 	}
+	if len(changedChatIds) == 0 {
+		return nil
+	}
 
 	event.ChatSeen.Trigger(event.ChatSeenPayload{
+		ConvId:      convId,
 		RequestFrom: matchEntity.RequestFrom,
 		RequestTo:   matchEntity.RequestTo,
+		UpToChatId:  upToChatId,
 		SeenChatIds: changedChatIds,
+		SeenAt:      seenAt,
 	})
+	event.UnreadChanged.Trigger(event.UnreadChangedPayload{UserId: userId})
+	return nil
+}
+
+// UpdateDeliveredChat acknowledge the chats received by the recipient realtime connection
+func (c *Chat) UpdateDeliveredChat(recipientId string, chatIds []string) error {
+	deliveredAt := time.Now()
+	deliveredChats, err := c.chatRepo.UpdateDeliveredChat(recipientId, chatIds, deliveredAt)
+	if err != nil {
+		return err
+	}
+	deliveredByConv := make(map[string]*event.ChatDeliveredPayload)
+	for _, deliveredChat := range deliveredChats {
+		payload, ok := deliveredByConv[deliveredChat.ConversationId]
+		if !ok {
+			payload = &event.ChatDeliveredPayload{
+				ConvId:      deliveredChat.ConversationId,
+				AuthorId:    deliveredChat.Author,
+				RecipientId: recipientId,
+				DeliveredAt: deliveredAt,
+			}
+			deliveredByConv[deliveredChat.ConversationId] = payload
+		}
+		payload.DeliveredChatIds = append(payload.DeliveredChatIds, deliveredChat.Id)
+	}
+	for _, payload := range deliveredByConv {
+		event.ChatDelivered.Trigger(*payload)
+	}
 	return nil
 }
-func (c *Chat) GetMessages(convoId string, filter chat.Filter) ([]chatEntity.DTO, error) {
-	chats, err := c.chatRepo.SelectChat(convoId, filter)
+func (c *Chat) GetMessages(convoId, viewerId string, filter chat.Filter) ([]chatEntity.DTO, error) {
+	chats, err := c.chatRepo.SelectChat(convoId, viewerId, filter)
 	if err != nil {
 		return nil, err
 	}
 
 	chatsDTO := make([]chatEntity.DTO, 0, len(chats))
 	for _, chat := range chats {
-		chatsDTO = append(chatsDTO, c.convertToDTO(chat))
+		chatDTO := c.convertToDTO(chat)
+		if err := c.resolveUrls(&chatDTO); err != nil {
+			return nil, err
+		}
+		chatsDTO = append(chatsDTO, chatDTO)
+	}
+	return chatsDTO, nil
+}
+
+func (c *Chat) GetMessageById(chatId string) (chatEntity.DTO, error) {
+	chat, err := c.chatRepo.SelectChatById(chatId)
+	if err != nil {
+		return chatEntity.DTO{}, err
+	}
+	return c.convertToDTO(chat), nil
+}
+
+// GetMessageContext list the chat with up to size chats around it, oldest first
+func (c *Chat) GetMessageContext(chatId string, size int) ([]chatEntity.DTO, error) {
+	chats, err := c.chatRepo.SelectChatContext(chatId, size)
+	if err != nil {
+		return nil, err
+	}
+	chatsDTO := make([]chatEntity.DTO, 0, len(chats))
+	for _, chat := range chats {
+		chatDTO := c.convertToDTO(chat)
+		if err := c.resolveUrls(&chatDTO); err != nil {
+			return nil, err
+		}
+		chatsDTO = append(chatsDTO, chatDTO)
 	}
 	return chatsDTO, nil
 }
 
-func (c *Chat) DeleteMessagesById(chatId string) error {
-	err := c.chatRepo.DeleteChatById(chatId)
+// EditMessage replace the text of the chat within chatEntity.EditWindow, the previous text is kept as history
+func (c *Chat) EditMessage(convId, chatId, message string) (chatEntity.DTO, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return chatEntity.DTO{}, common.WrapWithNewError(ErrChatNotEditable, http.StatusUnprocessableEntity, "message must not be empty")
+	}
+	current, err := c.chatRepo.SelectChatById(chatId)
+	if err != nil {
+		return chatEntity.DTO{}, err
+	}
+	if current.DeletedAt.Valid {
+		return chatEntity.DTO{}, common.WrapWithNewError(ErrChatNotEditable, http.StatusGone, "chat is already deleted")
+	}
+	if current.Attachment != nil || current.Sticker != nil {
+		return chatEntity.DTO{}, common.WrapWithNewError(ErrChatNotEditable, http.StatusUnprocessableEntity, "only text chat could be edited")
+	}
+	editedAt := time.Now()
+	editableSince := editedAt.Add(-chatEntity.EditWindow)
+	if current.SentAt.Before(editableSince) {
+		return chatEntity.DTO{}, common.WrapWithNewError(ErrChatNotEditable, http.StatusForbidden, "edit window has passed")
+	}
+	if current.Messages == message {
+		return c.convertToDTO(current), nil
+	}
+	matchDAO, err := c.matchRepo.GetMatchById(convId)
+	if err != nil {
+		return chatEntity.DTO{}, err
+	}
+	edited, err := c.chatRepo.UpdateChatMessage(chatId, message, editedAt, editableSince)
+	if err != nil {
+		return chatEntity.DTO{}, err
+	}
+	editedDTO := c.convertToDTO(edited)
+	event.ChatEdited.Trigger(event.ChatEditedPayload{
+		ConvId:      convId,
+		RequestFrom: matchDAO.RequestFrom,
+		RequestTo:   matchDAO.RequestTo,
+		Chat:        editedDTO,
+	})
+	return editedDTO, nil
+}
+
+func (c *Chat) GetMessageHistory(chatId string) ([]chatEntity.History, error) {
+	history, err := c.chatRepo.SelectChatHistory(chatId)
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// DeleteMessagesById delete the chat for everyone, leaving a tombstone in the conversation
+func (c *Chat) DeleteMessagesById(convId, chatId string) error {
+	matchDAO, err := c.matchRepo.GetMatchById(convId)
+	if err != nil {
+		return err
+	}
+	deletedAt := time.Now()
+	err = c.chatRepo.DeleteChatById(chatId, deletedAt)
+	if err != nil {
+		return err
+	}
+	event.ChatDeleted.Trigger(event.ChatDeletedPayload{
+		ConvId:      convId,
+		RequestFrom: matchDAO.RequestFrom,
+		RequestTo:   matchDAO.RequestTo,
+		ChatId:      chatId,
+		DeletedAt:   deletedAt,
+	})
+	// unseen chat counted in the partner unread
+	event.UnreadChanged.Trigger(event.UnreadChangedPayload{UserId: matchDAO.RequestFrom})
+	event.UnreadChanged.Trigger(event.UnreadChangedPayload{UserId: matchDAO.RequestTo})
+	return nil
+}
+
+// AddReaction react the chat with emoji, reacting with the same emoji twice is no-op
+func (c *Chat) AddReaction(convId, chatId, userId, emoji string) error {
+	matchDAO, err := c.matchRepo.GetMatchById(convId)
+	if err != nil {
+		return err
+	}
+	added, err := c.chatRepo.InsertReaction(chatId, userId, emoji)
+	if err != nil {
+		return err
+	}
+	if !added {
+		current, err := c.chatRepo.SelectChatById(chatId)
+		if err != nil {
+			return err
+		}
+		if current.DeletedAt.Valid {
+			return common.WrapWithNewError(ErrChatNotEditable, http.StatusGone, "chat is already deleted")
+		}
+		return nil
+	}
+	event.ReactionChanged.Trigger(event.ReactionChangedPayload{
+		ConvId:      convId,
+		RequestFrom: matchDAO.RequestFrom,
+		RequestTo:   matchDAO.RequestTo,
+		ChatId:      chatId,
+		UserId:      userId,
+		Emoji:       emoji,
+		Added:       true,
+	})
+	return nil
+}
+
+func (c *Chat) RemoveReaction(convId, chatId, userId, emoji string) error {
+	matchDAO, err := c.matchRepo.GetMatchById(convId)
+	if err != nil {
+		return err
+	}
+	err = c.chatRepo.DeleteReaction(chatId, userId, emoji)
 	if err != nil {
 		return err
 	}
+	event.ReactionChanged.Trigger(event.ReactionChangedPayload{
+		ConvId:      convId,
+		RequestFrom: matchDAO.RequestFrom,
+		RequestTo:   matchDAO.RequestTo,
+		ChatId:      chatId,
+		UserId:      userId,
+		Emoji:       emoji,
+		Added:       false,
+	})
+	return nil
+}
+
+// resolveUrls replace the blob key of the attachment, its thumbnail and the sticker with the signed url.
+// the attachment and sticker is copied since the DAO share it
+func (c *Chat) resolveUrls(chat *chatEntity.DTO) error {
+	if chat.Attachment != nil {
+		attachment := *chat.Attachment
+		url, err := c.blobUrl.Resolve(attachment.BlobLink)
+		if err != nil {
+			return err
+		}
+		attachment.BlobLink = url
+		if attachment.ThumbnailLink != nil {
+			thumbnailUrl, err := c.blobUrl.Resolve(*attachment.ThumbnailLink)
+			if err != nil {
+				return err
+			}
+			attachment.ThumbnailLink = &thumbnailUrl
+		}
+		chat.Attachment = &attachment
+	}
+	if chat.Sticker != nil {
+		sticker := *chat.Sticker
+		url, err := c.blobUrl.Resolve(sticker.BlobLink)
+		if err != nil {
+			return err
+		}
+		sticker.BlobLink = url
+		chat.Sticker = &sticker
+	}
 	return nil
 }
+
 func (*Chat) sanitizeChat(chat chatEntity.DAO) []chatEntity.DAO {
 	chat.Messages = strings.TrimSpace(chat.Messages)
 	if chat.Attachment != nil && chat.Messages != "" {
@@ -113,6 +353,8 @@ func (*Chat) convertToDAO(content chatEntity.DTO) chatEntity.DAO {
 		Messages:       content.Messages,
 		SentAt:         content.SentAt,
 		Attachment:     content.Attachment,
+		Sticker:        content.Sticker,
+		Reactions:      content.Reactions,
 	}
 	if content.ReplyTo != nil {
 		chatDAO.ReplyTo = sql.NullString{
@@ -120,12 +362,30 @@ func (*Chat) convertToDAO(content chatEntity.DTO) chatEntity.DAO {
 			String: *content.ReplyTo,
 		}
 	}
+	if content.DeliveredAt != nil {
+		chatDAO.DeliveredAt = sql.NullTime{
+			Valid: true,
+			Time:  *content.DeliveredAt,
+		}
+	}
 	if content.SeenAt != nil {
 		chatDAO.SeenAt = sql.NullTime{
 			Valid: true,
 			Time:  *content.SeenAt,
 		}
 	}
+	if content.EditedAt != nil {
+		chatDAO.EditedAt = sql.NullTime{
+			Valid: true,
+			Time:  *content.EditedAt,
+		}
+	}
+	if content.DeletedAt != nil {
+		chatDAO.DeletedAt = sql.NullTime{
+			Valid: true,
+			Time:  *content.DeletedAt,
+		}
+	}
 
 	return chatDAO
 }
@@ -138,12 +398,24 @@ func (*Chat) convertToDTO(content chatEntity.DAO) chatEntity.DTO {
 		Messages:       content.Messages,
 		SentAt:         content.SentAt,
 		Attachment:     content.Attachment,
+		Sticker:        content.Sticker,
+		Reactions:      content.Reactions,
 	}
 	if content.ReplyTo.Valid {
 		chatDomain.ReplyTo = &content.ReplyTo.String
 	}
+	if content.DeliveredAt.Valid {
+		chatDomain.DeliveredAt = &content.DeliveredAt.Time
+	}
 	if content.SeenAt.Valid {
 		chatDomain.SeenAt = &content.SeenAt.Time
 	}
+	if content.EditedAt.Valid {
+		chatDomain.EditedAt = &content.EditedAt.Time
+	}
+	if content.DeletedAt.Valid {
+		chatDomain.DeletedAt = &content.DeletedAt.Time
+	}
+	chatDomain.Kind = chatEntity.KindOf(chatDomain)
 	return chatDomain
 }
@@ -3,10 +3,12 @@ package service
 import (
 	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/xyedo/blindate/pkg/common"
 	"github.com/xyedo/blindate/pkg/domain/conversation"
 	convEntity "github.com/xyedo/blindate/pkg/domain/conversation/entities"
+	"github.com/xyedo/blindate/pkg/domain/event"
 	"github.com/xyedo/blindate/pkg/domain/match"
 	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
 )
@@ -16,23 +18,28 @@ var (
 	ErrInvalidMatchStatus = errors.New("not yet accepted/revealed in matchId")
 )
 
-func NewConversation(convRepo conversation.Repository, matchRepo match.Repository) *Conversation {
+func NewConversation(convRepo conversation.Repository, matchRepo match.Repository, blobUrl *BlobUrl) *Conversation {
 	return &Conversation{
 		convRepo:  convRepo,
 		matchRepo: matchRepo,
+		blobUrl:   blobUrl,
 	}
 }
 
 type Conversation struct {
 	convRepo  conversation.Repository
 	matchRepo match.Repository
+	blobUrl   *BlobUrl
 }
 
-func (c *Conversation) CreateConversation(matchId string) (string, error) {
+func (c *Conversation) CreateConversation(matchId, userId string) (string, error) {
 	matchDAO, err := c.matchRepo.GetMatchById(matchId)
 	if err != nil {
 		return "", err
 	}
+	if !(matchDAO.RequestFrom == userId || matchDAO.RequestTo == userId) {
+		return "", common.WrapWithNewError(common.ErrAuthorNotValid, http.StatusForbidden, "users not in this match")
+	}
 	if matchDAO.RequestStatus != string(matchEntity.Accepted) {
 		return "", ErrInvalidMatchStatus
 	}
@@ -55,12 +62,16 @@ func (c *Conversation) FindConversationById(matchId string) (convEntity.DTO, err
 		conv.ToUser.FullName = ""
 		conv.ToUser.ProfilePic = ""
 	}
+	if err := c.resolveProfilePics(&conv); err != nil {
+		return convEntity.DTO{}, err
+	}
 
 	return conv, nil
 }
 
-func (c *Conversation) GetConversationByUserId(userId string) ([]convEntity.DTO, error) {
-	convs, err := c.convRepo.SelectConversationByUserId(userId, nil)
+// GetConversationByUserId list the conversation of userId, nil filter list every conversation regardless the inbox state
+func (c *Conversation) GetConversationByUserId(userId string, filter *conversation.Filter) ([]convEntity.DTO, error) {
+	convs, err := c.convRepo.SelectConversationByUserId(userId, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -71,9 +82,49 @@ func (c *Conversation) GetConversationByUserId(userId string) ([]convEntity.DTO,
 			convs[i].ToUser.FullName = ""
 			convs[i].ToUser.ProfilePic = ""
 		}
+		if err := c.resolveProfilePics(&convs[i]); err != nil {
+			return nil, err
+		}
 	}
 	return convs, nil
 }
+
+// resolveProfilePics replace the picture key of both user with the signed url, the hidden picture stay empty
+func (c *Conversation) resolveProfilePics(conv *convEntity.DTO) error {
+	fromUrl, err := c.blobUrl.Resolve(conv.FromUser.ProfilePic)
+	if err != nil {
+		return err
+	}
+	toUrl, err := c.blobUrl.Resolve(conv.ToUser.ProfilePic)
+	if err != nil {
+		return err
+	}
+	conv.FromUser.ProfilePic = fromUrl
+	conv.ToUser.ProfilePic = toUrl
+	return nil
+}
+func (c *Conversation) PutState(convoId, userId string, newState convEntity.NewState) (convEntity.State, error) {
+	state, err := c.convRepo.UpdateState(convoId, userId, convEntity.State{
+		MutedUntil: newState.MutedUntil,
+		Pinned:     *newState.Pinned,
+		Archived:   *newState.Archived,
+	})
+	if err != nil {
+		return convEntity.State{}, err
+	}
+	// muted conversation is excluded from the badge
+	event.UnreadChanged.Trigger(event.UnreadChangedPayload{UserId: userId})
+	return state, nil
+}
+
+func (c *Conversation) GetTotalUnread(userId string) (int, error) {
+	total, err := c.convRepo.SelectTotalUnread(userId)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
 func (c *Conversation) DeleteConversationById(convoId string) error {
 	err := c.convRepo.DeleteConversationById(convoId)
 	if err != nil {
@@ -3,9 +3,9 @@ package service
 import (
 	"fmt"
 	"log"
-	"time"
 
 	"github.com/xyedo/blindate/pkg/domain/event"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
 	websocketEntity "github.com/xyedo/blindate/pkg/domain/ws"
 )
 
@@ -15,20 +15,77 @@ type EventDeps struct {
 	MatchSvc *Match
 	Online   *Online
 	Ws       *Ws
+	BlobGc   *BlobGc
+	Account  *Account
+	Export   *Export
 }
 
 func (d *EventDeps) HandleSeenAtevent(payload event.ChatSeenPayload) {
 	var response websocketEntity.Response
 	response.Action = "update.chat.seenAt"
 	response.Data = map[string]any{
+		"convId":      payload.ConvId,
+		"upTo":        payload.UpToChatId,
 		"seenChatIds": payload.SeenChatIds,
+		"seenAt":      payload.SeenAt,
+	}
+	d.eventWriteJSON(payload.RequestFrom, response)
+	d.eventWriteJSON(payload.RequestTo, response)
+}
+
+func (d *EventDeps) HandleDeliveredAtEvent(payload event.ChatDeliveredPayload) {
+	var response websocketEntity.Response
+	response.Action = "update.chat.deliveredAt"
+	response.Data = map[string]any{
+		"convId":           payload.ConvId,
+		"deliveredChatIds": payload.DeliveredChatIds,
+		"deliveredAt":      payload.DeliveredAt,
+	}
+	d.eventWriteJSON(payload.AuthorId, response)
+	d.eventWriteJSON(payload.RecipientId, response)
+}
+
+func (d *EventDeps) HandleEditedChatEvent(payload event.ChatEditedPayload) {
+	var response websocketEntity.Response
+	response.Action = "update.chat.edited"
+	response.Data = map[string]any{
+		"convId": payload.ConvId,
+		"chat":   payload.Chat,
+	}
+	d.eventWriteJSON(payload.RequestFrom, response)
+	d.eventWriteJSON(payload.RequestTo, response)
+}
+
+func (d *EventDeps) HandleDeletedChatEvent(payload event.ChatDeletedPayload) {
+	var response websocketEntity.Response
+	response.Action = "update.chat.deleted"
+	response.Data = map[string]any{
+		"convId":    payload.ConvId,
+		"chatId":    payload.ChatId,
+		"deletedAt": payload.DeletedAt,
+	}
+	d.eventWriteJSON(payload.RequestFrom, response)
+	d.eventWriteJSON(payload.RequestTo, response)
+}
+
+func (d *EventDeps) HandleReactionChangedEvent(payload event.ReactionChangedPayload) {
+	var response websocketEntity.Response
+	response.Action = "reaction.removed"
+	if payload.Added {
+		response.Action = "reaction.added"
+	}
+	response.Data = map[string]any{
+		"convId": payload.ConvId,
+		"chatId": payload.ChatId,
+		"userId": payload.UserId,
+		"emoji":  payload.Emoji,
 	}
 	d.eventWriteJSON(payload.RequestFrom, response)
 	d.eventWriteJSON(payload.RequestTo, response)
 }
 
 func (d *EventDeps) HandleProfileUpdateEvent(payload event.ProfileUpdatedPayload) {
-	convs, err := d.ConvSvc.GetConversationByUserId(payload.UserId)
+	convs, err := d.ConvSvc.GetConversationByUserId(payload.UserId, nil)
 	if err != nil {
 		log.Println(err)
 		return
@@ -39,21 +96,31 @@ func (d *EventDeps) HandleProfileUpdateEvent(payload event.ProfileUpdatedPayload
 		return
 	}
 	for _, conv := range convs {
-		if conv.FromUser.ID == payload.UserId || conv.ToUser.ID == payload.UserId {
-			continue
+		partnerId := conv.FromUser.ID
+		if partnerId == payload.UserId {
+			partnerId = conv.ToUser.ID
 		}
-
-		var response websocketEntity.Response
-		response.Action = "update.conversation.profile"
-		response.Data = map[string]any{"updatedUser": updatedUser}
-
-		d.eventWriteJSON(conv.FromUser.ID, response)
-		d.eventWriteJSON(conv.ToUser.ID, response)
+		// the partner see the same user as the conversation list, the identity stay hidden until revealed
+		partnerView := map[string]any{
+			"id":    updatedUser.ID,
+			"alias": updatedUser.Alias,
+		}
+		if conv.RevealStatus == string(matchEntity.Accepted) {
+			partnerView["fullName"] = updatedUser.FullName
+			partnerView["profilePicture"] = updatedUser.ProfilePic
+		}
+		d.eventWriteJSON(partnerId, websocketEntity.Response{
+			Action: "update.conversation.profile",
+			Data: map[string]any{
+				"convId":      conv.Id,
+				"updatedUser": partnerView,
+			},
+		})
 	}
 }
 
 func (d *EventDeps) HandleRevealUpdateEvent(payload event.MatchRevealedPayload) {
-	matchEntity, err := d.MatchSvc.GetMatchById(payload.MatchId)
+	matchDTO, err := d.MatchSvc.GetMatchById(payload.MatchId)
 	if err != nil {
 		log.Println(err)
 		return
@@ -61,11 +128,12 @@ func (d *EventDeps) HandleRevealUpdateEvent(payload event.MatchRevealedPayload)
 	response := websocketEntity.Response{
 		Action: fmt.Sprintf("reveal.%s", payload.MatchStatus),
 		Data: map[string]any{
-			"match": matchEntity,
+			"match":   matchDTO,
+			"actorId": payload.ActorId,
 		},
 	}
-	d.eventWriteJSON(matchEntity.RequestFrom, response)
-	d.eventWriteJSON(matchEntity.RequestTo, response)
+	d.eventWriteJSON(matchDTO.RequestFrom, response)
+	d.eventWriteJSON(matchDTO.RequestTo, response)
 
 }
 func (d *EventDeps) HandleCreateChatEvent(payload event.ChatCreatedPayload) {
@@ -85,18 +153,49 @@ func (d *EventDeps) HandleCreateChatEvent(payload event.ChatCreatedPayload) {
 	d.eventWriteJSON(conv.ToUser.ID, resp)
 }
 
-func (d *EventDeps) eventWriteJSON(userId string, resp websocketEntity.Response) {
-	socket, ok := d.Ws.ReverseClient.Get(userId)
-	if !ok {
+func (d *EventDeps) HandlePresenceChangedEvent(payload event.PresenceChangedPayload) {
+	convs, err := d.ConvSvc.GetConversationByUserId(payload.UserId, nil)
+	if err != nil {
+		log.Println(err)
 		return
 	}
-	socket.SetWriteDeadline(time.Now().Add(writeWait))
-	err := socket.WriteJSON(resp)
+	for _, conv := range convs {
+		partnerId := conv.FromUser.ID
+		if partnerId == payload.UserId {
+			partnerId = conv.ToUser.ID
+		}
+		presence, err := d.Online.GetPresence(partnerId, payload.UserId, true)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		d.eventWriteJSON(partnerId, websocketEntity.Response{
+			Action: "update.conversation.presence",
+			Data: map[string]any{
+				"convId":   conv.Id,
+				"presence": presence,
+			},
+		})
+	}
+}
+
+func (d *EventDeps) HandleUnreadChangedEvent(payload event.UnreadChangedPayload) {
+	total, err := d.ConvSvc.GetTotalUnread(payload.UserId)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	d.eventWriteJSON(payload.UserId, websocketEntity.Response{
+		Action: "update.conversation.unread",
+		Data: map[string]any{
+			"totalUnread": total,
+		},
+	})
+}
+
+func (d *EventDeps) eventWriteJSON(userId string, resp websocketEntity.Response) {
+	err := d.Ws.Send(userId, resp)
 	if err != nil {
 		log.Println("webscoket err", err)
-		socket.Close()
-		d.Ws.Clients.Delete(socket)
-		d.Ws.ReverseClient.Delete(userId)
-		d.Online.PutOnline(userId, false)
 	}
 }
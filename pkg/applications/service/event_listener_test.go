@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	convEntity "github.com/xyedo/blindate/pkg/domain/conversation/entities"
+	"github.com/xyedo/blindate/pkg/domain/event"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+	websocketEntity "github.com/xyedo/blindate/pkg/domain/ws"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+// recordingClient keep every response written to it
+type recordingClient struct {
+	key       string
+	responses *[]websocketEntity.Response
+}
+
+func (r recordingClient) Key() string { return r.key }
+func (r recordingClient) WriteJSON(v any) error {
+	*r.responses = append(*r.responses, v.(websocketEntity.Response))
+	return nil
+}
+func (r recordingClient) SetWriteDeadline(time.Time) error { return nil }
+func (r recordingClient) Close() error                     { return nil }
+
+func Test_HandleProfileUpdateEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userId := util.RandomUUID()
+	revealedId := util.RandomUUID()
+	hiddenId := util.RandomUUID()
+
+	revealedConv := convEntity.DTO{Id: util.RandomUUID(), RevealStatus: string(matchEntity.Accepted)}
+	revealedConv.FromUser.ID = userId
+	revealedConv.ToUser.ID = revealedId
+	hiddenConv := convEntity.DTO{Id: util.RandomUUID(), RevealStatus: string(matchEntity.Requested)}
+	hiddenConv.FromUser.ID = hiddenId
+	hiddenConv.ToUser.ID = userId
+
+	convRepo := mockrepo.NewMockConversation(ctrl)
+	convRepo.EXPECT().SelectConversationByUserId(gomock.Eq(userId), gomock.Nil()).Times(1).
+		Return([]convEntity.DTO{revealedConv, hiddenConv}, nil)
+	userRepo := mockrepo.NewMockUser(ctrl)
+	userRepo.EXPECT().GetUserById(gomock.Eq(userId)).Times(1).Return(userEntity.FullDTO{
+		ID:       userId,
+		Alias:    "bob",
+		FullName: "Uncle Bob",
+		Email:    "uncle.bob@cool.com",
+	}, nil)
+	userRepo.EXPECT().SelectProfilePicture(gomock.Eq(userId), gomock.Nil()).Times(1).Return(nil, nil)
+
+	ws := NewWs(nil)
+	received := map[string]*[]websocketEntity.Response{}
+	for _, id := range []string{userId, revealedId, hiddenId} {
+		responses := &[]websocketEntity.Response{}
+		received[id] = responses
+		client := recordingClient{key: util.RandomUUID(), responses: responses}
+		ws.Clients.Set(client.Key(), id)
+		ws.ReverseClient.Set(id, client)
+	}
+	deps := EventDeps{
+		UserSvc: NewUser(userRepo, nil),
+		ConvSvc: NewConversation(convRepo, nil, nil),
+		Ws:      ws,
+	}
+
+	deps.HandleProfileUpdateEvent(event.ProfileUpdatedPayload{UserId: userId})
+
+	assert.Empty(t, *received[userId])
+
+	require.Len(t, *received[revealedId], 1)
+	revealedResp := (*received[revealedId])[0]
+	assert.Equal(t, "update.conversation.profile", revealedResp.Action)
+	assert.Equal(t, revealedConv.Id, revealedResp.Data["convId"])
+	revealedUser := revealedResp.Data["updatedUser"].(map[string]any)
+	assert.Equal(t, userId, revealedUser["id"])
+	assert.Equal(t, "Uncle Bob", revealedUser["fullName"])
+	assert.NotContains(t, revealedUser, "email")
+
+	require.Len(t, *received[hiddenId], 1)
+	hiddenResp := (*received[hiddenId])[0]
+	assert.Equal(t, "update.conversation.profile", hiddenResp.Action)
+	assert.Equal(t, hiddenConv.Id, hiddenResp.Data["convId"])
+	hiddenUser := hiddenResp.Data["updatedUser"].(map[string]any)
+	assert.Equal(t, "bob", hiddenUser["alias"])
+	assert.NotContains(t, hiddenUser, "fullName")
+	assert.NotContains(t, hiddenUser, "profilePicture")
+}
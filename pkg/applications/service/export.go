@@ -0,0 +1,394 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/xyedo/blindate/pkg/common"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
+	"github.com/xyedo/blindate/pkg/domain/export"
+	exportEntity "github.com/xyedo/blindate/pkg/domain/export/entities"
+	"github.com/xyedo/blindate/pkg/domain/interest"
+	"github.com/xyedo/blindate/pkg/domain/user"
+)
+
+// exportPrefix is not collected by the orphaned blob collector, the exporter delete the expired archive itself
+const exportPrefix = "data-export"
+
+const (
+	// exportBatchSize bound the archive built in one run
+	exportBatchSize = 10
+	// exportStaleAfter is when the processing job is claimed again, eg: the server restarted in the middle of it
+	exportStaleAfter = time.Hour
+)
+
+// NewExport build the personal data archive, ttl is how long the archive is downloadable
+func NewExport(exportRepo export.Repository, userRepo user.Repository, basicInfoSvc *BasicInfo, interestRepo interest.Repository, locationSvc *Location, attachment Attachment, ttl time.Duration) *Export {
+	return &Export{
+		exportRepo:   exportRepo,
+		userRepo:     userRepo,
+		basicInfoSvc: basicInfoSvc,
+		interestRepo: interestRepo,
+		locationSvc:  locationSvc,
+		attachment:   attachment,
+		ttl:          ttl,
+	}
+}
+
+type Export struct {
+	exportRepo   export.Repository
+	userRepo     user.Repository
+	basicInfoSvc *BasicInfo
+	interestRepo interest.Repository
+	locationSvc  *Location
+	attachment   Attachment
+	ttl          time.Duration
+}
+
+// RequestExport queue the export, the archive is built by the exporter
+func (e *Export) RequestExport(userId string) (exportEntity.Job, error) {
+	job := exportEntity.Job{
+		UserId: userId,
+	}
+	err := e.exportRepo.InsertJob(&job)
+	if err != nil {
+		return exportEntity.Job{}, err
+	}
+	return job, nil
+}
+
+func (e *Export) GetExports(userId string) ([]exportEntity.Job, error) {
+	jobs, err := e.exportRepo.SelectJobsByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs {
+		err = e.signDownload(&jobs[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return jobs, nil
+}
+
+// GetExportById hide the export of another user as not found
+func (e *Export) GetExportById(userId, exportId string) (exportEntity.Job, error) {
+	job, err := e.exportRepo.SelectJobById(exportId)
+	if err != nil {
+		return exportEntity.Job{}, err
+	}
+	if job.UserId != userId {
+		return exportEntity.Job{}, common.WrapErrorWithMsg(errors.New("export of another user"), common.ErrResourceNotFound, "export not found")
+	}
+	err = e.signDownload(&job)
+	if err != nil {
+		return exportEntity.Job{}, err
+	}
+	return job, nil
+}
+
+func (e *Export) signDownload(job *exportEntity.Job) error {
+	if job.Status != exportEntity.StatusReady || job.BlobKey == nil {
+		return nil
+	}
+	url, err := e.attachment.GetPresignedUrl(*job.BlobKey)
+	if err != nil {
+		return err
+	}
+	job.DownloadUrl = url
+	return nil
+}
+
+// Process build the queued archive then delete the expired one
+func (e *Export) Process() (exportEntity.Report, error) {
+	var report exportEntity.Report
+	for i := 0; i < exportBatchSize; i++ {
+		now := time.Now()
+		job, err := e.exportRepo.ClaimJob(now, now.Add(-exportStaleAfter))
+		if err != nil {
+			if errors.Is(err, common.ErrResourceNotFound) {
+				break
+			}
+			return report, err
+		}
+		err = e.build(&job)
+		completedAt := time.Now()
+		job.CompletedAt = &completedAt
+		if err != nil {
+			log.Println("export build err", job.Id, err)
+			reason := "the export could not be completed, please request a new one"
+			job.Status = exportEntity.StatusFailed
+			job.Error = &reason
+			report.Failed++
+		} else {
+			expiresAt := completedAt.Add(e.ttl)
+			job.Status = exportEntity.StatusReady
+			job.ExpiresAt = &expiresAt
+			report.Ready++
+		}
+		err = e.exportRepo.UpdateJob(job)
+		if err != nil {
+			// the job is gone, eg: the account is purged, so the archive is never downloaded
+			if job.BlobKey != nil {
+				if delErr := e.attachment.DeleteBlob(*job.BlobKey); delErr != nil {
+					log.Println("export delete err", *job.BlobKey, delErr)
+				}
+			}
+			return report, err
+		}
+	}
+
+	expired, err := e.exportRepo.SelectExpiredJobs(time.Now(), exportBatchSize)
+	if err != nil {
+		return report, err
+	}
+	for _, job := range expired {
+		if job.BlobKey != nil {
+			if err := e.attachment.DeleteBlob(*job.BlobKey); err != nil {
+				log.Println("export delete err", *job.BlobKey, err)
+				continue
+			}
+		}
+		job.Status = exportEntity.StatusExpired
+		job.BlobKey = nil
+		job.SizeBytes = nil
+		err = e.exportRepo.UpdateJob(job)
+		if err != nil {
+			return report, err
+		}
+		report.Expired++
+	}
+	return report, nil
+}
+
+func (e *Export) RunExporter(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		report, err := e.Process()
+		if err != nil {
+			log.Println("exporter err", err)
+		}
+		if report.Ready+report.Failed+report.Expired > 0 {
+			log.Printf("exporter built %d archive, failed %d, expired %d", report.Ready, report.Failed, report.Expired)
+		}
+	}
+}
+
+// build write the archive to a temporary file first, the upload need its length
+func (e *Export) build(job *exportEntity.Job) error {
+	tmp, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	err = e.writeArchive(tmp, *job)
+	if err != nil {
+		return err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	key, err := e.attachment.UploadBlob(tmp, attachmentEntity.Uploader{
+		Length:      size,
+		ContentType: "application/zip",
+		Prefix:      exportPrefix,
+		Ext:         ".zip",
+	})
+	if err != nil {
+		return err
+	}
+	job.BlobKey = &key
+	job.SizeBytes = &size
+	return nil
+}
+
+// writeArchive put every json first then the manifest, the profile section the user never filled is left out
+func (e *Export) writeArchive(w io.Writer, job exportEntity.Job) error {
+	archive := &exportArchive{
+		zw:         zip.NewWriter(w),
+		attachment: e.attachment,
+		added:      make(map[string]bool),
+		manifest: exportEntity.Manifest{
+			ExportId:     job.Id,
+			UserId:       job.UserId,
+			GeneratedAt:  time.Now(),
+			Files:        make([]exportEntity.ManifestFile, 0),
+			MissingMedia: make([]string, 0),
+		},
+	}
+
+	found, err := e.userRepo.GetUserById(job.UserId)
+	if err != nil {
+		return err
+	}
+	err = archive.addJSON("user.json", "account of the user", 1, found)
+	if err != nil {
+		return err
+	}
+
+	pictures, err := e.userRepo.SelectProfilePicture(job.UserId, nil)
+	if err != nil && !errors.Is(err, common.ErrResourceNotFound) {
+		return err
+	}
+	for i := range pictures {
+		for _, link := range []*string{&pictures[i].PictureLink, &pictures[i].MediumLink, &pictures[i].ThumbnailLink} {
+			*link, err = archive.addMedia(*link, "profile picture "+pictures[i].Id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	err = archive.addJSON("profile_pictures.json", "profile pictures, the link is the path inside the archive", len(pictures), pictures)
+	if err != nil {
+		return err
+	}
+
+	basicInfo, err := e.basicInfoSvc.GetBasicInfoByUserId(job.UserId)
+	if err == nil {
+		err = archive.addJSON("basic_info.json", "basic info of the profile", 1, basicInfo)
+	}
+	if err != nil && !errors.Is(err, common.ErrResourceNotFound) {
+		return err
+	}
+
+	interests, err := e.interestRepo.GetInterest(job.UserId)
+	if err == nil {
+		err = archive.addJSON("interests.json", "bio, hobbies, movie series, travels and sports", 1, interests)
+	}
+	if err != nil && !errors.Is(err, common.ErrResourceNotFound) {
+		return err
+	}
+
+	location, err := e.locationSvc.GetLocation(job.UserId)
+	if err == nil {
+		err = archive.addJSON("location.json", "the latest location, the previous location is not kept", 1, location)
+	}
+	if err != nil && !errors.Is(err, common.ErrResourceNotFound) {
+		return err
+	}
+
+	matches, err := e.exportRepo.SelectMatches(job.UserId)
+	if err != nil {
+		return err
+	}
+	err = archive.addJSON("matches.json", "match requested by or to the user", len(matches), matches)
+	if err != nil {
+		return err
+	}
+
+	convs, err := e.exportRepo.SelectConversations(job.UserId)
+	if err != nil {
+		return err
+	}
+	err = archive.addJSON("conversations.json", "conversation of the user and its settings", len(convs), convs)
+	if err != nil {
+		return err
+	}
+
+	chats, err := e.exportRepo.SelectAuthoredChats(job.UserId)
+	if err != nil {
+		return err
+	}
+	for i := range chats {
+		if chats[i].BlobKey == nil {
+			continue
+		}
+		chats[i].MediaPath, err = archive.addMedia(*chats[i].BlobKey, "attachment of chat "+chats[i].Id)
+		if err != nil {
+			return err
+		}
+	}
+	err = archive.addJSON("chats.json", "chat authored by the user, the attachment is at mediaPath", len(chats), chats)
+	if err != nil {
+		return err
+	}
+
+	err = archive.writeJSON("manifest.json", archive.manifest)
+	if err != nil {
+		return err
+	}
+	return archive.zw.Close()
+}
+
+type exportArchive struct {
+	zw         *zip.Writer
+	attachment Attachment
+	manifest   exportEntity.Manifest
+	// added dedupe the media, eg: the rendition of the old profile picture fallback to the same blob
+	added map[string]bool
+}
+
+func (a *exportArchive) addJSON(path, description string, records int, v any) error {
+	err := a.writeJSON(path, v)
+	if err != nil {
+		return err
+	}
+	a.manifest.Files = append(a.manifest.Files, exportEntity.ManifestFile{
+		Path:        path,
+		Description: description,
+		Records:     records,
+	})
+	return nil
+}
+
+func (a *exportArchive) writeJSON(path string, v any) error {
+	f, err := a.zw.Create(path)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// addMedia return the path of the blob inside the archive, the blob failed to be fetched is listed in the manifest
+// instead of failing the whole export
+func (a *exportArchive) addMedia(key, description string) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+	path := "media/" + key
+	if a.added[key] {
+		return path, nil
+	}
+	blob, err := a.attachment.ReadBlob(key)
+	if err != nil {
+		log.Println("export read blob err", key, err)
+		a.manifest.MissingMedia = append(a.manifest.MissingMedia, key)
+		return "", nil
+	}
+	defer blob.Close()
+
+	// the media is already compressed
+	f, err := a.zw.CreateHeader(&zip.FileHeader{
+		Name:     path,
+		Method:   zip.Store,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, blob)
+	if err != nil {
+		return "", err
+	}
+	a.added[key] = true
+	a.manifest.Files = append(a.manifest.Files, exportEntity.ManifestFile{
+		Path:        path,
+		Description: description,
+	})
+	return path, nil
+}
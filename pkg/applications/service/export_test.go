@@ -0,0 +1,188 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/common"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
+	basicInfoEntity "github.com/xyedo/blindate/pkg/domain/basicinfo/entities"
+	exportEntity "github.com/xyedo/blindate/pkg/domain/export/entities"
+	interestEntity "github.com/xyedo/blindate/pkg/domain/interest/entities"
+	locationEntity "github.com/xyedo/blindate/pkg/domain/location/entities"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+)
+
+func Test_ExportProcess(t *testing.T) {
+	ttl := 7 * 24 * time.Hour
+	notFound := common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound)
+	newExport := func(ctrl *gomock.Controller, m *memoryAttachment) (*Export, *mockrepo.MockExport, *mockrepo.MockUser) {
+		exportRepo := mockrepo.NewMockExport(ctrl)
+		userRepo := mockrepo.NewMockUser(ctrl)
+		basicInfoRepo := mockrepo.NewMockBasicInfo(ctrl)
+		basicInfoRepo.EXPECT().GetBasicInfoByUserId(gomock.Any()).AnyTimes().Return(basicInfoEntity.DAO{}, notFound)
+		interestRepo := mockrepo.NewMockInterest(ctrl)
+		interestRepo.EXPECT().GetInterest(gomock.Any()).AnyTimes().Return(interestEntity.FullDTO{}, notFound)
+		locationRepo := mockrepo.NewMockLocation(ctrl)
+		locationRepo.EXPECT().GetLocationByUserId(gomock.Any()).AnyTimes().
+			Return(locationEntity.DAO{UserId: "user", Geog: "POINT(1.5 2.5)"}, nil)
+		return NewExport(exportRepo, userRepo, NewBasicInfo(basicInfoRepo), interestRepo, NewLocation(locationRepo), m, ttl), exportRepo, userRepo
+	}
+
+	t.Run("build the archive", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		m := NewMemoryAttachment(NewHmacUrl("https://api.test/blobs/", []byte("secret"), time.Minute))
+		picture, err := m.UploadBlob(strings.NewReader("picture"), attachmentEntity.Uploader{Prefix: "profile-picture", Ext: ".jpg"})
+		require.NoError(t, err)
+		media, err := m.UploadBlob(strings.NewReader("media"), attachmentEntity.Uploader{Prefix: "chat-attachment", Ext: ".png"})
+		require.NoError(t, err)
+		missing := "chat-attachment/missing.png"
+
+		svc, exportRepo, userRepo := newExport(ctrl, m)
+		job := exportEntity.Job{Id: "export", UserId: "user", Status: exportEntity.StatusProcessing}
+		gomock.InOrder(
+			exportRepo.EXPECT().ClaimJob(gomock.Any(), gomock.Any()).Times(1).Return(job, nil),
+			exportRepo.EXPECT().ClaimJob(gomock.Any(), gomock.Any()).Times(1).Return(exportEntity.Job{}, notFound),
+		)
+		userRepo.EXPECT().GetUserById(gomock.Eq("user")).Times(1).Return(userEntity.FullDTO{ID: "user", Password: "hashed"}, nil)
+		userRepo.EXPECT().SelectProfilePicture(gomock.Eq("user"), gomock.Nil()).Times(1).
+			Return([]userEntity.ProfilePic{{Id: "1", PictureLink: picture, MediumLink: picture, ThumbnailLink: picture}}, nil)
+		exportRepo.EXPECT().SelectMatches(gomock.Eq("user")).Times(1).Return([]exportEntity.Match{{Id: "match"}}, nil)
+		exportRepo.EXPECT().SelectConversations(gomock.Eq("user")).Times(1).Return([]exportEntity.Conversation{{Id: "match"}}, nil)
+		exportRepo.EXPECT().SelectAuthoredChats(gomock.Eq("user")).Times(1).Return([]exportEntity.Chat{
+			{Id: "text", Messages: "hello"},
+			{Id: "media", BlobKey: &media},
+			{Id: "missing", BlobKey: &missing},
+		}, nil)
+		var updated exportEntity.Job
+		exportRepo.EXPECT().UpdateJob(gomock.Any()).Times(1).DoAndReturn(func(job exportEntity.Job) error {
+			updated = job
+			return nil
+		})
+		exportRepo.EXPECT().SelectExpiredJobs(gomock.Any(), gomock.Any()).Times(1).Return([]exportEntity.Job{}, nil)
+
+		report, err := svc.Process()
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Ready)
+		assert.Equal(t, exportEntity.StatusReady, updated.Status)
+		require.NotNil(t, updated.ExpiresAt)
+		assert.WithinDuration(t, time.Now().Add(ttl), *updated.ExpiresAt, time.Minute)
+		require.NotNil(t, updated.BlobKey)
+		assert.True(t, strings.HasPrefix(*updated.BlobKey, exportPrefix+"/"))
+
+		files := readArchive(t, m, *updated.BlobKey)
+		assert.NotContains(t, files, "basic_info.json", "the profile section never filled is left out")
+		assert.Contains(t, files, "location.json")
+		assert.Equal(t, "picture", files["media/"+picture])
+		assert.Equal(t, "media", files["media/"+media])
+		assert.NotContains(t, files["user.json"], "hashed")
+
+		var manifest exportEntity.Manifest
+		require.NoError(t, json.Unmarshal([]byte(files["manifest.json"]), &manifest))
+		assert.Equal(t, "export", manifest.ExportId)
+		assert.Equal(t, []string{missing}, manifest.MissingMedia)
+
+		var chats []exportEntity.Chat
+		require.NoError(t, json.Unmarshal([]byte(files["chats.json"]), &chats))
+		require.Len(t, chats, 3)
+		assert.Empty(t, chats[0].MediaPath)
+		assert.Equal(t, "media/"+media, chats[1].MediaPath)
+		assert.Empty(t, chats[2].MediaPath)
+	})
+	t.Run("failed export", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		m := NewMemoryAttachment(NewHmacUrl("https://api.test/blobs/", []byte("secret"), time.Minute))
+		svc, exportRepo, userRepo := newExport(ctrl, m)
+		gomock.InOrder(
+			exportRepo.EXPECT().ClaimJob(gomock.Any(), gomock.Any()).Times(1).Return(exportEntity.Job{Id: "export", UserId: "user"}, nil),
+			exportRepo.EXPECT().ClaimJob(gomock.Any(), gomock.Any()).Times(1).Return(exportEntity.Job{}, notFound),
+		)
+		userRepo.EXPECT().GetUserById(gomock.Eq("user")).Times(1).Return(userEntity.FullDTO{}, errors.New("connection reset"))
+		exportRepo.EXPECT().UpdateJob(gomock.Any()).Times(1).DoAndReturn(func(job exportEntity.Job) error {
+			assert.Equal(t, exportEntity.StatusFailed, job.Status)
+			assert.NotNil(t, job.Error)
+			assert.Nil(t, job.BlobKey)
+			return nil
+		})
+		exportRepo.EXPECT().SelectExpiredJobs(gomock.Any(), gomock.Any()).Times(1).Return([]exportEntity.Job{}, nil)
+
+		report, err := svc.Process()
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Failed)
+		assert.Empty(t, m.blobs)
+	})
+	t.Run("expired archive is deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		m := NewMemoryAttachment(NewHmacUrl("https://api.test/blobs/", []byte("secret"), time.Minute))
+		key, err := m.UploadBlob(strings.NewReader("zip"), attachmentEntity.Uploader{Prefix: exportPrefix, Ext: ".zip"})
+		require.NoError(t, err)
+
+		svc, exportRepo, _ := newExport(ctrl, m)
+		exportRepo.EXPECT().ClaimJob(gomock.Any(), gomock.Any()).Times(1).Return(exportEntity.Job{}, notFound)
+		exportRepo.EXPECT().SelectExpiredJobs(gomock.Any(), gomock.Any()).Times(1).
+			Return([]exportEntity.Job{{Id: "export", UserId: "user", Status: exportEntity.StatusReady, BlobKey: &key}}, nil)
+		exportRepo.EXPECT().UpdateJob(gomock.Any()).Times(1).DoAndReturn(func(job exportEntity.Job) error {
+			assert.Equal(t, exportEntity.StatusExpired, job.Status)
+			assert.Nil(t, job.BlobKey)
+			return nil
+		})
+
+		report, err := svc.Process()
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Expired)
+		assert.NotContains(t, m.blobs, key)
+	})
+}
+
+func Test_GetExportById(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := NewMemoryAttachment(NewHmacUrl("https://api.test/blobs/", []byte("secret"), time.Minute))
+	key := exportPrefix + "/archive.zip"
+	exportRepo := mockrepo.NewMockExport(ctrl)
+	exportRepo.EXPECT().SelectJobById(gomock.Eq("export")).AnyTimes().
+		Return(exportEntity.Job{Id: "export", UserId: "user", Status: exportEntity.StatusReady, BlobKey: &key}, nil)
+	svc := NewExport(exportRepo, nil, nil, nil, nil, m, time.Hour)
+
+	job, err := svc.GetExportById("user", "export")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(job.DownloadUrl, "https://api.test/blobs/"+key))
+
+	_, err = svc.GetExportById("another", "export")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, common.ErrResourceNotFound)
+}
+
+func readArchive(t *testing.T, m *memoryAttachment, key string) map[string]string {
+	blob, err := m.ReadBlob(key)
+	require.NoError(t, err)
+	defer blob.Close()
+	content, err := io.ReadAll(blob)
+	require.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	files := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = string(data)
+	}
+	return files
+}
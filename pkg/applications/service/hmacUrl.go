@@ -0,0 +1,61 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xyedo/blindate/pkg/common"
+)
+
+var ErrBlobUrlInvalid = errors.New("blob url is invalid")
+
+// NewHmacUrl sign the blob url served by the app itself, baseUrl is the public url of the blob route
+func NewHmacUrl(baseUrl string, secret []byte, expires time.Duration) *HmacUrl {
+	return &HmacUrl{
+		baseUrl: strings.TrimSuffix(baseUrl, "/"),
+		secret:  secret,
+		expires: expires,
+	}
+}
+
+type HmacUrl struct {
+	baseUrl string
+	secret  []byte
+	expires time.Duration
+}
+
+func (h *HmacUrl) Sign(key string) string {
+	expiresAt := strconv.FormatInt(time.Now().Add(h.expires).Unix(), 10)
+	query := url.Values{
+		"expires":   {expiresAt},
+		"signature": {h.mac(key, expiresAt)},
+	}
+	return h.baseUrl + "/" + (&url.URL{Path: key}).EscapedPath() + "?" + query.Encode()
+}
+
+func (h *HmacUrl) Verify(key, expires, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return common.WrapWithNewError(ErrBlobUrlInvalid, http.StatusForbidden, "blob url is invalid")
+	}
+	if !hmac.Equal([]byte(h.mac(key, expires)), []byte(signature)) {
+		return common.WrapWithNewError(ErrBlobUrlInvalid, http.StatusForbidden, "blob url is invalid")
+	}
+	if time.Now().Unix() > expiresAt {
+		return common.WrapWithNewError(ErrBlobUrlInvalid, http.StatusForbidden, "blob url is expired")
+	}
+	return nil
+}
+
+func (h *HmacUrl) mac(key, expiresAt string) string {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(key + "\n" + expiresAt))
+	return hex.EncodeToString(mac.Sum(nil))
+}
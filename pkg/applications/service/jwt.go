@@ -7,10 +7,12 @@ import (
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/xyedo/blindate/pkg/common"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
 )
 
 type customClaims struct {
-	CredentialId string `json:"credId,omitempty"`
+	CredentialId string          `json:"credId,omitempty"`
+	Role         userEntity.Role `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -30,29 +32,43 @@ type Jwt struct {
 	refreshExpires string
 }
 
-func (j *Jwt) GenerateAccessToken(id string) (string, error) {
-	return generateToken(id, j.accessSecret, j.accessExpires)
+// GenerateAccessToken carry the role so the route could be authorized without hitting the database,
+// the changed role is picked up on the next refresh
+func (j *Jwt) GenerateAccessToken(id string, role userEntity.Role) (string, error) {
+	return generateToken(id, role, j.accessSecret, j.accessExpires)
 }
 
 func (j *Jwt) GenerateRefreshToken(id string) (string, error) {
-	return generateToken(id, j.refreshSecret, j.refreshExpires)
+	return generateToken(id, "", j.refreshSecret, j.refreshExpires)
 }
 
 func (j *Jwt) ValidateRefreshToken(token string) (string, error) {
-	return validateToken(token, j.refreshSecret)
+	claims, err := validateToken(token, j.refreshSecret)
+	if err != nil {
+		return "", err
+	}
+	return claims.CredentialId, nil
 
 }
 
-func (j *Jwt) ValidateAccessToken(token string) (string, error) {
-	return validateToken(token, j.accessSecret)
+// ValidateAccessToken return the user id and role, token issued before the role is introduced is a plain user
+func (j *Jwt) ValidateAccessToken(token string) (string, userEntity.Role, error) {
+	claims, err := validateToken(token, j.accessSecret)
+	if err != nil {
+		return "", "", err
+	}
+	if claims.Role == "" {
+		return claims.CredentialId, userEntity.RoleUser, nil
+	}
+	return claims.CredentialId, claims.Role, nil
 }
 
-func generateToken(id, secret string, expires string) (string, error) {
+func generateToken(id string, role userEntity.Role, secret string, expires string) (string, error) {
 	duration, err := time.ParseDuration(expires)
 	if err != nil {
 		panic(err)
 	}
-	claims := generateCustomClaims(id, duration)
+	claims := generateCustomClaims(id, role, duration)
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	encodedToken, err := token.SignedString([]byte(secret))
 	if err != nil {
@@ -61,7 +77,7 @@ func generateToken(id, secret string, expires string) (string, error) {
 	return encodedToken, nil
 }
 
-func validateToken(token, secret string) (string, error) {
+func validateToken(token, secret string) (*customClaims, error) {
 	decodedToken, err := jwt.ParseWithClaims(token, &customClaims{}, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, common.ErrNotMatchCredential
@@ -72,22 +88,23 @@ func validateToken(token, secret string) (string, error) {
 		var jwtErr *jwt.ValidationError
 		if errors.As(err, &jwtErr) {
 			if jwtErr.Errors == jwt.ValidationErrorExpired {
-				return "", common.WrapWithNewError(err, http.StatusUnauthorized, "token is expired, please auth again!")
+				return nil, common.WrapWithNewError(err, http.StatusUnauthorized, "token is expired, please auth again!")
 			}
 		}
-		return "", common.WrapError(err, common.ErrNotMatchCredential)
+		return nil, common.WrapError(err, common.ErrNotMatchCredential)
 	}
 	claims, ok := decodedToken.Claims.(*customClaims)
 	if !ok || !decodedToken.Valid {
-		return "", common.ErrNotMatchCredential
+		return nil, common.ErrNotMatchCredential
 	}
 
-	return claims.CredentialId, nil
+	return claims, nil
 }
 
-func generateCustomClaims(id string, duration time.Duration) customClaims {
+func generateCustomClaims(id string, role userEntity.Role, duration time.Duration) customClaims {
 	return customClaims{
 		CredentialId: id,
+		Role:         role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
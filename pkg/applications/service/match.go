@@ -1,23 +1,38 @@
 package service
 
 import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
 	"github.com/xyedo/blindate/pkg/common"
-	"github.com/xyedo/blindate/pkg/domain/event"
+	"github.com/xyedo/blindate/pkg/domain/block"
 	"github.com/xyedo/blindate/pkg/domain/location"
 	"github.com/xyedo/blindate/pkg/domain/match"
 	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
 )
 
-func NewMatch(matchRepo match.Repository, locationRepo location.Repository) *Match {
+// ErrNotMatchActor is the participant changing the match on behalf of the other
+var ErrNotMatchActor = errors.New("not the actor of the match transition")
+
+// revealExpireBatchSize bound the reveal expired in one run
+const revealExpireBatchSize = 100
+
+func NewMatch(matchRepo match.Repository, locationRepo location.Repository, blockRepo block.Repository, reveal matchEntity.RevealPolicy) *Match {
 	return &Match{
 		matchRepo:    matchRepo,
 		locationRepo: locationRepo,
+		blockRepo:    blockRepo,
+		reveal:       reveal,
 	}
 }
 
 type Match struct {
 	matchRepo    match.Repository
 	locationRepo location.Repository
+	blockRepo    block.Repository
+	reveal       matchEntity.RevealPolicy
 }
 
 func (m *Match) FindUserToMatch(userId string) ([]matchEntity.UserDTO, error) {
@@ -36,6 +51,13 @@ func (m *Match) FindUserToMatch(userId string) ([]matchEntity.UserDTO, error) {
 	return toUsers, nil
 }
 func (m *Match) PostNewMatch(fromUserId, toUserId string, matchStatus matchEntity.Status) (string, error) {
+	blocked, err := m.blockRepo.IsBlocked(fromUserId, toUserId)
+	if err != nil {
+		return "", err
+	}
+	if blocked {
+		return "", common.WrapWithNewError(ErrUserBlocked, http.StatusForbidden, "could not match with this user")
+	}
 	id, err := m.matchRepo.InsertNewMatch(fromUserId, toUserId, matchStatus)
 	if err != nil {
 		return "", err
@@ -43,8 +65,8 @@ func (m *Match) PostNewMatch(fromUserId, toUserId string, matchStatus matchEntit
 	return id, nil
 
 }
-func (m *Match) GetMatchReqToUserId(userId string) ([]matchEntity.FullUserDTO, error) {
-	matcheds, err := m.matchRepo.SelectMatchReqToUserId(userId)
+func (m *Match) GetMatchesByUserId(userId string, filter match.Filter) ([]matchEntity.FullUserDTO, error) {
+	matcheds, err := m.matchRepo.SelectMatchesByUserId(userId, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -52,75 +74,83 @@ func (m *Match) GetMatchReqToUserId(userId string) ([]matchEntity.FullUserDTO, e
 	return matcheds, nil
 }
 
-func (m *Match) RequestChange(matchId string, matchStatus matchEntity.Status) error {
-	matchDAO, err := m.GetMatchById(matchId)
+// RequestChange move the match request, see requestMachine for who could trigger which transition
+func (m *Match) RequestChange(userId, matchId string, matchStatus matchEntity.Status) error {
+	matchDAO, err := m.getParticipantMatch(userId, matchId)
 	if err != nil {
 		return err
 	}
-	switch matchStatus {
-	case matchEntity.Requested:
-		if matchDAO.RequestStatus != string(matchEntity.Unknown) {
-			return ErrInvalidMatchStatus
-		}
-		matchDAO.RequestStatus = string(matchEntity.Requested)
-	case matchEntity.Declined:
-		matchDAO.RequestStatus = string(matchEntity.Declined)
-	case matchEntity.Accepted:
-		if matchDAO.RequestStatus != string(matchEntity.Requested) {
-			return ErrInvalidMatchStatus
+	return m.transition(requestMachine, userId, matchDAO, matchStatus)
+}
+
+// RevealChange move the reveal of the accepted match, see revealMachine. it return the status the reveal moved to,
+// on mutual reveal the request of the second participant accept it
+func (m *Match) RevealChange(userId, matchId string, matchStatus matchEntity.Status) (matchEntity.Status, error) {
+	matchDAO, err := m.getParticipantMatch(userId, matchId)
+	if err != nil {
+		return "", err
+	}
+	if m.reveal.Mutual {
+		switch {
+		case matchStatus == matchEntity.Accepted:
+			return "", common.WrapWithNewError(ErrInvalidMatchStatus, http.StatusUnprocessableEntity, "both participants must request the reveal")
+		case matchStatus == matchEntity.Requested &&
+			matchDAO.RevealStatus == string(matchEntity.Requested) &&
+			matchDAO.RevealRequestedBy.String != userId:
+			matchStatus = matchEntity.Accepted
 		}
-		matchDAO.RequestStatus = string(matchEntity.Accepted)
 	}
-	err = m.updateMatch(matchDAO)
+	err = m.transition(revealMachine, userId, matchDAO, matchStatus)
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	return matchStatus, nil
 }
 
-func (m *Match) RevealChange(matchId string, matchStatus matchEntity.Status) error {
-	matchDAO, err := m.GetMatchById(matchId)
+// ExpireReveals expire the reveal request nobody answered in time
+func (m *Match) ExpireReveals() (int, error) {
+	matchs, err := m.matchRepo.SelectExpiredReveals(time.Now(), revealExpireBatchSize)
 	if err != nil {
-		return err
-	}
-	if matchDAO.RequestStatus != string(matchEntity.Accepted) {
-		return ErrInvalidMatchStatus
+		return 0, err
 	}
-	switch matchStatus {
-	case matchEntity.Requested:
-		if matchDAO.RevealStatus != string(matchEntity.Unknown) {
-			return ErrInvalidMatchStatus
+	expired := 0
+	for _, matchDAO := range matchs {
+		// the reveal answered in the meantime is a conflict
+		err = m.transition(revealMachine, "", matchDAO, matchEntity.Expired)
+		if err != nil {
+			log.Println("reveal expire err", matchDAO.Id, err)
+			continue
 		}
-		matchDAO.RevealStatus = string(matchEntity.Requested)
-		event.MatchRevealed.Trigger(event.MatchRevealedPayload{
-			MatchId:     matchId,
-			MatchStatus: matchEntity.Requested,
-		})
-	case matchEntity.Declined:
-		if matchDAO.RevealStatus == string(matchEntity.Unknown) {
-			return ErrInvalidMatchStatus
+		expired++
+	}
+	return expired, nil
+}
+
+func (m *Match) RunRevealExpirer(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		expired, err := m.ExpireReveals()
+		if err != nil {
+			log.Println("reveal expirer err", err)
 		}
-		matchDAO.RevealStatus = string(matchEntity.Declined)
-		event.MatchRevealed.Trigger(event.MatchRevealedPayload{
-			MatchId:     matchId,
-			MatchStatus: matchEntity.Declined,
-		})
-	case matchEntity.Accepted:
-		if matchDAO.RevealStatus != string(matchEntity.Requested) {
-			return ErrInvalidMatchStatus
+		if expired > 0 {
+			log.Printf("reveal expirer expired %d reveal request", expired)
 		}
-		matchDAO.RevealStatus = string(matchEntity.Accepted)
-		event.MatchRevealed.Trigger(event.MatchRevealedPayload{
-			MatchId:     matchId,
-			MatchStatus: matchEntity.Accepted,
-		})
 	}
+}
 
-	err = m.updateMatch(matchDAO)
+// GetMatchEvents return the timeline of the match to its participant
+func (m *Match) GetMatchEvents(userId, matchId string) ([]matchEntity.Event, error) {
+	_, err := m.getParticipantMatch(userId, matchId)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	events, err := m.matchRepo.SelectEventsByMatchId(matchId)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
 }
 
 func (m *Match) GetMatchById(matchId string) (matchEntity.MatchDAO, error) {
@@ -131,10 +161,14 @@ func (m *Match) GetMatchById(matchId string) (matchEntity.MatchDAO, error) {
 	return matchDAO, nil
 }
 
-func (m *Match) updateMatch(matchEntity matchEntity.MatchDAO) error {
-	err := m.matchRepo.UpdateMatchById(matchEntity)
+// getParticipantMatch hide the match of another user as not found
+func (m *Match) getParticipantMatch(userId, matchId string) (matchEntity.MatchDAO, error) {
+	matchDAO, err := m.GetMatchById(matchId)
 	if err != nil {
-		return err
+		return matchEntity.MatchDAO{}, err
 	}
-	return nil
+	if userId != matchDAO.RequestFrom && userId != matchDAO.RequestTo {
+		return matchEntity.MatchDAO{}, common.WrapErrorWithMsg(ErrNotMatchActor, common.ErrResourceNotFound, "match not found")
+	}
+	return matchDAO, nil
 }
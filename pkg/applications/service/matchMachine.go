@@ -0,0 +1,257 @@
+package service
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/xyedo/blindate/pkg/common"
+	"github.com/xyedo/blindate/pkg/domain/event"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+)
+
+// matchActor is the participant allowed to trigger the transition
+type matchActor int
+
+const (
+	actorRequester matchActor = iota + 1
+	actorRequested
+	actorParticipant
+	// actorRevealRequester is the participant who requested the pending reveal
+	actorRevealRequester
+	// actorRevealResponder is the other participant of the pending reveal
+	actorRevealResponder
+	// actorServer is the transition made without user, eg: expiry
+	actorServer
+)
+
+func (a matchActor) allowed(userId string, matchDAO matchEntity.MatchDAO) bool {
+	participant := userId == matchDAO.RequestFrom || userId == matchDAO.RequestTo
+	switch a {
+	case actorRequester:
+		return userId == matchDAO.RequestFrom
+	case actorRequested:
+		return userId == matchDAO.RequestTo
+	case actorParticipant:
+		return participant
+	case actorRevealRequester:
+		return participant && userId == matchDAO.RevealRequestedBy.String
+	case actorRevealResponder:
+		return participant && userId != matchDAO.RevealRequestedBy.String
+	case actorServer:
+		return userId == ""
+	}
+	return false
+}
+
+// matchStep is what the transition is evaluated with
+type matchStep struct {
+	actorId string
+	now     time.Time
+	reveal  matchEntity.RevealPolicy
+}
+
+type matchTransition struct {
+	from  []matchEntity.Status
+	actor matchActor
+	// allowBlocked is the transition the blocked pair could still make, eg: walking away from the match
+	allowBlocked bool
+	// notActorMsg and invalidFromMsg are the response when the actor or the current status is not allowed
+	notActorMsg    string
+	invalidFromMsg string
+	// guard is checked after the actor and the current status
+	guard func(matchDAO matchEntity.MatchDAO, step matchStep) error
+	// apply is run before the match is persisted
+	apply func(matchDAO *matchEntity.MatchDAO, step matchStep)
+}
+
+func (t matchTransition) allowedFrom(status matchEntity.Status) bool {
+	for _, from := range t.from {
+		if from == status {
+			return true
+		}
+	}
+	return false
+}
+
+// matchMachine is the allowed transition of one status of the match, keyed by the target status
+type matchMachine struct {
+	kind        matchEntity.EventKind
+	blockedMsg  string
+	status      func(matchDAO *matchEntity.MatchDAO) *string
+	transitions map[matchEntity.Status]matchTransition
+	// notify is run once the transition is persisted
+	notify func(matchDAO matchEntity.MatchDAO, to matchEntity.Status, step matchStep)
+}
+
+var requestMachine = matchMachine{
+	kind:       matchEntity.KindRequest,
+	blockedMsg: "could not match with this user",
+	status: func(matchDAO *matchEntity.MatchDAO) *string {
+		return &matchDAO.RequestStatus
+	},
+	transitions: map[matchEntity.Status]matchTransition{
+		matchEntity.Requested: {
+			from:           []matchEntity.Status{matchEntity.Unknown, matchEntity.Withdrawn},
+			actor:          actorRequester,
+			notActorMsg:    "only the requester could request the match",
+			invalidFromMsg: "match could not be requested again",
+		},
+		matchEntity.Withdrawn: {
+			from:           []matchEntity.Status{matchEntity.Requested},
+			actor:          actorRequester,
+			allowBlocked:   true,
+			notActorMsg:    "only the requester could withdraw the match request",
+			invalidFromMsg: "only the pending match request could be withdrawn",
+		},
+		matchEntity.Accepted: {
+			from:           []matchEntity.Status{matchEntity.Requested},
+			actor:          actorRequested,
+			notActorMsg:    "only the requested user could accept or decline the match",
+			invalidFromMsg: "only the pending match request could be accepted",
+			apply: func(matchDAO *matchEntity.MatchDAO, step matchStep) {
+				matchDAO.AcceptedAt = sql.NullTime{Time: step.now, Valid: true}
+			},
+		},
+		matchEntity.Declined: {
+			from:           []matchEntity.Status{matchEntity.Requested, matchEntity.Accepted},
+			actor:          actorRequested,
+			allowBlocked:   true,
+			notActorMsg:    "only the requested user could accept or decline the match",
+			invalidFromMsg: "match is already declined or withdrawn",
+		},
+	},
+}
+
+// revealMachine is the handshake of the accepted match, the reveal is requested by one participant and answered by the other.
+// the accepted reveal is final
+var revealMachine = matchMachine{
+	kind:       matchEntity.KindReveal,
+	blockedMsg: "could not reveal to this user",
+	status: func(matchDAO *matchEntity.MatchDAO) *string {
+		return &matchDAO.RevealStatus
+	},
+	transitions: map[matchEntity.Status]matchTransition{
+		matchEntity.Requested: {
+			from:           []matchEntity.Status{matchEntity.Unknown, matchEntity.Cancelled, matchEntity.Expired},
+			actor:          actorParticipant,
+			invalidFromMsg: "reveal could not be requested again",
+			guard:          requireAcceptedMatch,
+			apply: func(matchDAO *matchEntity.MatchDAO, step matchStep) {
+				matchDAO.RevealRequestedBy = sql.NullString{String: step.actorId, Valid: true}
+				matchDAO.RevealRequestedAt = sql.NullTime{Time: step.now, Valid: true}
+				matchDAO.RevealExpiresAt = sql.NullTime{}
+				if step.reveal.Ttl > 0 {
+					matchDAO.RevealExpiresAt = sql.NullTime{Time: step.now.Add(step.reveal.Ttl), Valid: true}
+				}
+			},
+		},
+		matchEntity.Accepted: {
+			from:           []matchEntity.Status{matchEntity.Requested},
+			actor:          actorRevealResponder,
+			notActorMsg:    "only the other participant could accept the reveal",
+			invalidFromMsg: "only the pending reveal could be accepted",
+			guard: func(matchDAO matchEntity.MatchDAO, step matchStep) error {
+				if err := requireAcceptedMatch(matchDAO, step); err != nil {
+					return err
+				}
+				// the expirer has not caught up yet
+				if matchDAO.RevealExpiresAt.Valid && !step.now.Before(matchDAO.RevealExpiresAt.Time) {
+					return common.WrapWithNewError(ErrInvalidMatchStatus, http.StatusUnprocessableEntity, "reveal request has expired")
+				}
+				return nil
+			},
+			apply: func(matchDAO *matchEntity.MatchDAO, step matchStep) {
+				matchDAO.RevealedAt = sql.NullTime{Time: step.now, Valid: true}
+			},
+		},
+		matchEntity.Declined: {
+			from:           []matchEntity.Status{matchEntity.Requested},
+			actor:          actorRevealResponder,
+			allowBlocked:   true,
+			notActorMsg:    "only the other participant could decline the reveal, cancel it instead",
+			invalidFromMsg: "only the pending reveal could be declined",
+		},
+		matchEntity.Cancelled: {
+			from:           []matchEntity.Status{matchEntity.Requested},
+			actor:          actorRevealRequester,
+			allowBlocked:   true,
+			notActorMsg:    "only the requester could cancel the reveal",
+			invalidFromMsg: "only the pending reveal could be cancelled",
+		},
+		matchEntity.Expired: {
+			from:           []matchEntity.Status{matchEntity.Requested},
+			actor:          actorServer,
+			allowBlocked:   true,
+			notActorMsg:    "reveal could not be expired by the user",
+			invalidFromMsg: "only the pending reveal could expire",
+		},
+	},
+	notify: func(matchDAO matchEntity.MatchDAO, to matchEntity.Status, step matchStep) {
+		event.MatchRevealed.Trigger(event.MatchRevealedPayload{
+			MatchId:     matchDAO.Id,
+			MatchStatus: to,
+			ActorId:     step.actorId,
+		})
+	},
+}
+
+func requireAcceptedMatch(matchDAO matchEntity.MatchDAO, _ matchStep) error {
+	if matchDAO.RequestStatus != string(matchEntity.Accepted) {
+		return common.WrapWithNewError(ErrInvalidMatchStatus, http.StatusUnprocessableEntity, "match is not accepted yet")
+	}
+	return nil
+}
+
+// transition check the actor and the current status against the machine, then persist the match with its event.
+// the empty userId is the server
+func (m *Match) transition(machine matchMachine, userId string, matchDAO matchEntity.MatchDAO, to matchEntity.Status) error {
+	t, ok := machine.transitions[to]
+	if !ok {
+		return common.WrapWithNewError(ErrInvalidMatchStatus, http.StatusUnprocessableEntity, "invalid "+string(machine.kind)+" status")
+	}
+	if matchDAO.Blocked && !t.allowBlocked {
+		return common.WrapWithNewError(ErrUserBlocked, http.StatusForbidden, machine.blockedMsg)
+	}
+	if !t.actor.allowed(userId, matchDAO) {
+		return common.WrapWithNewError(ErrNotMatchActor, http.StatusForbidden, t.notActorMsg)
+	}
+	status := machine.status(&matchDAO)
+	from := matchEntity.Status(*status)
+	if !t.allowedFrom(from) {
+		return common.WrapWithNewError(ErrInvalidMatchStatus, http.StatusUnprocessableEntity, t.invalidFromMsg)
+	}
+	step := matchStep{
+		actorId: userId,
+		now:     time.Now(),
+		reveal:  m.reveal,
+	}
+	if t.guard != nil {
+		if err := t.guard(matchDAO, step); err != nil {
+			return err
+		}
+	}
+
+	*status = string(to)
+	if t.apply != nil {
+		t.apply(&matchDAO, step)
+	}
+	newEvent := matchEntity.Event{
+		MatchId:    matchDAO.Id,
+		Kind:       machine.kind,
+		FromStatus: from,
+		ToStatus:   to,
+		CreatedAt:  step.now,
+	}
+	if userId != "" {
+		newEvent.ActorId = &userId
+	}
+	err := m.matchRepo.TransitionMatch(matchDAO, &newEvent)
+	if err != nil {
+		return err
+	}
+	if machine.notify != nil {
+		machine.notify(matchDAO, to, step)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/common"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+)
+
+func Test_RevealChange(t *testing.T) {
+	accepted := matchEntity.MatchDAO{
+		Id:            "match",
+		RequestFrom:   "from",
+		RequestTo:     "to",
+		RequestStatus: string(matchEntity.Accepted),
+		RevealStatus:  string(matchEntity.Unknown),
+	}
+	requestedBy := func(userId string) matchEntity.MatchDAO {
+		requested := accepted
+		requested.RevealStatus = string(matchEntity.Requested)
+		requested.RevealRequestedBy = sql.NullString{String: userId, Valid: true}
+		requested.RevealExpiresAt = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+		return requested
+	}
+	tests := []struct {
+		name       string
+		policy     matchEntity.RevealPolicy
+		userId     string
+		matchDAO   func() matchEntity.MatchDAO
+		status     matchEntity.Status
+		wantStatus int
+		wantEvent  *matchEntity.Event
+		checkFunc  func(t *testing.T, matchDAO matchEntity.MatchDAO)
+	}{
+		{
+			name:      "requested by either participant",
+			policy:    matchEntity.RevealPolicy{Ttl: time.Hour},
+			userId:    "to",
+			matchDAO:  func() matchEntity.MatchDAO { return accepted },
+			status:    matchEntity.Requested,
+			wantEvent: &matchEntity.Event{Kind: matchEntity.KindReveal, FromStatus: matchEntity.Unknown, ToStatus: matchEntity.Requested},
+			checkFunc: func(t *testing.T, matchDAO matchEntity.MatchDAO) {
+				assert.Equal(t, "to", matchDAO.RevealRequestedBy.String)
+				require.True(t, matchDAO.RevealExpiresAt.Valid)
+				assert.WithinDuration(t, time.Now().Add(time.Hour), matchDAO.RevealExpiresAt.Time, time.Minute)
+			},
+		},
+		{
+			name:      "never expire without ttl",
+			userId:    "to",
+			matchDAO:  func() matchEntity.MatchDAO { return accepted },
+			status:    matchEntity.Requested,
+			wantEvent: &matchEntity.Event{Kind: matchEntity.KindReveal, FromStatus: matchEntity.Unknown, ToStatus: matchEntity.Requested},
+			checkFunc: func(t *testing.T, matchDAO matchEntity.MatchDAO) {
+				assert.False(t, matchDAO.RevealExpiresAt.Valid)
+			},
+		},
+		{
+			name:      "accepted by the other participant",
+			userId:    "from",
+			matchDAO:  func() matchEntity.MatchDAO { return requestedBy("to") },
+			status:    matchEntity.Accepted,
+			wantEvent: &matchEntity.Event{Kind: matchEntity.KindReveal, FromStatus: matchEntity.Requested, ToStatus: matchEntity.Accepted},
+			checkFunc: func(t *testing.T, matchDAO matchEntity.MatchDAO) {
+				assert.True(t, matchDAO.RevealedAt.Valid)
+			},
+		},
+		{
+			name:       "accepted by the requester",
+			userId:     "to",
+			matchDAO:   func() matchEntity.MatchDAO { return requestedBy("to") },
+			status:     matchEntity.Accepted,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "accepted after expiry",
+			userId: "from",
+			matchDAO: func() matchEntity.MatchDAO {
+				expired := requestedBy("to")
+				expired.RevealExpiresAt.Time = time.Now().Add(-time.Minute)
+				return expired
+			},
+			status:     matchEntity.Accepted,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:      "cancelled by the requester",
+			userId:    "to",
+			matchDAO:  func() matchEntity.MatchDAO { return requestedBy("to") },
+			status:    matchEntity.Cancelled,
+			wantEvent: &matchEntity.Event{Kind: matchEntity.KindReveal, FromStatus: matchEntity.Requested, ToStatus: matchEntity.Cancelled},
+		},
+		{
+			name:       "cancelled by the other participant",
+			userId:     "from",
+			matchDAO:   func() matchEntity.MatchDAO { return requestedBy("to") },
+			status:     matchEntity.Cancelled,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "requested again once cancelled",
+			userId: "from",
+			matchDAO: func() matchEntity.MatchDAO {
+				cancelled := requestedBy("to")
+				cancelled.RevealStatus = string(matchEntity.Cancelled)
+				return cancelled
+			},
+			status:    matchEntity.Requested,
+			wantEvent: &matchEntity.Event{Kind: matchEntity.KindReveal, FromStatus: matchEntity.Cancelled, ToStatus: matchEntity.Requested},
+			checkFunc: func(t *testing.T, matchDAO matchEntity.MatchDAO) {
+				assert.Equal(t, "from", matchDAO.RevealRequestedBy.String)
+			},
+		},
+		{
+			name:   "match is not accepted yet",
+			userId: "from",
+			matchDAO: func() matchEntity.MatchDAO {
+				requested := accepted
+				requested.RequestStatus = string(matchEntity.Requested)
+				return requested
+			},
+			status:     matchEntity.Requested,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "declined before requested",
+			userId:     "from",
+			matchDAO:   func() matchEntity.MatchDAO { return accepted },
+			status:     matchEntity.Declined,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "blocked pair could only walk away",
+			userId: "from",
+			matchDAO: func() matchEntity.MatchDAO {
+				blocked := accepted
+				blocked.Blocked = true
+				return blocked
+			},
+			status:     matchEntity.Requested,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "blocked pair declined",
+			userId: "from",
+			matchDAO: func() matchEntity.MatchDAO {
+				blocked := requestedBy("to")
+				blocked.Blocked = true
+				return blocked
+			},
+			status:    matchEntity.Declined,
+			wantEvent: &matchEntity.Event{Kind: matchEntity.KindReveal, FromStatus: matchEntity.Requested, ToStatus: matchEntity.Declined},
+		},
+		{
+			name:       "expired by the user",
+			userId:     "from",
+			matchDAO:   func() matchEntity.MatchDAO { return requestedBy("to") },
+			status:     matchEntity.Expired,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "withdrawn is not a reveal status",
+			userId:     "from",
+			matchDAO:   func() matchEntity.MatchDAO { return accepted },
+			status:     matchEntity.Withdrawn,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:      "mutual reveal by the second request",
+			policy:    matchEntity.RevealPolicy{Mutual: true},
+			userId:    "from",
+			matchDAO:  func() matchEntity.MatchDAO { return requestedBy("to") },
+			status:    matchEntity.Requested,
+			wantEvent: &matchEntity.Event{Kind: matchEntity.KindReveal, FromStatus: matchEntity.Requested, ToStatus: matchEntity.Accepted},
+		},
+		{
+			name:       "mutual reveal could not be accepted",
+			policy:     matchEntity.RevealPolicy{Mutual: true},
+			userId:     "from",
+			matchDAO:   func() matchEntity.MatchDAO { return requestedBy("to") },
+			status:     matchEntity.Accepted,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "mutual reveal requested twice by the same user",
+			policy:     matchEntity.RevealPolicy{Mutual: true},
+			userId:     "to",
+			matchDAO:   func() matchEntity.MatchDAO { return requestedBy("to") },
+			status:     matchEntity.Requested,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			matchRepo.EXPECT().GetMatchById(gomock.Eq("match")).Times(1).Return(tt.matchDAO(), nil)
+			if tt.wantEvent != nil {
+				matchRepo.EXPECT().TransitionMatch(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(matchDAO matchEntity.MatchDAO, event *matchEntity.Event) error {
+					assert.Equal(t, string(tt.wantEvent.ToStatus), matchDAO.RevealStatus)
+					assert.Equal(t, tt.wantEvent.Kind, event.Kind)
+					assert.Equal(t, tt.wantEvent.FromStatus, event.FromStatus)
+					assert.Equal(t, tt.wantEvent.ToStatus, event.ToStatus)
+					require.NotNil(t, event.ActorId)
+					assert.Equal(t, tt.userId, *event.ActorId)
+					if tt.checkFunc != nil {
+						tt.checkFunc(t, matchDAO)
+					}
+					return nil
+				})
+			} else {
+				matchRepo.EXPECT().TransitionMatch(gomock.Any(), gomock.Any()).Times(0)
+			}
+			svc := NewMatch(matchRepo, nil, nil, tt.policy)
+
+			revealStatus, err := svc.RevealChange(tt.userId, "match", tt.status)
+			if tt.wantStatus == 0 {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantEvent.ToStatus, revealStatus)
+				return
+			}
+			require.Error(t, err)
+			var apiErr common.APIError
+			require.ErrorAs(t, err, &apiErr)
+			status, _ := apiErr.APIError()
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func Test_ExpireReveals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	matchRepo := mockrepo.NewMockMatch(ctrl)
+	pending := matchEntity.MatchDAO{
+		Id:                "match",
+		RequestFrom:       "from",
+		RequestTo:         "to",
+		RequestStatus:     string(matchEntity.Accepted),
+		RevealStatus:      string(matchEntity.Requested),
+		RevealRequestedBy: sql.NullString{String: "from", Valid: true},
+		RevealExpiresAt:   sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+	}
+	answered := pending
+	answered.Id = "answered"
+	matchRepo.EXPECT().SelectExpiredReveals(gomock.Any(), gomock.Eq(revealExpireBatchSize)).Times(1).
+		Return([]matchEntity.MatchDAO{pending, answered}, nil)
+	matchRepo.EXPECT().TransitionMatch(gomock.Any(), gomock.Any()).Times(2).DoAndReturn(func(matchDAO matchEntity.MatchDAO, event *matchEntity.Event) error {
+		if matchDAO.Id == "answered" {
+			return common.WrapWithNewError(sql.ErrNoRows, http.StatusConflict, "match has been changed, please try again")
+		}
+		assert.Equal(t, string(matchEntity.Expired), matchDAO.RevealStatus)
+		assert.Nil(t, event.ActorId, "expired by the server")
+		return nil
+	})
+	svc := NewMatch(matchRepo, nil, nil, matchEntity.RevealPolicy{Ttl: time.Hour})
+
+	expired, err := svc.ExpireReveals()
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+}
+
+func Test_GetMatchEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	matchRepo := mockrepo.NewMockMatch(ctrl)
+	matchRepo.EXPECT().GetMatchById(gomock.Eq("match")).AnyTimes().
+		Return(matchEntity.MatchDAO{Id: "match", RequestFrom: "from", RequestTo: "to"}, nil)
+	matchRepo.EXPECT().SelectEventsByMatchId(gomock.Eq("match")).Times(1).Return([]matchEntity.Event{{Id: "event"}}, nil)
+	svc := NewMatch(matchRepo, nil, nil, matchEntity.RevealPolicy{})
+
+	events, err := svc.GetMatchEvents("to", "match")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	_, err = svc.GetMatchEvents("another", "match")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, common.ErrResourceNotFound)
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+
+	"github.com/xyedo/blindate/internal/media"
+	"github.com/xyedo/blindate/pkg/common"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+var ErrMediaRejected = errors.New("media is rejected")
+
+// waveformBars is the length of the voice note waveform
+const waveformBars = 64
+
+var imageExt = map[string]string{
+	"jpeg": ".jpg",
+	"png":  ".png",
+	"gif":  ".gif",
+}
+
+func NewMedia(limits attachmentEntity.Limits) *Media {
+	return &Media{
+		limits: limits,
+	}
+}
+
+type Media struct {
+	limits attachmentEntity.Limits
+}
+
+// Process validate the size, dimension and duration of the media. image and video metadata is stripped,
+// image get a thumbnail and audio get a waveform. size is the declared length, the content is still bounded when the declaration lie
+func (m *Media) Process(file io.Reader, size int64, mediaType string) (attachmentEntity.Processed, error) {
+	switch {
+	case attachmentEntity.IsAudio(mediaType):
+		return m.processAudio(file, size, mediaType)
+	case attachmentEntity.IsImage(mediaType):
+		return m.processImage(file, size, mediaType)
+	case attachmentEntity.IsVideo(mediaType):
+		return m.processVideo(file, size, mediaType)
+	}
+	return attachmentEntity.Processed{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity, "not valid mime-type")
+}
+
+func (m *Media) processAudio(file io.Reader, size int64, mediaType string) (attachmentEntity.Processed, error) {
+	content, err := m.read(file, size, m.limits.MaxAudioBytes, "voice note")
+	if err != nil {
+		return attachmentEntity.Processed{}, err
+	}
+	var audio media.Audio
+	var ext string
+	if mediaType == "audio/mpeg" {
+		audio, err = media.ProbeMP3(content)
+		ext = ".mp3"
+	} else {
+		audio, err = media.ProbeOgg(content)
+		ext = ".ogg"
+	}
+	if err != nil {
+		return attachmentEntity.Processed{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity, "voice note is malformed")
+	}
+	if audio.Duration > m.limits.MaxVoiceDuration {
+		return attachmentEntity.Processed{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity,
+			fmt.Sprintf("voice note must not be longer than %d seconds", int(m.limits.MaxVoiceDuration.Seconds())))
+	}
+	return attachmentEntity.Processed{
+		Content:   content,
+		MediaType: mediaType,
+		Ext:       ext,
+		Duration:  audio.Duration,
+		Waveform:  media.Waveform(audio.Intensity, waveformBars),
+	}, nil
+}
+
+func (m *Media) processImage(file io.Reader, size int64, mediaType string) (attachmentEntity.Processed, error) {
+	content, err := m.read(file, size, m.limits.MaxImageBytes, "image")
+	if err != nil {
+		return attachmentEntity.Processed{}, err
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return attachmentEntity.Processed{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity, "image is malformed")
+	}
+	if err := m.checkDimension(config.Width, config.Height, "image"); err != nil {
+		return attachmentEntity.Processed{}, err
+	}
+	clean, err := media.SanitizeImage(content, format)
+	if err != nil {
+		if errors.Is(err, media.ErrMalformed) {
+			return attachmentEntity.Processed{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity, "image is malformed")
+		}
+		return attachmentEntity.Processed{}, err
+	}
+	// the orientation could swap the dimension
+	config, _, err = image.DecodeConfig(bytes.NewReader(clean))
+	if err != nil {
+		return attachmentEntity.Processed{}, err
+	}
+	thumbnail, err := media.Thumbnail(clean, m.limits.ThumbnailSize)
+	if err != nil {
+		if errors.Is(err, media.ErrMalformed) {
+			return attachmentEntity.Processed{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity, "image is malformed")
+		}
+		return attachmentEntity.Processed{}, err
+	}
+	return attachmentEntity.Processed{
+		Content:   clean,
+		MediaType: mediaType,
+		Ext:       imageExt[format],
+		Width:     config.Width,
+		Height:    config.Height,
+		Thumbnail: thumbnail,
+	}, nil
+}
+
+func (m *Media) processVideo(file io.Reader, size int64, mediaType string) (attachmentEntity.Processed, error) {
+	content, err := m.read(file, size, m.limits.MaxVideoBytes, "video")
+	if err != nil {
+		return attachmentEntity.Processed{}, err
+	}
+	video, err := media.ProbeMP4(content)
+	if err != nil {
+		if errors.Is(err, media.ErrNoVideoTrack) {
+			return attachmentEntity.Processed{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity, "video track not found")
+		}
+		return attachmentEntity.Processed{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity, "video is malformed")
+	}
+	if err := m.checkDimension(video.Width, video.Height, "video"); err != nil {
+		return attachmentEntity.Processed{}, err
+	}
+	if video.Duration > m.limits.MaxVideoDuration {
+		return attachmentEntity.Processed{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity,
+			fmt.Sprintf("video must not be longer than %d seconds", int(m.limits.MaxVideoDuration.Seconds())))
+	}
+	clean, err := media.StripMP4Metadata(content)
+	if err != nil {
+		return attachmentEntity.Processed{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity, "video is malformed")
+	}
+	return attachmentEntity.Processed{
+		Content:   clean,
+		MediaType: mediaType,
+		Ext:       ".mp4",
+		Width:     video.Width,
+		Height:    video.Height,
+		Duration:  video.Duration,
+	}, nil
+}
+
+// ProcessProfilePicture decode and re-encode the picture as jpeg renditions, so the metadata and anything
+// but the pixel (eg: script of svg, polyglot payload) is never served back
+func (m *Media) ProcessProfilePicture(file io.Reader, size int64, mediaType string) (attachmentEntity.ProfileRenditions, error) {
+	if !attachmentEntity.IsImage(mediaType) {
+		return attachmentEntity.ProfileRenditions{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity, "not valid mime-type")
+	}
+	content, err := m.read(file, size, m.limits.MaxImageBytes, "image")
+	if err != nil {
+		return attachmentEntity.ProfileRenditions{}, err
+	}
+	// checked before decoding so the decompression bomb is not allocated
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return attachmentEntity.ProfileRenditions{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity, "image is malformed")
+	}
+	if err := m.checkDimension(config.Width, config.Height, "image"); err != nil {
+		return attachmentEntity.ProfileRenditions{}, err
+	}
+	img, err := media.Upright(content)
+	if err != nil {
+		return attachmentEntity.ProfileRenditions{}, common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity, "image is malformed")
+	}
+	var renditions attachmentEntity.ProfileRenditions
+	renditions.Full, err = media.EncodeJPEG(img, m.limits.Profile.Full, 90)
+	if err != nil {
+		return attachmentEntity.ProfileRenditions{}, err
+	}
+	renditions.Medium, err = media.EncodeJPEG(img, m.limits.Profile.Medium, 85)
+	if err != nil {
+		return attachmentEntity.ProfileRenditions{}, err
+	}
+	renditions.Thumbnail, err = media.EncodeJPEG(img, m.limits.Profile.Thumbnail, 80)
+	if err != nil {
+		return attachmentEntity.ProfileRenditions{}, err
+	}
+	return renditions, nil
+}
+
+// PlaceholderAvatar render the generated avatar of seed as jpeg, rendition is full, medium or thumbnail
+func (m *Media) PlaceholderAvatar(seed, rendition string) ([]byte, error) {
+	size := m.limits.Profile.Medium
+	switch rendition {
+	case "full":
+		size = m.limits.Profile.Full
+	case "thumbnail":
+		size = m.limits.Profile.Thumbnail
+	}
+	return media.EncodeJPEG(util.CreatePlaceholderAvatar(seed, size), size, 90)
+}
+
+func (*Media) read(file io.Reader, size, max int64, kind string) ([]byte, error) {
+	tooLarge := common.WrapWithNewError(ErrMediaRejected, http.StatusRequestEntityTooLarge,
+		fmt.Sprintf("max byte of %s to upload is %dmB", kind, max>>20))
+	if size > max {
+		return nil, tooLarge
+	}
+	content, err := io.ReadAll(io.LimitReader(file, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > max {
+		return nil, tooLarge
+	}
+	return content, nil
+}
+
+func (m *Media) checkDimension(width, height int, kind string) error {
+	if width <= 0 || height <= 0 {
+		return common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity, kind+" is malformed")
+	}
+	if width > m.limits.MaxDimension || height > m.limits.MaxDimension {
+		return common.WrapWithNewError(ErrMediaRejected, http.StatusUnprocessableEntity,
+			fmt.Sprintf("%s dimension must not exceed %dpx", kind, m.limits.MaxDimension))
+	}
+	return nil
+}
@@ -64,6 +64,36 @@ func (mr *MockAttachmentMockRecorder) GetPresignedUrl(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPresignedUrl", reflect.TypeOf((*MockAttachment)(nil).GetPresignedUrl), arg0)
 }
 
+// ListBlobs mocks base method.
+func (m *MockAttachment) ListBlobs(arg0 string) ([]attachmentEntity.Blob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlobs", arg0)
+	ret0, _ := ret[0].([]attachmentEntity.Blob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlobs indicates an expected call of ListBlobs.
+func (mr *MockAttachmentMockRecorder) ListBlobs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlobs", reflect.TypeOf((*MockAttachment)(nil).ListBlobs), arg0)
+}
+
+// ReadBlob mocks base method.
+func (m *MockAttachment) ReadBlob(arg0 string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadBlob", arg0)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadBlob indicates an expected call of ReadBlob.
+func (mr *MockAttachmentMockRecorder) ReadBlob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadBlob", reflect.TypeOf((*MockAttachment)(nil).ReadBlob), arg0)
+}
+
 // UploadBlob mocks base method.
 func (m *MockAttachment) UploadBlob(arg0 io.Reader, arg1 attachmentEntity.Uploader) (string, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xyedo/blindate/pkg/common"
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
+	"github.com/xyedo/blindate/pkg/domain/moderation"
+	moderationEntity "github.com/xyedo/blindate/pkg/domain/moderation/entities"
+	"github.com/xyedo/blindate/pkg/domain/user"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+)
+
+// reportContextSize is the number of chats shown before and after the reported chat
+const reportContextSize = 10
+
+var ErrInvalidModeration = errors.New("invalid moderation")
+
+func NewModeration(moderationRepo moderation.Repository, userRepo user.Repository, chatSvc *Chat) *Moderation {
+	return &Moderation{
+		moderationRepo: moderationRepo,
+		userRepo:       userRepo,
+		chatSvc:        chatSvc,
+	}
+}
+
+type Moderation struct {
+	moderationRepo moderation.Repository
+	userRepo       user.Repository
+	chatSvc        *Chat
+}
+
+func (m *Moderation) GetReports(filter moderation.ReportFilter) ([]blockEntity.Report, error) {
+	if filter.Status == "" {
+		filter.Status = blockEntity.ReportOpen
+	}
+	reports, err := m.moderationRepo.SelectReports(filter)
+	if err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (m *Moderation) GetReportById(reportId string) (blockEntity.Report, error) {
+	report, err := m.moderationRepo.SelectReportById(reportId)
+	if err != nil {
+		return blockEntity.Report{}, err
+	}
+	return report, nil
+}
+
+// ReviewReport close the open report as resolved or dismissed
+func (m *Moderation) ReviewReport(moderator moderationEntity.Moderator, reportId string, review moderationEntity.ReviewReport) (blockEntity.Report, error) {
+	report, err := m.moderationRepo.SelectReportById(reportId)
+	if err != nil {
+		return blockEntity.Report{}, err
+	}
+	if report.Status != blockEntity.ReportOpen {
+		return blockEntity.Report{}, common.WrapWithNewError(ErrInvalidModeration, http.StatusUnprocessableEntity, "report is already reviewed")
+	}
+	action := moderationEntity.ActionReportResolved
+	report.Status = blockEntity.ReportResolved
+	if review.Status == string(blockEntity.ReportDismissed) {
+		action = moderationEntity.ActionReportDismissed
+		report.Status = blockEntity.ReportDismissed
+	}
+	reviewedAt := time.Now()
+	report.ReviewedBy = &moderator.Id
+	report.ReviewedAt = &reviewedAt
+	report.Resolution = nil
+	if review.Resolution != nil {
+		if resolution := strings.TrimSpace(*review.Resolution); resolution != "" {
+			report.Resolution = &resolution
+		}
+	}
+	err = m.moderationRepo.UpdateReportReview(report, moderationEntity.AuditLog{
+		ModeratorId:  &moderator.Id,
+		Action:       action,
+		TargetUserId: &report.ReportedId,
+		ReportId:     &report.Id,
+		Details:      report.Resolution,
+	})
+	if err != nil {
+		return blockEntity.Report{}, err
+	}
+	return report, nil
+}
+
+// GetReportContext list the chats around the reported chat, reading private conversation is audited
+func (m *Moderation) GetReportContext(moderator moderationEntity.Moderator, reportId string) ([]chatEntity.DTO, error) {
+	report, err := m.moderationRepo.SelectReportById(reportId)
+	if err != nil {
+		return nil, err
+	}
+	if report.ChatId == nil {
+		return nil, common.WrapWithNewError(ErrInvalidModeration, http.StatusNotFound, "report has no chat reference")
+	}
+	chats, err := m.chatSvc.GetMessageContext(*report.ChatId, reportContextSize)
+	if err != nil {
+		return nil, err
+	}
+	err = m.moderationRepo.InsertAuditLog(moderationEntity.AuditLog{
+		ModeratorId:  &moderator.Id,
+		Action:       moderationEntity.ActionReportContextRead,
+		TargetUserId: &report.ReportedId,
+		ReportId:     &report.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chats, nil
+}
+
+// Suspend restrict userId for the duration, an empty duration ban the user permanently.
+// only admin could restrict another staff
+func (m *Moderation) Suspend(moderator moderationEntity.Moderator, userId string, newSuspension moderationEntity.NewSuspension) (moderationEntity.Suspension, error) {
+	if moderator.Id == userId {
+		return moderationEntity.Suspension{}, common.WrapWithNewError(ErrInvalidModeration, http.StatusUnprocessableEntity, "could not suspend yourself")
+	}
+	reason := strings.TrimSpace(newSuspension.Reason)
+	if reason == "" {
+		return moderationEntity.Suspension{}, common.WrapWithNewError(ErrInvalidModeration, http.StatusUnprocessableEntity, "reason must not be empty")
+	}
+	target, err := m.userRepo.GetUserById(userId)
+	if err != nil {
+		return moderationEntity.Suspension{}, err
+	}
+	if target.Role.IsStaff() && moderator.Role != userEntity.RoleAdmin {
+		return moderationEntity.Suspension{}, common.WrapWithNewError(ErrInvalidModeration, http.StatusForbidden, "only admin could suspend a staff")
+	}
+	suspension := moderationEntity.Suspension{
+		UserId:      userId,
+		ModeratorId: &moderator.Id,
+		Reason:      reason,
+	}
+	action := moderationEntity.ActionUserBanned
+	details := reason
+	if newSuspension.Duration != "" {
+		duration, err := parseSuspensionDuration(newSuspension.Duration)
+		if err != nil {
+			return moderationEntity.Suspension{}, err
+		}
+		endsAt := time.Now().Add(duration)
+		suspension.EndsAt = &endsAt
+		action = moderationEntity.ActionUserSuspended
+		details = fmt.Sprintf("%s (until %s)", reason, endsAt.UTC().Format(time.RFC3339))
+	}
+	err = m.moderationRepo.InsertSuspension(&suspension, moderationEntity.AuditLog{
+		ModeratorId:  &moderator.Id,
+		Action:       action,
+		TargetUserId: &userId,
+		Details:      &details,
+	})
+	if err != nil {
+		return moderationEntity.Suspension{}, err
+	}
+	return suspension, nil
+}
+
+// LiftSuspension end every active suspension and ban of userId
+func (m *Moderation) LiftSuspension(moderator moderationEntity.Moderator, userId string) error {
+	err := m.moderationRepo.LiftSuspension(userId, moderator.Id, time.Now(), moderationEntity.AuditLog{
+		ModeratorId:  &moderator.Id,
+		Action:       moderationEntity.ActionSuspensionLifted,
+		TargetUserId: &userId,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *Moderation) GetSuspensions(userId string) ([]moderationEntity.Suspension, error) {
+	suspensions, err := m.moderationRepo.SelectSuspensions(userId)
+	if err != nil {
+		return nil, err
+	}
+	return suspensions, nil
+}
+
+// ChangeRole grant or revoke the staff role, the role is checked on every request so it take effect immediately,
+// only the already opened websocket connection keep running until it is closed
+func (m *Moderation) ChangeRole(moderator moderationEntity.Moderator, userId string, newRole moderationEntity.NewRole) error {
+	if moderator.Id == userId {
+		return common.WrapWithNewError(ErrInvalidModeration, http.StatusUnprocessableEntity, "could not change your own role")
+	}
+	target, err := m.userRepo.GetUserById(userId)
+	if err != nil {
+		return err
+	}
+	role := userEntity.Role(newRole.Role)
+	if target.Role == role {
+		return nil
+	}
+	details := fmt.Sprintf("role changed from %s to %s", target.Role, role)
+	return m.moderationRepo.UpdateRole(userId, role, moderationEntity.AuditLog{
+		ModeratorId:  &moderator.Id,
+		Action:       moderationEntity.ActionRoleChanged,
+		TargetUserId: &userId,
+		Details:      &details,
+	})
+}
+
+func (m *Moderation) GetAuditLogs(filter moderation.AuditFilter) ([]moderationEntity.AuditLog, error) {
+	logs, err := m.moderationRepo.SelectAuditLogs(filter)
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// PromoteAdmins bootstrap the configured admin, the promotion is audited as a system action
+func (m *Moderation) PromoteAdmins(userIds []string) error {
+	for _, userId := range userIds {
+		target, err := m.userRepo.GetUserById(userId)
+		if err != nil {
+			if errors.Is(err, common.ErrResourceNotFound) {
+				log.Printf("admin user %s is not found, skipping", userId)
+				continue
+			}
+			return err
+		}
+		if target.Role == userEntity.RoleAdmin {
+			continue
+		}
+		userId := userId
+		details := fmt.Sprintf("role changed from %s to %s", target.Role, userEntity.RoleAdmin)
+		err = m.moderationRepo.UpdateRole(userId, userEntity.RoleAdmin, moderationEntity.AuditLog{
+			Action:       moderationEntity.ActionRoleChanged,
+			TargetUserId: &userId,
+			Details:      &details,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseSuspensionDuration accept the time.ParseDuration format and whole days e.g. 7d
+func parseSuspensionDuration(val string) (time.Duration, error) {
+	var duration time.Duration
+	var err error
+	if strings.HasSuffix(val, "d") {
+		var n int
+		n, err = strconv.Atoi(strings.TrimSuffix(val, "d"))
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		duration, err = time.ParseDuration(val)
+	}
+	if err != nil || duration <= 0 {
+		return 0, common.WrapWithNewError(ErrInvalidModeration, http.StatusUnprocessableEntity, "duration must be a positive duration e.g. 12h or 7d")
+	}
+	return duration, nil
+}
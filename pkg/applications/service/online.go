@@ -1,8 +1,10 @@
 package service
 
 import (
+	"log"
 	"time"
 
+	"github.com/xyedo/blindate/pkg/domain/event"
 	"github.com/xyedo/blindate/pkg/domain/online"
 	onlineEntities "github.com/xyedo/blindate/pkg/domain/online/entities"
 )
@@ -32,10 +34,90 @@ func (o *Online) GetOnline(userId string) (onlineEntities.DTO, error) {
 	return userOnline, nil
 
 }
+
+// GetPresence return the presence of userId as seen by viewerId, with the owner presence settings applied
+func (o *Online) GetPresence(viewerId, userId string, isMatched bool) (onlineEntities.Presence, error) {
+	userOnline, err := o.onlineRepository.SelectOnline(userId)
+	if err != nil {
+		return onlineEntities.Presence{}, err
+	}
+	if viewerId == userId {
+		userOnline.Visibility = onlineEntities.VisibleToEveryone
+		userOnline.LastSeenPrecision = onlineEntities.LastSeenExact
+	}
+	return userOnline.PresenceFor(isMatched, time.Now()), nil
+}
+
+func (o *Online) PutSettings(userId string, settings onlineEntities.Settings) error {
+	userOnline, err := o.onlineRepository.SelectOnline(userId)
+	if err != nil {
+		return err
+	}
+	err = o.onlineRepository.UpdateSettings(userId, settings)
+	if err != nil {
+		return err
+	}
+	// let the partners re-evaluate what they are allowed to see
+	event.PresenceChanged.Trigger(event.PresenceChangedPayload{
+		UserId:     userId,
+		IsOnline:   userOnline.IsOnline,
+		LastOnline: userOnline.LastOnline,
+	})
+	return nil
+}
+
 func (o *Online) PutOnline(userId string, online bool) error {
 	err := o.onlineRepository.UpdateOnline(userId, online)
 	if err != nil {
 		return err
 	}
+	event.PresenceChanged.Trigger(event.PresenceChangedPayload{
+		UserId:     userId,
+		IsOnline:   online,
+		LastOnline: time.Now(),
+	})
 	return nil
 }
+
+// Heartbeat mark the user online, presence event only triggered when the user was offline
+func (o *Online) Heartbeat(userId string) error {
+	now := time.Now()
+	wasOnline, err := o.onlineRepository.UpdateHeartbeat(userId, now)
+	if err != nil {
+		return err
+	}
+	if !wasOnline {
+		event.PresenceChanged.Trigger(event.PresenceChangedPayload{
+			UserId:     userId,
+			IsOnline:   true,
+			LastOnline: now,
+		})
+	}
+	return nil
+}
+
+// SweepStale mark offline every online user which last heartbeat is older than timeout
+func (o *Online) SweepStale(timeout time.Duration) error {
+	staleOnlines, err := o.onlineRepository.UpdateStaleOffline(time.Now().Add(-timeout))
+	if err != nil {
+		return err
+	}
+	for _, staleOnline := range staleOnlines {
+		event.PresenceChanged.Trigger(event.PresenceChangedPayload{
+			UserId:     staleOnline.UserId,
+			IsOnline:   false,
+			LastOnline: staleOnline.LastOnline,
+		})
+	}
+	return nil
+}
+
+func (o *Online) RunStaleSweeper(interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := o.SweepStale(timeout); err != nil {
+			log.Println("stale online sweeper err", err)
+		}
+	}
+}
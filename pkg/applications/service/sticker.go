@@ -0,0 +1,105 @@
+package service
+
+import (
+	"time"
+
+	"github.com/xyedo/blindate/pkg/domain/sticker"
+	stickerEntity "github.com/xyedo/blindate/pkg/domain/sticker/entities"
+)
+
+func NewSticker(stickerRepo sticker.Repository, blobUrl *BlobUrl) *Sticker {
+	return &Sticker{
+		stickerRepo: stickerRepo,
+		blobUrl:     blobUrl,
+	}
+}
+
+type Sticker struct {
+	stickerRepo sticker.Repository
+	blobUrl     *BlobUrl
+}
+
+func (s *Sticker) CreatePack(newPack stickerEntity.NewPack) (string, error) {
+	id, err := s.stickerRepo.InsertPack(newPack.Name)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// GetCatalogue list the active packs with its active stickers
+func (s *Sticker) GetCatalogue() ([]stickerEntity.Pack, error) {
+	packs, err := s.stickerRepo.SelectPacks(false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range packs {
+		if err := s.resolveUrls(&packs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return packs, nil
+}
+
+func (s *Sticker) GetPacks() ([]stickerEntity.Pack, error) {
+	packs, err := s.stickerRepo.SelectPacks(true)
+	if err != nil {
+		return nil, err
+	}
+	for i := range packs {
+		if err := s.resolveUrls(&packs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return packs, nil
+}
+
+func (s *Sticker) GetPackById(packId string) (stickerEntity.Pack, error) {
+	pack, err := s.stickerRepo.SelectPackById(packId)
+	if err != nil {
+		return stickerEntity.Pack{}, err
+	}
+	if err := s.resolveUrls(&pack); err != nil {
+		return stickerEntity.Pack{}, err
+	}
+	return pack, nil
+}
+
+func (s *Sticker) UpdatePack(packId string, update stickerEntity.UpdatePack) (stickerEntity.Pack, error) {
+	pack, err := s.stickerRepo.UpdatePack(packId, update, time.Now())
+	if err != nil {
+		return stickerEntity.Pack{}, err
+	}
+	if err := s.resolveUrls(&pack); err != nil {
+		return stickerEntity.Pack{}, err
+	}
+	return pack, nil
+}
+
+func (s *Sticker) AddSticker(newSticker stickerEntity.Sticker) (string, error) {
+	err := s.stickerRepo.InsertSticker(&newSticker)
+	if err != nil {
+		return "", err
+	}
+	return newSticker.Id, nil
+}
+
+func (s *Sticker) ArchiveSticker(stickerId string) error {
+	err := s.stickerRepo.ArchiveSticker(stickerId, time.Now())
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// resolveUrls replace the blob key of every sticker in the pack with the signed url
+func (s *Sticker) resolveUrls(pack *stickerEntity.Pack) error {
+	for i := range pack.Stickers {
+		url, err := s.blobUrl.Resolve(pack.Stickers[i].BlobLink)
+		if err != nil {
+			return err
+		}
+		pack.Stickers[i].BlobLink = url
+	}
+	return nil
+}
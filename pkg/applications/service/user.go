@@ -3,7 +3,6 @@ package service
 import (
 	"errors"
 	"net/http"
-	"path/filepath"
 
 	"github.com/xyedo/blindate/pkg/common"
 	"github.com/xyedo/blindate/pkg/domain/event"
@@ -12,14 +11,18 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-func NewUser(userRepo user.Repository) *User {
+var ErrProfilePicOrder = errors.New("profile picture order is invalid")
+
+func NewUser(userRepo user.Repository, blobUrl *BlobUrl) *User {
 	return &User{
 		userRepository: userRepo,
+		blobUrl:        blobUrl,
 	}
 }
 
 type User struct {
 	userRepository user.Repository
+	blobUrl        *BlobUrl
 }
 
 func (u *User) CreateUser(newUser userEntity.Register) (string, error) {
@@ -53,7 +56,10 @@ func (u *User) GetUserByIdWithSelectedProfPic(id string) (userEntity.FullDTO, er
 	if err != nil {
 		return userEntity.FullDTO{}, err
 	}
-	user.ProfilePic = profPics
+	user.ProfilePic, err = u.resolveProfilePics(profPics)
+	if err != nil {
+		return userEntity.FullDTO{}, err
+	}
 	return user, nil
 }
 
@@ -114,14 +120,8 @@ func (u *User) CreateNewProfilePic(profPicParam userEntity.ProfilePic) (string,
 	if len(profPics) >= 5 {
 		return "", common.WrapWithNewError(common.ErrMaxProfilePicture, http.StatusUnprocessableEntity, "maximal profile pics is 5")
 	}
-	if profPicParam.Selected {
-		_, err := u.userRepository.ProfilePicSelectedToFalse(profPicParam.UserId)
-		if err != nil {
-			return "", err
-		}
-	}
-	profPicParam.PictureLink = filepath.Base(profPicParam.PictureLink)
-	id, err := u.userRepository.CreateProfilePicture(profPicParam.UserId, profPicParam.PictureLink, profPicParam.Selected)
+	// the repository unselect the other picture in the same transaction
+	id, err := u.userRepository.CreateProfilePicture(profPicParam)
 	if err != nil {
 		return "", err
 	}
@@ -132,6 +132,81 @@ func (u *User) CreateNewProfilePic(profPicParam userEntity.ProfilePic) (string,
 	}
 	return id, nil
 }
+
+// GetProfilePics list the picture of userId ordered by its position
+func (u *User) GetProfilePics(userId string) ([]userEntity.ProfilePic, error) {
+	profPics, err := u.userRepository.SelectProfilePicture(userId, nil)
+	if err != nil {
+		return nil, err
+	}
+	return u.resolveProfilePics(profPics)
+}
+
+func (u *User) SelectProfilePic(userId, id string) error {
+	err := u.userRepository.UpdateSelectedProfilePicture(userId, id)
+	if err != nil {
+		return err
+	}
+	event.ProfileUpdated.Trigger(event.ProfileUpdatedPayload{
+		UserId: userId,
+	})
+	return nil
+}
+
+// ReorderProfilePics put the picture in the order of ids, ids must hold every picture of userId once
+func (u *User) ReorderProfilePics(userId string, ids []string) ([]userEntity.ProfilePic, error) {
+	profPics, err := u.userRepository.SelectProfilePicture(userId, nil)
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[string]bool, len(profPics))
+	for _, profPic := range profPics {
+		owned[profPic.Id] = true
+	}
+	for _, id := range ids {
+		if !owned[id] {
+			return nil, common.WrapWithNewError(ErrProfilePicOrder, http.StatusUnprocessableEntity, "ids must contain every profile picture exactly once")
+		}
+		delete(owned, id)
+	}
+	if len(owned) != 0 {
+		return nil, common.WrapWithNewError(ErrProfilePicOrder, http.StatusUnprocessableEntity, "ids must contain every profile picture exactly once")
+	}
+	err = u.userRepository.UpdateProfilePicturePosition(userId, ids)
+	if err != nil {
+		return nil, err
+	}
+	event.ProfileUpdated.Trigger(event.ProfileUpdatedPayload{
+		UserId: userId,
+	})
+	return u.GetProfilePics(userId)
+}
+
+// DeleteProfilePic return the deleted picture so the caller could delete its blob
+func (u *User) DeleteProfilePic(userId, id string) (userEntity.ProfilePic, error) {
+	deleted, err := u.userRepository.DeleteProfilePicture(userId, id)
+	if err != nil {
+		return userEntity.ProfilePic{}, err
+	}
+	event.ProfileUpdated.Trigger(event.ProfileUpdatedPayload{
+		UserId: userId,
+	})
+	return deleted, nil
+}
+
+func (u *User) resolveProfilePics(profPics []userEntity.ProfilePic) ([]userEntity.ProfilePic, error) {
+	for i := range profPics {
+		for _, link := range []*string{&profPics[i].PictureLink, &profPics[i].MediumLink, &profPics[i].ThumbnailLink} {
+			url, err := u.blobUrl.Resolve(*link)
+			if err != nil {
+				return nil, err
+			}
+			*link = url
+		}
+	}
+	return profPics, nil
+}
+
 func hashAndSalt(password string) (string, error) {
 	hashedPass, err := bcrypt.GenerateFromPassword([]byte(password), 12)
 	if err != nil {
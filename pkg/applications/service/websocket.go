@@ -2,6 +2,8 @@ package service
 
 import (
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -13,20 +15,155 @@ const (
 	writeWait  = 10 * time.Second
 	pongWait   = 20 * time.Second
 	pingPeriod = 10 * time.Second
+
+	// eventHistorySize is how many event per user kept for Last-Event-ID resume
+	eventHistorySize = 100
 )
 
-func NewWs() *Ws {
-	return &Ws{
-		Clients:       rwmap.New[websocketEntity.Conn, string](),
-		ReverseClient: rwmap.New[string, websocketEntity.Conn](),
+func NewWs(online *Online) *Ws {
+	ws := &Ws{
+		online:        online,
+		Clients:       rwmap.New[string, string](),
+		ReverseClient: rwmap.New[string, websocketEntity.Client](),
 		WsChan:        make(chan websocketEntity.Payload),
+		histories:     rwmap.New[string, *eventHistory](),
 	}
+	// seeded with time so event id keep increasing across restart
+	ws.lastEventId.Store(uint64(time.Now().UnixMicro()))
+	return ws
 }
 
 type Ws struct {
-	Clients       *rwmap.RwMap[websocketEntity.Conn, string]
-	ReverseClient *rwmap.RwMap[string, websocketEntity.Conn]
+	Clients       *rwmap.RwMap[string, string]
+	ReverseClient *rwmap.RwMap[string, websocketEntity.Client]
 	WsChan        chan websocketEntity.Payload
+
+	online      *Online
+	lastEventId atomic.Uint64
+	historyMu   sync.Mutex
+	histories   *rwmap.RwMap[string, *eventHistory]
+}
+
+// Register set client as the active connection of userId regardless of the transport, and mark the user online
+func (ws *Ws) Register(userId string, client websocketEntity.Client) {
+	ws.Clients.Set(client.Key(), userId)
+	ws.ReverseClient.Set(userId, client)
+	if err := ws.online.Heartbeat(userId); err != nil {
+		log.Println("presence err", err)
+	}
+}
+
+// Unregister remove the client, the user is marked offline when client was still the active connection
+func (ws *Ws) Unregister(client websocketEntity.Client) (string, bool) {
+	userId, active := ws.Detach(client)
+	if active {
+		if err := ws.online.PutOnline(userId, false); err != nil {
+			log.Println("presence err", err)
+		}
+	}
+	return userId, active
+}
+
+// Detach remove the client but keep the user presence, used when the client is expected to reconnect.
+// the stale sweeper mark the user offline if it never does
+func (ws *Ws) Detach(client websocketEntity.Client) (string, bool) {
+	userId, ok := ws.Clients.Get(client.Key())
+	if !ok {
+		return "", false
+	}
+	ws.Clients.Delete(client.Key())
+	active, ok := ws.ReverseClient.Get(userId)
+	if !ok || active.Key() != client.Key() {
+		return userId, false
+	}
+	ws.ReverseClient.Delete(userId)
+	if history, ok := ws.histories.Get(userId); ok {
+		history.touch(time.Now())
+	}
+	return userId, true
+}
+
+// Heartbeat refresh the presence of the user owning client
+func (ws *Ws) Heartbeat(client websocketEntity.Client) {
+	userId, ok := ws.Clients.Get(client.Key())
+	if !ok {
+		return
+	}
+	if err := ws.online.Heartbeat(userId); err != nil {
+		log.Println("presence err", err)
+	}
+}
+
+// Send give the response an event id, keep it for resume and write it to the user active connection
+func (ws *Ws) Send(userId string, resp websocketEntity.Response) error {
+	resp.Id = ws.lastEventId.Add(1)
+	ws.history(userId).add(resp)
+	return ws.SendTransient(userId, resp)
+}
+
+// SendTransient write the response without keeping it, used for ephemeral event like typing indicator
+func (ws *Ws) SendTransient(userId string, resp websocketEntity.Response) error {
+	client, ok := ws.ReverseClient.Get(userId)
+	if !ok {
+		return nil
+	}
+	err := client.SetWriteDeadline(time.Now().Add(writeWait))
+	if err == nil {
+		err = client.WriteJSON(resp)
+	}
+	if err != nil {
+		_ = client.Close()
+		ws.Unregister(client)
+		return err
+	}
+	return nil
+}
+
+// Replay return the kept event of userId after lastEventId
+func (ws *Ws) Replay(userId string, lastEventId uint64) []websocketEntity.Response {
+	history, ok := ws.histories.Get(userId)
+	if !ok {
+		return nil
+	}
+	return history.after(lastEventId)
+}
+
+func (ws *Ws) history(userId string) *eventHistory {
+	ws.historyMu.Lock()
+	defer ws.historyMu.Unlock()
+	history, ok := ws.histories.Get(userId)
+	if !ok {
+		history = &eventHistory{lastActive: time.Now()}
+		ws.histories.Set(userId, history)
+	}
+	return history
+}
+
+// SweepHistories remove the kept event of every user which has no connection since ttl
+func (ws *Ws) SweepHistories(ttl time.Duration) int {
+	ws.historyMu.Lock()
+	defer ws.historyMu.Unlock()
+	deadline := time.Now().Add(-ttl)
+	removed := 0
+	ws.histories.Range(func(userId string, history *eventHistory) bool {
+		if _, connected := ws.ReverseClient.Get(userId); connected {
+			return true
+		}
+		if history.inactiveSince(deadline) {
+			ws.histories.Delete(userId)
+			removed++
+		}
+		return true
+	})
+	return removed
+}
+
+func (ws *Ws) RunHistorySweeper(interval, ttl time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ws.SweepHistories(ttl)
+	}
 }
 
 func (ws *Ws) ListenForWsPayload(conn *websocketEntity.Conn) {
@@ -41,6 +178,7 @@ func (ws *Ws) ListenForWsPayload(conn *websocketEntity.Conn) {
 
 	conn.SetPongHandler(func(appData string) error {
 		conn.SetReadDeadline(time.Now().Add(pongWait))
+		ws.Heartbeat(*conn)
 		return nil
 	})
 
@@ -75,12 +213,45 @@ func (ws *Ws) PingTicker(conn *websocketEntity.Conn) {
 
 func (ws *Ws) cleanUp(conn *websocketEntity.Conn) {
 	conn.Close()
+	ws.Unregister(*conn)
+}
 
-	userId, ok := ws.Clients.Get(*conn)
-	if !ok {
-		return
+type eventHistory struct {
+	mu     sync.Mutex
+	events []websocketEntity.Response
+	// lastActive is when the history is created or the user last disconnected
+	lastActive time.Time
+}
+
+func (h *eventHistory) touch(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastActive = now
+}
+
+func (h *eventHistory) inactiveSince(deadline time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lastActive.Before(deadline)
+}
+
+func (h *eventHistory) add(resp websocketEntity.Response) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, resp)
+	if len(h.events) > eventHistorySize {
+		h.events = h.events[len(h.events)-eventHistorySize:]
 	}
-	ws.Clients.Delete(*conn)
-	ws.ReverseClient.Delete(userId)
+}
 
+func (h *eventHistory) after(lastEventId uint64) []websocketEntity.Response {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	events := make([]websocketEntity.Response, 0)
+	for _, event := range h.events {
+		if event.Id > lastEventId {
+			events = append(events, event)
+		}
+	}
+	return events
 }
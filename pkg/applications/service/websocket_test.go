@@ -0,0 +1,54 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	websocketEntity "github.com/xyedo/blindate/pkg/domain/ws"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+type stubClient struct {
+	key string
+}
+
+func (s stubClient) Key() string                      { return s.key }
+func (s stubClient) WriteJSON(v any) error            { return nil }
+func (s stubClient) SetWriteDeadline(time.Time) error { return nil }
+func (s stubClient) Close() error                     { return nil }
+
+func Test_SweepHistories(t *testing.T) {
+	ws := NewWs(nil)
+	offlineId := util.RandomUUID()
+	connectedId := util.RandomUUID()
+	disconnectedId := util.RandomUUID()
+
+	connected := stubClient{key: util.RandomUUID()}
+	ws.Clients.Set(connected.Key(), connectedId)
+	ws.ReverseClient.Set(connectedId, connected)
+	disconnected := stubClient{key: util.RandomUUID()}
+	ws.Clients.Set(disconnected.Key(), disconnectedId)
+	ws.ReverseClient.Set(disconnectedId, disconnected)
+
+	for _, userId := range []string{offlineId, connectedId, disconnectedId} {
+		require.NoError(t, ws.Send(userId, websocketEntity.Response{Action: "test"}))
+		history, ok := ws.histories.Get(userId)
+		require.True(t, ok)
+		history.touch(time.Now().Add(-time.Hour))
+	}
+
+	// disconnecting refresh the history so the client still could resume
+	_, active := ws.Detach(disconnected)
+	require.True(t, active)
+
+	assert.Equal(t, 1, ws.SweepHistories(10*time.Minute))
+	assert.Empty(t, ws.Replay(offlineId, 0))
+	assert.Len(t, ws.Replay(connectedId, 0), 1)
+	assert.Len(t, ws.Replay(disconnectedId, 0), 1)
+
+	assert.Equal(t, 1, ws.SweepHistories(0))
+	assert.Empty(t, ws.Replay(disconnectedId, 0))
+	assert.Len(t, ws.Replay(connectedId, 0), 1)
+}
@@ -20,13 +20,19 @@ func (e sentinelAPIError) APIError() (int, string) {
 type sentinelWrappedError struct {
 	error
 	sentinel *sentinelAPIError
+	// msg override the sentinel message without mutating the shared sentinel
+	msg string
 }
 
 func (e sentinelWrappedError) Is(err error) bool {
 	return errors.Is(err, e.sentinel)
 }
 func (e sentinelWrappedError) APIError() (int, string) {
-	return e.sentinel.APIError()
+	status, msg := e.sentinel.APIError()
+	if e.msg != "" {
+		msg = e.msg
+	}
+	return status, msg
 }
 
 func WrapError(err error, sentinel *sentinelAPIError) error {
@@ -37,7 +43,5 @@ func WrapWithNewError(err error, status int, msg string) error {
 }
 
 func WrapErrorWithMsg(err error, sentinel *sentinelAPIError, msg string) error {
-	wrapedErr := sentinelWrappedError{error: err, sentinel: sentinel}
-	wrapedErr.sentinel.msg = msg
-	return wrapedErr
+	return sentinelWrappedError{error: err, sentinel: sentinel, msg: msg}
 }
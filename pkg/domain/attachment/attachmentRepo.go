@@ -0,0 +1,6 @@
+package attachmentEntity
+
+type Repository interface {
+	// SelectReferencedBlob return the keys still referenced by a chat attachment or a profile picture
+	SelectReferencedBlob(keys []string) ([]string, error)
+}
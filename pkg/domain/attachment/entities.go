@@ -1,8 +1,24 @@
 package attachmentEntity
 
+import "time"
+
 type Uploader struct {
 	Length      int64
 	ContentType string
 	Prefix      string
 	Ext         string
 }
+
+type Blob struct {
+	Key          string
+	LastModified time.Time
+}
+
+// GcReport summarize one run of the orphaned blob collector
+type GcReport struct {
+	DryRun  bool     `json:"dryRun"`
+	Scanned int      `json:"scanned"`
+	Orphans []string `json:"orphans"`
+	Deleted int      `json:"deleted"`
+	Failed  int      `json:"failed"`
+}
@@ -0,0 +1,75 @@
+package attachmentEntity
+
+import "time"
+
+var (
+	AudioTypes = []string{"application/ogg", "audio/mpeg"}
+	ImageTypes = []string{"image/jpeg", "image/png", "image/gif"}
+	VideoTypes = []string{"video/mp4"}
+)
+
+func IsAudio(mediaType string) bool {
+	return contains(AudioTypes, mediaType)
+}
+
+func IsImage(mediaType string) bool {
+	return contains(ImageTypes, mediaType)
+}
+
+func IsVideo(mediaType string) bool {
+	return contains(VideoTypes, mediaType)
+}
+
+// IsVisual tell whether the media could reveal the sender
+func IsVisual(mediaType string) bool {
+	return IsImage(mediaType) || IsVideo(mediaType)
+}
+
+func contains(mediaTypes []string, mediaType string) bool {
+	for _, valid := range mediaTypes {
+		if valid == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// Limits bound the media accepted on chat and profile picture
+type Limits struct {
+	MaxAudioBytes    int64
+	MaxImageBytes    int64
+	MaxVideoBytes    int64
+	MaxDimension     int
+	MaxVoiceDuration time.Duration
+	MaxVideoDuration time.Duration
+	ThumbnailSize    int
+	// Profile is the longest side in pixel of each profile picture rendition
+	Profile ProfileSizes
+}
+
+type ProfileSizes struct {
+	Full      int
+	Medium    int
+	Thumbnail int
+}
+
+// Processed is the sanitized media ready to be uploaded
+type Processed struct {
+	Content   []byte
+	MediaType string
+	Ext       string
+	Width     int
+	Height    int
+	Duration  time.Duration
+	// Thumbnail is jpeg encoded, nil for video and audio
+	Thumbnail []byte
+	// Waveform is the peak of each bar between 0 and 100, only for audio
+	Waveform []int
+}
+
+// ProfileRenditions is the jpeg re-encoded profile picture, stripped from its metadata
+type ProfileRenditions struct {
+	Full      []byte
+	Medium    []byte
+	Thumbnail []byte
+}
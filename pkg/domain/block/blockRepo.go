@@ -0,0 +1,13 @@
+package block
+
+import (
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+)
+
+type Repository interface {
+	InsertBlock(blockerId, blockedId string) error
+	DeleteBlock(blockerId, blockedId string) error
+	SelectBlocked(blockerId string) ([]blockEntity.Block, error)
+	IsBlocked(userId, otherId string) (bool, error)
+	InsertReport(report *blockEntity.Report) error
+}
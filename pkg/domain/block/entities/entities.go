@@ -0,0 +1,51 @@
+package blockEntity
+
+import "time"
+
+// Block is the user blocked by the viewer
+type Block struct {
+	UserId    string    `json:"userId" db:"user_id"`
+	Alias     string    `json:"alias" db:"alias"`
+	BlockedAt time.Time `json:"blockedAt" db:"created_at"`
+}
+
+type Reason string
+
+const (
+	Spam          Reason = "spam"
+	Harassment    Reason = "harassment"
+	Inappropriate Reason = "inappropriate"
+	FakeProfile   Reason = "fake_profile"
+	Underage      Reason = "underage"
+	Other         Reason = "other"
+)
+
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"
+	ReportResolved  ReportStatus = "resolved"
+	ReportDismissed ReportStatus = "dismissed"
+)
+
+// Report of an abuse, ChatId point to the reported message of their conversation
+type Report struct {
+	Id         string       `json:"id" db:"id"`
+	ReporterId string       `json:"reporterId" db:"reporter_id"`
+	ReportedId string       `json:"reportedId" db:"reported_id"`
+	Reason     Reason       `json:"reason" db:"reason"`
+	ChatId     *string      `json:"chatId" db:"chat_id"`
+	Details    *string      `json:"details" db:"details"`
+	CreatedAt  time.Time    `json:"createdAt" db:"created_at"`
+	Status     ReportStatus `json:"status" db:"status"`
+	ReviewedBy *string      `json:"reviewedBy" db:"reviewed_by"`
+	ReviewedAt *time.Time   `json:"reviewedAt" db:"reviewed_at"`
+	Resolution *string      `json:"resolution" db:"resolution"`
+}
+
+type NewReport struct {
+	ReportedId string  `json:"reportedId" binding:"required,uuid"`
+	Reason     string  `json:"reason" binding:"required,oneof=spam harassment inappropriate fake_profile underage other"`
+	ChatId     *string `json:"chatId" binding:"omitempty,uuid"`
+	Details    *string `json:"details" binding:"omitempty,max=1000"`
+}
@@ -17,7 +17,14 @@ type Cursor struct {
 }
 type Repository interface {
 	InsertNewChat(content *chatEntity.DAO) error
-	SelectChat(convoId string, filter Filter) ([]chatEntity.DAO, error)
-	UpdateSeenChat(convId, authorId string) ([]string, error)
-	DeleteChatById(chatId string) error
+	SelectChat(convoId, viewerId string, filter Filter) ([]chatEntity.DAO, error)
+	SelectChatById(chatId string) (chatEntity.DAO, error)
+	SelectChatContext(chatId string, size int) ([]chatEntity.DAO, error)
+	UpdateSeenChat(convId, authorId, upToChatId string, seenAt time.Time) ([]string, error)
+	UpdateDeliveredChat(recipientId string, chatIds []string, deliveredAt time.Time) ([]chatEntity.DAO, error)
+	UpdateChatMessage(chatId, messages string, editedAt, editableSince time.Time) (chatEntity.DAO, error)
+	SelectChatHistory(chatId string) ([]chatEntity.History, error)
+	DeleteChatById(chatId string, deletedAt time.Time) error
+	InsertReaction(chatId, userId, emoji string) (bool, error)
+	DeleteReaction(chatId, userId, emoji string) error
 }
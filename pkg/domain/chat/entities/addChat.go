@@ -1,6 +1,15 @@
 package chatEntity
 
+type SeenWatermark struct {
+	UpTo string `json:"upTo" binding:"required,uuid"`
+}
+
 type New struct {
 	Message string  `json:"message" binding:"required,max=4096"`
 	ReplyTo *string `json:"replyTo" binding:"omitempty,uuid"`
 }
+
+type NewSticker struct {
+	StickerId string  `json:"stickerId" binding:"required,uuid"`
+	ReplyTo   *string `json:"replyTo" binding:"omitempty,uuid"`
+}
@@ -12,6 +12,11 @@ type DAO struct {
 	Messages       string         `db:"messages"`
 	ReplyTo        sql.NullString `db:"reply_to"`
 	SentAt         time.Time      `db:"sent_at"`
+	DeliveredAt    sql.NullTime   `db:"delivered_at"`
 	SeenAt         sql.NullTime   `db:"seen_at"`
+	EditedAt       sql.NullTime   `db:"edited_at"`
+	DeletedAt      sql.NullTime   `db:"deleted_at"`
 	Attachment     *Attachment    `db:"attachment"`
+	Sticker        *Sticker       `db:"sticker"`
+	Reactions      []Reaction     `db:"-"`
 }
@@ -4,14 +4,22 @@ import "time"
 
 // Chat one convoersation to many chat
 type DTO struct {
-	Id             string      `json:"id"`
-	ConversationId string      `json:"conversationId"`
-	Author         string      `json:"author"`
-	Messages       string      `json:"messages"`
-	ReplyTo        *string     `json:"replyTo"`
-	SentAt         time.Time   `json:"sentAt"`
-	SeenAt         *time.Time  `json:"seenAt"`
-	Attachment     *Attachment `json:"attachment"`
+	Id             string     `json:"id"`
+	ConversationId string     `json:"conversationId"`
+	Author         string     `json:"author"`
+	Messages       string     `json:"messages"`
+	ReplyTo        *string    `json:"replyTo"`
+	SentAt         time.Time  `json:"sentAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt"`
+	SeenAt         *time.Time `json:"seenAt"`
+	EditedAt       *time.Time `json:"editedAt"`
+	// DeletedAt marks a tombstone, the messages and attachment is already wiped
+	DeletedAt  *time.Time  `json:"deletedAt"`
+	Attachment *Attachment `json:"attachment"`
+	Sticker    *Sticker    `json:"sticker"`
+	Kind       Kind        `json:"kind"`
+	// Reactions is only loaded on the chat list
+	Reactions []Reaction `json:"reactions,omitempty"`
 }
 
 // Attachment one to one with chat
@@ -19,4 +27,21 @@ type Attachment struct {
 	ChatId    string `json:"-" db:"chat_id"`
 	BlobLink  string `json:"blobLink" db:"blob_link"`
 	MediaType string `json:"mediaType" db:"media_type"`
+	// Width and Height is set for image and video
+	Width  *int `json:"width,omitempty" db:"width"`
+	Height *int `json:"height,omitempty" db:"height"`
+	// DurationMs is set for video and voice note
+	DurationMs *int `json:"durationMs,omitempty" db:"duration_ms"`
+	// Waveform is the peak between 0 and 100 of the voice note
+	Waveform []int `json:"waveform,omitempty" db:"waveform"`
+	// ThumbnailLink is the downscaled jpeg of the image
+	ThumbnailLink *string `json:"thumbnailLink,omitempty" db:"thumbnail_link"`
+}
+
+// Sticker is the catalogue sticker sent as the chat
+type Sticker struct {
+	Id        string `json:"id"`
+	PackId    string `json:"packId"`
+	BlobLink  string `json:"blobLink"`
+	MediaType string `json:"mediaType"`
 }
@@ -0,0 +1,16 @@
+package chatEntity
+
+import "time"
+
+// EditWindow is how long after sent the author could still edit the chat
+const EditWindow = 15 * time.Minute
+
+type Edit struct {
+	Message string `json:"message" binding:"required,max=4096"`
+}
+
+// History is the previous version of an edited chat, EditedAt is when it got replaced
+type History struct {
+	Messages string    `json:"messages" db:"messages"`
+	EditedAt time.Time `json:"editedAt" db:"edited_at"`
+}
@@ -0,0 +1,24 @@
+package chatEntity
+
+// Kind tell the client how to render the chat
+type Kind string
+
+const (
+	KindText       Kind = "text"
+	KindAttachment Kind = "attachment"
+	KindSticker    Kind = "sticker"
+	KindDeleted    Kind = "deleted"
+)
+
+func KindOf(chat DTO) Kind {
+	switch {
+	case chat.DeletedAt != nil:
+		return KindDeleted
+	case chat.Sticker != nil:
+		return KindSticker
+	case chat.Attachment != nil:
+		return KindAttachment
+	default:
+		return KindText
+	}
+}
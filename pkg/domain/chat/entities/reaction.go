@@ -0,0 +1,12 @@
+package chatEntity
+
+// Reaction is the aggregate of an emoji on a chat, Reacted is whether the viewer is one of the reactor
+type Reaction struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	Reacted bool   `json:"reacted"`
+}
+
+type NewReaction struct {
+	Emoji string `json:"emoji" binding:"required,validemoji"`
+}
@@ -0,0 +1,42 @@
+package chatEntity
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// maxEmojiRunes is enough for the longest zwj sequence (family, flag of subdivision)
+const maxEmojiRunes = 16
+
+func ValidEmoji(fl validator.FieldLevel) bool {
+	data, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return IsEmoji(data)
+}
+
+// IsEmoji report whether s is a single emoji, including its modifier and zwj sequence
+func IsEmoji(s string) bool {
+	if s == "" || utf8.RuneCountInString(s) > maxEmojiRunes {
+		return false
+	}
+	var hasSymbol, hasKeycap bool
+	for _, r := range s {
+		switch {
+		case r == '\u20e3': // combining keycap
+			hasKeycap = true
+		case r == '\u200d', r == '\ufe0f', r == '\ufe0e': // zwj and variation selector
+		case r >= 0x1f3fb && r <= 0x1f3ff: // skin tone
+		case r >= 0xe0020 && r <= 0xe007f: // subdivision flag tag
+		case unicode.Is(unicode.So, r):
+			hasSymbol = true
+		case r == '#' || r == '*' || (r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return hasSymbol || hasKeycap
+}
@@ -5,7 +5,13 @@ import (
 )
 
 type Filter struct {
-	Offset int
+	Cursor *Cursor
+	Limit  int
+	// Archived list the archived conversation instead of the inbox
+	Archived   bool
+	UnreadOnly bool
+	// Revealed when set, only list conversation which reveal is (or not yet) accepted
+	Revealed *bool
 }
 type Repository interface {
 	InsertConversation(matchId string) (string, error)
@@ -14,4 +20,6 @@ type Repository interface {
 	UpdateDayPass(convoId string) error
 	UpdateChatRow(convoId string) error
 	DeleteConversationById(convoId string) error
+	UpdateState(convoId, userId string, state convEntity.State) (convEntity.State, error)
+	SelectTotalUnread(userId string) (int, error)
 }
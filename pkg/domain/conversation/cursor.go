@@ -0,0 +1,60 @@
+package conversation
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	convEntity "github.com/xyedo/blindate/pkg/domain/conversation/entities"
+)
+
+var ErrInvalidCursor = errors.New("invalid conversation cursor")
+
+// Cursor is the position of a conversation in the list ordered by pinned, last activity and id
+type Cursor struct {
+	Pinned bool
+	At     time.Time
+	Id     string
+}
+
+func CursorOf(conv convEntity.DTO) Cursor {
+	var cursor Cursor
+	cursor.Id = conv.Id
+	if conv.State != nil {
+		cursor.Pinned = conv.State.Pinned
+		cursor.At = conv.State.LastActivityAt
+	}
+	return cursor
+}
+
+// Encode return opaque cursor that is safe to be put in url
+func (c Cursor) Encode() string {
+	raw := strings.Join([]string{strconv.FormatBool(c.Pinned), c.At.UTC().Format(time.RFC3339Nano), c.Id}, "|")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(encoded string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(parts[2]); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	pinned, err := strconv.ParseBool(parts[0])
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	at, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{Pinned: pinned, At: at, Id: parts[2]}, nil
+}
@@ -21,4 +21,5 @@ type DTO struct {
 	DayPass           int        `json:"dayPass" db:"day_pass"`
 	RequestStatus     string     `json:"-"`
 	RevealStatus      string     `json:"-"`
+	State             *State     `json:"state,omitempty"`
 }
@@ -0,0 +1,19 @@
+package convEntity
+
+import "time"
+
+// State is the per user inbox state of a conversation
+type State struct {
+	UnreadCount int        `json:"unreadCount"`
+	MutedUntil  *time.Time `json:"mutedUntil"`
+	Pinned      bool       `json:"pinned"`
+	Archived    bool       `json:"archived"`
+	// LastActivityAt is the last message sent at, or when the conversation created
+	LastActivityAt time.Time `json:"lastActivityAt"`
+}
+
+type NewState struct {
+	MutedUntil *time.Time `json:"mutedUntil"`
+	Pinned     *bool      `json:"pinned" binding:"required"`
+	Archived   *bool      `json:"archived" binding:"required"`
+}
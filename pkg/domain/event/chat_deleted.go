@@ -0,0 +1,27 @@
+package event
+
+import "time"
+
+var ChatDeleted chatDeleted
+
+type ChatDeletedPayload struct {
+	ConvId      string
+	RequestFrom string
+	RequestTo   string
+	ChatId      string
+	DeletedAt   time.Time
+}
+
+type chatDeleted struct {
+	handlers []interface{ HandleDeletedChatEvent(ChatDeletedPayload) }
+}
+
+func (m *chatDeleted) Register(handler interface{ HandleDeletedChatEvent(ChatDeletedPayload) }) {
+	m.handlers = append(m.handlers, handler)
+}
+
+func (m chatDeleted) Trigger(payload ChatDeletedPayload) {
+	for _, handler := range m.handlers {
+		go handler.HandleDeletedChatEvent(payload)
+	}
+}
@@ -0,0 +1,28 @@
+package event
+
+import (
+	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
+)
+
+var ChatEdited chatEdited
+
+type ChatEditedPayload struct {
+	ConvId      string
+	RequestFrom string
+	RequestTo   string
+	Chat        chatEntity.DTO
+}
+
+type chatEdited struct {
+	handlers []interface{ HandleEditedChatEvent(ChatEditedPayload) }
+}
+
+func (m *chatEdited) Register(handler interface{ HandleEditedChatEvent(ChatEditedPayload) }) {
+	m.handlers = append(m.handlers, handler)
+}
+
+func (m chatEdited) Trigger(payload ChatEditedPayload) {
+	for _, handler := range m.handlers {
+		go handler.HandleEditedChatEvent(payload)
+	}
+}
@@ -0,0 +1,27 @@
+package event
+
+import "time"
+
+var ChatDelivered chatDelivered
+
+type ChatDeliveredPayload struct {
+	ConvId           string
+	AuthorId         string
+	RecipientId      string
+	DeliveredChatIds []string
+	DeliveredAt      time.Time
+}
+
+type chatDelivered struct {
+	handlers []interface{ HandleDeliveredAtEvent(ChatDeliveredPayload) }
+}
+
+func (m *chatDelivered) Register(handler interface{ HandleDeliveredAtEvent(ChatDeliveredPayload) }) {
+	m.handlers = append(m.handlers, handler)
+}
+
+func (m chatDelivered) Trigger(payload ChatDeliveredPayload) {
+	for _, handler := range m.handlers {
+		go handler.HandleDeliveredAtEvent(payload)
+	}
+}
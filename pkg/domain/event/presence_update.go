@@ -0,0 +1,25 @@
+package event
+
+import "time"
+
+var PresenceChanged presenceChanged
+
+type PresenceChangedPayload struct {
+	UserId     string
+	IsOnline   bool
+	LastOnline time.Time
+}
+
+type presenceChanged struct {
+	handlers []interface{ HandlePresenceChangedEvent(PresenceChangedPayload) }
+}
+
+func (p *presenceChanged) Register(handler interface{ HandlePresenceChangedEvent(PresenceChangedPayload) }) {
+	p.handlers = append(p.handlers, handler)
+}
+
+func (p presenceChanged) Trigger(payload PresenceChangedPayload) {
+	for _, handler := range p.handlers {
+		go handler.HandlePresenceChangedEvent(payload)
+	}
+}
@@ -0,0 +1,28 @@
+package event
+
+var ReactionChanged reactionChanged
+
+type ReactionChangedPayload struct {
+	ConvId      string
+	RequestFrom string
+	RequestTo   string
+	ChatId      string
+	UserId      string
+	Emoji       string
+	// Added false means the reaction is removed
+	Added bool
+}
+
+type reactionChanged struct {
+	handlers []interface{ HandleReactionChangedEvent(ReactionChangedPayload) }
+}
+
+func (m *reactionChanged) Register(handler interface{ HandleReactionChangedEvent(ReactionChangedPayload) }) {
+	m.handlers = append(m.handlers, handler)
+}
+
+func (m reactionChanged) Trigger(payload ReactionChangedPayload) {
+	for _, handler := range m.handlers {
+		go handler.HandleReactionChangedEvent(payload)
+	}
+}
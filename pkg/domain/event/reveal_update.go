@@ -9,6 +9,8 @@ var MatchRevealed matchRevealed
 type MatchRevealedPayload struct {
 	MatchId     string
 	MatchStatus matchEntity.Status
+	// ActorId is empty when the reveal is moved by the server, eg: expiry
+	ActorId string
 }
 
 type matchRevealed struct {
@@ -1,11 +1,16 @@
 package event
 
+import "time"
+
 var ChatSeen chatSeen
 
 type ChatSeenPayload struct {
+	ConvId      string
 	RequestFrom string
 	RequestTo   string
+	UpToChatId  string
 	SeenChatIds []string
+	SeenAt      time.Time
 }
 
 type chatSeen struct {
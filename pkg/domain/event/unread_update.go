@@ -0,0 +1,21 @@
+package event
+
+var UnreadChanged unreadChanged
+
+type UnreadChangedPayload struct {
+	UserId string
+}
+
+type unreadChanged struct {
+	handlers []interface{ HandleUnreadChangedEvent(UnreadChangedPayload) }
+}
+
+func (m *unreadChanged) Register(handler interface{ HandleUnreadChangedEvent(UnreadChangedPayload) }) {
+	m.handlers = append(m.handlers, handler)
+}
+
+func (m unreadChanged) Trigger(payload UnreadChangedPayload) {
+	for _, handler := range m.handlers {
+		go handler.HandleUnreadChangedEvent(payload)
+	}
+}
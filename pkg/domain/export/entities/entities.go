@@ -0,0 +1,93 @@
+package exportEntity
+
+import "time"
+
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusProcessing Status = "processing"
+	StatusReady      Status = "ready"
+	StatusFailed     Status = "failed"
+	// StatusExpired the archive is deleted, a new export has to be requested
+	StatusExpired Status = "expired"
+)
+
+// Job is one export request, the archive is downloadable until ExpiresAt
+type Job struct {
+	Id          string     `db:"id" json:"id"`
+	UserId      string     `db:"user_id" json:"userId"`
+	Status      Status     `db:"status" json:"status"`
+	BlobKey     *string    `db:"blob_key" json:"-"`
+	SizeBytes   *int64     `db:"size_bytes" json:"sizeBytes,omitempty"`
+	Error       *string    `db:"error" json:"error,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"createdAt"`
+	StartedAt   *time.Time `db:"started_at" json:"startedAt,omitempty"`
+	CompletedAt *time.Time `db:"completed_at" json:"completedAt,omitempty"`
+	ExpiresAt   *time.Time `db:"expires_at" json:"expiresAt,omitempty"`
+	// DownloadUrl is signed on read, only when the archive is ready
+	DownloadUrl string `db:"-" json:"downloadUrl,omitempty"`
+}
+
+type Match struct {
+	Id            string     `db:"id" json:"id"`
+	RequestFrom   string     `db:"request_from" json:"requestFrom"`
+	RequestTo     string     `db:"request_to" json:"requestTo"`
+	RequestStatus string     `db:"request_status" json:"requestStatus"`
+	CreatedAt     time.Time  `db:"created_at" json:"createdAt"`
+	AcceptedAt    *time.Time `db:"accepted_at" json:"acceptedAt,omitempty"`
+	RevealStatus  string     `db:"reveal_status" json:"revealStatus"`
+	RevealedAt    *time.Time `db:"revealed_at" json:"revealedAt,omitempty"`
+}
+
+// Conversation is the state of the conversation seen by the user
+type Conversation struct {
+	Id             string     `db:"id" json:"id"`
+	PartnerId      string     `db:"partner_id" json:"partnerId"`
+	ChatRows       int        `db:"chat_rows" json:"chatRows"`
+	DayPass        int        `db:"day_pass" json:"dayPass"`
+	Pinned         bool       `db:"pinned" json:"pinned"`
+	Archived       bool       `db:"archived" json:"archived"`
+	MutedUntil     *time.Time `db:"muted_until" json:"mutedUntil,omitempty"`
+	LastActivityAt *time.Time `db:"last_activity_at" json:"lastActivityAt,omitempty"`
+}
+
+// Chat is the message authored by the user, the partner message is their own data
+type Chat struct {
+	Id             string     `db:"id" json:"id"`
+	ConversationId string     `db:"conversation_id" json:"conversationId"`
+	Messages       string     `db:"messages" json:"messages"`
+	ReplyTo        *string    `db:"reply_to" json:"replyTo,omitempty"`
+	StickerId      *string    `db:"sticker_id" json:"stickerId,omitempty"`
+	SentAt         time.Time  `db:"sent_at" json:"sentAt"`
+	DeliveredAt    *time.Time `db:"delivered_at" json:"deliveredAt,omitempty"`
+	SeenAt         *time.Time `db:"seen_at" json:"seenAt,omitempty"`
+	EditedAt       *time.Time `db:"edited_at" json:"editedAt,omitempty"`
+	DeletedAt      *time.Time `db:"deleted_at" json:"deletedAt,omitempty"`
+	MediaType      *string    `db:"media_type" json:"mediaType,omitempty"`
+	BlobKey        *string    `db:"blob_link" json:"-"`
+	// MediaPath is the path of the attachment inside the archive
+	MediaPath string `db:"-" json:"mediaPath,omitempty"`
+}
+
+type ManifestFile struct {
+	Path        string `json:"path"`
+	Description string `json:"description"`
+	Records     int    `json:"records,omitempty"`
+}
+
+// Manifest describe every file of the archive, the media failed to be fetched is listed in MissingMedia
+type Manifest struct {
+	ExportId     string         `json:"exportId"`
+	UserId       string         `json:"userId"`
+	GeneratedAt  time.Time      `json:"generatedAt"`
+	Files        []ManifestFile `json:"files"`
+	MissingMedia []string       `json:"missingMedia"`
+}
+
+// Report summarize one run of the exporter
+type Report struct {
+	Ready   int `json:"ready"`
+	Failed  int `json:"failed"`
+	Expired int `json:"expired"`
+}
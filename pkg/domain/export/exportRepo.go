@@ -0,0 +1,21 @@
+package export
+
+import (
+	"time"
+
+	exportEntity "github.com/xyedo/blindate/pkg/domain/export/entities"
+)
+
+type Repository interface {
+	InsertJob(job *exportEntity.Job) error
+	SelectJobById(exportId string) (exportEntity.Job, error)
+	SelectJobsByUserId(userId string) ([]exportEntity.Job, error)
+	// ClaimJob mark the oldest pending job as processing, the job still processing since before staleBefore is claimed again
+	ClaimJob(startedAt, staleBefore time.Time) (exportEntity.Job, error)
+	UpdateJob(job exportEntity.Job) error
+	SelectExpiredJobs(now time.Time, limit int) ([]exportEntity.Job, error)
+
+	SelectMatches(userId string) ([]exportEntity.Match, error)
+	SelectConversations(userId string) ([]exportEntity.Conversation, error)
+	SelectAuthoredChats(userId string) ([]exportEntity.Chat, error)
+}
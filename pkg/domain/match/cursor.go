@@ -0,0 +1,51 @@
+package match
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+)
+
+var ErrInvalidCursor = errors.New("invalid match cursor")
+
+// Cursor is the position of a match in the list ordered by created_at and id
+type Cursor struct {
+	At time.Time
+	Id string
+}
+
+func CursorOf(match matchEntity.FullUserDTO) Cursor {
+	return Cursor{
+		At: match.CreatedAt,
+		Id: match.MatchId,
+	}
+}
+
+// Encode return opaque cursor that is safe to be put in url
+func (c Cursor) Encode() string {
+	raw := c.At.UTC().Format(time.RFC3339Nano) + "|" + c.Id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(encoded string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 2 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(parts[1]); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	at, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{At: at, Id: parts[1]}, nil
+}
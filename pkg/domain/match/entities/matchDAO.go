@@ -14,4 +14,10 @@ type MatchDAO struct {
 	AcceptedAt    sql.NullTime `db:"accepted_at"`
 	RevealStatus  string       `db:"reveal_status"`
 	RevealedAt    sql.NullTime `db:"revealed_at"`
+	// RevealRequestedBy is the participant waiting for the other to answer the reveal
+	RevealRequestedBy sql.NullString `db:"reveal_requested_by"`
+	RevealRequestedAt sql.NullTime   `db:"reveal_requested_at"`
+	RevealExpiresAt   sql.NullTime   `db:"reveal_expires_at"`
+	// Blocked is true when either participant blocked the other
+	Blocked bool `db:"blocked"`
 }
@@ -0,0 +1,23 @@
+package matchEntity
+
+import "time"
+
+// EventKind is the status of the match moved by the event
+type EventKind string
+
+const (
+	KindRequest EventKind = "request"
+	KindReveal  EventKind = "reveal"
+)
+
+// Event is one transition of the match, the timeline is never updated
+type Event struct {
+	Id      string `json:"id" db:"id"`
+	MatchId string `json:"matchId" db:"match_id"`
+	// ActorId is nil when the transition is made by the server
+	ActorId    *string   `json:"actorId" db:"actor_id"`
+	Kind       EventKind `json:"kind" db:"kind"`
+	FromStatus Status    `json:"fromStatus" db:"from_status"`
+	ToStatus   Status    `json:"toStatus" db:"to_status"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+}
@@ -12,6 +12,12 @@ const (
 	Requested Status = "requested"
 	Declined  Status = "declined"
 	Accepted  Status = "accepted"
+	// Withdrawn is the request taken back by the requester before it is answered
+	Withdrawn Status = "withdrawn"
+	// Cancelled is the reveal taken back by its requester before it is answered
+	Cancelled Status = "cancelled"
+	// Expired is the reveal nobody answered in time
+	Expired Status = "expired"
 )
 
 var ErrInvalidMatchStatusFormat = errors.New("invalid MatchStatus format")
@@ -1,6 +1,13 @@
 package matchEntity
 
+import "time"
+
 type FullUserDTO struct {
-	MatchId string `json:"matchId"`
+	MatchId       string `json:"matchId"`
+	RequestStatus Status `json:"requestStatus"`
+	// Sent is true when the user is the requester, the embedded user is always the other participant
+	Sent       bool       `json:"sent"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty"`
 	UserDTO
 }
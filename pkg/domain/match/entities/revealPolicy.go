@@ -0,0 +1,12 @@
+package matchEntity
+
+import "time"
+
+// RevealPolicy configure the reveal handshake
+type RevealPolicy struct {
+	// Ttl is how long the reveal request wait for the other participant, zero never expire it
+	Ttl time.Duration
+	// Mutual require both participant to request the reveal instead of accepting it,
+	// the second request reveal the match
+	Mutual bool
+}
@@ -1,12 +1,41 @@
 package match
 
 import (
+	"time"
+
 	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
 )
 
+// Box is the list of match seen by the user
+type Box string
+
+const (
+	// BoxReceived is the pending request to the user
+	BoxReceived Box = "received"
+	// BoxSent is the pending request from the user
+	BoxSent     Box = "sent"
+	BoxAccepted Box = "accepted"
+	BoxDeclined Box = "declined"
+)
+
+type Filter struct {
+	Box    Box
+	Cursor *Cursor
+	Limit  int
+}
+
 type Repository interface {
+	// InsertNewMatch also record the first event of the match, the requester is the actor
 	InsertNewMatch(fromUserId, toUserId string, reqStatus matchEntity.Status) (string, error)
-	SelectMatchReqToUserId(userId string) ([]matchEntity.FullUserDTO, error)
+	// SelectMatchesByUserId list the match of the box, the oldest first. the blocked pair is left out
+	SelectMatchesByUserId(userId string, filter Filter) ([]matchEntity.FullUserDTO, error)
 	UpdateMatchById(matchEntity matchEntity.MatchDAO) error
 	GetMatchById(matchId string) (matchEntity.MatchDAO, error)
+	// TransitionMatch update the match and append the event in one transaction,
+	// the match is a conflict when its status is no longer the FromStatus of the event
+	TransitionMatch(matchDAO matchEntity.MatchDAO, event *matchEntity.Event) error
+	// SelectEventsByMatchId return the timeline of the match, the oldest first
+	SelectEventsByMatchId(matchId string) ([]matchEntity.Event, error)
+	// SelectExpiredReveals return the pending reveal whose expiry has passed, the oldest expiry first
+	SelectExpiredReveals(now time.Time, limit int) ([]matchEntity.MatchDAO, error)
 }
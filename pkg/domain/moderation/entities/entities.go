@@ -0,0 +1,63 @@
+package moderationEntity
+
+import (
+	"time"
+
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+)
+
+type Action string
+
+const (
+	ActionReportResolved    Action = "report.resolved"
+	ActionReportDismissed   Action = "report.dismissed"
+	ActionReportContextRead Action = "report.context_read"
+	ActionUserSuspended     Action = "user.suspended"
+	ActionUserBanned        Action = "user.banned"
+	ActionSuspensionLifted  Action = "user.suspension_lifted"
+	ActionRoleChanged       Action = "user.role_changed"
+)
+
+// Moderator is the staff taking the action
+type Moderator struct {
+	Id   string
+	Role userEntity.Role
+}
+
+// AuditLog record every moderator action, nil ModeratorId is taken by the system
+type AuditLog struct {
+	Id           string    `json:"id" db:"id"`
+	ModeratorId  *string   `json:"moderatorId" db:"moderator_id"`
+	Action       Action    `json:"action" db:"action"`
+	TargetUserId *string   `json:"targetUserId" db:"target_user_id"`
+	ReportId     *string   `json:"reportId" db:"report_id"`
+	Details      *string   `json:"details" db:"details"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Suspension restrict the user until EndsAt, nil EndsAt is a permanent ban
+type Suspension struct {
+	Id          string     `json:"id" db:"id"`
+	UserId      string     `json:"userId" db:"user_id"`
+	ModeratorId *string    `json:"moderatorId" db:"moderator_id"`
+	Reason      string     `json:"reason" db:"reason"`
+	EndsAt      *time.Time `json:"endsAt" db:"ends_at"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	LiftedAt    *time.Time `json:"liftedAt" db:"lifted_at"`
+	LiftedBy    *string    `json:"liftedBy" db:"lifted_by"`
+}
+
+type ReviewReport struct {
+	Status     string  `json:"status" binding:"required,oneof=resolved dismissed"`
+	Resolution *string `json:"resolution" binding:"omitempty,max=1000"`
+}
+
+// NewSuspension omit Duration to ban the user permanently
+type NewSuspension struct {
+	Reason   string `json:"reason" binding:"required,max=500"`
+	Duration string `json:"duration"`
+}
+
+type NewRole struct {
+	Role string `json:"role" binding:"required,oneof=user moderator admin"`
+}
@@ -0,0 +1,35 @@
+package moderation
+
+import (
+	"time"
+
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+	moderationEntity "github.com/xyedo/blindate/pkg/domain/moderation/entities"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+)
+
+type ReportFilter struct {
+	Status     blockEntity.ReportStatus
+	ReportedId string
+	Limit      int
+}
+
+type AuditFilter struct {
+	ModeratorId  string
+	TargetUserId string
+	Before       *time.Time
+	Limit        int
+}
+
+// Repository write the audit log in the same transaction as the moderator action
+type Repository interface {
+	SelectReports(filter ReportFilter) ([]blockEntity.Report, error)
+	SelectReportById(reportId string) (blockEntity.Report, error)
+	UpdateReportReview(report blockEntity.Report, audit moderationEntity.AuditLog) error
+	InsertSuspension(suspension *moderationEntity.Suspension, audit moderationEntity.AuditLog) error
+	LiftSuspension(userId, moderatorId string, at time.Time, audit moderationEntity.AuditLog) error
+	SelectSuspensions(userId string) ([]moderationEntity.Suspension, error)
+	UpdateRole(userId string, role userEntity.Role, audit moderationEntity.AuditLog) error
+	InsertAuditLog(audit moderationEntity.AuditLog) error
+	SelectAuditLogs(filter AuditFilter) ([]moderationEntity.AuditLog, error)
+}
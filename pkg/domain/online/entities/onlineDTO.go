@@ -4,7 +4,9 @@ import "time"
 
 // Online one to one with user
 type DTO struct {
-	UserId     string    `json:"-" db:"user_id"`
-	LastOnline time.Time `json:"lastOnline" db:"last_online"`
-	IsOnline   bool      `json:"isOnline" db:"is_online"`
+	UserId            string    `json:"-" db:"user_id"`
+	LastOnline        time.Time `json:"lastOnline" db:"last_online"`
+	IsOnline          bool      `json:"isOnline" db:"is_online"`
+	Visibility        string    `json:"visibility,omitempty" db:"visibility"`
+	LastSeenPrecision string    `json:"lastSeenPrecision,omitempty" db:"last_seen_precision"`
 }
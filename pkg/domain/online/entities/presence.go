@@ -0,0 +1,72 @@
+package onlineEntity
+
+import "time"
+
+const (
+	VisibleToEveryone = "everyone"
+	VisibleToMatches  = "matches"
+	VisibleToNobody   = "nobody"
+)
+
+const (
+	LastSeenExact  = "exact"
+	LastSeenCoarse = "coarse"
+)
+
+const (
+	LastSeenOnline      = "online"
+	LastSeenRecently    = "recently"
+	LastSeenWithinWeek  = "within_week"
+	LastSeenWithinMonth = "within_month"
+	LastSeenLongAgo     = "long_ago"
+	LastSeenHidden      = "hidden"
+)
+
+type Settings struct {
+	Visibility        string `json:"visibility" binding:"required,oneof=everyone matches nobody"`
+	LastSeenPrecision string `json:"lastSeenPrecision" binding:"required,oneof=exact coarse"`
+}
+
+// Presence is what other user see from the online, after the owner settings applied
+type Presence struct {
+	IsOnline   *bool      `json:"isOnline,omitempty"`
+	LastSeen   string     `json:"lastSeen"`
+	LastOnline *time.Time `json:"lastOnline,omitempty"`
+}
+
+func (o DTO) PresenceFor(isMatched bool, now time.Time) Presence {
+	switch o.Visibility {
+	case VisibleToNobody:
+		return Presence{LastSeen: LastSeenHidden}
+	case VisibleToMatches:
+		if !isMatched {
+			return Presence{LastSeen: LastSeenHidden}
+		}
+	}
+	isOnline := o.IsOnline
+	presence := Presence{
+		IsOnline: &isOnline,
+		LastSeen: LastSeenOnline,
+	}
+	if !o.IsOnline {
+		presence.LastSeen = coarseLastSeen(now.Sub(o.LastOnline))
+	}
+	if o.LastSeenPrecision != LastSeenCoarse {
+		lastOnline := o.LastOnline
+		presence.LastOnline = &lastOnline
+	}
+	return presence
+}
+
+func coarseLastSeen(elapsed time.Duration) string {
+	switch {
+	case elapsed <= 3*24*time.Hour:
+		return LastSeenRecently
+	case elapsed <= 7*24*time.Hour:
+		return LastSeenWithinWeek
+	case elapsed <= 30*24*time.Hour:
+		return LastSeenWithinMonth
+	default:
+		return LastSeenLongAgo
+	}
+}
@@ -1,9 +1,16 @@
 package online
 
-import onlineEntities "github.com/xyedo/blindate/pkg/domain/online/entities"
+import (
+	"time"
+
+	onlineEntities "github.com/xyedo/blindate/pkg/domain/online/entities"
+)
 
 type Repository interface {
 	InsertNewOnline(on onlineEntities.DTO) error
 	UpdateOnline(userId string, online bool) error
 	SelectOnline(userId string) (onlineEntities.DTO, error)
+	UpdateHeartbeat(userId string, at time.Time) (bool, error)
+	UpdateStaleOffline(staleBefore time.Time) ([]onlineEntities.DTO, error)
+	UpdateSettings(userId string, settings onlineEntities.Settings) error
 }
@@ -0,0 +1,32 @@
+package stickerEntity
+
+import "time"
+
+// Pack group the stickers in the catalogue, archived pack is hidden from the catalogue
+// but the sticker is still rendered in the sent chat
+type Pack struct {
+	Id         string     `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	ArchivedAt *time.Time `json:"archivedAt" db:"archived_at"`
+	Stickers   []Sticker  `json:"stickers" db:"-"`
+}
+
+type Sticker struct {
+	Id         string     `json:"id" db:"id"`
+	PackId     string     `json:"packId" db:"pack_id"`
+	Emoji      *string    `json:"emoji" db:"emoji"`
+	BlobLink   string     `json:"blobLink" db:"blob_link"`
+	MediaType  string     `json:"mediaType" db:"media_type"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	ArchivedAt *time.Time `json:"archivedAt" db:"archived_at"`
+}
+
+type NewPack struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+type UpdatePack struct {
+	Name     *string `json:"name" binding:"omitempty,min=1,max=50"`
+	Archived *bool   `json:"archived"`
+}
@@ -0,0 +1,16 @@
+package sticker
+
+import (
+	"time"
+
+	stickerEntity "github.com/xyedo/blindate/pkg/domain/sticker/entities"
+)
+
+type Repository interface {
+	InsertPack(name string) (string, error)
+	SelectPacks(withArchived bool) ([]stickerEntity.Pack, error)
+	SelectPackById(packId string) (stickerEntity.Pack, error)
+	UpdatePack(packId string, update stickerEntity.UpdatePack, at time.Time) (stickerEntity.Pack, error)
+	InsertSticker(sticker *stickerEntity.Sticker) error
+	ArchiveSticker(stickerId string, at time.Time) error
+}
@@ -0,0 +1,14 @@
+package userEntity
+
+// DeleteAccount re-confirm the password before the account is scheduled for deletion
+type DeleteAccount struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// PurgeReport summarize one run of the account purger
+type PurgeReport struct {
+	Purged      int `json:"purged"`
+	Failed      int `json:"failed"`
+	BlobDeleted int `json:"blobDeleted"`
+	BlobFailed  int `json:"blobFailed"`
+}
@@ -0,0 +1,15 @@
+package userEntity
+
+// Role is carried in the access token, the route group decide which role is allowed
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// IsStaff is the role allowed to moderate the other user
+func (r Role) IsStaff() bool {
+	return r == RoleModerator || r == RoleAdmin
+}
@@ -10,7 +10,15 @@ type FullDTO struct {
 	ProfilePic []ProfilePic `db:"-" json:"profilePicture,omitempty"`
 	Password   string       `db:"password" json:"-"`
 	Active     bool         `db:"active" json:"-"`
-	Dob        time.Time    `db:"dob" json:"dob"`
-	CreatedAt  time.Time    `db:"created_at" json:"createdAt"`
-	UpdatedAt  time.Time    `db:"updated_at" json:"updatedAt"`
+	Role       Role         `db:"role" json:"-"`
+	// Banned and SuspendedUntil is the active restriction, the restricted user could not auth
+	Banned         bool       `db:"banned" json:"-"`
+	SuspendedUntil *time.Time `db:"suspended_until" json:"-"`
+	// DeletionRequestedAt start the grace period before the account is purged, login cancel it
+	DeletionRequestedAt *time.Time `db:"deletion_requested_at" json:"deletionRequestedAt,omitempty"`
+	// SnoozedAt hide the user from discovery
+	SnoozedAt *time.Time `db:"snoozed_at" json:"snoozedAt,omitempty"`
+	Dob       time.Time  `db:"dob" json:"dob"`
+	CreatedAt time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updatedAt"`
 }
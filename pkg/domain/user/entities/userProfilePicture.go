@@ -1,9 +1,18 @@
 package userEntity
 
-// ProfilePic one to many with user
+// ProfilePic one to many with user, at most one picture is selected per user
 type ProfilePic struct {
-	Id          string `json:"id" db:"id"`
-	UserId      string `json:"userId" db:"user_id"`
-	Selected    bool   `json:"selected" db:"selected"`
-	PictureLink string `json:"pictureLink" db:"picture_ref"`
+	Id       string `json:"id" db:"id"`
+	UserId   string `json:"userId" db:"user_id"`
+	Selected bool   `json:"selected" db:"selected"`
+	// PictureLink is the full rendition, the medium and thumbnail fallback to it on the picture uploaded before the renditions
+	PictureLink   string `json:"pictureLink" db:"picture_ref"`
+	MediumLink    string `json:"mediumLink" db:"medium_ref"`
+	ThumbnailLink string `json:"thumbnailLink" db:"thumbnail_ref"`
+	// Position is the zero based order of the picture
+	Position int `json:"position" db:"position"`
+}
+
+type ReorderProfilePic struct {
+	Ids []string `json:"ids" binding:"required,min=1,max=5,dive,number"`
 }
@@ -1,6 +1,8 @@
 package user
 
 import (
+	"time"
+
 	userEntities "github.com/xyedo/blindate/pkg/domain/user/entities"
 )
 
@@ -13,7 +15,17 @@ type Repository interface {
 	GetUserById(id string) (userEntities.FullDTO, error)
 	GetUserByEmail(email string) (userEntities.FullDTO, error)
 	UpdateUser(user userEntities.FullDTO) error
-	CreateProfilePicture(userId, pictureRef string, selected bool) (string, error)
+	CreateProfilePicture(profPic userEntities.ProfilePic) (string, error)
 	SelectProfilePicture(userId string, params *ProfilePicQuery) ([]userEntities.ProfilePic, error)
 	ProfilePicSelectedToFalse(userId string) (int64, error)
+	UpdateSelectedProfilePicture(userId, id string) error
+	UpdateProfilePicturePosition(userId string, ids []string) error
+	DeleteProfilePicture(userId, id string) (userEntities.ProfilePic, error)
+	// UpdateDeletionRequest start the grace period, nil requestedAt cancel it
+	UpdateDeletionRequest(userId string, requestedAt *time.Time) error
+	// UpdateSnooze hide the user from discovery, nil snoozedAt show the user again
+	UpdateSnooze(userId string, snoozedAt *time.Time) error
+	SelectPurgeable(requestedBefore time.Time, limit int) ([]string, error)
+	// PurgeUser erase the personal data of the user and return the blob keys to be deleted
+	PurgeUser(userId string, purgedAt time.Time) ([]string, error)
 }
@@ -1,8 +1,13 @@
 package websocketEntity
 
-import "github.com/gorilla/websocket"
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
 
 type Response struct {
+	Id     uint64         `json:"id,omitempty"`
 	Action string         `json:"action"`
 	Data   map[string]any `json:"data"`
 }
@@ -12,6 +17,20 @@ type Payload struct {
 	Payload string `json:"payload"`
 	Conn    Conn   `json:"-"`
 }
+
+// Client is a server to client transport, either websocket or server-sent events
+type Client interface {
+	Key() string
+	WriteJSON(v any) error
+	SetWriteDeadline(t time.Time) error
+	Close() error
+}
+
 type Conn struct {
 	*websocket.Conn
+	Id string
+}
+
+func (c Conn) Key() string {
+	return c.Id
 }
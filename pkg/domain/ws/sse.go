@@ -0,0 +1,68 @@
+package websocketEntity
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrSseClosed = errors.New("sse connection closed")
+var ErrSseSlowConsumer = errors.New("sse connection buffer is full")
+
+func NewSseConn(id string, bufferSize int) *SseConn {
+	return &SseConn{
+		id:     id,
+		events: make(chan Response, bufferSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// SseConn queue the response, the http handler owning the stream is the one writing it
+type SseConn struct {
+	id        string
+	events    chan Response
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *SseConn) Key() string {
+	return s.id
+}
+
+func (s *SseConn) WriteJSON(v any) error {
+	resp, ok := v.(Response)
+	if !ok {
+		return errors.New("sse connection only accept websocketEntity.Response")
+	}
+	select {
+	case <-s.done:
+		return ErrSseClosed
+	default:
+	}
+	select {
+	case s.events <- resp:
+		return nil
+	default:
+		return ErrSseSlowConsumer
+	}
+}
+
+// SetWriteDeadline is a no-op, writing never block on SseConn
+func (*SseConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func (s *SseConn) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	return nil
+}
+
+func (s *SseConn) Events() <-chan Response {
+	return s.events
+}
+
+func (s *SseConn) Done() <-chan struct{} {
+	return s.done
+}
@@ -1,19 +1,35 @@
 package infra
 
 import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/xyedo/blindate/pkg/applications/gateway"
 	"github.com/xyedo/blindate/pkg/applications/service"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
 	"github.com/xyedo/blindate/pkg/infra/repository"
 	"github.com/xyedo/blindate/pkg/interfaces/http/api"
 )
 
-func (cfg *Config) Container(db *sqlx.DB) (api.Route, service.EventDeps, gateway.Deps) {
-	attachmentSvc := service.NewS3(cfg.BucketName)
+func (cfg *Config) Container(db *sqlx.DB) (api.Route, service.EventDeps, gateway.Deps, error) {
+	origins, err := api.NewOrigins(cfg.trustedOrigins())
+	if err != nil {
+		return api.Route{}, service.EventDeps{}, gateway.Deps{}, err
+	}
+	if origins.AllowAny() && cfg.Cors.AllowCredentials {
+		return api.Route{}, service.EventDeps{}, gateway.Deps{}, errors.New("cors: trusted origin * could not allow credentials, list the origins or disable -cors-allow-credentials")
+	}
+	attachmentSvc, blobHandler, err := cfg.attachment()
+	if err != nil {
+		return api.Route{}, service.EventDeps{}, gateway.Deps{}, err
+	}
+	blobUrl := service.NewBlobUrl(attachmentSvc, cfg.Attachment.UrlExpires)
 
 	userRepo := repository.NewUser(db)
-	userSvc := service.NewUser(userRepo)
-	userHandler := api.NewUser(userSvc, attachmentSvc)
+	userSvc := service.NewUser(userRepo, blobUrl)
 
 	healthcheckHander := api.NewHealthCheck()
 
@@ -39,20 +55,54 @@ func (cfg *Config) Container(db *sqlx.DB) (api.Route, service.EventDeps, gateway
 	authSvc := service.NewAuth(authRepo, userRepo, tokenSvc)
 	authHandler := api.NewAuth(authSvc)
 
+	blockRepo := repository.NewBlock(db)
 	matchRepo := repository.NewMatch(db)
-	matchSvc := service.NewMatch(matchRepo, locationRepo)
+	matchSvc := service.NewMatch(matchRepo, locationRepo, blockRepo, matchEntity.RevealPolicy{
+		Ttl:    cfg.Reveal.Ttl,
+		Mutual: cfg.Reveal.Mutual,
+	})
 	matchHandler := api.NewMatch(matchSvc)
 
 	convRepo := repository.NewConversation(db)
-	convSvc := service.NewConversation(convRepo, matchRepo)
-	convHandler := api.NewConvo(convSvc)
+	convSvc := service.NewConversation(convRepo, matchRepo, blobUrl)
+	convHandler := api.NewConvo(convSvc, onlineSvc)
 
 	chatRepp := repository.NewChat(db)
-	chatSvc := service.NewChat(chatRepp, matchRepo)
-	chatHandler := api.NewChat(chatSvc, attachmentSvc)
+	chatSvc := service.NewChat(chatRepp, matchRepo, blobUrl)
+	mediaSvc := service.NewMedia(attachmentEntity.Limits{
+		MaxAudioBytes:    cfg.Media.MaxAudioBytes,
+		MaxImageBytes:    cfg.Media.MaxImageBytes,
+		MaxVideoBytes:    cfg.Media.MaxVideoBytes,
+		MaxDimension:     cfg.Media.MaxDimension,
+		MaxVoiceDuration: cfg.Media.MaxVoiceDuration,
+		MaxVideoDuration: cfg.Media.MaxVideoDuration,
+		ThumbnailSize:    cfg.Media.ThumbnailSize,
+		Profile: attachmentEntity.ProfileSizes{
+			Full:      cfg.Media.ProfileFullSize,
+			Medium:    cfg.Media.ProfileMediumSize,
+			Thumbnail: cfg.Media.ProfileThumbnailSize,
+		},
+	})
+	chatHandler := api.NewChat(chatSvc, attachmentSvc, mediaSvc)
+	blockHandler := api.NewBlock(service.NewBlock(blockRepo, chatRepp, matchRepo))
+	moderationSvc := service.NewModeration(repository.NewModeration(db), userRepo, chatSvc)
+	err = moderationSvc.PromoteAdmins(cfg.Admin.UserIds)
+	if err != nil {
+		return api.Route{}, service.EventDeps{}, gateway.Deps{}, err
+	}
+	moderationHandler := api.NewModeration(moderationSvc)
+	userHandler := api.NewUser(userSvc, attachmentSvc, mediaSvc)
+
+	stickerRepo := repository.NewSticker(db)
+	stickerSvc := service.NewSticker(stickerRepo, blobUrl)
+	stickerHandler := api.NewSticker(stickerSvc, attachmentSvc)
+
+	blobGc := service.NewBlobGc(attachmentSvc, repository.NewAttachment(db), cfg.BlobGc.Grace)
+	accountSvc := service.NewAccount(userRepo, attachmentSvc, cfg.Account.DeletionGrace)
+	exportSvc := service.NewExport(repository.NewExport(db), userRepo, basicInfoSvc, interestRepo, locationService, attachmentSvc, cfg.Export.Ttl)
 
-	wsSvc := service.NewWs()
-	WsHandler := api.NewWs(wsSvc, onlineSvc)
+	wsSvc := service.NewWs(onlineSvc)
+	WsHandler := api.NewWs(wsSvc, origins)
 	return api.Route{
 			User:           userHandler,
 			Healthcheck:    healthcheckHander,
@@ -60,22 +110,102 @@ func (cfg *Config) Container(db *sqlx.DB) (api.Route, service.EventDeps, gateway
 			Location:       locationHandler,
 			Authentication: authHandler,
 			Tokenizer:      tokenSvc,
+			Access:         authSvc,
+			Authorizer:     api.NewAuthorizer(matchSvc, chatSvc),
 			Interest:       interestHandler,
 			Online:         onlineHandler,
 			Convo:          convHandler,
 			Chat:           chatHandler,
+			Sticker:        stickerHandler,
+			Blob:           blobHandler,
+			Block:          blockHandler,
+			Moderation:     moderationHandler,
+			Account:        api.NewAccount(accountSvc),
+			Export:         api.NewExport(exportSvc),
 			Match:          matchHandler,
 			Webscoket:      WsHandler,
+			Cors: api.Cors{
+				Origins:          origins,
+				AllowCredentials: cfg.Cors.AllowCredentials,
+				MaxAge:           cfg.Cors.MaxAge,
+			},
 		}, service.EventDeps{
 			UserSvc:  userSvc,
 			ConvSvc:  convSvc,
 			MatchSvc: matchSvc,
 			Online:   onlineSvc,
 			Ws:       wsSvc,
+			BlobGc:   blobGc,
+			Account:  accountSvc,
+			Export:   exportSvc,
 		}, gateway.Deps{
 			Ws:         wsSvc,
 			ChatSvc:    chatSvc,
 			MatchSvc:   matchSvc,
 			OnlinceSvc: onlineSvc,
+		}, nil
+}
+
+// attachment build the configured driver, the blob handler is nil when the driver serve its own url
+func (cfg *Config) attachment() (service.Attachment, *api.Blob, error) {
+	driver := cfg.Attachment.Driver
+	if driver == "" {
+		driver = "s3"
+		if cfg.Env == "development" {
+			driver = "local"
+		}
+	}
+	if driver == "s3" {
+		attachmentSvc, err := service.NewS3(service.S3Options{
+			Bucket:     cfg.Attachment.S3.Bucket,
+			Region:     cfg.Attachment.S3.Region,
+			Endpoint:   cfg.Attachment.S3.Endpoint,
+			PathStyle:  cfg.Attachment.S3.PathStyle,
+			UrlExpires: cfg.Attachment.UrlExpires,
+		})
+		if err != nil {
+			return nil, nil, err
 		}
+		return attachmentSvc, nil, nil
+	}
+
+	secret := []byte(cfg.Attachment.SigningSecret)
+	if len(secret) == 0 {
+		if cfg.Env != "development" {
+			return nil, nil, errors.New("attachment signing secret is required by the local and memory driver")
+		}
+		// the url signed before restart is invalidated, acceptable on development
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, nil, err
+		}
+	}
+	baseUrl := cfg.Attachment.Local.BaseUrl
+	if baseUrl == "" {
+		baseUrl = fmt.Sprintf("http://localhost:%d/api/v1/blobs", cfg.Port)
+	}
+	urls := service.NewHmacUrl(baseUrl, secret, cfg.Attachment.UrlExpires)
+	switch driver {
+	case "local":
+		attachmentSvc, err := service.NewLocalAttachment(cfg.Attachment.Local.Dir, urls)
+		if err != nil {
+			return nil, nil, err
+		}
+		return attachmentSvc, api.NewBlob(attachmentSvc), nil
+	case "memory":
+		attachmentSvc := service.NewMemoryAttachment(urls)
+		return attachmentSvc, api.NewBlob(attachmentSvc), nil
+	}
+	return nil, nil, fmt.Errorf("unknown attachment driver %q", driver)
+}
+
+func (cfg *Config) trustedOrigins() []string {
+	if len(cfg.Cors.TrustedOrigins) != 0 {
+		return cfg.Cors.TrustedOrigins
+	}
+	origins := []string{"https://blindate.com"}
+	if cfg.Env == "development" {
+		origins = append(origins, "http://localhost:3000")
+	}
+	return origins
 }
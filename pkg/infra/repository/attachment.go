@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/xyedo/blindate/pkg/common"
+)
+
+func NewAttachment(db *sqlx.DB) *AttachmentCon {
+	return &AttachmentCon{
+		conn: db,
+	}
+}
+
+type AttachmentCon struct {
+	conn *sqlx.DB
+}
+
+func (a *AttachmentCon) SelectReferencedBlob(keys []string) ([]string, error) {
+	query := `
+	SELECT k::TEXT FROM unnest($1::CITEXT[]) AS k
+	WHERE k IN (SELECT blob_link FROM media)
+		OR k IN (SELECT thumbnail_link FROM media WHERE thumbnail_link IS NOT NULL)
+		OR k IN (SELECT picture_ref FROM profile_picture)
+		OR k IN (SELECT medium_ref FROM profile_picture WHERE medium_ref IS NOT NULL)
+		OR k IN (SELECT thumbnail_ref FROM profile_picture WHERE thumbnail_ref IS NOT NULL)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	referenced := make([]string, 0)
+	err := a.conn.SelectContext(ctx, &referenced, query, pq.Array(keys))
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	return referenced, nil
+}
@@ -0,0 +1,81 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+	"github.com/xyedo/blindate/pkg/infra/repository"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_SelectReferencedBlob(t *testing.T) {
+	repo := repository.NewAttachment(testQuery)
+	chatRepo := repository.NewChat(testQuery)
+	userRepo := repository.NewUser(testQuery)
+
+	fromUsr := createNewAccount(t)
+	toUsr := createNewAccount(t)
+	matchId, err := repository.NewMatch(testQuery).InsertNewMatch(fromUsr.ID, toUsr.ID, matchEntity.Requested)
+	require.NoError(t, err)
+	convoId, err := repository.NewConversation(testQuery).InsertConversation(matchId)
+	require.NoError(t, err)
+
+	blobLink := "chat-attachment/" + util.RandomUUID() + ".jpg"
+	thumbnail := "chat-thumbnail/" + util.RandomUUID() + ".jpg"
+	err = chatRepo.InsertNewChat(&chatEntity.DAO{
+		ConversationId: convoId,
+		Author:         fromUsr.ID,
+		SentAt:         time.Now(),
+		Attachment: &chatEntity.Attachment{
+			BlobLink:      blobLink,
+			MediaType:     "image/jpeg",
+			ThumbnailLink: &thumbnail,
+		},
+	})
+	require.NoError(t, err)
+	pictureRef := "profile-picture/" + util.RandomUUID() + ".png"
+	_, err = userRepo.CreateProfilePicture(userEntity.ProfilePic{UserId: fromUsr.ID, PictureLink: pictureRef, Selected: true})
+	require.NoError(t, err)
+	rendition := userEntity.ProfilePic{
+		UserId:        fromUsr.ID,
+		PictureLink:   "profile-picture/" + util.RandomUUID() + ".jpg",
+		MediumLink:    "profile-picture/" + util.RandomUUID() + ".jpg",
+		ThumbnailLink: "profile-picture/" + util.RandomUUID() + ".jpg",
+	}
+	_, err = userRepo.CreateProfilePicture(rendition)
+	require.NoError(t, err)
+
+	t.Run("only the referenced key is returned", func(t *testing.T) {
+		orphan := "chat-attachment/" + util.RandomUUID() + ".jpg"
+		referenced, err := repo.SelectReferencedBlob([]string{blobLink, orphan, thumbnail, pictureRef})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{blobLink, thumbnail, pictureRef}, referenced)
+	})
+	t.Run("every rendition is referenced", func(t *testing.T) {
+		keys := []string{rendition.PictureLink, rendition.MediumLink, rendition.ThumbnailLink}
+		referenced, err := repo.SelectReferencedBlob(keys)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, keys, referenced)
+	})
+	t.Run("empty keys", func(t *testing.T) {
+		referenced, err := repo.SelectReferencedBlob([]string{})
+		require.NoError(t, err)
+		assert.Empty(t, referenced)
+	})
+	t.Run("deleted user is no longer referenced", func(t *testing.T) {
+		usr := createNewAccount(t)
+		ref := "profile-picture/" + util.RandomUUID() + ".png"
+		_, err := userRepo.CreateProfilePicture(userEntity.ProfilePic{UserId: usr.ID, PictureLink: ref, Selected: true})
+		require.NoError(t, err)
+		testQuery.MustExec(`DELETE FROM users WHERE id = $1`, usr.ID)
+
+		referenced, err := repo.SelectReferencedBlob([]string{ref})
+		require.NoError(t, err)
+		assert.Empty(t, referenced)
+	})
+}
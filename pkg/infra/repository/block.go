@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/xyedo/blindate/pkg/common"
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+)
+
+// blockedBetween is the condition that either side of the pair blocked the other,
+// userId and otherId is the column or the placeholder of the pair
+func blockedBetween(userId, otherId string) string {
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1
+		FROM blocks
+		WHERE
+			(blocker_id = %[1]s AND blocked_id = %[2]s) OR
+			(blocker_id = %[2]s AND blocked_id = %[1]s)
+	)`, userId, otherId)
+}
+
+func NewBlock(conn *sqlx.DB) *BlockConn {
+	return &BlockConn{
+		conn: conn,
+	}
+}
+
+type BlockConn struct {
+	conn *sqlx.DB
+}
+
+// InsertBlock is idempotent, blocking the same user twice keep the first block
+func (b *BlockConn) InsertBlock(blockerId, blockedId string) error {
+	query := `
+	INSERT INTO blocks(blocker_id, blocked_id)
+	VALUES($1, $2)
+	ON CONFLICT (blocker_id, blocked_id) DO NOTHING`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := b.conn.ExecContext(ctx, query, blockerId, blockedId)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			if pqErr.Code == "23503" {
+				return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "user is not found")
+			}
+			if pqErr.Code == "23514" {
+				return common.WrapWithNewError(err, http.StatusUnprocessableEntity, "could not block yourself")
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+func (b *BlockConn) DeleteBlock(blockerId, blockedId string) error {
+	query := `DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := b.conn.ExecContext(ctx, query, blockerId, blockedId)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return err
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if row == 0 {
+		return common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound)
+	}
+	return nil
+}
+
+// SelectBlocked list the user blocked by blockerId, the latest block first
+func (b *BlockConn) SelectBlocked(blockerId string) ([]blockEntity.Block, error) {
+	query := `
+	SELECT
+		u.id AS user_id,
+		u.alias,
+		b.created_at
+	FROM blocks b
+	JOIN users u
+		ON u.id = b.blocked_id
+	WHERE b.blocker_id = $1
+	ORDER BY b.created_at DESC, u.id`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	blocks := make([]blockEntity.Block, 0)
+	err := b.conn.SelectContext(ctx, &blocks, query, blockerId)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// IsBlocked report whether either user blocked the other
+func (b *BlockConn) IsBlocked(userId, otherId string) (bool, error) {
+	query := `SELECT ` + blockedBetween("$1::UUID", "$2::UUID")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var blocked bool
+	err := b.conn.GetContext(ctx, &blocked, query, userId, otherId)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return false, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return false, err
+	}
+	return blocked, nil
+}
+
+func (b *BlockConn) InsertReport(report *blockEntity.Report) error {
+	query := `
+	INSERT INTO reports(reporter_id, reported_id, reason, chat_id, details)
+	VALUES($1, $2, $3, $4, $5)
+	RETURNING id, created_at, status`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row := b.conn.QueryRowxContext(ctx, query, report.ReporterId, report.ReportedId, report.Reason, report.ChatId, report.Details)
+	err := row.Scan(&report.Id, &report.CreatedAt, &report.Status)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			if strings.Contains(pqErr.Constraint, "reported_id") {
+				return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "reported user is not found")
+			}
+			if strings.Contains(pqErr.Constraint, "chat_id") {
+				return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "chat is not found")
+			}
+			if strings.Contains(pqErr.Constraint, "reason") {
+				return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "reason is invalid")
+			}
+			return common.WrapError(err, common.ErrRefNotFound23503)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/common"
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
+	locationEntity "github.com/xyedo/blindate/pkg/domain/location/entities"
+	"github.com/xyedo/blindate/pkg/domain/match"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+	"github.com/xyedo/blindate/pkg/infra/repository"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_InsertBlock(t *testing.T) {
+	blockRepo := repository.NewBlock(testQuery)
+	t.Run("valid and idempotent", func(t *testing.T) {
+		blocker := createNewAccount(t)
+		blocked := createNewAccount(t)
+		err := blockRepo.InsertBlock(blocker.ID, blocked.ID)
+		require.NoError(t, err)
+		err = blockRepo.InsertBlock(blocker.ID, blocked.ID)
+		require.NoError(t, err)
+
+		blocks, err := blockRepo.SelectBlocked(blocker.ID)
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, blocked.ID, blocks[0].UserId)
+		assert.Equal(t, blocked.Alias, blocks[0].Alias)
+		assert.False(t, blocks[0].BlockedAt.IsZero())
+
+		blocks, err = blockRepo.SelectBlocked(blocked.ID)
+		require.NoError(t, err)
+		assert.Empty(t, blocks)
+	})
+	t.Run("unknown user", func(t *testing.T) {
+		blocker := createNewAccount(t)
+		err := blockRepo.InsertBlock(blocker.ID, util.RandomUUID())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrRefNotFound23503)
+	})
+	t.Run("block yourself", func(t *testing.T) {
+		blocker := createNewAccount(t)
+		err := blockRepo.InsertBlock(blocker.ID, blocker.ID)
+		require.Error(t, err)
+		apiErr, ok := err.(common.APIError)
+		require.True(t, ok)
+		status, _ := apiErr.APIError()
+		assert.Equal(t, 422, status)
+	})
+}
+
+func Test_DeleteBlock(t *testing.T) {
+	blockRepo := repository.NewBlock(testQuery)
+	blocker := createNewAccount(t)
+	blocked := createNewAccount(t)
+	err := blockRepo.InsertBlock(blocker.ID, blocked.ID)
+	require.NoError(t, err)
+
+	t.Run("only the blocker could unblock", func(t *testing.T) {
+		err := blockRepo.DeleteBlock(blocked.ID, blocker.ID)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+	t.Run("valid", func(t *testing.T) {
+		err := blockRepo.DeleteBlock(blocker.ID, blocked.ID)
+		require.NoError(t, err)
+		isBlocked, err := blockRepo.IsBlocked(blocker.ID, blocked.ID)
+		require.NoError(t, err)
+		assert.False(t, isBlocked)
+	})
+}
+
+func Test_IsBlocked(t *testing.T) {
+	blockRepo := repository.NewBlock(testQuery)
+	blocker := createNewAccount(t)
+	blocked := createNewAccount(t)
+	stranger := createNewAccount(t)
+	err := blockRepo.InsertBlock(blocker.ID, blocked.ID)
+	require.NoError(t, err)
+
+	isBlocked, err := blockRepo.IsBlocked(blocker.ID, blocked.ID)
+	require.NoError(t, err)
+	assert.True(t, isBlocked)
+	isBlocked, err = blockRepo.IsBlocked(blocked.ID, blocker.ID)
+	require.NoError(t, err)
+	assert.True(t, isBlocked, "block apply to both side")
+	isBlocked, err = blockRepo.IsBlocked(blocker.ID, stranger.ID)
+	require.NoError(t, err)
+	assert.False(t, isBlocked)
+}
+
+func Test_BlockHidePair(t *testing.T) {
+	blockRepo := repository.NewBlock(testQuery)
+	matchRepo := repository.NewMatch(testQuery)
+	convRepo := repository.NewConversation(testQuery)
+	chatRepo := repository.NewChat(testQuery)
+
+	t.Run("discovery", func(t *testing.T) {
+		locRepo := repository.NewLocation(testQuery)
+		user := createNewAccount(t)
+		fromLoc := createNewLocation(t, user.ID)
+		neighbour := createNewAccount(t)
+		err := locRepo.InsertNewLocation(&locationEntity.DAO{UserId: neighbour.ID, Geog: fromLoc.Geog})
+		require.NoError(t, err)
+
+		closest, err := locRepo.GetClosestUser(user.ID, fromLoc.Geog, 1)
+		require.NoError(t, err)
+		require.Len(t, closest, 1)
+		require.Equal(t, neighbour.ID, closest[0].UserId)
+
+		err = blockRepo.InsertBlock(neighbour.ID, user.ID)
+		require.NoError(t, err)
+		closest, err = locRepo.GetClosestUser(user.ID, fromLoc.Geog, 1)
+		require.NoError(t, err)
+		for _, candidate := range closest {
+			assert.NotEqual(t, neighbour.ID, candidate.UserId)
+		}
+	})
+	t.Run("match request", func(t *testing.T) {
+		fromUsr := createNewAccount(t)
+		toUsr := createNewAccount(t)
+		matchId, err := matchRepo.InsertNewMatch(fromUsr.ID, toUsr.ID, matchEntity.Requested)
+		require.NoError(t, err)
+		requests, err := matchRepo.SelectMatchesByUserId(toUsr.ID, match.Filter{Box: match.BoxReceived})
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+
+		err = blockRepo.InsertBlock(toUsr.ID, fromUsr.ID)
+		require.NoError(t, err)
+		requests, err = matchRepo.SelectMatchesByUserId(toUsr.ID, match.Filter{Box: match.BoxReceived})
+		require.NoError(t, err)
+		assert.Empty(t, requests)
+		matchDAO, err := matchRepo.GetMatchById(matchId)
+		require.NoError(t, err)
+		assert.True(t, matchDAO.Blocked)
+	})
+	t.Run("conversation and unread", func(t *testing.T) {
+		fromUsr := createNewAccount(t)
+		toUsr := createNewAccount(t)
+		matchId, err := matchRepo.InsertNewMatch(fromUsr.ID, toUsr.ID, matchEntity.Accepted)
+		require.NoError(t, err)
+		convoId, err := convRepo.InsertConversation(matchId)
+		require.NoError(t, err)
+		err = chatRepo.InsertNewChat(&chatEntity.DAO{
+			ConversationId: convoId,
+			Author:         fromUsr.ID,
+			Messages:       util.RandomString(12),
+			SentAt:         time.Now(),
+		})
+		require.NoError(t, err)
+		total, err := convRepo.SelectTotalUnread(toUsr.ID)
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+
+		err = blockRepo.InsertBlock(fromUsr.ID, toUsr.ID)
+		require.NoError(t, err)
+		for _, userId := range []string{fromUsr.ID, toUsr.ID} {
+			convs, err := convRepo.SelectConversationByUserId(userId, nil)
+			require.NoError(t, err)
+			assert.Empty(t, convs)
+		}
+		_, err = convRepo.SelectConversationById(convoId)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+		total, err = convRepo.SelectTotalUnread(toUsr.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, total)
+
+		err = blockRepo.DeleteBlock(fromUsr.ID, toUsr.ID)
+		require.NoError(t, err)
+		convs, err := convRepo.SelectConversationByUserId(toUsr.ID, nil)
+		require.NoError(t, err)
+		assert.Len(t, convs, 1)
+	})
+}
+
+func Test_InsertReport(t *testing.T) {
+	blockRepo := repository.NewBlock(testQuery)
+	t.Run("valid with chat", func(t *testing.T) {
+		chatRepo := repository.NewChat(testQuery)
+		chatId, convoId := createNewChat(chatRepo, t)
+		matchDAO, err := repository.NewMatch(testQuery).GetMatchById(convoId)
+		require.NoError(t, err)
+		details := util.RandomString(20)
+		report := blockEntity.Report{
+			ReporterId: matchDAO.RequestTo,
+			ReportedId: matchDAO.RequestFrom,
+			Reason:     blockEntity.Harassment,
+			ChatId:     &chatId,
+			Details:    &details,
+		}
+		err = blockRepo.InsertReport(&report)
+		require.NoError(t, err)
+		assert.NotEmpty(t, report.Id)
+		assert.False(t, report.CreatedAt.IsZero())
+	})
+	t.Run("valid without chat", func(t *testing.T) {
+		report := blockEntity.Report{
+			ReporterId: createNewAccount(t).ID,
+			ReportedId: createNewAccount(t).ID,
+			Reason:     blockEntity.FakeProfile,
+		}
+		err := blockRepo.InsertReport(&report)
+		require.NoError(t, err)
+		assert.NotEmpty(t, report.Id)
+	})
+	t.Run("unknown reported user", func(t *testing.T) {
+		err := blockRepo.InsertReport(&blockEntity.Report{
+			ReporterId: createNewAccount(t).ID,
+			ReportedId: util.RandomUUID(),
+			Reason:     blockEntity.Spam,
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrRefNotFound23503)
+	})
+	t.Run("invalid reason", func(t *testing.T) {
+		err := blockRepo.InsertReport(&blockEntity.Report{
+			ReporterId: createNewAccount(t).ID,
+			ReportedId: createNewAccount(t).ID,
+			Reason:     "boring",
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrRefNotFound23503)
+	})
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
@@ -26,29 +27,61 @@ type ChatConn struct {
 
 func (c *ChatConn) InsertNewChat(content *chatEntity.DAO) error {
 	chatQ := `
-	INSERT INTO chats(conversation_id,author,messages,reply_to,sent_at)
-	VALUES($1,$2,$3,$4, $5)
-	RETURNING id`
+	WITH new_chat AS (
+		INSERT INTO chats(conversation_id,author,messages,reply_to,sent_at,sticker_id)
+		SELECT $1,$2,$3,$4,$5,$6
+		WHERE $6::UUID IS NULL
+			OR EXISTS (
+				SELECT 1 FROM stickers
+				JOIN sticker_packs ON sticker_packs.id = stickers.pack_id
+				WHERE stickers.id = $6 AND stickers.archived_at IS NULL AND sticker_packs.archived_at IS NULL
+			)
+		RETURNING id, conversation_id, author, sent_at
+	), state AS (
+		UPDATE conversation_states SET
+			unread_count = unread_count + CASE WHEN conversation_states.user_id != new_chat.author THEN 1 ELSE 0 END,
+			last_activity_at = GREATEST(last_activity_at, new_chat.sent_at)
+		FROM new_chat
+		WHERE conversation_states.conversation_id = new_chat.conversation_id
+	)
+	SELECT
+		new_chat.id,
+		stickers.pack_id,
+		stickers.blob_link,
+		stickers.media_type
+	FROM new_chat
+	LEFT JOIN stickers
+		ON stickers.id = $6`
+	var stickerId sql.NullString
+	if content.Sticker != nil {
+		stickerId = sql.NullString{Valid: true, String: content.Sticker.Id}
+	}
 	contentArgs := []any{
 		content.ConversationId,
 		content.Author,
 		content.Messages,
 		content.ReplyTo,
 		content.SentAt,
+		stickerId,
 	}
 
 	attachmentQ := `
-	INSERT INTO media(chat_id, blob_link,media_type)
-	VALUES($1,$2,$3)`
+	INSERT INTO media(chat_id, blob_link, media_type, width, height, duration_ms, thumbnail_link, waveform)
+	VALUES($1,$2,$3,$4,$5,$6,$7,$8)`
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := c.execTx(ctx, func(q *sqlx.DB) error {
-		err := q.GetContext(ctx, &content.Id, chatQ, contentArgs...)
+	err := c.execTx(ctx, func(q queryer) error {
+		var stickerPackId, stickerBlobLink, stickerMediaType sql.NullString
+		err := q.QueryRowxContext(ctx, chatQ, contentArgs...).Scan(&content.Id, &stickerPackId, &stickerBlobLink, &stickerMediaType)
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				return common.WrapError(err, common.ErrTooLongAccessingDB)
 			}
+			// only archived sticker or sticker of archived pack is filtered out
+			if errors.Is(err, sql.ErrNoRows) {
+				return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "sticker is invalid")
+			}
 			var pqErr *pq.Error
 			if errors.As(err, &pqErr) {
 				if pqErr.Code == "23503" {
@@ -61,13 +94,30 @@ func (c *ChatConn) InsertNewChat(content *chatEntity.DAO) error {
 					if strings.Contains(pqErr.Constraint, "reply_to") {
 						return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "replyTo is invalid")
 					}
+					if strings.Contains(pqErr.Constraint, "sticker_id") {
+						return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "sticker is invalid")
+					}
 				}
 				return pqErr
 			}
 			return err
 		}
+		if content.Sticker != nil {
+			content.Sticker.PackId = stickerPackId.String
+			content.Sticker.BlobLink = stickerBlobLink.String
+			content.Sticker.MediaType = stickerMediaType.String
+		}
 		if content.Attachment != nil {
-			attachmentArgs := []any{content.Id, content.Attachment.BlobLink, content.Attachment.MediaType}
+			attachmentArgs := []any{
+				content.Id,
+				content.Attachment.BlobLink,
+				content.Attachment.MediaType,
+				content.Attachment.Width,
+				content.Attachment.Height,
+				content.Attachment.DurationMs,
+				content.Attachment.ThumbnailLink,
+				waveformArray(content.Attachment.Waveform),
+			}
 			_, err = q.ExecContext(ctx, attachmentQ, attachmentArgs...)
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
@@ -96,15 +146,38 @@ func (c *ChatConn) InsertNewChat(content *chatEntity.DAO) error {
 	return nil
 }
 
-func (c *ChatConn) DeleteChatById(chatId string) error {
+// DeleteChatById keep the row as tombstone so the replies still reference it, the content, attachment, sticker, history and reactions is wiped.
+// the blob of the attachment is left for the storage to collect
+func (c *ChatConn) DeleteChatById(chatId string, deletedAt time.Time) error {
 	query := `
-	DELETE FROM chats WHERE id = $1 RETURNING id`
+	WITH deleted AS (
+		UPDATE chats SET
+			messages = '',
+			sticker_id = NULL,
+			deleted_at = $2
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id, conversation_id, author, seen_at
+	), attachment AS (
+		DELETE FROM media USING deleted WHERE media.chat_id = deleted.id
+	), history AS (
+		DELETE FROM chat_edits USING deleted WHERE chat_edits.chat_id = deleted.id
+	), reactions AS (
+		DELETE FROM chat_reactions USING deleted WHERE chat_reactions.chat_id = deleted.id
+	), unread AS (
+		UPDATE conversation_states SET
+			unread_count = GREATEST(unread_count - 1, 0)
+		FROM deleted
+		WHERE deleted.seen_at IS NULL
+			AND conversation_states.conversation_id = deleted.conversation_id
+			AND conversation_states.user_id != deleted.author
+	)
+	SELECT id FROM deleted`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	var retChatId string
-	err := c.conn.GetContext(ctx, &retChatId, query, chatId)
+	err := c.conn.GetContext(ctx, &retChatId, query, chatId, deletedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return common.WrapError(err, common.ErrRefNotFound23503)
@@ -118,89 +191,262 @@ func (c *ChatConn) DeleteChatById(chatId string) error {
 	return nil
 }
 
-// func (c chat) SelectChatById(chatId string) (*entity.Chat, error) {
-// 	query := `
-// 	SELECT
-// 		chats.id,
-// 		chats.conversation_id,
-// 		chats.author,
-// 		chats.messages,
-// 		chats.reply_to,
-// 		chats.sent_at,
-// 		chats.seen_at,
-// 		media.blob_link,
-// 		media.media_type
-// 	FROM chats
-// 	LEFT JOIN media
-// 		ON media.chat_id = chats.id
-// 	WHERE chats.id = $1`
-// 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-// 	defer cancel()
-
-//		row := c.conn.QueryRowxContext(ctx, query, chatId)
-//		newChat, err := c.createNewChat(row)
-//		if err != nil {
-//			return nil, err
-//		}
-//		return &newChat, nil
-//	}
-func (c *ChatConn) UpdateSeenChat(convId, authorId string) ([]string, error) {
-	query := `UPDATE chats SET seen_at = $1 WHERE conversation_id = $2 AND author != $3 RETURNING id`
+// UpdateChatMessage replace the messages and keep the previous one in the history.
+// chat which is deleted or sent before editableSince is not found
+func (c *ChatConn) UpdateChatMessage(chatId, messages string, editedAt, editableSince time.Time) (chatEntity.DAO, error) {
+	query := `
+	WITH prev AS (
+		SELECT id, messages FROM chats
+		WHERE id = $1 AND deleted_at IS NULL AND sent_at >= $4
+		FOR UPDATE
+	), history AS (
+		INSERT INTO chat_edits(chat_id, messages, edited_at)
+		SELECT id, messages, $3 FROM prev
+	), edited AS (
+		UPDATE chats SET
+			messages = $2,
+			edited_at = $3
+		FROM prev
+		WHERE chats.id = prev.id
+		RETURNING chats.*
+	)
+	SELECT` + selectChatColumns + `
+	FROM edited AS chats` + selectChatJoins
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row := c.conn.QueryRowxContext(ctx, query, chatId, messages, editedAt, editableSince)
+	editedChat, err := c.createNewChat(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return chatEntity.DAO{}, common.WrapErrorWithMsg(err, common.ErrResourceNotFound, "chat is no longer editable")
+		}
+		if errors.Is(err, context.Canceled) {
+			return chatEntity.DAO{}, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return chatEntity.DAO{}, err
+	}
+	return editedChat, nil
+}
+
+func (c *ChatConn) SelectChatHistory(chatId string) ([]chatEntity.History, error) {
+	query := `
+	SELECT
+		messages,
+		edited_at
+	FROM chat_edits
+	WHERE chat_id = $1
+	ORDER BY edited_at DESC`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	history := make([]chatEntity.History, 0)
+	err := c.conn.SelectContext(ctx, &history, query, chatId)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	return history, nil
+}
+
+func (c *ChatConn) SelectChatById(chatId string) (chatEntity.DAO, error) {
+	query := `
+	SELECT` + selectChatColumns + `
+	FROM chats` + selectChatJoins + `
+	WHERE chats.id = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row := c.conn.QueryRowxContext(ctx, query, chatId)
+	newChat, err := c.createNewChat(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return chatEntity.DAO{}, common.WrapError(err, common.ErrResourceNotFound)
+		}
+		if errors.Is(err, context.Canceled) {
+			return chatEntity.DAO{}, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return chatEntity.DAO{}, err
+	}
+	return newChat, nil
+}
+
+// SelectChatContext list the chat together with up to size chats sent before and after it in the same conversation,
+// oldest first. tombstone is included so the surrounding conversation stay readable
+func (c *ChatConn) SelectChatContext(chatId string, size int) ([]chatEntity.DAO, error) {
+	query := `
+	WITH target AS (
+		SELECT id, conversation_id, sent_at FROM chats WHERE id = $1
+	), around AS (
+		(SELECT chats.id
+		FROM chats, target
+		WHERE chats.conversation_id = target.conversation_id
+			AND (chats.sent_at, chats.id) < (target.sent_at, target.id)
+		ORDER BY chats.sent_at DESC, chats.id DESC
+		LIMIT $2)
+		UNION ALL
+		(SELECT id FROM target)
+		UNION ALL
+		(SELECT chats.id
+		FROM chats, target
+		WHERE chats.conversation_id = target.conversation_id
+			AND (chats.sent_at, chats.id) > (target.sent_at, target.id)
+		ORDER BY chats.sent_at, chats.id
+		LIMIT $2)
+	)
+	SELECT` + selectChatColumns + `
+	FROM chats` + selectChatJoins + `
+	WHERE chats.id IN (SELECT id FROM around)
+	ORDER BY chats.sent_at, chats.id`
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	var retIds []string
-	err := c.conn.SelectContext(ctx, &retIds, query, sql.NullTime{Valid: true, Time: time.Now()}, convId, authorId)
+	rows, err := c.conn.QueryxContext(ctx, query, chatId, size)
 	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	defer rows.Close()
+	chats := make([]chatEntity.DAO, 0)
+	for rows.Next() {
+		newChat, err := c.createNewChat(rows)
+		if err != nil {
+			return nil, err
+		}
+		chats = append(chats, newChat)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(chats) == 0 {
+		return nil, common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "chat not found")
+	}
+	return chats, nil
+}
+
+// UpdateSeenChat mark every unseen chat from the partner sent up to and including upToChatId
+func (c *ChatConn) UpdateSeenChat(convId, authorId, upToChatId string, seenAt time.Time) ([]string, error) {
+	watermarkQ := `SELECT id, sent_at FROM chats WHERE id = $1 AND conversation_id = $2`
+	query := `
+	WITH seen AS (
+		UPDATE chats SET
+			seen_at = $1,
+			delivered_at = COALESCE(delivered_at, $1)
+		WHERE conversation_id = $2
+			AND author != $3
+			AND seen_at IS NULL
+			AND deleted_at IS NULL
+			AND (sent_at, id) <= ($4::TIMESTAMPTZ, $5)
+		RETURNING id
+	), unread AS (
+		UPDATE conversation_states SET
+			unread_count = GREATEST(unread_count - (SELECT COUNT(*) FROM seen), 0)
+		WHERE conversation_id = $2 AND user_id = $3
+	)
+	SELECT id FROM seen`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
+	var watermark struct {
+		Id     string    `db:"id"`
+		SentAt time.Time `db:"sent_at"`
+	}
+	err := c.conn.GetContext(ctx, &watermark, watermarkQ, upToChatId, convId)
+	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
 		}
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, common.WrapError(err, common.ErrRefNotFound23503)
+			return nil, common.WrapErrorWithMsg(err, common.ErrResourceNotFound, "upTo chat is not found in this conversation")
 		}
 		return nil, err
 	}
-	if len(retIds) == 0 {
-		return nil, common.ErrResourceNotFound
+
+	retIds := make([]string, 0)
+	err = c.conn.SelectContext(ctx, &retIds, query, seenAt, convId, authorId, watermark.SentAt, watermark.Id)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
 	}
 	return retIds, nil
 }
 
-func (c *ChatConn) SelectChat(convoId string, filter chat.Filter) ([]chatEntity.DAO, error) {
+// UpdateDeliveredChat mark the chats delivered to recipientId, chat authored by the recipient itself or
+// outside the recipient conversation are ignored
+func (c *ChatConn) UpdateDeliveredChat(recipientId string, chatIds []string, deliveredAt time.Time) ([]chatEntity.DAO, error) {
+	query := `
+	UPDATE chats SET
+		delivered_at = $1
+	FROM match
+	WHERE chats.id = ANY($2)
+		AND chats.delivered_at IS NULL
+		AND chats.author != $3
+		AND match.id = chats.conversation_id
+		AND (match.request_from = $3 OR match.request_to = $3)
+	RETURNING chats.id, chats.conversation_id, chats.author, chats.delivered_at`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	chats := make([]chatEntity.DAO, 0)
+	err := c.conn.SelectContext(ctx, &chats, query, deliveredAt, pq.Array(chatIds), recipientId)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	return chats, nil
+}
+
+// SelectChat list the chats of the conversation, each with its reactions aggregated for viewerId
+func (c *ChatConn) SelectChat(convoId, viewerId string, filter chat.Filter) ([]chatEntity.DAO, error) {
 	if filter.Limit == 0 {
 		filter.Limit = 20
 	}
 	args := make([]any, 0)
 	query := `
-	SELECT 
-		chats.id,
-		chats.conversation_id,
-		chats.author,
-		chats.messages,
-		chats.reply_to,
-		chats.sent_at,
-		chats.seen_at,
-		media.blob_link,
-		media.media_type
-	FROM chats
-	LEFT JOIN media
-		ON chats.id = media.chat_id
+	SELECT` + selectChatColumns + `,
+		reactions.aggregate
+	FROM chats` + selectChatJoins + `
+	LEFT JOIN LATERAL (
+		SELECT
+			COALESCE(json_agg(json_build_object(
+				'emoji', emoji,
+				'count', count,
+				'reacted', reacted
+			) ORDER BY first_reacted_at), '[]') AS aggregate
+		FROM (
+			SELECT
+				emoji,
+				COUNT(*) AS count,
+				bool_or(user_id = $2) AS reacted,
+				MIN(reacted_at) AS first_reacted_at
+			FROM chat_reactions
+			WHERE chat_reactions.chat_id = chats.id
+			GROUP BY emoji
+		) AS grouped
+	) AS reactions ON TRUE
 	WHERE 
 		chats.conversation_id=$1`
-	args = append(args, convoId)
+	args = append(args, convoId, viewerId)
 	if filter.Cursor != nil {
 		if filter.Cursor.After {
-			query += ` AND (chats.id, chats.sent_at) < ($2, $3::TIMESTAMPTZ)`
+			query += ` AND (chats.id, chats.sent_at) < ($3, $4::TIMESTAMPTZ)`
 		} else {
-			query += ` AND (chats.id, chats.sent_at) > ($2, $3::TIMESTAMPTZ)`
+			query += ` AND (chats.id, chats.sent_at) > ($3, $4::TIMESTAMPTZ)`
 		}
 		args = append(args, filter.Cursor.Id, filter.Cursor.At)
 		query += ` ORDER BY chats.sent_at DESC, id DESC
-		LIMIT $4`
+		LIMIT $5`
 	} else {
 		query += ` ORDER BY chats.sent_at DESC, id DESC
-		LIMIT $2`
+		LIMIT $3`
 	}
 	args = append(args, filter.Limit)
 
@@ -217,7 +463,12 @@ func (c *ChatConn) SelectChat(convoId string, filter chat.Filter) ([]chatEntity.
 	defer rows.Close()
 	chats := make([]chatEntity.DAO, 0)
 	for rows.Next() {
-		newChat, err := c.createNewChat(rows)
+		var reactions []byte
+		newChat, err := c.createNewChat(rows, &reactions)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(reactions, &newChat.Reactions)
 		if err != nil {
 			return nil, err
 		}
@@ -228,21 +479,120 @@ func (c *ChatConn) SelectChat(convoId string, filter chat.Filter) ([]chatEntity.
 	}
 	return chats, nil
 }
-func (*ChatConn) createNewChat(row sqlx.ColScanner) (chatEntity.DAO, error) {
+
+// InsertReaction add the emoji of userId to the chat, reacting twice with the same emoji is not added.
+// tombstone could not be reacted and treated as not found
+func (c *ChatConn) InsertReaction(chatId, userId, emoji string) (bool, error) {
+	query := `
+	INSERT INTO chat_reactions(chat_id, user_id, emoji)
+	SELECT id, $2, $3 FROM chats WHERE id = $1 AND deleted_at IS NULL
+	ON CONFLICT (chat_id, user_id, emoji) DO NOTHING
+	RETURNING chat_id`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ret := make([]string, 0, 1)
+	err := c.conn.SelectContext(ctx, &ret, query, chatId, userId, emoji)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return false, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return false, common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "user is invalid")
+		}
+		return false, err
+	}
+	return len(ret) != 0, nil
+}
+
+func (c *ChatConn) DeleteReaction(chatId, userId, emoji string) error {
+	query := `
+	DELETE FROM chat_reactions
+	WHERE chat_id = $1 AND user_id = $2 AND emoji = $3`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := c.conn.ExecContext(ctx, query, chatId, userId, emoji)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "reaction not found")
+	}
+	return nil
+}
+
+// selectChatColumns is the columns scanned by createNewChat, expect selectChatJoins
+var selectChatColumns = `
+		chats.id,
+		chats.conversation_id,
+		chats.author,
+		chats.messages,
+		chats.reply_to,
+		chats.sent_at,
+		chats.delivered_at,
+		chats.seen_at,
+		chats.edited_at,
+		chats.deleted_at,
+		media.blob_link,
+		media.media_type,
+		media.width,
+		media.height,
+		media.duration_ms,
+		media.thumbnail_link,
+		media.waveform,
+		stickers.id,
+		stickers.pack_id,
+		stickers.blob_link,
+		stickers.media_type`
+
+var selectChatJoins = `
+	LEFT JOIN media
+		ON media.chat_id = chats.id
+	LEFT JOIN stickers
+		ON stickers.id = chats.sticker_id`
+
+// createNewChat scan the selectChatColumns, followed by extraDest
+func (*ChatConn) createNewChat(row sqlx.ColScanner, extraDest ...any) (chatEntity.DAO, error) {
 	var newChat chatEntity.DAO
 	var blobLink sql.NullString
 	var mediaType sql.NullString
-	err := row.Scan(
+	var width, height, durationMs sql.NullInt32
+	var thumbnailLink sql.NullString
+	var waveform pq.Int64Array
+	var stickerId, stickerPackId, stickerBlobLink, stickerMediaType sql.NullString
+	dest := []any{
 		&newChat.Id,
 		&newChat.ConversationId,
 		&newChat.Author,
 		&newChat.Messages,
 		&newChat.ReplyTo,
 		&newChat.SentAt,
+		&newChat.DeliveredAt,
 		&newChat.SeenAt,
+		&newChat.EditedAt,
+		&newChat.DeletedAt,
 		&blobLink,
 		&mediaType,
-	)
+		&width,
+		&height,
+		&durationMs,
+		&thumbnailLink,
+		&waveform,
+		&stickerId,
+		&stickerPackId,
+		&stickerBlobLink,
+		&stickerMediaType,
+	}
+	err := row.Scan(append(dest, extraDest...)...)
 	if err != nil {
 		return chatEntity.DAO{}, err
 	}
@@ -252,9 +602,48 @@ func (*ChatConn) createNewChat(row sqlx.ColScanner) (chatEntity.DAO, error) {
 			BlobLink:  blobLink.String,
 			MediaType: mediaType.String,
 		}
+		if width.Valid && height.Valid {
+			w, h := int(width.Int32), int(height.Int32)
+			newChat.Attachment.Width = &w
+			newChat.Attachment.Height = &h
+		}
+		if durationMs.Valid {
+			d := int(durationMs.Int32)
+			newChat.Attachment.DurationMs = &d
+		}
+		if thumbnailLink.Valid {
+			newChat.Attachment.ThumbnailLink = &thumbnailLink.String
+		}
+		if waveform != nil {
+			newChat.Attachment.Waveform = make([]int, 0, len(waveform))
+			for _, peak := range waveform {
+				newChat.Attachment.Waveform = append(newChat.Attachment.Waveform, int(peak))
+			}
+		}
+	}
+	if stickerId.Valid {
+		newChat.Sticker = &chatEntity.Sticker{
+			Id:        stickerId.String,
+			PackId:    stickerPackId.String,
+			BlobLink:  stickerBlobLink.String,
+			MediaType: stickerMediaType.String,
+		}
 	}
 	return newChat, nil
 }
-func (c *ChatConn) execTx(ctx context.Context, q func(q *sqlx.DB) error) error {
+
+// waveformArray keep the column NULL when there is no waveform
+func waveformArray(waveform []int) pq.Int64Array {
+	if waveform == nil {
+		return nil
+	}
+	peaks := make(pq.Int64Array, 0, len(waveform))
+	for _, peak := range waveform {
+		peaks = append(peaks, int64(peak))
+	}
+	return peaks
+}
+
+func (c *ChatConn) execTx(ctx context.Context, q func(q queryer) error) error {
 	return execGeneric(c.conn, ctx, q, &sql.TxOptions{Isolation: sql.LevelReadCommitted, ReadOnly: false})
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/xyedo/blindate/pkg/domain/chat"
 	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
 	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+	stickerEntity "github.com/xyedo/blindate/pkg/domain/sticker/entities"
 	"github.com/xyedo/blindate/pkg/infra/repository"
 	"github.com/xyedo/blindate/pkg/util"
 )
@@ -62,6 +63,63 @@ func Test_InsertNewChat(t *testing.T) {
 		})
 		require.NoError(t, err)
 	})
+	t.Run("valid new chat w image attachment", func(t *testing.T) {
+		convoId, fromUsr, _ := setup(t)
+		width, height := 640, 480
+		thumbnail := "chat-thumbnail/" + util.RandomUUID() + ".jpg"
+
+		newChat := &chatEntity.DAO{
+			ConversationId: convoId,
+			Author:         fromUsr,
+			SentAt:         time.Now(),
+			Attachment: &chatEntity.Attachment{
+				BlobLink:      "chat-attachment/" + util.RandomUUID() + ".jpg",
+				MediaType:     "image/jpeg",
+				Width:         &width,
+				Height:        &height,
+				ThumbnailLink: &thumbnail,
+			},
+		}
+		err := chatRepo.InsertNewChat(newChat)
+		require.NoError(t, err)
+
+		got, err := chatRepo.SelectChatById(newChat.Id)
+		require.NoError(t, err)
+		require.NotNil(t, got.Attachment)
+		require.NotNil(t, got.Attachment.Width)
+		assert.Equal(t, width, *got.Attachment.Width)
+		assert.Equal(t, height, *got.Attachment.Height)
+		assert.Nil(t, got.Attachment.DurationMs)
+		require.NotNil(t, got.Attachment.ThumbnailLink)
+		assert.Equal(t, thumbnail, *got.Attachment.ThumbnailLink)
+	})
+	t.Run("valid new chat w voice note waveform", func(t *testing.T) {
+		convoId, fromUsr, _ := setup(t)
+		durationMs := 3000
+		waveform := []int{0, 12, 100, 57, 3}
+
+		newChat := &chatEntity.DAO{
+			ConversationId: convoId,
+			Author:         fromUsr,
+			SentAt:         time.Now(),
+			Attachment: &chatEntity.Attachment{
+				BlobLink:   "chat-attachment/" + util.RandomUUID() + ".ogg",
+				MediaType:  "application/ogg",
+				DurationMs: &durationMs,
+				Waveform:   waveform,
+			},
+		}
+		err := chatRepo.InsertNewChat(newChat)
+		require.NoError(t, err)
+
+		got, err := chatRepo.SelectChatById(newChat.Id)
+		require.NoError(t, err)
+		require.NotNil(t, got.Attachment)
+		assert.Nil(t, got.Attachment.Width)
+		require.NotNil(t, got.Attachment.DurationMs)
+		assert.Equal(t, durationMs, *got.Attachment.DurationMs)
+		assert.Equal(t, waveform, got.Attachment.Waveform)
+	})
 	t.Run("valid chat but invalid attachment type", func(t *testing.T) {
 		convoId, fromUsr, _ := setup(t)
 
@@ -78,6 +136,70 @@ func Test_InsertNewChat(t *testing.T) {
 		require.Error(t, err)
 		assert.ErrorIs(t, err, common.ErrRefNotFound23503)
 	})
+	t.Run("valid new chat w sticker", func(t *testing.T) {
+		convoId, fromUsr, _ := setup(t)
+		sticker := createNewSticker(t, createNewPack(t))
+
+		newChat := &chatEntity.DAO{
+			ConversationId: convoId,
+			Author:         fromUsr,
+			SentAt:         time.Now(),
+			Sticker:        &chatEntity.Sticker{Id: sticker.Id},
+		}
+		err := chatRepo.InsertNewChat(newChat)
+		require.NoError(t, err)
+		assert.Equal(t, sticker.BlobLink, newChat.Sticker.BlobLink)
+
+		got, err := chatRepo.SelectChatById(newChat.Id)
+		require.NoError(t, err)
+		require.NotNil(t, got.Sticker)
+		assert.Equal(t, sticker.Id, got.Sticker.Id)
+		assert.Equal(t, sticker.PackId, got.Sticker.PackId)
+		assert.Equal(t, sticker.MediaType, got.Sticker.MediaType)
+	})
+	t.Run("archived sticker", func(t *testing.T) {
+		convoId, fromUsr, _ := setup(t)
+		sticker := createNewSticker(t, createNewPack(t))
+		err := repository.NewSticker(testQuery).ArchiveSticker(sticker.Id, time.Now())
+		require.NoError(t, err)
+
+		err = chatRepo.InsertNewChat(&chatEntity.DAO{
+			ConversationId: convoId,
+			Author:         fromUsr,
+			SentAt:         time.Now(),
+			Sticker:        &chatEntity.Sticker{Id: sticker.Id},
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrRefNotFound23503)
+	})
+	t.Run("sticker of archived pack", func(t *testing.T) {
+		convoId, fromUsr, _ := setup(t)
+		packId := createNewPack(t)
+		sticker := createNewSticker(t, packId)
+		archived := true
+		_, err := repository.NewSticker(testQuery).UpdatePack(packId, stickerEntity.UpdatePack{Archived: &archived}, time.Now())
+		require.NoError(t, err)
+
+		err = chatRepo.InsertNewChat(&chatEntity.DAO{
+			ConversationId: convoId,
+			Author:         fromUsr,
+			SentAt:         time.Now(),
+			Sticker:        &chatEntity.Sticker{Id: sticker.Id},
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrRefNotFound23503)
+	})
+	t.Run("unknown sticker", func(t *testing.T) {
+		convoId, fromUsr, _ := setup(t)
+		err := chatRepo.InsertNewChat(&chatEntity.DAO{
+			ConversationId: convoId,
+			Author:         fromUsr,
+			SentAt:         time.Now(),
+			Sticker:        &chatEntity.Sticker{Id: util.RandomUUID()},
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrRefNotFound23503)
+	})
 	t.Run("invalid conversationId", func(t *testing.T) {
 		_, fromUsr, _ := setup(t)
 		err := chatRepo.InsertNewChat(&chatEntity.DAO{
@@ -118,55 +240,146 @@ func Test_InsertNewChat(t *testing.T) {
 }
 
 func Test_UpdateSeenChatById(t *testing.T) {
-	chat := repository.NewChat(testQuery)
-	t.Run("valid", func(t *testing.T) {
+	chatRepo := repository.NewChat(testQuery)
+	setup := func(t *testing.T) (convoId, fromUserId, toUserId string) {
 		conv := repository.NewConversation(testQuery)
 		matchRepo := repository.NewMatch(testQuery)
 		fromUsr := createNewAccount(t)
 		toUsr := createNewAccount(t)
 		matchId, err := matchRepo.InsertNewMatch(fromUsr.ID, toUsr.ID, matchEntity.Requested)
 		require.NoError(t, err)
-		convoId, err := conv.InsertConversation(matchId)
+		convoId, err = conv.InsertConversation(matchId)
 		require.NoError(t, err)
 		require.NotEmpty(t, convoId)
+		return convoId, fromUsr.ID, toUsr.ID
+	}
+	insertChat := func(t *testing.T, convoId, authorId string, sentAt time.Time) string {
 		//this should not happen in prod. the author must have link by converstation
 		newChat := &chatEntity.DAO{
 			ConversationId: convoId,
-			Author:         fromUsr.ID,
-			Messages:       "whatsup sexy!",
-			SentAt:         time.Now(),
+			Author:         authorId,
+			Messages:       util.RandomString(12),
+			SentAt:         sentAt,
 		}
-		err = chat.InsertNewChat(newChat)
+		err := chatRepo.InsertNewChat(newChat)
 		require.NoError(t, err)
-		err = chat.InsertNewChat(&chatEntity.DAO{
-			ConversationId: convoId,
-			Author:         toUsr.ID,
-			Messages:       "omg whatsup",
-			SentAt:         time.Now(),
-		})
-		require.NoError(t, err)
-		changedChatIds, err := chat.UpdateSeenChat(convoId, fromUsr.ID)
+		return newChat.Id
+	}
+	t.Run("valid", func(t *testing.T) {
+		convoId, fromUsrId, toUsrId := setup(t)
+		insertChat(t, convoId, fromUsrId, time.Now())
+		partnerChatId := insertChat(t, convoId, toUsrId, time.Now())
+
+		changedChatIds, err := chatRepo.UpdateSeenChat(convoId, fromUsrId, partnerChatId, time.Now())
 		require.NoError(t, err)
 		require.Len(t, changedChatIds, 1)
-		assert.NotEmpty(t, changedChatIds[0])
+		assert.Equal(t, partnerChatId, changedChatIds[0])
+	})
+	t.Run("only up to the watermark", func(t *testing.T) {
+		convoId, fromUsrId, toUsrId := setup(t)
+		now := time.Now()
+		firstChatId := insertChat(t, convoId, toUsrId, now.Add(-2*time.Minute))
+		watermarkChatId := insertChat(t, convoId, toUsrId, now.Add(-time.Minute))
+		insertChat(t, convoId, toUsrId, now)
+
+		changedChatIds, err := chatRepo.UpdateSeenChat(convoId, fromUsrId, watermarkChatId, now)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{firstChatId, watermarkChatId}, changedChatIds)
+
+		changedChatIds, err = chatRepo.UpdateSeenChat(convoId, fromUsrId, watermarkChatId, now)
+		require.NoError(t, err)
+		assert.Empty(t, changedChatIds)
+
+		chats, err := chatRepo.SelectChat(convoId, fromUsrId, chat.Filter{})
+		require.NoError(t, err)
+		for _, c := range chats {
+			if c.Id == firstChatId || c.Id == watermarkChatId {
+				assert.True(t, c.SeenAt.Valid)
+				assert.True(t, c.DeliveredAt.Valid)
+				continue
+			}
+			assert.False(t, c.SeenAt.Valid)
+		}
+	})
+	t.Run("watermark not in conversation", func(t *testing.T) {
+		convoId, fromUsrId, _ := setup(t)
+		otherConvoId, _, otherToUsrId := setup(t)
+		otherChatId := insertChat(t, otherConvoId, otherToUsrId, time.Now())
+
+		changedChatIds, err := chatRepo.UpdateSeenChat(convoId, fromUsrId, otherChatId, time.Now())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+		assert.Empty(t, changedChatIds)
 	})
 	t.Run("invalid chatId", func(t *testing.T) {
-		changedChatIds, err := chat.UpdateSeenChat(util.RandomUUID(), util.RandomUUID())
+		changedChatIds, err := chatRepo.UpdateSeenChat(util.RandomUUID(), util.RandomUUID(), util.RandomUUID(), time.Now())
 		require.Error(t, err)
 		assert.ErrorIs(t, err, common.ErrResourceNotFound)
 		assert.Empty(t, changedChatIds)
 	})
 }
 
+func Test_UpdateDeliveredChat(t *testing.T) {
+	chatRepo := repository.NewChat(testQuery)
+	t.Run("valid", func(t *testing.T) {
+		chatId, convoId := createNewChat(chatRepo, t)
+		matchDAO, err := repository.NewMatch(testQuery).GetMatchById(convoId)
+		require.NoError(t, err)
+
+		deliveredChats, err := chatRepo.UpdateDeliveredChat(matchDAO.RequestTo, []string{chatId}, time.Now())
+		require.NoError(t, err)
+		require.Len(t, deliveredChats, 1)
+		assert.Equal(t, chatId, deliveredChats[0].Id)
+		assert.Equal(t, convoId, deliveredChats[0].ConversationId)
+		assert.Equal(t, matchDAO.RequestFrom, deliveredChats[0].Author)
+		assert.True(t, deliveredChats[0].DeliveredAt.Valid)
+
+		deliveredChats, err = chatRepo.UpdateDeliveredChat(matchDAO.RequestTo, []string{chatId}, time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, deliveredChats)
+	})
+	t.Run("author can not ack own chat", func(t *testing.T) {
+		chatId, convoId := createNewChat(chatRepo, t)
+		matchDAO, err := repository.NewMatch(testQuery).GetMatchById(convoId)
+		require.NoError(t, err)
+
+		deliveredChats, err := chatRepo.UpdateDeliveredChat(matchDAO.RequestFrom, []string{chatId}, time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, deliveredChats)
+	})
+	t.Run("outsider can not ack", func(t *testing.T) {
+		chatId, _ := createNewChat(chatRepo, t)
+
+		deliveredChats, err := chatRepo.UpdateDeliveredChat(util.RandomUUID(), []string{chatId}, time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, deliveredChats)
+	})
+}
+
 func Test_DeleteChat(t *testing.T) {
 	chat := repository.NewChat(testQuery)
 	t.Run("valid delete", func(t *testing.T) {
 		chatId, _ := createNewChat(chat, t)
-		err := chat.DeleteChatById(chatId)
+		deletedAt := time.Now()
+		err := chat.DeleteChatById(chatId, deletedAt)
+		require.NoError(t, err)
+
+		tombstone, err := chat.SelectChatById(chatId)
 		require.NoError(t, err)
+		assert.Empty(t, tombstone.Messages)
+		require.True(t, tombstone.DeletedAt.Valid)
+		assert.WithinDuration(t, deletedAt, tombstone.DeletedAt.Time, time.Second)
 	})
 	t.Run("invalid chatId", func(t *testing.T) {
-		err := chat.DeleteChatById(util.RandomUUID())
+		err := chat.DeleteChatById(util.RandomUUID(), time.Now())
+		require.Error(t, err)
+		require.ErrorIs(t, err, common.ErrRefNotFound23503)
+	})
+	t.Run("already deleted", func(t *testing.T) {
+		chatId, _ := createNewChat(chat, t)
+		err := chat.DeleteChatById(chatId, time.Now())
+		require.NoError(t, err)
+		err = chat.DeleteChatById(chatId, time.Now())
 		require.Error(t, err)
 		require.ErrorIs(t, err, common.ErrRefNotFound23503)
 	})
@@ -188,7 +401,7 @@ func Test_DeleteChat(t *testing.T) {
 		}
 		err = chat.InsertNewChat(newChat)
 		require.NoError(t, err)
-		err = chat.InsertNewChat(&chatEntity.DAO{
+		reply := &chatEntity.DAO{
 			ConversationId: convoId,
 			Author:         fromUsr.ID,
 			Messages:       util.RandomString(12),
@@ -197,10 +410,149 @@ func Test_DeleteChat(t *testing.T) {
 				String: newChat.Id,
 			},
 			SentAt: time.Now(),
-		})
+		}
+		err = chat.InsertNewChat(reply)
+		require.NoError(t, err)
+		err = chat.DeleteChatById(newChat.Id, time.Now())
+		require.NoError(t, err)
+
+		retReply, err := chat.SelectChatById(reply.Id)
+		require.NoError(t, err)
+		require.True(t, retReply.ReplyTo.Valid)
+		assert.Equal(t, newChat.Id, retReply.ReplyTo.String)
+	})
+}
+
+func Test_UpdateChatMessage(t *testing.T) {
+	chat := repository.NewChat(testQuery)
+	t.Run("valid edit keep history", func(t *testing.T) {
+		chatId, _ := createNewChat(chat, t)
+		firstEdit := time.Now()
+		edited, err := chat.UpdateChatMessage(chatId, "first edit", firstEdit, firstEdit.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, "first edit", edited.Messages)
+		require.True(t, edited.EditedAt.Valid)
+
+		secondEdit := firstEdit.Add(time.Second)
+		edited, err = chat.UpdateChatMessage(chatId, "second edit", secondEdit, firstEdit.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, "second edit", edited.Messages)
+
+		history, err := chat.SelectChatHistory(chatId)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, "first edit", history[0].Messages)
+		assert.Equal(t, "whatsup sexy!", history[1].Messages)
+	})
+	t.Run("edit window passed", func(t *testing.T) {
+		chatId, _ := createNewChat(chat, t)
+		_, err := chat.UpdateChatMessage(chatId, util.RandomString(12), time.Now(), time.Now().Add(time.Hour))
+		require.Error(t, err)
+		require.ErrorIs(t, err, common.ErrResourceNotFound)
+
+		history, err := chat.SelectChatHistory(chatId)
+		require.NoError(t, err)
+		assert.Empty(t, history)
+	})
+	t.Run("deleted chat is not editable", func(t *testing.T) {
+		chatId, _ := createNewChat(chat, t)
+		_, err := chat.UpdateChatMessage(chatId, util.RandomString(12), time.Now(), time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		err = chat.DeleteChatById(chatId, time.Now())
+		require.NoError(t, err)
+
+		_, err = chat.UpdateChatMessage(chatId, util.RandomString(12), time.Now(), time.Now().Add(-time.Hour))
+		require.Error(t, err)
+		require.ErrorIs(t, err, common.ErrResourceNotFound)
+
+		history, err := chat.SelectChatHistory(chatId)
+		require.NoError(t, err)
+		assert.Empty(t, history)
+	})
+}
+
+func Test_Reaction(t *testing.T) {
+	chatRepo := repository.NewChat(testQuery)
+	conv := repository.NewConversation(testQuery)
+	matchRepo := repository.NewMatch(testQuery)
+	setup := func(t *testing.T) (convoId, chatId, fromUserId, toUserId string) {
+		fromUsr := createNewAccount(t)
+		toUsr := createNewAccount(t)
+		matchId, err := matchRepo.InsertNewMatch(fromUsr.ID, toUsr.ID, matchEntity.Requested)
+		require.NoError(t, err)
+		convoId, err = conv.InsertConversation(matchId)
+		require.NoError(t, err)
+		newChat := &chatEntity.DAO{
+			ConversationId: convoId,
+			Author:         fromUsr.ID,
+			Messages:       util.RandomString(12),
+			SentAt:         time.Now(),
+		}
+		err = chatRepo.InsertNewChat(newChat)
+		require.NoError(t, err)
+		return convoId, newChat.Id, fromUsr.ID, toUsr.ID
+	}
+	t.Run("aggregated per viewer", func(t *testing.T) {
+		convoId, chatId, fromUserId, toUserId := setup(t)
+		added, err := chatRepo.InsertReaction(chatId, toUserId, "👍")
+		require.NoError(t, err)
+		assert.True(t, added)
+		added, err = chatRepo.InsertReaction(chatId, toUserId, "👍")
+		require.NoError(t, err)
+		assert.False(t, added)
+		added, err = chatRepo.InsertReaction(chatId, fromUserId, "👍")
+		require.NoError(t, err)
+		assert.True(t, added)
+		added, err = chatRepo.InsertReaction(chatId, toUserId, "😂")
+		require.NoError(t, err)
+		assert.True(t, added)
+
+		chats, err := chatRepo.SelectChat(convoId, fromUserId, chat.Filter{})
+		require.NoError(t, err)
+		require.Len(t, chats, 1)
+		assert.Equal(t, []chatEntity.Reaction{
+			{Emoji: "👍", Count: 2, Reacted: true},
+			{Emoji: "😂", Count: 1, Reacted: false},
+		}, chats[0].Reactions)
+
+		err = chatRepo.DeleteReaction(chatId, toUserId, "👍")
+		require.NoError(t, err)
+		chats, err = chatRepo.SelectChat(convoId, toUserId, chat.Filter{})
+		require.NoError(t, err)
+		require.Len(t, chats, 1)
+		assert.Equal(t, []chatEntity.Reaction{
+			{Emoji: "👍", Count: 1, Reacted: false},
+			{Emoji: "😂", Count: 1, Reacted: true},
+		}, chats[0].Reactions)
+	})
+	t.Run("no reaction", func(t *testing.T) {
+		convoId, _, fromUserId, _ := setup(t)
+		chats, err := chatRepo.SelectChat(convoId, fromUserId, chat.Filter{})
 		require.NoError(t, err)
-		err = chat.DeleteChatById(newChat.Id)
+		require.Len(t, chats, 1)
+		assert.NotNil(t, chats[0].Reactions)
+		assert.Empty(t, chats[0].Reactions)
+	})
+	t.Run("remove missing reaction", func(t *testing.T) {
+		_, chatId, _, toUserId := setup(t)
+		err := chatRepo.DeleteReaction(chatId, toUserId, "👍")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+	t.Run("tombstone could not be reacted and wiped", func(t *testing.T) {
+		convoId, chatId, fromUserId, toUserId := setup(t)
+		_, err := chatRepo.InsertReaction(chatId, toUserId, "👍")
+		require.NoError(t, err)
+		err = chatRepo.DeleteChatById(chatId, time.Now())
 		require.NoError(t, err)
+
+		added, err := chatRepo.InsertReaction(chatId, toUserId, "😂")
+		require.NoError(t, err)
+		assert.False(t, added)
+		chats, err := chatRepo.SelectChat(convoId, fromUserId, chat.Filter{})
+		require.NoError(t, err)
+		require.Len(t, chats, 1)
+		assert.Empty(t, chats[0].Reactions)
 	})
 }
 
@@ -252,15 +604,15 @@ func Test_SelectChat(t *testing.T) {
 			require.NoError(t, err)
 		}
 		t.Run("with default offset", func(t *testing.T) {
-			retChats, err := chatRepo.SelectChat(convoId, chat.Filter{})
+			retChats, err := chatRepo.SelectChat(convoId, fromUsr.ID, chat.Filter{})
 			require.NoError(t, err)
 			require.NotEmpty(t, retChats)
 		})
 		t.Run("with cursor", func(t *testing.T) {
-			retChats, err := chatRepo.SelectChat(convoId, chat.Filter{})
+			retChats, err := chatRepo.SelectChat(convoId, fromUsr.ID, chat.Filter{})
 			require.NoError(t, err)
 			require.Len(t, retChats, 20)
-			before20Chats, err := chatRepo.SelectChat(convoId, chat.Filter{
+			before20Chats, err := chatRepo.SelectChat(convoId, fromUsr.ID, chat.Filter{
 				Cursor: &chat.Cursor{
 					After: true,
 					At:    chatDivider.SentAt,
@@ -269,7 +621,7 @@ func Test_SelectChat(t *testing.T) {
 			})
 			require.NoError(t, err)
 			require.NotEmpty(t, before20Chats)
-			after20Chats, err := chatRepo.SelectChat(convoId, chat.Filter{
+			after20Chats, err := chatRepo.SelectChat(convoId, fromUsr.ID, chat.Filter{
 				Cursor: &chat.Cursor{
 					After: false,
 					At:    chatDivider.SentAt,
@@ -282,6 +634,44 @@ func Test_SelectChat(t *testing.T) {
 	})
 
 }
+func Test_SelectChatContext(t *testing.T) {
+	chatRepo := repository.NewChat(testQuery)
+	chatId, convoId := createNewChat(chatRepo, t)
+	matchDAO, err := repository.NewMatch(testQuery).GetMatchById(convoId)
+	require.NoError(t, err)
+	// the target is sent just before sentAt, so it sit in between the minutes
+	sentAt := time.Now()
+	chatIds := make([]string, 0, 7)
+	for i := -3; i <= 3; i++ {
+		if i == 0 {
+			continue
+		}
+		newChat := &chatEntity.DAO{
+			ConversationId: convoId,
+			Author:         matchDAO.RequestTo,
+			Messages:       util.RandomString(15),
+			SentAt:         sentAt.Add(time.Duration(i) * time.Minute),
+		}
+		err = chatRepo.InsertNewChat(newChat)
+		require.NoError(t, err)
+		chatIds = append(chatIds, newChat.Id)
+	}
+
+	t.Run("chats around the target", func(t *testing.T) {
+		chats, err := chatRepo.SelectChatContext(chatId, 2)
+		require.NoError(t, err)
+		require.Len(t, chats, 5)
+		assert.Equal(t, chatIds[1], chats[0].Id)
+		assert.Equal(t, chatId, chats[2].Id)
+		assert.Equal(t, chatIds[4], chats[4].Id)
+	})
+	t.Run("unknown chat", func(t *testing.T) {
+		_, err := chatRepo.SelectChatContext(util.RandomUUID(), 2)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+}
+
 func createNewChat(chat *repository.ChatConn, t *testing.T) (string, string) {
 	conv := repository.NewConversation(testQuery)
 	matchRepo := repository.NewMatch(testQuery)
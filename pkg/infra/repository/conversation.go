@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/xyedo/blindate/pkg/common"
 	"github.com/xyedo/blindate/pkg/domain/conversation"
 	convEntity "github.com/xyedo/blindate/pkg/domain/conversation/entities"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
 )
 
 func NewConversation(conn *sqlx.DB) *ConvConn {
@@ -26,9 +28,20 @@ type ConvConn struct {
 
 func (c *ConvConn) InsertConversation(matchId string) (string, error) {
 	query := `
-	INSERT INTO conversations(match_id)
-	VALUES($1)
-	RETURNING match_id`
+	WITH conv AS (
+		INSERT INTO conversations(match_id)
+		VALUES($1)
+		RETURNING match_id
+	), state AS (
+		INSERT INTO conversation_states(conversation_id, user_id)
+		SELECT conv.match_id, participant.user_id
+		FROM conv
+		JOIN match ON match.id = conv.match_id
+		CROSS JOIN LATERAL (
+			VALUES (match.request_from), (match.request_to)
+		) AS participant(user_id)
+	)
+	SELECT match_id FROM conv`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -54,7 +67,7 @@ func (c *ConvConn) InsertConversation(matchId string) (string, error) {
 	return convoId, nil
 }
 
-var selectConvo = `
+var selectConvoColumns = `
 SELECT 
 conv.match_id AS id,
 conv.chat_rows AS chat_rows,
@@ -64,7 +77,7 @@ creator.full_name AS creator_full_name,
 creator.alias AS creator_alias,
 (
 	SELECT
-		picture_ref
+		COALESCE(thumbnail_ref, picture_ref)
 	FROM profile_picture 
 	WHERE user_id = creator.id
 	ORDER BY selected DESC, id DESC
@@ -75,7 +88,7 @@ recipient.full_name AS recipient_full_name,
 recipient.alias AS recipient_alias,
 (
 	SELECT 
-		picture_ref
+		COALESCE(thumbnail_ref, picture_ref)
 	FROM profile_picture
 	WHERE user_id = recipient.id
 	ORDER BY selected DESC, id DESC
@@ -85,27 +98,37 @@ c.messages AS last_messages,
 c.sent_at AS last_messages_sent_at,
 c.seen_at AS last_messages_seen_at,
 match.request_status AS request_status,
-match.reveal_status AS reveal_status
-FROM conversations AS conv
+match.reveal_status AS reveal_status`
+
+// selectConvoJoins expect conv to be joined, last message (tombstone excluded) is looked up per conversation by chats_conversation_last_idx
+var selectConvoJoins = `
 JOIN match
 	ON match.id = conv.match_id
 JOIN users AS creator 
 	ON creator.id = match.request_from
 JOIN users AS recipient
 	ON recipient.id = match.request_to
-LEFT JOIN (
-	SELECT DISTINCT ON (conversation_id) 
-		conversation_id,
+LEFT JOIN LATERAL (
+	SELECT
 		messages,
 		sent_at,
 		seen_at
 	FROM chats 
-	ORDER BY conversation_id, sent_at DESC
-) AS c ON c.conversation_id = conv.match_id`
+	WHERE chats.conversation_id = conv.match_id AND chats.deleted_at IS NULL
+	ORDER BY sent_at DESC, id DESC
+	LIMIT 1
+) AS c ON TRUE`
+
+var selectConvo = selectConvoColumns + `
+FROM conversations AS conv` + selectConvoJoins
 
+// notBlockedConvo hide the conversation of the pair which one of them blocked the other, expect match to be joined
+var notBlockedConvo = ` AND NOT ` + blockedBetween("match.request_from", "match.request_to")
+
+// SelectConversationById return the conversation, the conversation of a blocked pair is not found
 func (c *ConvConn) SelectConversationById(matchId string) (convEntity.DTO, error) {
 	convQuery := selectConvo +
-		` WHERE conv.match_id = $1`
+		` WHERE conv.match_id = $1` + notBlockedConvo
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -127,18 +150,49 @@ func (c *ConvConn) SelectConversationById(matchId string) (convEntity.DTO, error
 
 }
 
+// SelectConversationByUserId list the conversation of UserId ordered by pinned, last activity and id.
+// nil filter list every conversation regardless the inbox state
 func (c *ConvConn) SelectConversationByUserId(UserId string, filter *conversation.Filter) ([]convEntity.DTO, error) {
-	convQuery := selectConvo +
-		` WHERE 
-			creator.id = $1 OR
-			recipient.id = $1
-		ORDER BY last_messages_sent_at DESC
-		LIMIT 20`
+	convQuery := selectConvoColumns + `,
+	state.unread_count,
+	state.muted_until,
+	state.pinned,
+	state.archived,
+	state.last_activity_at
+	FROM conversation_states AS state
+	JOIN conversations AS conv
+		ON conv.match_id = state.conversation_id` + selectConvoJoins + `
+	WHERE state.user_id = $1` + notBlockedConvo
 
 	args := []any{UserId}
 	if filter != nil {
-		convQuery += ` OFFSET $2`
-		args = append(args, filter.Offset)
+		if filter.Limit == 0 {
+			filter.Limit = 20
+		}
+		args = append(args, filter.Archived)
+		convQuery += fmt.Sprintf(` AND state.archived = $%d`, len(args))
+		if filter.UnreadOnly {
+			convQuery += ` AND state.unread_count > 0`
+		}
+		if filter.Revealed != nil {
+			args = append(args, matchEntity.Accepted)
+			if *filter.Revealed {
+				convQuery += fmt.Sprintf(` AND match.reveal_status = $%d`, len(args))
+			} else {
+				convQuery += fmt.Sprintf(` AND match.reveal_status != $%d`, len(args))
+			}
+		}
+		if filter.Cursor != nil {
+			args = append(args, filter.Cursor.Pinned, filter.Cursor.At, filter.Cursor.Id)
+			convQuery += fmt.Sprintf(` AND (state.pinned, state.last_activity_at, state.conversation_id) < ($%d, $%d::TIMESTAMPTZ, $%d)`,
+				len(args)-2, len(args)-1, len(args))
+		}
+	}
+	convQuery += `
+	ORDER BY state.pinned DESC, state.last_activity_at DESC, state.conversation_id DESC`
+	if filter != nil {
+		args = append(args, filter.Limit)
+		convQuery += fmt.Sprintf(` LIMIT $%d`, len(args))
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -160,10 +214,16 @@ func (c *ConvConn) SelectConversationByUserId(UserId string, filter *conversatio
 
 	convs := make([]convEntity.DTO, 0)
 	for rows.Next() {
-		newConv, err := c.createNewChat(rows)
+		var state convEntity.State
+		var mutedUntil sql.NullTime
+		newConv, err := c.createNewChat(rows, &state.UnreadCount, &mutedUntil, &state.Pinned, &state.Archived, &state.LastActivityAt)
 		if err != nil {
 			return nil, err
 		}
+		if mutedUntil.Valid {
+			state.MutedUntil = &mutedUntil.Time
+		}
+		newConv.State = &state
 		convs = append(convs, newConv)
 	}
 	if err = rows.Err(); err != nil {
@@ -235,7 +295,64 @@ func (c *ConvConn) DeleteConversationById(convoId string) error {
 	return nil
 }
 
-func (*ConvConn) createNewChat(row sqlx.ColScanner) (convEntity.DTO, error) {
+func (c *ConvConn) UpdateState(convoId, userId string, state convEntity.State) (convEntity.State, error) {
+	query := `
+	UPDATE conversation_states SET
+		muted_until = $1,
+		pinned = $2,
+		archived = $3
+	WHERE conversation_id = $4 AND user_id = $5
+	RETURNING unread_count, muted_until, pinned, archived, last_activity_at`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var newState convEntity.State
+	var mutedUntil sql.NullTime
+	err := c.conn.QueryRowxContext(ctx, query, state.MutedUntil, state.Pinned, state.Archived, convoId, userId).
+		Scan(&newState.UnreadCount, &mutedUntil, &newState.Pinned, &newState.Archived, &newState.LastActivityAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return convEntity.State{}, common.WrapError(err, common.ErrResourceNotFound)
+		}
+		if errors.Is(err, context.Canceled) {
+			return convEntity.State{}, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return convEntity.State{}, err
+	}
+	if mutedUntil.Valid {
+		newState.MutedUntil = &mutedUntil.Time
+	}
+	return newState, nil
+}
+
+// SelectTotalUnread sum the unread count of userId, muted and blocked conversation are not counted
+func (c *ConvConn) SelectTotalUnread(userId string) (int, error) {
+	query := `
+	SELECT
+		COALESCE(SUM(state.unread_count), 0)
+	FROM conversation_states AS state
+	JOIN match
+		ON match.id = state.conversation_id
+	WHERE state.user_id = $1
+		AND (state.muted_until IS NULL OR state.muted_until <= NOW())` + notBlockedConvo
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var total int
+	err := c.conn.GetContext(ctx, &total, query, userId)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return 0, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return 0, err
+	}
+	return total, nil
+}
+
+// createNewChat scan the selectConvo columns, followed by extraDest
+func (*ConvConn) createNewChat(row sqlx.ColScanner, extraDest ...any) (convEntity.DTO, error) {
 	var newConv convEntity.DTO
 
 	var creatorProfPic sql.NullString
@@ -244,7 +361,7 @@ func (*ConvConn) createNewChat(row sqlx.ColScanner) (convEntity.DTO, error) {
 	var lastMessage sql.NullString
 	var lastMessageSentAt sql.NullTime
 	var seenAt sql.NullTime
-	err := row.Scan(
+	dest := []any{
 		&newConv.Id,
 		&newConv.ChatRows,
 		&newConv.DayPass,
@@ -261,7 +378,8 @@ func (*ConvConn) createNewChat(row sqlx.ColScanner) (convEntity.DTO, error) {
 		&seenAt,
 		&newConv.RequestStatus,
 		&newConv.RevealStatus,
-	)
+	}
+	err := row.Scan(append(dest, extraDest...)...)
 	if err != nil {
 		return convEntity.DTO{}, err
 	}
@@ -11,7 +11,9 @@ import (
 	"github.com/xyedo/blindate/pkg/domain/chat"
 	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
 	"github.com/xyedo/blindate/pkg/domain/conversation"
+	convEntity "github.com/xyedo/blindate/pkg/domain/conversation/entities"
 	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
 	"github.com/xyedo/blindate/pkg/infra/repository"
 	"github.com/xyedo/blindate/pkg/util"
 )
@@ -53,22 +55,22 @@ func Test_SelectConversationById(t *testing.T) {
 		for i := 0; i < 4; i++ {
 			if i == 2 {
 				expectedProfilePicCreator = "true.png"
-				_, err := user.CreateProfilePicture(fromUsr.ID, expectedProfilePicCreator, true)
+				_, err := user.CreateProfilePicture(userEntity.ProfilePic{UserId: fromUsr.ID, PictureLink: expectedProfilePicCreator, Selected: true})
 				require.NoError(t, err)
 				continue
 			}
-			_, err := user.CreateProfilePicture(fromUsr.ID, fmt.Sprintf("%d.png", i), false)
+			_, err := user.CreateProfilePicture(userEntity.ProfilePic{UserId: fromUsr.ID, PictureLink: fmt.Sprintf("%d.png", i), Selected: false})
 			require.NoError(t, err)
 		}
 		var expectedProfilePicRecipient string
 		for i := 0; i < 4; i++ {
 			if i == 3 {
 				expectedProfilePicRecipient = fmt.Sprintf("%d.png", i)
-				_, err := user.CreateProfilePicture(toUsr.ID, expectedProfilePicRecipient, false)
+				_, err := user.CreateProfilePicture(userEntity.ProfilePic{UserId: toUsr.ID, PictureLink: expectedProfilePicRecipient, Selected: false})
 				require.NoError(t, err)
 				continue
 			}
-			_, err := user.CreateProfilePicture(toUsr.ID, fmt.Sprintf("%d.png", i), false)
+			_, err := user.CreateProfilePicture(userEntity.ProfilePic{UserId: toUsr.ID, PictureLink: fmt.Sprintf("%d.png", i), Selected: false})
 			require.NoError(t, err)
 		}
 		matchId, err := matchRepo.InsertNewMatch(fromUsr.ID, toUsr.ID, matchEntity.Requested)
@@ -136,22 +138,22 @@ func Test_SelectConvoByUserId(t *testing.T) {
 		for i := 0; i < 4; i++ {
 			if i == 2 {
 				expectedProfilePicCreator = "true.png"
-				_, err := user.CreateProfilePicture(fromUsr.ID, expectedProfilePicCreator, true)
+				_, err := user.CreateProfilePicture(userEntity.ProfilePic{UserId: fromUsr.ID, PictureLink: expectedProfilePicCreator, Selected: true})
 				require.NoError(t, err)
 				continue
 			}
-			_, err := user.CreateProfilePicture(fromUsr.ID, fmt.Sprintf("%d.png", i), false)
+			_, err := user.CreateProfilePicture(userEntity.ProfilePic{UserId: fromUsr.ID, PictureLink: fmt.Sprintf("%d.png", i), Selected: false})
 			require.NoError(t, err)
 		}
 		var expectedProfilePicRecipient string
 		for i := 0; i < 4; i++ {
 			if i == 3 {
 				expectedProfilePicRecipient = fmt.Sprintf("%d.png", i)
-				_, err := user.CreateProfilePicture(toUsr.ID, expectedProfilePicRecipient, false)
+				_, err := user.CreateProfilePicture(userEntity.ProfilePic{UserId: toUsr.ID, PictureLink: expectedProfilePicRecipient, Selected: false})
 				require.NoError(t, err)
 				continue
 			}
-			_, err := user.CreateProfilePicture(toUsr.ID, fmt.Sprintf("%d.png", i), false)
+			_, err := user.CreateProfilePicture(userEntity.ProfilePic{UserId: toUsr.ID, PictureLink: fmt.Sprintf("%d.png", i), Selected: false})
 			require.NoError(t, err)
 		}
 		matchId, err := matchRepo.InsertNewMatch(fromUsr.ID, toUsr.ID, matchEntity.Requested)
@@ -215,11 +217,11 @@ func Test_SelectConvoByUserId(t *testing.T) {
 	})
 	t.Run("valid with full attr and lot match", func(t *testing.T) {
 		fromUsr := createNewAccount(t)
-		_, err := user.CreateProfilePicture(fromUsr.ID, util.RandomUUID()+".png", true)
+		_, err := user.CreateProfilePicture(userEntity.ProfilePic{UserId: fromUsr.ID, PictureLink: util.RandomUUID() + ".png", Selected: true})
 		require.NoError(t, err)
 		for i := 0; i < 30; i++ {
 			toUsr := createNewAccount(t)
-			_, err = user.CreateProfilePicture(toUsr.ID, util.RandomUUID()+".png", false)
+			_, err = user.CreateProfilePicture(userEntity.ProfilePic{UserId: toUsr.ID, PictureLink: util.RandomUUID() + ".png", Selected: false})
 			require.NoError(t, err)
 			matchId, err := matchRepo.InsertNewMatch(fromUsr.ID, toUsr.ID, matchEntity.Requested)
 			require.NoError(t, err)
@@ -241,17 +243,42 @@ func Test_SelectConvoByUserId(t *testing.T) {
 				require.NoError(t, err)
 			}
 		}
-		t.Run("without offset", func(t *testing.T) {
+		t.Run("without filter list everything", func(t *testing.T) {
 			res, err := conv.SelectConversationByUserId(fromUsr.ID, nil)
 			require.NoError(t, err)
-			require.NotEmpty(t, res)
-			require.Len(t, res, 20)
+			require.Len(t, res, 30)
 		})
-		t.Run("with offset", func(t *testing.T) {
-			res, err := conv.SelectConversationByUserId(fromUsr.ID, &conversation.Filter{Offset: 10})
+		t.Run("with cursor", func(t *testing.T) {
+			firstPage, err := conv.SelectConversationByUserId(fromUsr.ID, &conversation.Filter{})
 			require.NoError(t, err)
-			require.NotEmpty(t, res)
-			require.Len(t, res, 20)
+			require.Len(t, firstPage, 20)
+
+			cursor := conversation.CursorOf(firstPage[len(firstPage)-1])
+			secondPage, err := conv.SelectConversationByUserId(fromUsr.ID, &conversation.Filter{Cursor: &cursor})
+			require.NoError(t, err)
+			require.Len(t, secondPage, 10)
+
+			seen := make(map[string]bool)
+			for _, page := range [][]convEntity.DTO{firstPage, secondPage} {
+				for _, c := range page {
+					assert.False(t, seen[c.Id], "conversation listed twice")
+					seen[c.Id] = true
+				}
+			}
+			for i := 1; i < len(firstPage); i++ {
+				assert.False(t, firstPage[i].State.LastActivityAt.After(firstPage[i-1].State.LastActivityAt))
+			}
+		})
+		t.Run("with revealed filter", func(t *testing.T) {
+			revealed := true
+			res, err := conv.SelectConversationByUserId(fromUsr.ID, &conversation.Filter{Revealed: &revealed})
+			require.NoError(t, err)
+			assert.Empty(t, res)
+
+			revealed = false
+			res, err = conv.SelectConversationByUserId(fromUsr.ID, &conversation.Filter{Revealed: &revealed, Limit: 50})
+			require.NoError(t, err)
+			assert.Len(t, res, 30)
 		})
 
 	})
@@ -322,7 +349,7 @@ func Test_DeleteConvoById(t *testing.T) {
 		require.Error(t, err)
 		assert.ErrorIs(t, err, common.ErrResourceNotFound)
 		assert.Empty(t, convs)
-		chats, err := chatRepo.SelectChat(convoId, chat.Filter{
+		chats, err := chatRepo.SelectChat(convoId, fromUsr.ID, chat.Filter{
 			Limit: 10,
 		})
 		require.NoError(t, err)
@@ -342,3 +369,113 @@ func createNewConvo(conv *repository.ConvConn, t *testing.T) string {
 	require.NotEmpty(t, id)
 	return id
 }
+
+func Test_ConversationState(t *testing.T) {
+	convRepo := repository.NewConversation(testQuery)
+	chatRepo := repository.NewChat(testQuery)
+	setup := func(t *testing.T) (convoId, fromUserId, toUserId string) {
+		matchRepo := repository.NewMatch(testQuery)
+		fromUsr := createNewAccount(t)
+		toUsr := createNewAccount(t)
+		matchId, err := matchRepo.InsertNewMatch(fromUsr.ID, toUsr.ID, matchEntity.Requested)
+		require.NoError(t, err)
+		convoId, err = convRepo.InsertConversation(matchId)
+		require.NoError(t, err)
+		return convoId, fromUsr.ID, toUsr.ID
+	}
+	insertChat := func(t *testing.T, convoId, authorId string) string {
+		newChat := &chatEntity.DAO{
+			ConversationId: convoId,
+			Author:         authorId,
+			Messages:       util.RandomString(12),
+			SentAt:         time.Now(),
+		}
+		err := chatRepo.InsertNewChat(newChat)
+		require.NoError(t, err)
+		return newChat.Id
+	}
+	unreadOf := func(t *testing.T, userId string) int {
+		res, err := convRepo.SelectConversationByUserId(userId, nil)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.NotNil(t, res[0].State)
+		return res[0].State.UnreadCount
+	}
+
+	t.Run("unread maintained on insert, seen and delete", func(t *testing.T) {
+		convoId, fromUserId, toUserId := setup(t)
+		insertChat(t, convoId, fromUserId)
+		secondChatId := insertChat(t, convoId, fromUserId)
+		lastChatId := insertChat(t, convoId, fromUserId)
+		assert.Equal(t, 3, unreadOf(t, toUserId))
+		assert.Equal(t, 0, unreadOf(t, fromUserId))
+
+		_, err := chatRepo.UpdateSeenChat(convoId, toUserId, secondChatId, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 1, unreadOf(t, toUserId))
+
+		err = chatRepo.DeleteChatById(lastChatId, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 0, unreadOf(t, toUserId))
+
+		total, err := convRepo.SelectTotalUnread(toUserId)
+		require.NoError(t, err)
+		assert.Equal(t, 0, total)
+	})
+	t.Run("deleted unseen chat is not seen again", func(t *testing.T) {
+		convoId, fromUserId, toUserId := setup(t)
+		deletedChatId := insertChat(t, convoId, fromUserId)
+		insertChat(t, convoId, fromUserId)
+		lastChatId := insertChat(t, convoId, fromUserId)
+		assert.Equal(t, 3, unreadOf(t, toUserId))
+
+		err := chatRepo.DeleteChatById(deletedChatId, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 2, unreadOf(t, toUserId))
+
+		insertChat(t, convoId, fromUserId)
+		seenIds, err := chatRepo.UpdateSeenChat(convoId, toUserId, lastChatId, time.Now())
+		require.NoError(t, err)
+		assert.Len(t, seenIds, 2)
+		assert.NotContains(t, seenIds, deletedChatId)
+		assert.Equal(t, 1, unreadOf(t, toUserId), "the chat sent after the watermark is still unread")
+	})
+	t.Run("update state", func(t *testing.T) {
+		convoId, fromUserId, toUserId := setup(t)
+		insertChat(t, convoId, fromUserId)
+
+		total, err := convRepo.SelectTotalUnread(toUserId)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+
+		mutedUntil := time.Now().Add(time.Hour)
+		state, err := convRepo.UpdateState(convoId, toUserId, convEntity.State{
+			MutedUntil: &mutedUntil,
+			Pinned:     true,
+			Archived:   true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, state.UnreadCount)
+		require.NotNil(t, state.MutedUntil)
+		assert.True(t, state.Pinned)
+		assert.True(t, state.Archived)
+
+		total, err = convRepo.SelectTotalUnread(toUserId)
+		require.NoError(t, err)
+		assert.Equal(t, 0, total)
+
+		inbox, err := convRepo.SelectConversationByUserId(toUserId, &conversation.Filter{})
+		require.NoError(t, err)
+		assert.Empty(t, inbox)
+		archived, err := convRepo.SelectConversationByUserId(toUserId, &conversation.Filter{Archived: true, UnreadOnly: true})
+		require.NoError(t, err)
+		require.Len(t, archived, 1)
+		assert.Equal(t, convoId, archived[0].Id)
+	})
+	t.Run("not a member", func(t *testing.T) {
+		convoId, _, _ := setup(t)
+		_, err := convRepo.UpdateState(convoId, util.RandomUUID(), convEntity.State{})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+}
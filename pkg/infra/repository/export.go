@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/xyedo/blindate/pkg/common"
+	exportEntity "github.com/xyedo/blindate/pkg/domain/export/entities"
+)
+
+func NewExport(conn *sqlx.DB) *ExportConn {
+	return &ExportConn{
+		conn: conn,
+	}
+}
+
+type ExportConn struct {
+	conn *sqlx.DB
+}
+
+var exportJobColumns = `
+		id,
+		user_id,
+		status,
+		blob_key,
+		size_bytes,
+		error,
+		created_at,
+		started_at,
+		completed_at,
+		expires_at`
+
+// InsertJob queue a pending export, only one export could be in progress per user
+func (e *ExportConn) InsertJob(job *exportEntity.Job) error {
+	query := `
+	INSERT INTO data_exports(user_id)
+	VALUES($1)
+	RETURNING` + exportJobColumns
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := e.conn.GetContext(ctx, job, query, job.UserId)
+	if err != nil {
+		return e.wrapError(err)
+	}
+	return nil
+}
+
+func (e *ExportConn) SelectJobById(exportId string) (exportEntity.Job, error) {
+	query := `
+	SELECT` + exportJobColumns + `
+	FROM data_exports
+	WHERE id = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var job exportEntity.Job
+	err := e.conn.GetContext(ctx, &job, query, exportId)
+	if err != nil {
+		return exportEntity.Job{}, e.wrapError(err)
+	}
+	return job, nil
+}
+
+// SelectJobsByUserId list every export of the user, the latest first
+func (e *ExportConn) SelectJobsByUserId(userId string) ([]exportEntity.Job, error) {
+	query := `
+	SELECT` + exportJobColumns + `
+	FROM data_exports
+	WHERE user_id = $1
+	ORDER BY created_at DESC, id`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	jobs := make([]exportEntity.Job, 0)
+	err := e.conn.SelectContext(ctx, &jobs, query, userId)
+	if err != nil {
+		return nil, e.wrapError(err)
+	}
+	return jobs, nil
+}
+
+// ClaimJob skip the job claimed by another exporter, the empty queue is not found
+func (e *ExportConn) ClaimJob(startedAt, staleBefore time.Time) (exportEntity.Job, error) {
+	query := `
+	UPDATE data_exports SET
+		status = 'processing',
+		started_at = $1
+	WHERE id = (
+		SELECT
+			id
+		FROM data_exports
+		WHERE status = 'pending'
+			OR (status = 'processing' AND started_at < $2)
+		ORDER BY created_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING` + exportJobColumns
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var job exportEntity.Job
+	err := e.conn.GetContext(ctx, &job, query, startedAt, staleBefore)
+	if err != nil {
+		return exportEntity.Job{}, e.wrapError(err)
+	}
+	return job, nil
+}
+
+func (e *ExportConn) UpdateJob(job exportEntity.Job) error {
+	query := `
+	UPDATE data_exports SET
+		status = $2,
+		blob_key = $3,
+		size_bytes = $4,
+		error = $5,
+		completed_at = $6,
+		expires_at = $7
+	WHERE id = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := e.conn.ExecContext(ctx, query, job.Id, job.Status, job.BlobKey, job.SizeBytes, job.Error, job.CompletedAt, job.ExpiresAt)
+	if err != nil {
+		return e.wrapError(err)
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if row == 0 {
+		return common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "export not found")
+	}
+	return nil
+}
+
+// SelectExpiredJobs list the ready export which archive has to be deleted
+func (e *ExportConn) SelectExpiredJobs(now time.Time, limit int) ([]exportEntity.Job, error) {
+	query := `
+	SELECT` + exportJobColumns + `
+	FROM data_exports
+	WHERE status = 'ready' AND expires_at < $1
+	ORDER BY expires_at
+	LIMIT $2`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	jobs := make([]exportEntity.Job, 0)
+	err := e.conn.SelectContext(ctx, &jobs, query, now, limit)
+	if err != nil {
+		return nil, e.wrapError(err)
+	}
+	return jobs, nil
+}
+
+// SelectMatches list every match the user is part of, the data query is given longer as it is unbounded
+func (e *ExportConn) SelectMatches(userId string) ([]exportEntity.Match, error) {
+	query := `
+	SELECT
+		id,
+		request_from,
+		request_to,
+		request_status,
+		created_at,
+		accepted_at,
+		reveal_status,
+		revealed_at
+	FROM match
+	WHERE request_from = $1 OR request_to = $1
+	ORDER BY created_at, id`
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	matches := make([]exportEntity.Match, 0)
+	err := e.conn.SelectContext(ctx, &matches, query, userId)
+	if err != nil {
+		return nil, e.wrapError(err)
+	}
+	return matches, nil
+}
+
+func (e *ExportConn) SelectConversations(userId string) ([]exportEntity.Conversation, error) {
+	query := `
+	SELECT
+		conv.match_id AS id,
+		CASE WHEN m.request_from = $1 THEN m.request_to ELSE m.request_from END AS partner_id,
+		conv.chat_rows,
+		conv.day_pass,
+		COALESCE(cs.pinned, FALSE) AS pinned,
+		COALESCE(cs.archived, FALSE) AS archived,
+		cs.muted_until,
+		cs.last_activity_at
+	FROM conversations conv
+	JOIN match m
+		ON m.id = conv.match_id
+	LEFT JOIN conversation_states cs
+		ON cs.conversation_id = conv.match_id AND cs.user_id = $1
+	WHERE m.request_from = $1 OR m.request_to = $1
+	ORDER BY cs.last_activity_at DESC NULLS LAST, conv.match_id`
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	convs := make([]exportEntity.Conversation, 0)
+	err := e.conn.SelectContext(ctx, &convs, query, userId)
+	if err != nil {
+		return nil, e.wrapError(err)
+	}
+	return convs, nil
+}
+
+// SelectAuthoredChats list every chat authored by the user including the deleted one, the oldest first
+func (e *ExportConn) SelectAuthoredChats(userId string) ([]exportEntity.Chat, error) {
+	query := `
+	SELECT
+		c.id,
+		c.conversation_id,
+		c.messages,
+		c.reply_to,
+		c.sticker_id,
+		c.sent_at,
+		c.delivered_at,
+		c.seen_at,
+		c.edited_at,
+		c.deleted_at,
+		media.media_type,
+		media.blob_link
+	FROM chats c
+	LEFT JOIN media
+		ON media.chat_id = c.id
+	WHERE c.author = $1
+	ORDER BY c.sent_at, c.id`
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	chats := make([]exportEntity.Chat, 0)
+	err := e.conn.SelectContext(ctx, &chats, query, userId)
+	if err != nil {
+		return nil, e.wrapError(err)
+	}
+	return chats, nil
+}
+
+func (*ExportConn) wrapError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return common.WrapError(err, common.ErrTooLongAccessingDB)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return common.WrapErrorWithMsg(err, common.ErrResourceNotFound, "export not found")
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if pqErr.Code == "23503" {
+			return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "user not found")
+		}
+		if pqErr.Code == "23505" {
+			return common.WrapWithNewError(err, http.StatusConflict, "an export is already in progress")
+		}
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/common"
+	exportEntity "github.com/xyedo/blindate/pkg/domain/export/entities"
+	"github.com/xyedo/blindate/pkg/infra/repository"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_ExportJob(t *testing.T) {
+	exportRepo := repository.NewExport(testQuery)
+	user := createNewAccount(t)
+
+	job := exportEntity.Job{UserId: user.ID}
+	err := exportRepo.InsertJob(&job)
+	require.NoError(t, err)
+	assert.NotEmpty(t, job.Id)
+	assert.Equal(t, exportEntity.StatusPending, job.Status)
+
+	t.Run("only one export in progress", func(t *testing.T) {
+		err := exportRepo.InsertJob(&exportEntity.Job{UserId: user.ID})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already in progress")
+	})
+	t.Run("unknown user", func(t *testing.T) {
+		err := exportRepo.InsertJob(&exportEntity.Job{UserId: util.RandomUUID()})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrRefNotFound23503)
+	})
+	t.Run("claim then complete", func(t *testing.T) {
+		// the other test may queue their own job, so claim until ours
+		var claimed exportEntity.Job
+		for claimed.Id != job.Id {
+			claimed, err = exportRepo.ClaimJob(time.Now(), time.Now().Add(-time.Hour))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, exportEntity.StatusProcessing, claimed.Status)
+		assert.NotNil(t, claimed.StartedAt)
+
+		key := "data-export/" + util.RandomUUID() + ".zip"
+		size := int64(42)
+		completedAt := time.Now()
+		expiresAt := completedAt.Add(-time.Minute)
+		claimed.Status = exportEntity.StatusReady
+		claimed.BlobKey = &key
+		claimed.SizeBytes = &size
+		claimed.CompletedAt = &completedAt
+		claimed.ExpiresAt = &expiresAt
+		err := exportRepo.UpdateJob(claimed)
+		require.NoError(t, err)
+
+		got, err := exportRepo.SelectJobById(job.Id)
+		require.NoError(t, err)
+		assert.Equal(t, exportEntity.StatusReady, got.Status)
+		require.NotNil(t, got.BlobKey)
+		assert.Equal(t, key, *got.BlobKey)
+
+		expired, err := exportRepo.SelectExpiredJobs(time.Now(), 1000)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(expired))
+		for _, e := range expired {
+			ids = append(ids, e.Id)
+		}
+		assert.Contains(t, ids, job.Id)
+	})
+	t.Run("a new export once completed", func(t *testing.T) {
+		err := exportRepo.InsertJob(&exportEntity.Job{UserId: user.ID})
+		require.NoError(t, err)
+		jobs, err := exportRepo.SelectJobsByUserId(user.ID)
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		assert.Equal(t, job.Id, jobs[1].Id)
+	})
+	t.Run("unknown export", func(t *testing.T) {
+		_, err := exportRepo.SelectJobById(util.RandomUUID())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+}
+
+func Test_ExportData(t *testing.T) {
+	chatRepo := repository.NewChat(testQuery)
+	exportRepo := repository.NewExport(testQuery)
+	chatId, convoId := createNewChat(chatRepo, t)
+	match, err := repository.NewMatch(testQuery).GetMatchById(convoId)
+	require.NoError(t, err)
+
+	matches, err := exportRepo.SelectMatches(match.RequestFrom)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, convoId, matches[0].Id)
+
+	convs, err := exportRepo.SelectConversations(match.RequestTo)
+	require.NoError(t, err)
+	require.Len(t, convs, 1)
+	assert.Equal(t, match.RequestFrom, convs[0].PartnerId)
+
+	chats, err := exportRepo.SelectAuthoredChats(match.RequestFrom)
+	require.NoError(t, err)
+	require.Len(t, chats, 1)
+	assert.Equal(t, chatId, chats[0].Id)
+
+	chats, err = exportRepo.SelectAuthoredChats(match.RequestTo)
+	require.NoError(t, err)
+	assert.Empty(t, chats, "the partner chat is not exported")
+}
@@ -183,7 +183,7 @@ func (i *IntrConn) InsertInterestHobbies(interestId string, hobbies []interestEn
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := i.execTx(ctx, func(q *sqlx.DB) error {
+	err := i.execTx(ctx, func(q queryer) error {
 		var retIds []string
 		err := q.SelectContext(ctx, &retIds, query, args...)
 		if err != nil {
@@ -241,7 +241,7 @@ func (i *IntrConn) UpdateInterestHobbies(interestId string, hobbies []interestEn
 			WHERE up.id = new_values.id)`, stmnt)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	err := i.execTx(ctx, func(q *sqlx.DB) error {
+	err := i.execTx(ctx, func(q queryer) error {
 		res, err := q.ExecContext(ctx, query, args...)
 		if err != nil {
 			return err
@@ -282,7 +282,7 @@ func (i *IntrConn) DeleteInterestHobbies(interestId string, ids []string) ([]str
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	var retIds []string
-	err := i.execTx(ctx, func(q *sqlx.DB) error {
+	err := i.execTx(ctx, func(q queryer) error {
 
 		err := q.SelectContext(ctx, &retIds, query, args...)
 		if err != nil {
@@ -336,7 +336,7 @@ func (i *IntrConn) InsertInterestMovieSeries(interestId string, movieSeries []in
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := i.execTx(ctx, func(q *sqlx.DB) error {
+	err := i.execTx(ctx, func(q queryer) error {
 		var retIds []string
 		err := q.SelectContext(ctx, &retIds, query, args...)
 		if err != nil {
@@ -394,7 +394,7 @@ func (i *IntrConn) UpdateInterestMovieSeries(interestId string, movieSeries []in
 			WHERE up.id = new_values.id)`, stmnt)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	err := i.execTx(ctx, func(q *sqlx.DB) error {
+	err := i.execTx(ctx, func(q queryer) error {
 		res, err := q.ExecContext(ctx, query, args...)
 		if err != nil {
 			return err
@@ -432,7 +432,7 @@ func (i *IntrConn) DeleteInterestMovieSeries(interestId string, ids []string) ([
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	var retIds []string
-	err := i.execTx(ctx, func(q *sqlx.DB) error {
+	err := i.execTx(ctx, func(q queryer) error {
 		err := q.SelectContext(ctx, &retIds, query, args...)
 		if err != nil {
 			return err
@@ -483,7 +483,7 @@ func (i *IntrConn) InsertInterestTraveling(interestId string, travels []interest
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	err := i.execTx(ctx, func(q *sqlx.DB) error {
+	err := i.execTx(ctx, func(q queryer) error {
 		var retIds []string
 		err := q.SelectContext(ctx, &retIds, query, args...)
 		if err != nil {
@@ -541,7 +541,7 @@ func (i *IntrConn) UpdateInterestTraveling(interestId string, travels []interest
 			WHERE up.id = new_values.id)`, stmnt)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	err := i.execTx(ctx, func(q *sqlx.DB) error {
+	err := i.execTx(ctx, func(q queryer) error {
 		res, err := q.ExecContext(ctx, query, args...)
 		if err != nil {
 			return err
@@ -582,7 +582,7 @@ func (i *IntrConn) DeleteInterestTraveling(interestId string, ids []string) ([]s
 	defer cancel()
 
 	var retIds []string
-	err := i.execTx(ctx, func(q *sqlx.DB) error {
+	err := i.execTx(ctx, func(q queryer) error {
 		err := q.SelectContext(ctx, &retIds, query, args...)
 		if err != nil {
 			return err
@@ -635,7 +635,7 @@ func (i *IntrConn) InsertInterestSports(interestId string, sports []interestEnti
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := i.execTx(ctx, func(q *sqlx.DB) error {
+	err := i.execTx(ctx, func(q queryer) error {
 		var retIds []string
 		err := q.SelectContext(ctx, &retIds, query, args...)
 		if err != nil {
@@ -694,7 +694,7 @@ func (i *IntrConn) UpdateInterestSport(interestId string, sports []interestEntit
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := i.execTx(ctx, func(q *sqlx.DB) error {
+	err := i.execTx(ctx, func(q queryer) error {
 		res, err := q.ExecContext(ctx, query, args...)
 		if err != nil {
 			return err
@@ -736,7 +736,7 @@ func (i *IntrConn) DeleteInterestSports(interestId string, ids []string) ([]stri
 	defer cancel()
 
 	var retIds []string
-	err := i.execTx(ctx, func(q *sqlx.DB) error {
+	err := i.execTx(ctx, func(q queryer) error {
 		err := q.SelectContext(ctx, &retIds, query, args...)
 		if err != nil {
 			return err
@@ -809,6 +809,6 @@ func (*IntrConn) parsingError(err error) error {
 	}
 	return err
 }
-func (i *IntrConn) execTx(ctx context.Context, q func(q *sqlx.DB) error) error {
+func (i *IntrConn) execTx(ctx context.Context, q func(q queryer) error) error {
 	return execGeneric(i.conn, ctx, q, &sql.TxOptions{Isolation: sql.LevelReadCommitted, ReadOnly: false})
 }
@@ -169,6 +169,14 @@ func (l *LocConn) GetClosestUser(userId, geom string, limit int) ([]matchEntity.
 				m.request_to = u.id OR
 				m.request_from = u.id
 		) AND u.id != $3
+			AND u.snoozed_at IS NULL
+			AND u.deletion_requested_at IS NULL
+			AND NOT ` + blockedBetween("u.id", "$3") + `
+			AND NOT EXISTS (
+				SELECT 1
+				FROM suspensions s
+				WHERE s.user_id = u.id AND s.lifted_at IS NULL AND (s.ends_at IS NULL OR s.ends_at > NOW())
+			)
 		ORDER BY l.geog <-> ST_GeomFromText($1)
 		LIMIT $2`
 
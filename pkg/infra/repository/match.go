@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"strings"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/xyedo/blindate/pkg/common"
 	basicInfoEntity "github.com/xyedo/blindate/pkg/domain/basicinfo/entities"
 	interestEntity "github.com/xyedo/blindate/pkg/domain/interest/entities"
+	"github.com/xyedo/blindate/pkg/domain/match"
 	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
 )
 
@@ -40,7 +43,22 @@ func (m *MatchConn) InsertNewMatch(fromUserId, toUserId string, reqStatus matchE
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	var matchId string
-	err := m.conn.GetContext(ctx, &matchId, query, args...)
+	err := m.execTx(ctx, func(q queryer) error {
+		err := q.GetContext(ctx, &matchId, query, args...)
+		if err != nil {
+			return err
+		}
+		if reqStatus == matchEntity.Unknown {
+			return nil
+		}
+		return insertMatchEvent(ctx, q, &matchEntity.Event{
+			MatchId:    matchId,
+			ActorId:    &fromUserId,
+			Kind:       matchEntity.KindRequest,
+			FromStatus: matchEntity.Unknown,
+			ToStatus:   reqStatus,
+		})
+	})
 	if err != nil {
 		var pqErr *pq.Error
 		switch {
@@ -59,6 +77,7 @@ func (m *MatchConn) InsertNewMatch(fromUserId, toUserId string, reqStatus matchE
 				case strings.Contains(pqErr.Constraint, "reveal_status"):
 					return "", common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "invalid enums on revealStatus")
 				}
+				return "", pqErr
 			case "23505":
 				return "", common.WrapErrorWithMsg(err, common.ErrUniqueConstraint23505, "match already created")
 			default:
@@ -71,10 +90,17 @@ func (m *MatchConn) InsertNewMatch(fromUserId, toUserId string, reqStatus matchE
 	return matchId, nil
 }
 
-func (m *MatchConn) SelectMatchReqToUserId(userId string) ([]matchEntity.FullUserDTO, error) {
+func (m *MatchConn) SelectMatchesByUserId(userId string, filter match.Filter) ([]matchEntity.FullUserDTO, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 20
+	}
 	query := `
 	SELECT 
 		m.id as match_id,
+		m.request_status,
+		m.request_from = $1 as sent,
+		m.created_at,
+		m.accepted_at,
 		u.id as user_id,
 		u.alias,
 		u.dob, 
@@ -112,21 +138,42 @@ func (m *MatchConn) SelectMatchReqToUserId(userId string) ([]matchEntity.FullUse
 			WHERE i.id IS NOT NULL AND interest_id = i.id
 		) as interest_sport
 	FROM match m
-	RIGHT JOIN users u
-		ON u.id = m.request_from
+	JOIN users u
+		ON u.id = CASE WHEN m.request_from = $1 THEN m.request_to ELSE m.request_from END
 	LEFT JOIN basic_info b
 		ON u.id = b.user_id
 	LEFT JOIN interests i
-		ON i.user_id = u.id
-	WHERE m.request_to = $1
-		AND m.request_status = 'requested'
-	ORDER BY m.created_at ASC
-	LIMIT 20`
+		ON i.user_id = u.id`
+	args := []any{userId}
+	switch filter.Box {
+	case match.BoxSent:
+		query += `
+	WHERE m.request_from = $1 AND m.request_status = 'requested'`
+	case match.BoxAccepted:
+		query += `
+	WHERE (m.request_from = $1 OR m.request_to = $1) AND m.request_status = 'accepted'`
+	case match.BoxDeclined:
+		query += `
+	WHERE (m.request_from = $1 OR m.request_to = $1) AND m.request_status = 'declined'`
+	default:
+		query += `
+	WHERE m.request_to = $1 AND m.request_status = 'requested'`
+	}
+	query += `
+		AND NOT ` + blockedBetween("m.request_from", "m.request_to")
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.At, filter.Cursor.Id)
+		query += fmt.Sprintf(` AND (m.created_at, m.id) > ($%d::TIMESTAMPTZ, $%d)`, len(args)-1, len(args))
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(`
+	ORDER BY m.created_at ASC, m.id ASC
+	LIMIT $%d`, len(args))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	rows, err := m.conn.QueryxContext(ctx, query, userId)
+	rows, err := m.conn.QueryxContext(ctx, query, args...)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
@@ -167,7 +214,11 @@ func (m *MatchConn) GetMatchById(matchId string) (matchEntity.MatchDAO, error) {
 			created_at,
 			accepted_at,
 			reveal_status,
-			revealed_at
+			revealed_at,
+			reveal_requested_by,
+			reveal_requested_at,
+			reveal_expires_at,
+			` + blockedBetween("request_from", "request_to") + ` AS blocked
 		FROM match
 		WHERE id = $1`
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -191,14 +242,20 @@ func (m *MatchConn) UpdateMatchById(matchDAO matchEntity.MatchDAO) error {
 		request_status=$1, 
 		accepted_at=$2, 
 		reveal_status=$3, 
-		revealed_at=$4
-	WHERE id = $5
+		revealed_at=$4,
+		reveal_requested_by=$5,
+		reveal_requested_at=$6,
+		reveal_expires_at=$7
+	WHERE id = $8
 	RETURNING id`
 	args := []any{
 		matchDAO.RequestStatus,
 		matchDAO.AcceptedAt,
 		matchDAO.RevealStatus,
 		matchDAO.RevealedAt,
+		matchDAO.RevealRequestedBy,
+		matchDAO.RevealRequestedAt,
+		matchDAO.RevealExpiresAt,
 		matchDAO.Id,
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -227,6 +284,153 @@ func (m *MatchConn) UpdateMatchById(matchDAO matchEntity.MatchDAO) error {
 	}
 	return nil
 }
+func (m *MatchConn) TransitionMatch(matchDAO matchEntity.MatchDAO, event *matchEntity.Event) error {
+	statusColumn := "request_status"
+	if event.Kind == matchEntity.KindReveal {
+		statusColumn = "reveal_status"
+	}
+	query := `
+	UPDATE match SET
+		request_status=$1,
+		accepted_at=$2,
+		reveal_status=$3,
+		revealed_at=$4,
+		reveal_requested_by=$5,
+		reveal_requested_at=$6,
+		reveal_expires_at=$7
+	WHERE id = $8 AND ` + statusColumn + ` = $9
+	RETURNING id`
+	args := []any{
+		matchDAO.RequestStatus,
+		matchDAO.AcceptedAt,
+		matchDAO.RevealStatus,
+		matchDAO.RevealedAt,
+		matchDAO.RevealRequestedBy,
+		matchDAO.RevealRequestedAt,
+		matchDAO.RevealExpiresAt,
+		matchDAO.Id,
+		string(event.FromStatus),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := m.execTx(ctx, func(q queryer) error {
+		err := q.GetContext(ctx, &matchDAO.Id, query, args...)
+		if err != nil {
+			return err
+		}
+		event.MatchId = matchDAO.Id
+		return insertMatchEvent(ctx, q, event)
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return common.WrapWithNewError(err, http.StatusConflict, "match has been changed, please try again")
+		case errors.Is(err, context.Canceled):
+			return common.WrapError(err, common.ErrTooLongAccessingDB)
+		case errors.As(err, &pqErr) && pqErr.Code == "23503":
+			switch {
+			case strings.Contains(pqErr.Constraint, "actor_id"):
+				return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "invalid user on actor")
+			case strings.Contains(pqErr.Constraint, "status"):
+				return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "invalid enums on match status")
+			}
+			return pqErr
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MatchConn) SelectEventsByMatchId(matchId string) ([]matchEntity.Event, error) {
+	query := `
+	SELECT
+		id,
+		match_id,
+		actor_id,
+		kind,
+		from_status,
+		to_status,
+		created_at
+	FROM match_events
+	WHERE match_id = $1
+	ORDER BY created_at ASC, id ASC`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := make([]matchEntity.Event, 0)
+	err := m.conn.SelectContext(ctx, &events, query, matchId)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	return events, nil
+}
+
+func (m *MatchConn) SelectExpiredReveals(now time.Time, limit int) ([]matchEntity.MatchDAO, error) {
+	query := `
+	SELECT
+		id,
+		request_from,
+		request_to,
+		request_status,
+		created_at,
+		accepted_at,
+		reveal_status,
+		revealed_at,
+		reveal_requested_by,
+		reveal_requested_at,
+		reveal_expires_at,
+		` + blockedBetween("request_from", "request_to") + ` AS blocked
+	FROM match
+	WHERE reveal_status = 'requested' AND reveal_expires_at <= $1
+	ORDER BY reveal_expires_at ASC
+	LIMIT $2`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	matchs := make([]matchEntity.MatchDAO, 0)
+	err := m.conn.SelectContext(ctx, &matchs, query, now, limit)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	return matchs, nil
+}
+
+func (m *MatchConn) execTx(ctx context.Context, q func(q queryer) error) error {
+	return execGeneric(m.conn, ctx, q, &sql.TxOptions{Isolation: sql.LevelReadCommitted, ReadOnly: false})
+}
+
+func insertMatchEvent(ctx context.Context, q queryer, event *matchEntity.Event) error {
+	query := `
+	INSERT INTO match_events(
+		match_id,
+		actor_id,
+		kind,
+		from_status,
+		to_status,
+		created_at
+		)
+	VALUES($1,$2,$3,$4,$5,$6)
+	RETURNING id`
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	args := []any{
+		event.MatchId,
+		event.ActorId,
+		string(event.Kind),
+		string(event.FromStatus),
+		string(event.ToStatus),
+		event.CreatedAt,
+	}
+	return q.GetContext(ctx, &event.Id, query, args...)
+}
+
 func (*MatchConn) createCandidatematch(row sqlx.ColScanner) (matchEntity.FullUserDTO, error) {
 	var newMatch matchEntity.FullUserDTO
 	var newBasicInfo basicInfoEntity.DAO
@@ -238,8 +442,13 @@ func (*MatchConn) createCandidatematch(row sqlx.ColScanner) (matchEntity.FullUse
 	var movieSeries pq.StringArray
 	var travels pq.StringArray
 	var sports pq.StringArray
+	var acceptedAt sql.NullTime
 	err := row.Scan(
 		&newMatch.MatchId,
+		&newMatch.RequestStatus,
+		&newMatch.Sent,
+		&newMatch.CreatedAt,
+		&acceptedAt,
 		&newMatch.UserId,
 		&newMatch.Alias,
 		&newMatch.Dob,
@@ -264,6 +473,9 @@ func (*MatchConn) createCandidatematch(row sqlx.ColScanner) (matchEntity.FullUse
 	if err != nil {
 		return matchEntity.FullUserDTO{}, err
 	}
+	if acceptedAt.Valid {
+		newMatch.AcceptedAt = &acceptedAt.Time
+	}
 	if newBasicInfoGender.Valid {
 		newMatch.Gender = &newBasicInfoGender.String
 	}
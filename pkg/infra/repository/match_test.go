@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/xyedo/blindate/pkg/common"
+	"github.com/xyedo/blindate/pkg/domain/match"
 	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
 	"github.com/xyedo/blindate/pkg/infra/repository"
 	"github.com/xyedo/blindate/pkg/util"
@@ -66,7 +67,7 @@ func Test_InsertNewMatch(t *testing.T) {
 	})
 }
 
-func Test_SelectMatchReqToUserId(t *testing.T) {
+func Test_SelectMatchesByUserId(t *testing.T) {
 	matchRepo := repository.NewMatch(testQuery)
 	t.Run("valid match", func(t *testing.T) {
 		toUser := createNewAccount(t)
@@ -95,22 +96,53 @@ func Test_SelectMatchReqToUserId(t *testing.T) {
 			}
 		}
 
-		matchs, err := matchRepo.SelectMatchReqToUserId(toUser.ID)
+		matchs, err := matchRepo.SelectMatchesByUserId(toUser.ID, match.Filter{Box: match.BoxReceived})
 		require.NoError(t, err)
-		require.NotEmpty(t, matchs)
+		require.Len(t, matchs, 5)
 		assert.Equal(t, ExpectedfirstFirstUserId, matchs[0].UserId)
+		assert.False(t, matchs[0].Sent)
+		assert.Equal(t, matchEntity.Requested, matchs[0].RequestStatus)
 		jsonCandidate, err := json.MarshalIndent(matchs, "", " ")
 		require.NoError(t, err)
 		log.Println(string(jsonCandidate))
+
+		cursor := match.CursorOf(matchs[1])
+		page, err := matchRepo.SelectMatchesByUserId(toUser.ID, match.Filter{Box: match.BoxReceived, Cursor: &cursor, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, matchs[2].MatchId, page[0].MatchId)
+		assert.Equal(t, matchs[3].MatchId, page[1].MatchId)
+
+		sent, err := matchRepo.SelectMatchesByUserId(ExpectedfirstFirstUserId, match.Filter{Box: match.BoxSent})
+		require.NoError(t, err)
+		require.Len(t, sent, 1)
+		assert.Equal(t, toUser.ID, sent[0].UserId)
+		assert.True(t, sent[0].Sent)
+	})
+	t.Run("accepted by either participant", func(t *testing.T) {
+		fromUsr := createNewAccount(t)
+		toUsr := createNewAccount(t)
+		matchId, err := matchRepo.InsertNewMatch(fromUsr.ID, toUsr.ID, matchEntity.Accepted)
+		require.NoError(t, err)
+		for _, userId := range []string{fromUsr.ID, toUsr.ID} {
+			matchs, err := matchRepo.SelectMatchesByUserId(userId, match.Filter{Box: match.BoxAccepted})
+			require.NoError(t, err)
+			require.Len(t, matchs, 1)
+			assert.Equal(t, matchId, matchs[0].MatchId)
+			assert.NotEqual(t, userId, matchs[0].UserId)
+		}
+		matchs, err := matchRepo.SelectMatchesByUserId(toUsr.ID, match.Filter{Box: match.BoxDeclined})
+		require.NoError(t, err)
+		assert.Empty(t, matchs)
 	})
 	t.Run("zero matchs with valid user", func(t *testing.T) {
 		user := createNewAccount(t)
-		convs, err := matchRepo.SelectMatchReqToUserId(user.ID)
+		convs, err := matchRepo.SelectMatchesByUserId(user.ID, match.Filter{Box: match.BoxReceived})
 		require.NoError(t, err)
 		assert.Empty(t, convs)
 	})
 	t.Run("zero matchs with invalid user", func(t *testing.T) {
-		convs, err := matchRepo.SelectMatchReqToUserId(util.RandomUUID())
+		convs, err := matchRepo.SelectMatchesByUserId(util.RandomUUID(), match.Filter{Box: match.BoxReceived})
 		require.NoError(t, err)
 		assert.Empty(t, convs)
 	})
@@ -182,6 +214,119 @@ func Test_UpdateMatchById(t *testing.T) {
 	})
 
 }
+func Test_TransitionMatch(t *testing.T) {
+	matchRepo := repository.NewMatch(testQuery)
+	fromUsr := createNewAccount(t)
+	toUsr := createNewAccount(t)
+	matchId, err := matchRepo.InsertNewMatch(fromUsr.ID, toUsr.ID, matchEntity.Requested)
+	require.NoError(t, err)
+
+	t.Run("the first event is recorded", func(t *testing.T) {
+		events, err := matchRepo.SelectEventsByMatchId(matchId)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.NotNil(t, events[0].ActorId)
+		assert.Equal(t, fromUsr.ID, *events[0].ActorId)
+		assert.Equal(t, matchEntity.KindRequest, events[0].Kind)
+		assert.Equal(t, matchEntity.Unknown, events[0].FromStatus)
+		assert.Equal(t, matchEntity.Requested, events[0].ToStatus)
+	})
+	t.Run("valid transition", func(t *testing.T) {
+		matchDAO, err := matchRepo.GetMatchById(matchId)
+		require.NoError(t, err)
+		matchDAO.RequestStatus = string(matchEntity.Accepted)
+		matchDAO.AcceptedAt = sql.NullTime{Valid: true, Time: time.Now()}
+		event := matchEntity.Event{
+			ActorId:    &toUsr.ID,
+			Kind:       matchEntity.KindRequest,
+			FromStatus: matchEntity.Requested,
+			ToStatus:   matchEntity.Accepted,
+		}
+		err = matchRepo.TransitionMatch(matchDAO, &event)
+		require.NoError(t, err)
+		assert.NotEmpty(t, event.Id)
+
+		events, err := matchRepo.SelectEventsByMatchId(matchId)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, event.Id, events[1].Id)
+	})
+	t.Run("stale status is a conflict", func(t *testing.T) {
+		matchDAO, err := matchRepo.GetMatchById(matchId)
+		require.NoError(t, err)
+		matchDAO.RequestStatus = string(matchEntity.Withdrawn)
+		err = matchRepo.TransitionMatch(matchDAO, &matchEntity.Event{
+			ActorId:    &fromUsr.ID,
+			Kind:       matchEntity.KindRequest,
+			FromStatus: matchEntity.Requested,
+			ToStatus:   matchEntity.Withdrawn,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no rows")
+
+		events, err := matchRepo.SelectEventsByMatchId(matchId)
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+	t.Run("unknown match has no event", func(t *testing.T) {
+		events, err := matchRepo.SelectEventsByMatchId(util.RandomUUID())
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+}
+func Test_SelectExpiredReveals(t *testing.T) {
+	matchRepo := repository.NewMatch(testQuery)
+	fromUsr := createNewAccount(t)
+	toUsr := createNewAccount(t)
+	matchId, err := matchRepo.InsertNewMatch(fromUsr.ID, toUsr.ID, matchEntity.Accepted)
+	require.NoError(t, err)
+	matchDAO, err := matchRepo.GetMatchById(matchId)
+	require.NoError(t, err)
+
+	now := time.Now()
+	matchDAO.RevealStatus = string(matchEntity.Requested)
+	matchDAO.RevealRequestedBy = sql.NullString{String: fromUsr.ID, Valid: true}
+	matchDAO.RevealRequestedAt = sql.NullTime{Time: now.Add(-2 * time.Hour), Valid: true}
+	matchDAO.RevealExpiresAt = sql.NullTime{Time: now.Add(-time.Hour), Valid: true}
+	err = matchRepo.TransitionMatch(matchDAO, &matchEntity.Event{
+		ActorId:    &fromUsr.ID,
+		Kind:       matchEntity.KindReveal,
+		FromStatus: matchEntity.Unknown,
+		ToStatus:   matchEntity.Requested,
+	})
+	require.NoError(t, err)
+
+	found, err := matchRepo.GetMatchById(matchId)
+	require.NoError(t, err)
+	assert.Equal(t, fromUsr.ID, found.RevealRequestedBy.String)
+	assert.True(t, found.RevealExpiresAt.Valid)
+
+	ids := func(matchs []matchEntity.MatchDAO) []string {
+		ids := make([]string, 0, len(matchs))
+		for _, m := range matchs {
+			ids = append(ids, m.Id)
+		}
+		return ids
+	}
+	expired, err := matchRepo.SelectExpiredReveals(now, 1000)
+	require.NoError(t, err)
+	assert.Contains(t, ids(expired), matchId)
+
+	expired, err = matchRepo.SelectExpiredReveals(now.Add(-2*time.Hour), 1000)
+	require.NoError(t, err)
+	assert.NotContains(t, ids(expired), matchId, "not expired yet")
+
+	found.RevealStatus = string(matchEntity.Expired)
+	err = matchRepo.TransitionMatch(found, &matchEntity.Event{
+		Kind:       matchEntity.KindReveal,
+		FromStatus: matchEntity.Requested,
+		ToStatus:   matchEntity.Expired,
+	})
+	require.NoError(t, err)
+	expired, err = matchRepo.SelectExpiredReveals(now, 1000)
+	require.NoError(t, err)
+	assert.NotContains(t, ids(expired), matchId, "only the pending reveal expire")
+}
 func createNewMatch(t *testing.T) string {
 	matchRepo := repository.NewMatch(testQuery)
 	fromUsr := createNewAccount(t)
@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/xyedo/blindate/pkg/domain/attachment (interfaces: Repository)
+
+// Package mockrepo is a generated GoMock package.
+package mockrepo
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAttachment is a mock of Repository interface.
+type MockAttachment struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentMockRecorder
+}
+
+// MockAttachmentMockRecorder is the mock recorder for MockAttachment.
+type MockAttachmentMockRecorder struct {
+	mock *MockAttachment
+}
+
+// NewMockAttachment creates a new mock instance.
+func NewMockAttachment(ctrl *gomock.Controller) *MockAttachment {
+	mock := &MockAttachment{ctrl: ctrl}
+	mock.recorder = &MockAttachmentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachment) EXPECT() *MockAttachmentMockRecorder {
+	return m.recorder
+}
+
+// SelectReferencedBlob mocks base method.
+func (m *MockAttachment) SelectReferencedBlob(arg0 []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectReferencedBlob", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectReferencedBlob indicates an expected call of SelectReferencedBlob.
+func (mr *MockAttachmentMockRecorder) SelectReferencedBlob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectReferencedBlob", reflect.TypeOf((*MockAttachment)(nil).SelectReferencedBlob), arg0)
+}
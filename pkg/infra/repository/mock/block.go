@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/xyedo/blindate/pkg/domain/block (interfaces: Repository)
+
+// Package mockrepo is a generated GoMock package.
+package mockrepo
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+)
+
+// MockBlock is a mock of Repository interface.
+type MockBlock struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlockMockRecorder
+}
+
+// MockBlockMockRecorder is the mock recorder for MockBlock.
+type MockBlockMockRecorder struct {
+	mock *MockBlock
+}
+
+// NewMockBlock creates a new mock instance.
+func NewMockBlock(ctrl *gomock.Controller) *MockBlock {
+	mock := &MockBlock{ctrl: ctrl}
+	mock.recorder = &MockBlockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlock) EXPECT() *MockBlockMockRecorder {
+	return m.recorder
+}
+
+// DeleteBlock mocks base method.
+func (m *MockBlock) DeleteBlock(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlock", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBlock indicates an expected call of DeleteBlock.
+func (mr *MockBlockMockRecorder) DeleteBlock(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlock", reflect.TypeOf((*MockBlock)(nil).DeleteBlock), arg0, arg1)
+}
+
+// InsertBlock mocks base method.
+func (m *MockBlock) InsertBlock(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBlock", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertBlock indicates an expected call of InsertBlock.
+func (mr *MockBlockMockRecorder) InsertBlock(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBlock", reflect.TypeOf((*MockBlock)(nil).InsertBlock), arg0, arg1)
+}
+
+// InsertReport mocks base method.
+func (m *MockBlock) InsertReport(arg0 *blockEntity.Report) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReport", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertReport indicates an expected call of InsertReport.
+func (mr *MockBlockMockRecorder) InsertReport(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReport", reflect.TypeOf((*MockBlock)(nil).InsertReport), arg0)
+}
+
+// IsBlocked mocks base method.
+func (m *MockBlock) IsBlocked(arg0, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBlocked", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBlocked indicates an expected call of IsBlocked.
+func (mr *MockBlockMockRecorder) IsBlocked(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBlocked", reflect.TypeOf((*MockBlock)(nil).IsBlocked), arg0, arg1)
+}
+
+// SelectBlocked mocks base method.
+func (m *MockBlock) SelectBlocked(arg0 string) ([]blockEntity.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectBlocked", arg0)
+	ret0, _ := ret[0].([]blockEntity.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectBlocked indicates an expected call of SelectBlocked.
+func (mr *MockBlockMockRecorder) SelectBlocked(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectBlocked", reflect.TypeOf((*MockBlock)(nil).SelectBlocked), arg0)
+}
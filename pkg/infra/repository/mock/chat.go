@@ -6,6 +6,7 @@ package mockrepo
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	chat "github.com/xyedo/blindate/pkg/domain/chat"
@@ -36,17 +37,31 @@ func (m *MockChat) EXPECT() *MockChatMockRecorder {
 }
 
 // DeleteChatById mocks base method.
-func (m *MockChat) DeleteChatById(arg0 string) error {
+func (m *MockChat) DeleteChatById(arg0 string, arg1 time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteChatById", arg0)
+	ret := m.ctrl.Call(m, "DeleteChatById", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteChatById indicates an expected call of DeleteChatById.
-func (mr *MockChatMockRecorder) DeleteChatById(arg0 interface{}) *gomock.Call {
+func (mr *MockChatMockRecorder) DeleteChatById(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChatById", reflect.TypeOf((*MockChat)(nil).DeleteChatById), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChatById", reflect.TypeOf((*MockChat)(nil).DeleteChatById), arg0, arg1)
+}
+
+// DeleteReaction mocks base method.
+func (m *MockChat) DeleteReaction(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReaction indicates an expected call of DeleteReaction.
+func (mr *MockChatMockRecorder) DeleteReaction(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReaction", reflect.TypeOf((*MockChat)(nil).DeleteReaction), arg0, arg1, arg2)
 }
 
 // InsertNewChat mocks base method.
@@ -63,32 +78,122 @@ func (mr *MockChatMockRecorder) InsertNewChat(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertNewChat", reflect.TypeOf((*MockChat)(nil).InsertNewChat), arg0)
 }
 
+// InsertReaction mocks base method.
+func (m *MockChat) InsertReaction(arg0, arg1, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertReaction indicates an expected call of InsertReaction.
+func (mr *MockChatMockRecorder) InsertReaction(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReaction", reflect.TypeOf((*MockChat)(nil).InsertReaction), arg0, arg1, arg2)
+}
+
 // SelectChat mocks base method.
-func (m *MockChat) SelectChat(arg0 string, arg1 chat.Filter) ([]chatEntity.DAO, error) {
+func (m *MockChat) SelectChat(arg0, arg1 string, arg2 chat.Filter) ([]chatEntity.DAO, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SelectChat", arg0, arg1)
+	ret := m.ctrl.Call(m, "SelectChat", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]chatEntity.DAO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SelectChat indicates an expected call of SelectChat.
-func (mr *MockChatMockRecorder) SelectChat(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockChatMockRecorder) SelectChat(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectChat", reflect.TypeOf((*MockChat)(nil).SelectChat), arg0, arg1, arg2)
+}
+
+// SelectChatById mocks base method.
+func (m *MockChat) SelectChatById(arg0 string) (chatEntity.DAO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectChatById", arg0)
+	ret0, _ := ret[0].(chatEntity.DAO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectChatById indicates an expected call of SelectChatById.
+func (mr *MockChatMockRecorder) SelectChatById(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectChatById", reflect.TypeOf((*MockChat)(nil).SelectChatById), arg0)
+}
+
+// SelectChatContext mocks base method.
+func (m *MockChat) SelectChatContext(arg0 string, arg1 int) ([]chatEntity.DAO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectChatContext", arg0, arg1)
+	ret0, _ := ret[0].([]chatEntity.DAO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectChatContext indicates an expected call of SelectChatContext.
+func (mr *MockChatMockRecorder) SelectChatContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectChatContext", reflect.TypeOf((*MockChat)(nil).SelectChatContext), arg0, arg1)
+}
+
+// SelectChatHistory mocks base method.
+func (m *MockChat) SelectChatHistory(arg0 string) ([]chatEntity.History, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectChatHistory", arg0)
+	ret0, _ := ret[0].([]chatEntity.History)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectChatHistory indicates an expected call of SelectChatHistory.
+func (mr *MockChatMockRecorder) SelectChatHistory(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectChatHistory", reflect.TypeOf((*MockChat)(nil).SelectChatHistory), arg0)
+}
+
+// UpdateChatMessage mocks base method.
+func (m *MockChat) UpdateChatMessage(arg0, arg1 string, arg2, arg3 time.Time) (chatEntity.DAO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChatMessage", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(chatEntity.DAO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateChatMessage indicates an expected call of UpdateChatMessage.
+func (mr *MockChatMockRecorder) UpdateChatMessage(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChatMessage", reflect.TypeOf((*MockChat)(nil).UpdateChatMessage), arg0, arg1, arg2, arg3)
+}
+
+// UpdateDeliveredChat mocks base method.
+func (m *MockChat) UpdateDeliveredChat(arg0 string, arg1 []string, arg2 time.Time) ([]chatEntity.DAO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDeliveredChat", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]chatEntity.DAO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDeliveredChat indicates an expected call of UpdateDeliveredChat.
+func (mr *MockChatMockRecorder) UpdateDeliveredChat(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectChat", reflect.TypeOf((*MockChat)(nil).SelectChat), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeliveredChat", reflect.TypeOf((*MockChat)(nil).UpdateDeliveredChat), arg0, arg1, arg2)
 }
 
 // UpdateSeenChat mocks base method.
-func (m *MockChat) UpdateSeenChat(arg0, arg1 string) ([]string, error) {
+func (m *MockChat) UpdateSeenChat(arg0, arg1, arg2 string, arg3 time.Time) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateSeenChat", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateSeenChat", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateSeenChat indicates an expected call of UpdateSeenChat.
-func (mr *MockChatMockRecorder) UpdateSeenChat(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockChatMockRecorder) UpdateSeenChat(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSeenChat", reflect.TypeOf((*MockChat)(nil).UpdateSeenChat), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSeenChat", reflect.TypeOf((*MockChat)(nil).UpdateSeenChat), arg0, arg1, arg2, arg3)
 }
@@ -94,6 +94,21 @@ func (mr *MockConversationMockRecorder) SelectConversationByUserId(arg0, arg1 in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectConversationByUserId", reflect.TypeOf((*MockConversation)(nil).SelectConversationByUserId), arg0, arg1)
 }
 
+// SelectTotalUnread mocks base method.
+func (m *MockConversation) SelectTotalUnread(arg0 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectTotalUnread", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectTotalUnread indicates an expected call of SelectTotalUnread.
+func (mr *MockConversationMockRecorder) SelectTotalUnread(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectTotalUnread", reflect.TypeOf((*MockConversation)(nil).SelectTotalUnread), arg0)
+}
+
 // UpdateChatRow mocks base method.
 func (m *MockConversation) UpdateChatRow(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -121,3 +136,18 @@ func (mr *MockConversationMockRecorder) UpdateDayPass(arg0 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDayPass", reflect.TypeOf((*MockConversation)(nil).UpdateDayPass), arg0)
 }
+
+// UpdateState mocks base method.
+func (m *MockConversation) UpdateState(arg0, arg1 string, arg2 convEntity.State) (convEntity.State, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateState", arg0, arg1, arg2)
+	ret0, _ := ret[0].(convEntity.State)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateState indicates an expected call of UpdateState.
+func (mr *MockConversationMockRecorder) UpdateState(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateState", reflect.TypeOf((*MockConversation)(nil).UpdateState), arg0, arg1, arg2)
+}
@@ -0,0 +1,169 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/xyedo/blindate/pkg/domain/export (interfaces: Repository)
+
+// Package mockrepo is a generated GoMock package.
+package mockrepo
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	exportEntity "github.com/xyedo/blindate/pkg/domain/export/entities"
+)
+
+// MockExport is a mock of Repository interface.
+type MockExport struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportMockRecorder
+}
+
+// MockExportMockRecorder is the mock recorder for MockExport.
+type MockExportMockRecorder struct {
+	mock *MockExport
+}
+
+// NewMockExport creates a new mock instance.
+func NewMockExport(ctrl *gomock.Controller) *MockExport {
+	mock := &MockExport{ctrl: ctrl}
+	mock.recorder = &MockExportMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExport) EXPECT() *MockExportMockRecorder {
+	return m.recorder
+}
+
+// ClaimJob mocks base method.
+func (m *MockExport) ClaimJob(arg0, arg1 time.Time) (exportEntity.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimJob", arg0, arg1)
+	ret0, _ := ret[0].(exportEntity.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimJob indicates an expected call of ClaimJob.
+func (mr *MockExportMockRecorder) ClaimJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimJob", reflect.TypeOf((*MockExport)(nil).ClaimJob), arg0, arg1)
+}
+
+// InsertJob mocks base method.
+func (m *MockExport) InsertJob(arg0 *exportEntity.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertJob", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertJob indicates an expected call of InsertJob.
+func (mr *MockExportMockRecorder) InsertJob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertJob", reflect.TypeOf((*MockExport)(nil).InsertJob), arg0)
+}
+
+// SelectAuthoredChats mocks base method.
+func (m *MockExport) SelectAuthoredChats(arg0 string) ([]exportEntity.Chat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectAuthoredChats", arg0)
+	ret0, _ := ret[0].([]exportEntity.Chat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectAuthoredChats indicates an expected call of SelectAuthoredChats.
+func (mr *MockExportMockRecorder) SelectAuthoredChats(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectAuthoredChats", reflect.TypeOf((*MockExport)(nil).SelectAuthoredChats), arg0)
+}
+
+// SelectConversations mocks base method.
+func (m *MockExport) SelectConversations(arg0 string) ([]exportEntity.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectConversations", arg0)
+	ret0, _ := ret[0].([]exportEntity.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectConversations indicates an expected call of SelectConversations.
+func (mr *MockExportMockRecorder) SelectConversations(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectConversations", reflect.TypeOf((*MockExport)(nil).SelectConversations), arg0)
+}
+
+// SelectExpiredJobs mocks base method.
+func (m *MockExport) SelectExpiredJobs(arg0 time.Time, arg1 int) ([]exportEntity.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectExpiredJobs", arg0, arg1)
+	ret0, _ := ret[0].([]exportEntity.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectExpiredJobs indicates an expected call of SelectExpiredJobs.
+func (mr *MockExportMockRecorder) SelectExpiredJobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectExpiredJobs", reflect.TypeOf((*MockExport)(nil).SelectExpiredJobs), arg0, arg1)
+}
+
+// SelectJobById mocks base method.
+func (m *MockExport) SelectJobById(arg0 string) (exportEntity.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectJobById", arg0)
+	ret0, _ := ret[0].(exportEntity.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectJobById indicates an expected call of SelectJobById.
+func (mr *MockExportMockRecorder) SelectJobById(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectJobById", reflect.TypeOf((*MockExport)(nil).SelectJobById), arg0)
+}
+
+// SelectJobsByUserId mocks base method.
+func (m *MockExport) SelectJobsByUserId(arg0 string) ([]exportEntity.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectJobsByUserId", arg0)
+	ret0, _ := ret[0].([]exportEntity.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectJobsByUserId indicates an expected call of SelectJobsByUserId.
+func (mr *MockExportMockRecorder) SelectJobsByUserId(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectJobsByUserId", reflect.TypeOf((*MockExport)(nil).SelectJobsByUserId), arg0)
+}
+
+// SelectMatches mocks base method.
+func (m *MockExport) SelectMatches(arg0 string) ([]exportEntity.Match, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectMatches", arg0)
+	ret0, _ := ret[0].([]exportEntity.Match)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectMatches indicates an expected call of SelectMatches.
+func (mr *MockExportMockRecorder) SelectMatches(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectMatches", reflect.TypeOf((*MockExport)(nil).SelectMatches), arg0)
+}
+
+// UpdateJob mocks base method.
+func (m *MockExport) UpdateJob(arg0 exportEntity.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateJob", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateJob indicates an expected call of UpdateJob.
+func (mr *MockExportMockRecorder) UpdateJob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJob", reflect.TypeOf((*MockExport)(nil).UpdateJob), arg0)
+}
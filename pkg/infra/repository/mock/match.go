@@ -6,8 +6,10 @@ package mockrepo
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	match "github.com/xyedo/blindate/pkg/domain/match"
 	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
 )
 
@@ -64,19 +66,63 @@ func (mr *MockMatchMockRecorder) InsertNewMatch(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertNewMatch", reflect.TypeOf((*MockMatch)(nil).InsertNewMatch), arg0, arg1, arg2)
 }
 
-// SelectMatchReqToUserId mocks base method.
-func (m *MockMatch) SelectMatchReqToUserId(arg0 string) ([]matchEntity.FullUserDTO, error) {
+// SelectEventsByMatchId mocks base method.
+func (m *MockMatch) SelectEventsByMatchId(arg0 string) ([]matchEntity.Event, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SelectMatchReqToUserId", arg0)
+	ret := m.ctrl.Call(m, "SelectEventsByMatchId", arg0)
+	ret0, _ := ret[0].([]matchEntity.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectEventsByMatchId indicates an expected call of SelectEventsByMatchId.
+func (mr *MockMatchMockRecorder) SelectEventsByMatchId(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectEventsByMatchId", reflect.TypeOf((*MockMatch)(nil).SelectEventsByMatchId), arg0)
+}
+
+// SelectExpiredReveals mocks base method.
+func (m *MockMatch) SelectExpiredReveals(arg0 time.Time, arg1 int) ([]matchEntity.MatchDAO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectExpiredReveals", arg0, arg1)
+	ret0, _ := ret[0].([]matchEntity.MatchDAO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectExpiredReveals indicates an expected call of SelectExpiredReveals.
+func (mr *MockMatchMockRecorder) SelectExpiredReveals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectExpiredReveals", reflect.TypeOf((*MockMatch)(nil).SelectExpiredReveals), arg0, arg1)
+}
+
+// SelectMatchesByUserId mocks base method.
+func (m *MockMatch) SelectMatchesByUserId(arg0 string, arg1 match.Filter) ([]matchEntity.FullUserDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectMatchesByUserId", arg0, arg1)
 	ret0, _ := ret[0].([]matchEntity.FullUserDTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SelectMatchReqToUserId indicates an expected call of SelectMatchReqToUserId.
-func (mr *MockMatchMockRecorder) SelectMatchReqToUserId(arg0 interface{}) *gomock.Call {
+// SelectMatchesByUserId indicates an expected call of SelectMatchesByUserId.
+func (mr *MockMatchMockRecorder) SelectMatchesByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectMatchesByUserId", reflect.TypeOf((*MockMatch)(nil).SelectMatchesByUserId), arg0, arg1)
+}
+
+// TransitionMatch mocks base method.
+func (m *MockMatch) TransitionMatch(arg0 matchEntity.MatchDAO, arg1 *matchEntity.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitionMatch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransitionMatch indicates an expected call of TransitionMatch.
+func (mr *MockMatchMockRecorder) TransitionMatch(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectMatchReqToUserId", reflect.TypeOf((*MockMatch)(nil).SelectMatchReqToUserId), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionMatch", reflect.TypeOf((*MockMatch)(nil).TransitionMatch), arg0, arg1)
 }
 
 // UpdateMatchById mocks base method.
@@ -0,0 +1,169 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/xyedo/blindate/pkg/domain/moderation (interfaces: Repository)
+
+// Package mockrepo is a generated GoMock package.
+package mockrepo
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+	moderation "github.com/xyedo/blindate/pkg/domain/moderation"
+	moderationEntity "github.com/xyedo/blindate/pkg/domain/moderation/entities"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+)
+
+// MockModeration is a mock of Repository interface.
+type MockModeration struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationMockRecorder
+}
+
+// MockModerationMockRecorder is the mock recorder for MockModeration.
+type MockModerationMockRecorder struct {
+	mock *MockModeration
+}
+
+// NewMockModeration creates a new mock instance.
+func NewMockModeration(ctrl *gomock.Controller) *MockModeration {
+	mock := &MockModeration{ctrl: ctrl}
+	mock.recorder = &MockModerationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModeration) EXPECT() *MockModerationMockRecorder {
+	return m.recorder
+}
+
+// InsertAuditLog mocks base method.
+func (m *MockModeration) InsertAuditLog(arg0 moderationEntity.AuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAuditLog", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertAuditLog indicates an expected call of InsertAuditLog.
+func (mr *MockModerationMockRecorder) InsertAuditLog(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAuditLog", reflect.TypeOf((*MockModeration)(nil).InsertAuditLog), arg0)
+}
+
+// InsertSuspension mocks base method.
+func (m *MockModeration) InsertSuspension(arg0 *moderationEntity.Suspension, arg1 moderationEntity.AuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertSuspension", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertSuspension indicates an expected call of InsertSuspension.
+func (mr *MockModerationMockRecorder) InsertSuspension(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertSuspension", reflect.TypeOf((*MockModeration)(nil).InsertSuspension), arg0, arg1)
+}
+
+// LiftSuspension mocks base method.
+func (m *MockModeration) LiftSuspension(arg0, arg1 string, arg2 time.Time, arg3 moderationEntity.AuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LiftSuspension", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LiftSuspension indicates an expected call of LiftSuspension.
+func (mr *MockModerationMockRecorder) LiftSuspension(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LiftSuspension", reflect.TypeOf((*MockModeration)(nil).LiftSuspension), arg0, arg1, arg2, arg3)
+}
+
+// SelectAuditLogs mocks base method.
+func (m *MockModeration) SelectAuditLogs(arg0 moderation.AuditFilter) ([]moderationEntity.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectAuditLogs", arg0)
+	ret0, _ := ret[0].([]moderationEntity.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectAuditLogs indicates an expected call of SelectAuditLogs.
+func (mr *MockModerationMockRecorder) SelectAuditLogs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectAuditLogs", reflect.TypeOf((*MockModeration)(nil).SelectAuditLogs), arg0)
+}
+
+// SelectReportById mocks base method.
+func (m *MockModeration) SelectReportById(arg0 string) (blockEntity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectReportById", arg0)
+	ret0, _ := ret[0].(blockEntity.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectReportById indicates an expected call of SelectReportById.
+func (mr *MockModerationMockRecorder) SelectReportById(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectReportById", reflect.TypeOf((*MockModeration)(nil).SelectReportById), arg0)
+}
+
+// SelectReports mocks base method.
+func (m *MockModeration) SelectReports(arg0 moderation.ReportFilter) ([]blockEntity.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectReports", arg0)
+	ret0, _ := ret[0].([]blockEntity.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectReports indicates an expected call of SelectReports.
+func (mr *MockModerationMockRecorder) SelectReports(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectReports", reflect.TypeOf((*MockModeration)(nil).SelectReports), arg0)
+}
+
+// SelectSuspensions mocks base method.
+func (m *MockModeration) SelectSuspensions(arg0 string) ([]moderationEntity.Suspension, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectSuspensions", arg0)
+	ret0, _ := ret[0].([]moderationEntity.Suspension)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectSuspensions indicates an expected call of SelectSuspensions.
+func (mr *MockModerationMockRecorder) SelectSuspensions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectSuspensions", reflect.TypeOf((*MockModeration)(nil).SelectSuspensions), arg0)
+}
+
+// UpdateReportReview mocks base method.
+func (m *MockModeration) UpdateReportReview(arg0 blockEntity.Report, arg1 moderationEntity.AuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReportReview", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReportReview indicates an expected call of UpdateReportReview.
+func (mr *MockModerationMockRecorder) UpdateReportReview(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReportReview", reflect.TypeOf((*MockModeration)(nil).UpdateReportReview), arg0, arg1)
+}
+
+// UpdateRole mocks base method.
+func (m *MockModeration) UpdateRole(arg0 string, arg1 userEntity.Role, arg2 moderationEntity.AuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockModerationMockRecorder) UpdateRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockModeration)(nil).UpdateRole), arg0, arg1, arg2)
+}
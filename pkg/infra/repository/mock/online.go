@@ -6,9 +6,10 @@ package mockrepo
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
-	onlineEntities "github.com/xyedo/blindate/pkg/domain/online/entities"
+	onlineEntity "github.com/xyedo/blindate/pkg/domain/online/entities"
 )
 
 // MockOnline is a mock of Repository interface.
@@ -35,7 +36,7 @@ func (m *MockOnline) EXPECT() *MockOnlineMockRecorder {
 }
 
 // InsertNewOnline mocks base method.
-func (m *MockOnline) InsertNewOnline(arg0 onlineEntities.DTO) error {
+func (m *MockOnline) InsertNewOnline(arg0 onlineEntity.DTO) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "InsertNewOnline", arg0)
 	ret0, _ := ret[0].(error)
@@ -49,10 +50,10 @@ func (mr *MockOnlineMockRecorder) InsertNewOnline(arg0 interface{}) *gomock.Call
 }
 
 // SelectOnline mocks base method.
-func (m *MockOnline) SelectOnline(arg0 string) (onlineEntities.DTO, error) {
+func (m *MockOnline) SelectOnline(arg0 string) (onlineEntity.DTO, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SelectOnline", arg0)
-	ret0, _ := ret[0].(onlineEntities.DTO)
+	ret0, _ := ret[0].(onlineEntity.DTO)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -63,6 +64,21 @@ func (mr *MockOnlineMockRecorder) SelectOnline(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectOnline", reflect.TypeOf((*MockOnline)(nil).SelectOnline), arg0)
 }
 
+// UpdateHeartbeat mocks base method.
+func (m *MockOnline) UpdateHeartbeat(arg0 string, arg1 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHeartbeat", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateHeartbeat indicates an expected call of UpdateHeartbeat.
+func (mr *MockOnlineMockRecorder) UpdateHeartbeat(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHeartbeat", reflect.TypeOf((*MockOnline)(nil).UpdateHeartbeat), arg0, arg1)
+}
+
 // UpdateOnline mocks base method.
 func (m *MockOnline) UpdateOnline(arg0 string, arg1 bool) error {
 	m.ctrl.T.Helper()
@@ -76,3 +92,32 @@ func (mr *MockOnlineMockRecorder) UpdateOnline(arg0, arg1 interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOnline", reflect.TypeOf((*MockOnline)(nil).UpdateOnline), arg0, arg1)
 }
+
+// UpdateSettings mocks base method.
+func (m *MockOnline) UpdateSettings(arg0 string, arg1 onlineEntity.Settings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSettings", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSettings indicates an expected call of UpdateSettings.
+func (mr *MockOnlineMockRecorder) UpdateSettings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSettings", reflect.TypeOf((*MockOnline)(nil).UpdateSettings), arg0, arg1)
+}
+
+// UpdateStaleOffline mocks base method.
+func (m *MockOnline) UpdateStaleOffline(arg0 time.Time) ([]onlineEntity.DTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStaleOffline", arg0)
+	ret0, _ := ret[0].([]onlineEntity.DTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStaleOffline indicates an expected call of UpdateStaleOffline.
+func (mr *MockOnlineMockRecorder) UpdateStaleOffline(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStaleOffline", reflect.TypeOf((*MockOnline)(nil).UpdateStaleOffline), arg0)
+}
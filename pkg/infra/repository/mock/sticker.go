@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/xyedo/blindate/pkg/domain/sticker (interfaces: Repository)
+
+// Package mockrepo is a generated GoMock package.
+package mockrepo
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	stickerEntity "github.com/xyedo/blindate/pkg/domain/sticker/entities"
+)
+
+// MockSticker is a mock of Repository interface.
+type MockSticker struct {
+	ctrl     *gomock.Controller
+	recorder *MockStickerMockRecorder
+}
+
+// MockStickerMockRecorder is the mock recorder for MockSticker.
+type MockStickerMockRecorder struct {
+	mock *MockSticker
+}
+
+// NewMockSticker creates a new mock instance.
+func NewMockSticker(ctrl *gomock.Controller) *MockSticker {
+	mock := &MockSticker{ctrl: ctrl}
+	mock.recorder = &MockStickerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSticker) EXPECT() *MockStickerMockRecorder {
+	return m.recorder
+}
+
+// ArchiveSticker mocks base method.
+func (m *MockSticker) ArchiveSticker(arg0 string, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveSticker", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveSticker indicates an expected call of ArchiveSticker.
+func (mr *MockStickerMockRecorder) ArchiveSticker(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveSticker", reflect.TypeOf((*MockSticker)(nil).ArchiveSticker), arg0, arg1)
+}
+
+// InsertPack mocks base method.
+func (m *MockSticker) InsertPack(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPack", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertPack indicates an expected call of InsertPack.
+func (mr *MockStickerMockRecorder) InsertPack(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPack", reflect.TypeOf((*MockSticker)(nil).InsertPack), arg0)
+}
+
+// InsertSticker mocks base method.
+func (m *MockSticker) InsertSticker(arg0 *stickerEntity.Sticker) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertSticker", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertSticker indicates an expected call of InsertSticker.
+func (mr *MockStickerMockRecorder) InsertSticker(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertSticker", reflect.TypeOf((*MockSticker)(nil).InsertSticker), arg0)
+}
+
+// SelectPackById mocks base method.
+func (m *MockSticker) SelectPackById(arg0 string) (stickerEntity.Pack, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectPackById", arg0)
+	ret0, _ := ret[0].(stickerEntity.Pack)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectPackById indicates an expected call of SelectPackById.
+func (mr *MockStickerMockRecorder) SelectPackById(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectPackById", reflect.TypeOf((*MockSticker)(nil).SelectPackById), arg0)
+}
+
+// SelectPacks mocks base method.
+func (m *MockSticker) SelectPacks(arg0 bool) ([]stickerEntity.Pack, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectPacks", arg0)
+	ret0, _ := ret[0].([]stickerEntity.Pack)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectPacks indicates an expected call of SelectPacks.
+func (mr *MockStickerMockRecorder) SelectPacks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectPacks", reflect.TypeOf((*MockSticker)(nil).SelectPacks), arg0)
+}
+
+// UpdatePack mocks base method.
+func (m *MockSticker) UpdatePack(arg0 string, arg1 stickerEntity.UpdatePack, arg2 time.Time) (stickerEntity.Pack, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePack", arg0, arg1, arg2)
+	ret0, _ := ret[0].(stickerEntity.Pack)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePack indicates an expected call of UpdatePack.
+func (mr *MockStickerMockRecorder) UpdatePack(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePack", reflect.TypeOf((*MockSticker)(nil).UpdatePack), arg0, arg1, arg2)
+}
@@ -6,6 +6,7 @@ package mockrepo
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	user "github.com/xyedo/blindate/pkg/domain/user"
@@ -36,18 +37,33 @@ func (m *MockUser) EXPECT() *MockUserMockRecorder {
 }
 
 // CreateProfilePicture mocks base method.
-func (m *MockUser) CreateProfilePicture(arg0, arg1 string, arg2 bool) (string, error) {
+func (m *MockUser) CreateProfilePicture(arg0 userEntity.ProfilePic) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateProfilePicture", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "CreateProfilePicture", arg0)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateProfilePicture indicates an expected call of CreateProfilePicture.
-func (mr *MockUserMockRecorder) CreateProfilePicture(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockUserMockRecorder) CreateProfilePicture(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProfilePicture", reflect.TypeOf((*MockUser)(nil).CreateProfilePicture), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProfilePicture", reflect.TypeOf((*MockUser)(nil).CreateProfilePicture), arg0)
+}
+
+// DeleteProfilePicture mocks base method.
+func (m *MockUser) DeleteProfilePicture(arg0, arg1 string) (userEntity.ProfilePic, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProfilePicture", arg0, arg1)
+	ret0, _ := ret[0].(userEntity.ProfilePic)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteProfilePicture indicates an expected call of DeleteProfilePicture.
+func (mr *MockUserMockRecorder) DeleteProfilePicture(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProfilePicture", reflect.TypeOf((*MockUser)(nil).DeleteProfilePicture), arg0, arg1)
 }
 
 // GetUserByEmail mocks base method.
@@ -110,6 +126,21 @@ func (mr *MockUserMockRecorder) ProfilePicSelectedToFalse(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProfilePicSelectedToFalse", reflect.TypeOf((*MockUser)(nil).ProfilePicSelectedToFalse), arg0)
 }
 
+// PurgeUser mocks base method.
+func (m *MockUser) PurgeUser(arg0 string, arg1 time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUser", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeUser indicates an expected call of PurgeUser.
+func (mr *MockUserMockRecorder) PurgeUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUser", reflect.TypeOf((*MockUser)(nil).PurgeUser), arg0, arg1)
+}
+
 // SelectProfilePicture mocks base method.
 func (m *MockUser) SelectProfilePicture(arg0 string, arg1 *user.ProfilePicQuery) ([]userEntity.ProfilePic, error) {
 	m.ctrl.T.Helper()
@@ -125,6 +156,77 @@ func (mr *MockUserMockRecorder) SelectProfilePicture(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectProfilePicture", reflect.TypeOf((*MockUser)(nil).SelectProfilePicture), arg0, arg1)
 }
 
+// SelectPurgeable mocks base method.
+func (m *MockUser) SelectPurgeable(arg0 time.Time, arg1 int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectPurgeable", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectPurgeable indicates an expected call of SelectPurgeable.
+func (mr *MockUserMockRecorder) SelectPurgeable(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectPurgeable", reflect.TypeOf((*MockUser)(nil).SelectPurgeable), arg0, arg1)
+}
+
+// UpdateDeletionRequest mocks base method.
+func (m *MockUser) UpdateDeletionRequest(arg0 string, arg1 *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDeletionRequest", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDeletionRequest indicates an expected call of UpdateDeletionRequest.
+func (mr *MockUserMockRecorder) UpdateDeletionRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeletionRequest", reflect.TypeOf((*MockUser)(nil).UpdateDeletionRequest), arg0, arg1)
+}
+
+// UpdateProfilePicturePosition mocks base method.
+func (m *MockUser) UpdateProfilePicturePosition(arg0 string, arg1 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfilePicturePosition", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProfilePicturePosition indicates an expected call of UpdateProfilePicturePosition.
+func (mr *MockUserMockRecorder) UpdateProfilePicturePosition(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfilePicturePosition", reflect.TypeOf((*MockUser)(nil).UpdateProfilePicturePosition), arg0, arg1)
+}
+
+// UpdateSelectedProfilePicture mocks base method.
+func (m *MockUser) UpdateSelectedProfilePicture(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSelectedProfilePicture", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSelectedProfilePicture indicates an expected call of UpdateSelectedProfilePicture.
+func (mr *MockUserMockRecorder) UpdateSelectedProfilePicture(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSelectedProfilePicture", reflect.TypeOf((*MockUser)(nil).UpdateSelectedProfilePicture), arg0, arg1)
+}
+
+// UpdateSnooze mocks base method.
+func (m *MockUser) UpdateSnooze(arg0 string, arg1 *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSnooze", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSnooze indicates an expected call of UpdateSnooze.
+func (mr *MockUserMockRecorder) UpdateSnooze(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSnooze", reflect.TypeOf((*MockUser)(nil).UpdateSnooze), arg0, arg1)
+}
+
 // UpdateUser mocks base method.
 func (m *MockUser) UpdateUser(arg0 userEntity.FullDTO) error {
 	m.ctrl.T.Helper()
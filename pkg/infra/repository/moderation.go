@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/xyedo/blindate/pkg/common"
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+	"github.com/xyedo/blindate/pkg/domain/moderation"
+	moderationEntity "github.com/xyedo/blindate/pkg/domain/moderation/entities"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+)
+
+func NewModeration(conn *sqlx.DB) *ModerationConn {
+	return &ModerationConn{
+		conn: conn,
+	}
+}
+
+type ModerationConn struct {
+	conn *sqlx.DB
+}
+
+var selectReportColumns = `
+	SELECT
+		id,
+		reporter_id,
+		reported_id,
+		reason,
+		chat_id,
+		details,
+		created_at,
+		status,
+		reviewed_by,
+		reviewed_at,
+		resolution
+	FROM reports`
+
+// SelectReports list the report queue, the oldest report first
+func (m *ModerationConn) SelectReports(filter moderation.ReportFilter) ([]blockEntity.Report, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 20
+	}
+	args := []any{filter.Status}
+	query := selectReportColumns + `
+	WHERE status = $1`
+	if filter.ReportedId != "" {
+		args = append(args, filter.ReportedId)
+		query += fmt.Sprintf(` AND reported_id = $%d`, len(args))
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(`
+	ORDER BY created_at, id
+	LIMIT $%d`, len(args))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reports := make([]blockEntity.Report, 0)
+	err := m.conn.SelectContext(ctx, &reports, query, args...)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (m *ModerationConn) SelectReportById(reportId string) (blockEntity.Report, error) {
+	query := selectReportColumns + `
+	WHERE id = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var report blockEntity.Report
+	err := m.conn.GetContext(ctx, &report, query, reportId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return blockEntity.Report{}, common.WrapErrorWithMsg(err, common.ErrResourceNotFound, "report not found")
+		}
+		if errors.Is(err, context.Canceled) {
+			return blockEntity.Report{}, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return blockEntity.Report{}, err
+	}
+	return report, nil
+}
+
+// UpdateReportReview close the open report, the reviewed report is not found
+func (m *ModerationConn) UpdateReportReview(report blockEntity.Report, audit moderationEntity.AuditLog) error {
+	query := `
+	UPDATE reports SET
+		status = $2,
+		reviewed_by = $3,
+		reviewed_at = $4,
+		resolution = $5
+	WHERE id = $1 AND status = 'open'`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return m.execTx(ctx, func(q queryer) error {
+		res, err := q.ExecContext(ctx, query, report.Id, report.Status, report.ReviewedBy, report.ReviewedAt, report.Resolution)
+		if err != nil {
+			return m.wrapError(err)
+		}
+		row, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if row == 0 {
+			return common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "open report not found")
+		}
+		return m.insertAuditLog(ctx, q, audit)
+	})
+}
+
+func (m *ModerationConn) InsertSuspension(suspension *moderationEntity.Suspension, audit moderationEntity.AuditLog) error {
+	query := `
+	INSERT INTO suspensions(user_id, moderator_id, reason, ends_at)
+	VALUES($1, $2, $3, $4)
+	RETURNING id, created_at`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return m.execTx(ctx, func(q queryer) error {
+		row := q.QueryRowxContext(ctx, query, suspension.UserId, suspension.ModeratorId, suspension.Reason, suspension.EndsAt)
+		err := row.Scan(&suspension.Id, &suspension.CreatedAt)
+		if err != nil {
+			return m.wrapError(err)
+		}
+		return m.insertAuditLog(ctx, q, audit)
+	})
+}
+
+// LiftSuspension end every active suspension and ban of userId
+func (m *ModerationConn) LiftSuspension(userId, moderatorId string, at time.Time, audit moderationEntity.AuditLog) error {
+	query := `
+	UPDATE suspensions SET
+		lifted_at = $3,
+		lifted_by = $2
+	WHERE user_id = $1
+		AND lifted_at IS NULL
+		AND (ends_at IS NULL OR ends_at > $3)`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return m.execTx(ctx, func(q queryer) error {
+		res, err := q.ExecContext(ctx, query, userId, moderatorId, at)
+		if err != nil {
+			return m.wrapError(err)
+		}
+		row, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if row == 0 {
+			return common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "active suspension not found")
+		}
+		return m.insertAuditLog(ctx, q, audit)
+	})
+}
+
+// SelectSuspensions list every suspension of userId including the lifted and expired one, the latest first
+func (m *ModerationConn) SelectSuspensions(userId string) ([]moderationEntity.Suspension, error) {
+	query := `
+	SELECT
+		id,
+		user_id,
+		moderator_id,
+		reason,
+		ends_at,
+		created_at,
+		lifted_at,
+		lifted_by
+	FROM suspensions
+	WHERE user_id = $1
+	ORDER BY created_at DESC, id`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	suspensions := make([]moderationEntity.Suspension, 0)
+	err := m.conn.SelectContext(ctx, &suspensions, query, userId)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	return suspensions, nil
+}
+
+func (m *ModerationConn) UpdateRole(userId string, role userEntity.Role, audit moderationEntity.AuditLog) error {
+	query := `UPDATE users SET role = $2 WHERE id = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return m.execTx(ctx, func(q queryer) error {
+		res, err := q.ExecContext(ctx, query, userId, role)
+		if err != nil {
+			return m.wrapError(err)
+		}
+		row, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if row == 0 {
+			return common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "user not found")
+		}
+		return m.insertAuditLog(ctx, q, audit)
+	})
+}
+
+func (m *ModerationConn) InsertAuditLog(audit moderationEntity.AuditLog) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return m.insertAuditLog(ctx, m.conn, audit)
+}
+
+// SelectAuditLogs list the audit log, the latest first. Before page through the older log
+func (m *ModerationConn) SelectAuditLogs(filter moderation.AuditFilter) ([]moderationEntity.AuditLog, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 50
+	}
+	conds := make([]string, 0)
+	args := make([]any, 0)
+	if filter.ModeratorId != "" {
+		args = append(args, filter.ModeratorId)
+		conds = append(conds, fmt.Sprintf(`moderator_id = $%d`, len(args)))
+	}
+	if filter.TargetUserId != "" {
+		args = append(args, filter.TargetUserId)
+		conds = append(conds, fmt.Sprintf(`target_user_id = $%d`, len(args)))
+	}
+	if filter.Before != nil {
+		args = append(args, *filter.Before)
+		conds = append(conds, fmt.Sprintf(`created_at < $%d`, len(args)))
+	}
+	query := `
+	SELECT
+		id,
+		moderator_id,
+		action,
+		target_user_id,
+		report_id,
+		details,
+		created_at
+	FROM moderation_audit_logs`
+	if len(conds) != 0 {
+		query += `
+	WHERE ` + strings.Join(conds, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(`
+	ORDER BY created_at DESC, id DESC
+	LIMIT $%d`, len(args))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	logs := make([]moderationEntity.AuditLog, 0)
+	err := m.conn.SelectContext(ctx, &logs, query, args...)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	return logs, nil
+}
+
+func (m *ModerationConn) insertAuditLog(ctx context.Context, q queryer, audit moderationEntity.AuditLog) error {
+	query := `
+	INSERT INTO moderation_audit_logs(moderator_id, action, target_user_id, report_id, details)
+	VALUES($1, $2, $3, $4, $5)`
+	_, err := q.ExecContext(ctx, query, audit.ModeratorId, audit.Action, audit.TargetUserId, audit.ReportId, audit.Details)
+	if err != nil {
+		return m.wrapError(err)
+	}
+	return nil
+}
+
+func (*ModerationConn) wrapError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return common.WrapError(err, common.ErrTooLongAccessingDB)
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		if strings.Contains(pqErr.Constraint, "user_id") {
+			return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "user not found")
+		}
+		if strings.Contains(pqErr.Constraint, "role") {
+			return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "role is invalid")
+		}
+		return common.WrapError(err, common.ErrRefNotFound23503)
+	}
+	return err
+}
+
+func (m *ModerationConn) execTx(ctx context.Context, q func(q queryer) error) error {
+	return execGeneric(m.conn, ctx, q, &sql.TxOptions{Isolation: sql.LevelReadCommitted, ReadOnly: false})
+}
@@ -0,0 +1,189 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/common"
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+	"github.com/xyedo/blindate/pkg/domain/moderation"
+	moderationEntity "github.com/xyedo/blindate/pkg/domain/moderation/entities"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+	"github.com/xyedo/blindate/pkg/infra/repository"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_ReportQueue(t *testing.T) {
+	blockRepo := repository.NewBlock(testQuery)
+	moderationRepo := repository.NewModeration(testQuery)
+	moderator := createNewAccount(t)
+	reported := createNewAccount(t)
+	report := blockEntity.Report{
+		ReporterId: createNewAccount(t).ID,
+		ReportedId: reported.ID,
+		Reason:     blockEntity.Spam,
+	}
+	err := blockRepo.InsertReport(&report)
+	require.NoError(t, err)
+	assert.Equal(t, blockEntity.ReportOpen, report.Status)
+
+	reports, err := moderationRepo.SelectReports(moderation.ReportFilter{Status: blockEntity.ReportOpen, ReportedId: reported.ID})
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, report.Id, reports[0].Id)
+
+	reviewedAt := time.Now()
+	resolution := util.RandomString(12)
+	report.Status = blockEntity.ReportResolved
+	report.ReviewedBy = &moderator.ID
+	report.ReviewedAt = &reviewedAt
+	report.Resolution = &resolution
+	audit := moderationEntity.AuditLog{
+		ModeratorId:  &moderator.ID,
+		Action:       moderationEntity.ActionReportResolved,
+		TargetUserId: &reported.ID,
+		ReportId:     &report.Id,
+	}
+	err = moderationRepo.UpdateReportReview(report, audit)
+	require.NoError(t, err)
+
+	t.Run("reviewed report leave the queue", func(t *testing.T) {
+		reports, err := moderationRepo.SelectReports(moderation.ReportFilter{Status: blockEntity.ReportOpen, ReportedId: reported.ID})
+		require.NoError(t, err)
+		assert.Empty(t, reports)
+		got, err := moderationRepo.SelectReportById(report.Id)
+		require.NoError(t, err)
+		assert.Equal(t, blockEntity.ReportResolved, got.Status)
+		require.NotNil(t, got.ReviewedBy)
+		assert.Equal(t, moderator.ID, *got.ReviewedBy)
+	})
+	t.Run("could not be reviewed twice", func(t *testing.T) {
+		err := moderationRepo.UpdateReportReview(report, audit)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+	t.Run("review is audited", func(t *testing.T) {
+		logs, err := moderationRepo.SelectAuditLogs(moderation.AuditFilter{ModeratorId: moderator.ID})
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		assert.Equal(t, moderationEntity.ActionReportResolved, logs[0].Action)
+		require.NotNil(t, logs[0].ReportId)
+		assert.Equal(t, report.Id, *logs[0].ReportId)
+	})
+}
+
+func Test_Suspension(t *testing.T) {
+	moderationRepo := repository.NewModeration(testQuery)
+	userRepo := repository.NewUser(testQuery)
+	moderator := createNewAccount(t)
+
+	t.Run("suspension restrict the user until lifted", func(t *testing.T) {
+		user := createNewAccount(t)
+		endsAt := time.Now().Add(24 * time.Hour)
+		suspension := moderationEntity.Suspension{
+			UserId:      user.ID,
+			ModeratorId: &moderator.ID,
+			Reason:      util.RandomString(12),
+			EndsAt:      &endsAt,
+		}
+		err := moderationRepo.InsertSuspension(&suspension, moderationEntity.AuditLog{
+			ModeratorId:  &moderator.ID,
+			Action:       moderationEntity.ActionUserSuspended,
+			TargetUserId: &user.ID,
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, suspension.Id)
+
+		got, err := userRepo.GetUserById(user.ID)
+		require.NoError(t, err)
+		assert.False(t, got.Banned)
+		require.NotNil(t, got.SuspendedUntil)
+		assert.WithinDuration(t, endsAt, *got.SuspendedUntil, time.Second)
+
+		err = moderationRepo.LiftSuspension(user.ID, moderator.ID, time.Now(), moderationEntity.AuditLog{
+			ModeratorId:  &moderator.ID,
+			Action:       moderationEntity.ActionSuspensionLifted,
+			TargetUserId: &user.ID,
+		})
+		require.NoError(t, err)
+		got, err = userRepo.GetUserByEmail(user.Email)
+		require.NoError(t, err)
+		assert.Nil(t, got.SuspendedUntil)
+
+		suspensions, err := moderationRepo.SelectSuspensions(user.ID)
+		require.NoError(t, err)
+		require.Len(t, suspensions, 1)
+		assert.NotNil(t, suspensions[0].LiftedAt)
+	})
+	t.Run("ban", func(t *testing.T) {
+		user := createNewAccount(t)
+		err := moderationRepo.InsertSuspension(&moderationEntity.Suspension{
+			UserId:      user.ID,
+			ModeratorId: &moderator.ID,
+			Reason:      util.RandomString(12),
+		}, moderationEntity.AuditLog{
+			ModeratorId:  &moderator.ID,
+			Action:       moderationEntity.ActionUserBanned,
+			TargetUserId: &user.ID,
+		})
+		require.NoError(t, err)
+		got, err := userRepo.GetUserByEmail(user.Email)
+		require.NoError(t, err)
+		assert.True(t, got.Banned)
+	})
+	t.Run("nothing to lift", func(t *testing.T) {
+		user := createNewAccount(t)
+		err := moderationRepo.LiftSuspension(user.ID, moderator.ID, time.Now(), moderationEntity.AuditLog{
+			ModeratorId:  &moderator.ID,
+			Action:       moderationEntity.ActionSuspensionLifted,
+			TargetUserId: &user.ID,
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+	t.Run("unknown user", func(t *testing.T) {
+		userId := util.RandomUUID()
+		err := moderationRepo.InsertSuspension(&moderationEntity.Suspension{
+			UserId:      userId,
+			ModeratorId: &moderator.ID,
+			Reason:      util.RandomString(12),
+		}, moderationEntity.AuditLog{
+			ModeratorId: &moderator.ID,
+			Action:      moderationEntity.ActionUserBanned,
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrRefNotFound23503)
+	})
+}
+
+func Test_UpdateRole(t *testing.T) {
+	moderationRepo := repository.NewModeration(testQuery)
+	userRepo := repository.NewUser(testQuery)
+	user := createNewAccount(t)
+
+	got, err := userRepo.GetUserById(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, userEntity.RoleUser, got.Role)
+
+	err = moderationRepo.UpdateRole(user.ID, userEntity.RoleModerator, moderationEntity.AuditLog{
+		Action:       moderationEntity.ActionRoleChanged,
+		TargetUserId: &user.ID,
+	})
+	require.NoError(t, err)
+	got, err = userRepo.GetUserById(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, userEntity.RoleModerator, got.Role)
+
+	logs, err := moderationRepo.SelectAuditLogs(moderation.AuditFilter{TargetUserId: user.ID})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Nil(t, logs[0].ModeratorId, "system action has no moderator")
+
+	err = moderationRepo.UpdateRole(util.RandomUUID(), userEntity.RoleAdmin, moderationEntity.AuditLog{
+		Action: moderationEntity.ActionRoleChanged,
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, common.ErrResourceNotFound)
+}
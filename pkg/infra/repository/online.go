@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -59,7 +60,7 @@ func (o *OnlineCon) InsertNewOnline(on onlineEntities.DTO) error {
 func (o *OnlineCon) SelectOnline(userId string) (onlineEntities.DTO, error) {
 	query := `
 	SELECT
-		user_id, last_online, is_online
+		user_id, last_online, is_online, visibility, last_seen_precision
 	FROM onlines
 	WHERE user_id = $1`
 
@@ -114,3 +115,83 @@ func (o *OnlineCon) UpdateOnline(userId string, online bool) error {
 	return nil
 
 }
+
+func (o *OnlineCon) UpdateHeartbeat(userId string, at time.Time) (bool, error) {
+	query := `
+	UPDATE onlines AS o SET
+		is_online = TRUE,
+		last_heartbeat = $1
+	FROM (
+		SELECT user_id, is_online
+		FROM onlines
+		WHERE user_id = $2
+		FOR UPDATE
+	) AS prev
+	WHERE o.user_id = prev.user_id
+	RETURNING prev.is_online`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var wasOnline bool
+	err := o.conn.GetContext(ctx, &wasOnline, query, at, userId)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return false, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, common.WrapError(err, common.ErrResourceNotFound)
+		}
+		return false, err
+	}
+	return wasOnline, nil
+}
+
+func (o *OnlineCon) UpdateStaleOffline(staleBefore time.Time) ([]onlineEntities.DTO, error) {
+	query := `
+	UPDATE onlines SET
+		is_online = FALSE,
+		last_online = COALESCE(last_heartbeat, last_online)
+	WHERE is_online
+		AND (last_heartbeat IS NULL OR last_heartbeat < $1)
+	RETURNING user_id, last_online, is_online`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	staleOnlines := make([]onlineEntities.DTO, 0)
+	err := o.conn.SelectContext(ctx, &staleOnlines, query, staleBefore)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	return staleOnlines, nil
+}
+
+func (o *OnlineCon) UpdateSettings(userId string, settings onlineEntities.Settings) error {
+	query := `
+	UPDATE onlines SET
+		visibility = $1,
+		last_seen_precision = $2
+	WHERE user_id = $3
+	RETURNING user_id`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var id string
+	err := o.conn.GetContext(ctx, &id, query, settings.Visibility, settings.LastSeenPrecision, userId)
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.Is(err, context.Canceled):
+			return common.WrapError(err, common.ErrTooLongAccessingDB)
+		case errors.Is(err, sql.ErrNoRows):
+			return common.WrapError(err, common.ErrResourceNotFound)
+		case errors.As(err, &pqErr) && pqErr.Code == "23503":
+			if strings.Contains(pqErr.Constraint, "visibility") {
+				return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "invalid enums on visibility")
+			}
+			return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "invalid enums on lastSeenPrecision")
+		default:
+			return err
+		}
+	}
+	return nil
+}
@@ -84,6 +84,64 @@ func Test_UpdateOnline(t *testing.T) {
 	})
 
 }
+func Test_UpdateHeartbeat(t *testing.T) {
+	repo := repository.NewOnline(testQuery)
+	t.Run("Valid Id", func(t *testing.T) {
+		user := createNewAccount(t)
+		createNewOnline(t, user.ID)
+		wasOnline, err := repo.UpdateHeartbeat(user.ID, time.Now())
+		require.NoError(t, err)
+		assert.False(t, wasOnline)
+
+		wasOnline, err = repo.UpdateHeartbeat(user.ID, time.Now())
+		require.NoError(t, err)
+		assert.True(t, wasOnline)
+
+		res, err := repo.SelectOnline(user.ID)
+		require.NoError(t, err)
+		assert.True(t, res.IsOnline)
+	})
+	t.Run("Invalid Id", func(t *testing.T) {
+		_, err := repo.UpdateHeartbeat(util.RandomUUID(), time.Now())
+		require.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+}
+
+func Test_UpdateStaleOffline(t *testing.T) {
+	repo := repository.NewOnline(testQuery)
+	staleUser := createNewAccount(t)
+	createNewOnline(t, staleUser.ID)
+	staleHeartbeat := time.Now().Add(-time.Hour)
+	_, err := repo.UpdateHeartbeat(staleUser.ID, staleHeartbeat)
+	require.NoError(t, err)
+
+	freshUser := createNewAccount(t)
+	createNewOnline(t, freshUser.ID)
+	_, err = repo.UpdateHeartbeat(freshUser.ID, time.Now())
+	require.NoError(t, err)
+
+	staleOnlines, err := repo.UpdateStaleOffline(time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	var staleIds []string
+	for _, staleOnline := range staleOnlines {
+		assert.False(t, staleOnline.IsOnline)
+		staleIds = append(staleIds, staleOnline.UserId)
+		if staleOnline.UserId == staleUser.ID {
+			assert.WithinDuration(t, staleHeartbeat, staleOnline.LastOnline, time.Second)
+		}
+	}
+	assert.Contains(t, staleIds, staleUser.ID)
+	assert.NotContains(t, staleIds, freshUser.ID)
+
+	res, err := repo.SelectOnline(staleUser.ID)
+	require.NoError(t, err)
+	assert.False(t, res.IsOnline)
+	res, err = repo.SelectOnline(freshUser.ID)
+	require.NoError(t, err)
+	assert.True(t, res.IsOnline)
+}
+
 func createNewOnline(t *testing.T, userId string) onlineEntities.DTO {
 	repo := repository.NewOnline(testQuery)
 	online := onlineEntities.DTO{
@@ -95,3 +153,42 @@ func createNewOnline(t *testing.T, userId string) onlineEntities.DTO {
 	assert.NoError(t, err)
 	return online
 }
+
+func Test_UpdateSettings(t *testing.T) {
+	repo := repository.NewOnline(testQuery)
+	t.Run("Valid Settings", func(t *testing.T) {
+		user := createNewAccount(t)
+		createNewOnline(t, user.ID)
+		res, err := repo.SelectOnline(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, onlineEntities.VisibleToEveryone, res.Visibility)
+		assert.Equal(t, onlineEntities.LastSeenExact, res.LastSeenPrecision)
+
+		err = repo.UpdateSettings(user.ID, onlineEntities.Settings{
+			Visibility:        onlineEntities.VisibleToMatches,
+			LastSeenPrecision: onlineEntities.LastSeenCoarse,
+		})
+		require.NoError(t, err)
+
+		res, err = repo.SelectOnline(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, onlineEntities.VisibleToMatches, res.Visibility)
+		assert.Equal(t, onlineEntities.LastSeenCoarse, res.LastSeenPrecision)
+	})
+	t.Run("Invalid Visibility", func(t *testing.T) {
+		user := createNewAccount(t)
+		createNewOnline(t, user.ID)
+		err := repo.UpdateSettings(user.ID, onlineEntities.Settings{
+			Visibility:        "friends",
+			LastSeenPrecision: onlineEntities.LastSeenExact,
+		})
+		require.ErrorIs(t, err, common.ErrRefNotFound23503)
+	})
+	t.Run("Invalid Id", func(t *testing.T) {
+		err := repo.UpdateSettings(util.RandomUUID(), onlineEntities.Settings{
+			Visibility:        onlineEntities.VisibleToNobody,
+			LastSeenPrecision: onlineEntities.LastSeenExact,
+		})
+		require.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+}
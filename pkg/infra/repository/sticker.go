@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/xyedo/blindate/pkg/common"
+	stickerEntity "github.com/xyedo/blindate/pkg/domain/sticker/entities"
+)
+
+func NewSticker(conn *sqlx.DB) *StickerConn {
+	return &StickerConn{
+		conn: conn,
+	}
+}
+
+type StickerConn struct {
+	conn *sqlx.DB
+}
+
+func (s *StickerConn) InsertPack(name string) (string, error) {
+	query := `INSERT INTO sticker_packs(name) VALUES($1) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var packId string
+	err := s.conn.GetContext(ctx, &packId, query, name)
+	if err != nil {
+		return "", s.packErr(err)
+	}
+	return packId, nil
+}
+
+// SelectPacks list the catalogue, archived pack and sticker is excluded unless withArchived
+func (s *StickerConn) SelectPacks(withArchived bool) ([]stickerEntity.Pack, error) {
+	packQ := `
+	SELECT
+		id,
+		name,
+		created_at,
+		archived_at
+	FROM sticker_packs
+	WHERE $1 OR archived_at IS NULL
+	ORDER BY created_at, id`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	packs := make([]stickerEntity.Pack, 0)
+	err := s.conn.SelectContext(ctx, &packs, packQ, withArchived)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	packIds := make([]string, 0, len(packs))
+	for _, pack := range packs {
+		packIds = append(packIds, pack.Id)
+	}
+	stickers, err := s.selectStickers(ctx, packIds, withArchived)
+	if err != nil {
+		return nil, err
+	}
+	for i := range packs {
+		packs[i].Stickers = stickers[packs[i].Id]
+	}
+	return packs, nil
+}
+
+// SelectPackById return the pack with every sticker, including the archived one
+func (s *StickerConn) SelectPackById(packId string) (stickerEntity.Pack, error) {
+	query := `
+	SELECT
+		id,
+		name,
+		created_at,
+		archived_at
+	FROM sticker_packs
+	WHERE id = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var pack stickerEntity.Pack
+	err := s.conn.GetContext(ctx, &pack, query, packId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return stickerEntity.Pack{}, common.WrapError(err, common.ErrResourceNotFound)
+		}
+		if errors.Is(err, context.Canceled) {
+			return stickerEntity.Pack{}, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return stickerEntity.Pack{}, err
+	}
+	stickers, err := s.selectStickers(ctx, []string{pack.Id}, true)
+	if err != nil {
+		return stickerEntity.Pack{}, err
+	}
+	pack.Stickers = stickers[pack.Id]
+	return pack, nil
+}
+
+// UpdatePack rename or (un)archive the pack, nil field is left as it is
+func (s *StickerConn) UpdatePack(packId string, update stickerEntity.UpdatePack, at time.Time) (stickerEntity.Pack, error) {
+	query := `
+	UPDATE sticker_packs SET
+		name = COALESCE($2, name),
+		archived_at = CASE
+			WHEN $3::BOOLEAN IS NULL THEN archived_at
+			WHEN $3 THEN COALESCE(archived_at, $4)
+			ELSE NULL
+		END
+	WHERE id = $1
+	RETURNING id, name, created_at, archived_at`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var pack stickerEntity.Pack
+	err := s.conn.GetContext(ctx, &pack, query, packId, update.Name, update.Archived, at)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return stickerEntity.Pack{}, common.WrapError(err, common.ErrResourceNotFound)
+		}
+		return stickerEntity.Pack{}, s.packErr(err)
+	}
+	return pack, nil
+}
+
+func (s *StickerConn) InsertSticker(sticker *stickerEntity.Sticker) error {
+	query := `
+	INSERT INTO stickers(pack_id, emoji, blob_link, media_type)
+	VALUES($1, $2, $3, $4)
+	RETURNING id, created_at`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row := s.conn.QueryRowxContext(ctx, query, sticker.PackId, sticker.Emoji, sticker.BlobLink, sticker.MediaType)
+	err := row.Scan(&sticker.Id, &sticker.CreatedAt)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			if strings.Contains(pqErr.Constraint, "pack_id") {
+				return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "sticker pack is invalid")
+			}
+			if strings.Contains(pqErr.Constraint, "media_type") {
+				return common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "provided media type is invalid")
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// ArchiveSticker hide the sticker from the catalogue, the sent chat still reference it
+func (s *StickerConn) ArchiveSticker(stickerId string, at time.Time) error {
+	query := `UPDATE stickers SET archived_at = $2 WHERE id = $1 AND archived_at IS NULL`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := s.conn.ExecContext(ctx, query, stickerId, at)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return err
+	}
+	row, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if row == 0 {
+		return common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound)
+	}
+	return nil
+}
+
+// selectStickers group the stickers of packIds by its pack
+func (s *StickerConn) selectStickers(ctx context.Context, packIds []string, withArchived bool) (map[string][]stickerEntity.Sticker, error) {
+	query := `
+	SELECT
+		id,
+		pack_id,
+		emoji,
+		blob_link,
+		media_type,
+		created_at,
+		archived_at
+	FROM stickers
+	WHERE pack_id = ANY($1) AND ($2 OR archived_at IS NULL)
+	ORDER BY created_at, id`
+
+	stickers := make([]stickerEntity.Sticker, 0)
+	err := s.conn.SelectContext(ctx, &stickers, query, pq.Array(packIds), withArchived)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	byPack := make(map[string][]stickerEntity.Sticker, len(packIds))
+	for _, packId := range packIds {
+		byPack[packId] = make([]stickerEntity.Sticker, 0)
+	}
+	for _, sticker := range stickers {
+		byPack[sticker.PackId] = append(byPack[sticker.PackId], sticker)
+	}
+	return byPack, nil
+}
+
+func (*StickerConn) packErr(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return common.WrapError(err, common.ErrTooLongAccessingDB)
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return common.WrapErrorWithMsg(err, common.ErrUniqueConstraint23505, "sticker pack with this name is already created")
+	}
+	return err
+}
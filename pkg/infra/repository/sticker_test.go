@@ -0,0 +1,161 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/common"
+	stickerEntity "github.com/xyedo/blindate/pkg/domain/sticker/entities"
+	"github.com/xyedo/blindate/pkg/infra/repository"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_InsertPack(t *testing.T) {
+	stickerRepo := repository.NewSticker(testQuery)
+	t.Run("valid", func(t *testing.T) {
+		createNewPack(t)
+	})
+	t.Run("duplicate name", func(t *testing.T) {
+		name := util.RandomString(12)
+		_, err := stickerRepo.InsertPack(name)
+		require.NoError(t, err)
+		_, err = stickerRepo.InsertPack(name)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrUniqueConstraint23505)
+	})
+}
+
+func Test_InsertSticker(t *testing.T) {
+	stickerRepo := repository.NewSticker(testQuery)
+	t.Run("valid", func(t *testing.T) {
+		sticker := createNewSticker(t, createNewPack(t))
+		assert.NotEmpty(t, sticker.Id)
+		assert.False(t, sticker.CreatedAt.IsZero())
+	})
+	t.Run("invalid pack", func(t *testing.T) {
+		err := stickerRepo.InsertSticker(&stickerEntity.Sticker{
+			PackId:    util.RandomUUID(),
+			BlobLink:  util.RandomUUID() + ".png",
+			MediaType: "image/png",
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrRefNotFound23503)
+	})
+	t.Run("invalid media type", func(t *testing.T) {
+		err := stickerRepo.InsertSticker(&stickerEntity.Sticker{
+			PackId:    createNewPack(t),
+			BlobLink:  util.RandomUUID() + ".ogg",
+			MediaType: "ogg",
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrRefNotFound23503)
+	})
+}
+
+func Test_SelectPacks(t *testing.T) {
+	stickerRepo := repository.NewSticker(testQuery)
+	activePack := createNewPack(t)
+	activeSticker := createNewSticker(t, activePack)
+	archivedSticker := createNewSticker(t, activePack)
+	err := stickerRepo.ArchiveSticker(archivedSticker.Id, time.Now())
+	require.NoError(t, err)
+	archivedPack := createNewPack(t)
+	archived := true
+	_, err = stickerRepo.UpdatePack(archivedPack, stickerEntity.UpdatePack{Archived: &archived}, time.Now())
+	require.NoError(t, err)
+
+	findPack := func(packs []stickerEntity.Pack, packId string) *stickerEntity.Pack {
+		for i := range packs {
+			if packs[i].Id == packId {
+				return &packs[i]
+			}
+		}
+		return nil
+	}
+	t.Run("catalogue exclude archived", func(t *testing.T) {
+		packs, err := stickerRepo.SelectPacks(false)
+		require.NoError(t, err)
+		assert.Nil(t, findPack(packs, archivedPack))
+		pack := findPack(packs, activePack)
+		require.NotNil(t, pack)
+		require.Len(t, pack.Stickers, 1)
+		assert.Equal(t, activeSticker.Id, pack.Stickers[0].Id)
+	})
+	t.Run("with archived", func(t *testing.T) {
+		packs, err := stickerRepo.SelectPacks(true)
+		require.NoError(t, err)
+		assert.NotNil(t, findPack(packs, archivedPack))
+		pack := findPack(packs, activePack)
+		require.NotNil(t, pack)
+		assert.Len(t, pack.Stickers, 2)
+	})
+	t.Run("by id include archived sticker", func(t *testing.T) {
+		pack, err := stickerRepo.SelectPackById(activePack)
+		require.NoError(t, err)
+		assert.Len(t, pack.Stickers, 2)
+	})
+	t.Run("by id not found", func(t *testing.T) {
+		_, err := stickerRepo.SelectPackById(util.RandomUUID())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+}
+
+func Test_UpdatePack(t *testing.T) {
+	stickerRepo := repository.NewSticker(testQuery)
+	t.Run("rename and archive", func(t *testing.T) {
+		packId := createNewPack(t)
+		name := util.RandomString(12)
+		archived := true
+		pack, err := stickerRepo.UpdatePack(packId, stickerEntity.UpdatePack{Name: &name, Archived: &archived}, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, name, pack.Name)
+		require.NotNil(t, pack.ArchivedAt)
+
+		archived = false
+		pack, err = stickerRepo.UpdatePack(packId, stickerEntity.UpdatePack{Archived: &archived}, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, name, pack.Name)
+		assert.Nil(t, pack.ArchivedAt)
+	})
+	t.Run("not found", func(t *testing.T) {
+		name := util.RandomString(12)
+		_, err := stickerRepo.UpdatePack(util.RandomUUID(), stickerEntity.UpdatePack{Name: &name}, time.Now())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+}
+
+func Test_ArchiveSticker(t *testing.T) {
+	stickerRepo := repository.NewSticker(testQuery)
+	sticker := createNewSticker(t, createNewPack(t))
+	err := stickerRepo.ArchiveSticker(sticker.Id, time.Now())
+	require.NoError(t, err)
+	err = stickerRepo.ArchiveSticker(sticker.Id, time.Now())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, common.ErrResourceNotFound)
+}
+
+func createNewPack(t *testing.T) string {
+	stickerRepo := repository.NewSticker(testQuery)
+	packId, err := stickerRepo.InsertPack(util.RandomString(12))
+	require.NoError(t, err)
+	require.NotEmpty(t, packId)
+	return packId
+}
+
+func createNewSticker(t *testing.T, packId string) stickerEntity.Sticker {
+	stickerRepo := repository.NewSticker(testQuery)
+	emoji := "\U0001F600"
+	sticker := stickerEntity.Sticker{
+		PackId:    packId,
+		Emoji:     &emoji,
+		BlobLink:  util.RandomUUID() + ".png",
+		MediaType: "image/png",
+	}
+	err := stickerRepo.InsertSticker(&sticker)
+	require.NoError(t, err)
+	return sticker
+}
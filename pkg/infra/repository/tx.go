@@ -8,13 +8,21 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// queryer is satisfied by both *sqlx.DB and *sqlx.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row
+}
+
 // to be more clean, use it with your class method
-func execGeneric(conn *sqlx.DB, ctx context.Context, cb func(q *sqlx.DB) error, option *sql.TxOptions) error {
+func execGeneric(conn *sqlx.DB, ctx context.Context, cb func(q queryer) error, option *sql.TxOptions) error {
 	tx, err := conn.BeginTxx(ctx, option)
 	if err != nil {
 		return err
 	}
-	err = cb(conn)
+	err = cb(tx)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("tx err : %v, rb err: %w", err, rbErr)
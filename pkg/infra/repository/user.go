@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"net/http"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -94,12 +95,26 @@ func (u *UserCon) UpdateUser(user userEntity.FullDTO) error {
 	return nil
 }
 
+// selectRestriction is the active ban and the end of the longest active suspension, expect users to be selected
+var selectRestriction = `
+	EXISTS (
+		SELECT 1
+		FROM suspensions s
+		WHERE s.user_id = users.id AND s.lifted_at IS NULL AND s.ends_at IS NULL
+	) AS banned,
+	(
+		SELECT MAX(s.ends_at)
+		FROM suspensions s
+		WHERE s.user_id = users.id AND s.lifted_at IS NULL AND s.ends_at > NOW()
+	) AS suspended_until`
+
 func (u *UserCon) GetUserById(id string) (userEntity.FullDTO, error) {
 	query := `
 		SELECT 
-			id, alias, full_name, email, "password",active, dob, created_at, updated_at
+			id, alias, full_name, email, "password",active, role, dob, created_at, updated_at,
+			deletion_requested_at, snoozed_at,` + selectRestriction + `
 		FROM users
-		WHERE id = $1`
+		WHERE id = $1 AND purged_at IS NULL`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -120,8 +135,8 @@ func (u *UserCon) GetUserById(id string) (userEntity.FullDTO, error) {
 func (u *UserCon) GetUserByEmail(email string) (userEntity.FullDTO, error) {
 	query := `
 		SELECT 
-			id, email, "password"
-		FROM users WHERE email = $1`
+			id, email, "password", role, deletion_requested_at,` + selectRestriction + `
+		FROM users WHERE email = $1 AND purged_at IS NULL`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -140,15 +155,32 @@ func (u *UserCon) GetUserByEmail(email string) (userEntity.FullDTO, error) {
 	return user, nil
 }
 
-func (u *UserCon) CreateProfilePicture(userId, pictureRef string, selected bool) (string, error) {
+// CreateProfilePicture append the picture after the last position, the other picture is unselected when it is selected
+func (u *UserCon) CreateProfilePicture(profPic userEntity.ProfilePic) (string, error) {
+	unselectQ := `
+	UPDATE profile_picture SET
+		selected = false
+	WHERE user_id = $1 AND selected`
 	query := `
-	INSERT INTO profile_picture(user_id,selected,picture_ref)
-	VALUES($1,$2,$3) RETURNING id`
+	INSERT INTO profile_picture(user_id, selected, picture_ref, medium_ref, thumbnail_ref, position)
+	VALUES($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), (
+		SELECT COALESCE(MAX(position) + 1, 0)
+		FROM profile_picture
+		WHERE user_id = $1
+	)) RETURNING id`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	var id string
-	err := u.conn.GetContext(ctx, &id, query, userId, selected, pictureRef)
+	err := u.execTx(ctx, func(q queryer) error {
+		if profPic.Selected {
+			_, err := q.ExecContext(ctx, unselectQ, profPic.UserId)
+			if err != nil {
+				return err
+			}
+		}
+		return q.GetContext(ctx, &id, query, profPic.UserId, profPic.Selected, profPic.PictureLink, profPic.MediumLink, profPic.ThumbnailLink)
+	})
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			return "", common.WrapError(err, common.ErrTooLongAccessingDB)
@@ -158,6 +190,10 @@ func (u *UserCon) CreateProfilePicture(userId, pictureRef string, selected bool)
 			if pqErr.Code == "23503" {
 				return "", common.WrapErrorWithMsg(err, common.ErrRefNotFound23503, "profile picture not found")
 			}
+			// the concurrent upload took the same position or selected
+			if pqErr.Code == "23505" {
+				return "", common.WrapWithNewError(err, http.StatusConflict, "profile picture was changed concurrently, please try again")
+			}
 			return "", pqErr
 		}
 		return "", err
@@ -170,7 +206,10 @@ func (u *UserCon) SelectProfilePicture(userId string, params *user.ProfilePicQue
 		id,
 		user_id,
 		selected,
-		picture_ref 
+		picture_ref,
+		COALESCE(medium_ref, picture_ref) AS medium_ref,
+		COALESCE(thumbnail_ref, picture_ref) AS thumbnail_ref,
+		position
 	FROM profile_picture 
 	WHERE user_id =$1`
 	args := []any{userId}
@@ -181,7 +220,7 @@ func (u *UserCon) SelectProfilePicture(userId string, params *user.ProfilePicQue
 		}
 
 	}
-	query += ` ORDER BY id ASC`
+	query += ` ORDER BY position ASC`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -221,4 +260,285 @@ func (u *UserCon) ProfilePicSelectedToFalse(userId string) (int64, error) {
 
 }
 
-// func (u *userCon) DeleteProfilePic(userId string, id)
+// UpdateSelectedProfilePicture select id and unselect the other picture of userId atomically
+func (u *UserCon) UpdateSelectedProfilePicture(userId, id string) error {
+	unselectQ := `
+	UPDATE profile_picture SET
+		selected = false
+	WHERE user_id = $1 AND selected AND id <> $2`
+	selectQ := `
+	UPDATE profile_picture SET
+		selected = true
+	WHERE user_id = $1 AND id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := u.execTx(ctx, func(q queryer) error {
+		_, err := q.ExecContext(ctx, unselectQ, userId, id)
+		if err != nil {
+			return err
+		}
+		res, err := q.ExecContext(ctx, selectQ, userId, id)
+		if err != nil {
+			return err
+		}
+		row, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if row == 0 {
+			return common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "profile picture not found")
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return common.WrapWithNewError(err, http.StatusConflict, "profile picture was changed concurrently, please try again")
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateProfilePicturePosition order the picture of userId following ids, ids must hold every picture of the user
+func (u *UserCon) UpdateProfilePicturePosition(userId string, ids []string) error {
+	query := `
+	UPDATE profile_picture AS p SET
+		position = o.position - 1
+	FROM unnest($2::INT[]) WITH ORDINALITY AS o(id, position)
+	WHERE p.id = o.id AND p.user_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := u.execTx(ctx, func(q queryer) error {
+		res, err := q.ExecContext(ctx, query, userId, pq.Array(ids))
+		if err != nil {
+			return err
+		}
+		row, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		// the picture is deleted or uploaded concurrently
+		if row != int64(len(ids)) {
+			return common.WrapError(sql.ErrNoRows, common.ErrTooLongAccessingDB)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return common.WrapWithNewError(err, http.StatusConflict, "profile picture was changed concurrently, please try again")
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteProfilePicture close the position gap, the first picture is selected when the selected one is deleted
+func (u *UserCon) DeleteProfilePicture(userId, id string) (userEntity.ProfilePic, error) {
+	deleteQ := `
+	DELETE FROM profile_picture
+	WHERE user_id = $1 AND id = $2
+	RETURNING id, user_id, selected, picture_ref,
+		COALESCE(medium_ref, picture_ref) AS medium_ref,
+		COALESCE(thumbnail_ref, picture_ref) AS thumbnail_ref,
+		position`
+	shiftQ := `
+	UPDATE profile_picture SET
+		position = position - 1
+	WHERE user_id = $1 AND position > $2`
+	selectFirstQ := `
+	UPDATE profile_picture SET
+		selected = true
+	WHERE user_id = $1 AND position = 0`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var deleted userEntity.ProfilePic
+	err := u.execTx(ctx, func(q queryer) error {
+		err := q.GetContext(ctx, &deleted, deleteQ, userId, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return common.WrapErrorWithMsg(err, common.ErrResourceNotFound, "profile picture not found")
+			}
+			return err
+		}
+		_, err = q.ExecContext(ctx, shiftQ, userId, deleted.Position)
+		if err != nil {
+			return err
+		}
+		if deleted.Selected {
+			_, err = q.ExecContext(ctx, selectFirstQ, userId)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return userEntity.ProfilePic{}, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return userEntity.ProfilePic{}, err
+	}
+	return deleted, nil
+}
+
+func (u *UserCon) UpdateDeletionRequest(userId string, requestedAt *time.Time) error {
+	query := `UPDATE users SET deletion_requested_at = $2 WHERE id = $1 AND purged_at IS NULL`
+	return u.updateAccountState(query, userId, requestedAt)
+}
+
+func (u *UserCon) UpdateSnooze(userId string, snoozedAt *time.Time) error {
+	query := `UPDATE users SET snoozed_at = $2 WHERE id = $1 AND purged_at IS NULL`
+	return u.updateAccountState(query, userId, snoozedAt)
+}
+
+func (u *UserCon) updateAccountState(query, userId string, at *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := u.conn.ExecContext(ctx, query, userId, at)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "user not found")
+	}
+	return nil
+}
+
+// SelectPurgeable list the user which deletion is requested before requestedBefore, the oldest request first
+func (u *UserCon) SelectPurgeable(requestedBefore time.Time, limit int) ([]string, error) {
+	query := `
+	SELECT id
+	FROM users
+	WHERE deletion_requested_at < $1 AND purged_at IS NULL
+	ORDER BY deletion_requested_at
+	LIMIT $2`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userIds := make([]string, 0)
+	err := u.conn.SelectContext(ctx, &userIds, query, requestedBefore, limit)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	return userIds, nil
+}
+
+// PurgeUser keep the users row as an anonymous tombstone, so the chat it authored stay in the partner conversation
+// as a deleted user. every other personal row is deleted, the attachment it sent is turned into a tombstone
+// and the unaccepted match is dropped. the blob keys is returned to be deleted once committed
+func (u *UserCon) PurgeUser(userId string, purgedAt time.Time) ([]string, error) {
+	anonymizeQ := `
+	UPDATE users SET
+		full_name = 'Deleted user',
+		alias = 'Deleted user',
+		email = id::TEXT || '@deleted.invalid',
+		"password" = '',
+		dob = '1900-01-01',
+		active = FALSE,
+		role = 'user',
+		snoozed_at = NULL,
+		purged_at = $2,
+		updated_at = $2
+	WHERE id = $1 AND deletion_requested_at IS NOT NULL AND purged_at IS NULL`
+	blobQ := `
+	SELECT blob_key FROM (
+		SELECT unnest(ARRAY[picture_ref, medium_ref, thumbnail_ref])::TEXT AS blob_key
+		FROM profile_picture
+		WHERE user_id = $1
+		UNION ALL
+		SELECT unnest(ARRAY[media.blob_link, media.thumbnail_link])::TEXT
+		FROM media
+		JOIN chats
+			ON chats.id = media.chat_id
+		WHERE chats.author = $1
+		UNION ALL
+		SELECT blob_key::TEXT
+		FROM data_exports
+		WHERE user_id = $1
+	) AS blobs
+	WHERE blob_key IS NOT NULL`
+	tombstoneQ := `
+	UPDATE chats SET
+		messages = '',
+		deleted_at = COALESCE(deleted_at, $2)
+	WHERE author = $1 AND id IN (SELECT chat_id FROM media)`
+	purgeQ := []string{
+		`DELETE FROM media USING chats WHERE media.chat_id = chats.id AND chats.author = $1`,
+		`DELETE FROM chat_edits USING chats WHERE chat_edits.chat_id = chats.id AND chats.author = $1`,
+		`DELETE FROM chat_reactions WHERE user_id = $1`,
+		`DELETE FROM match WHERE (request_from = $1 OR request_to = $1) AND request_status != 'accepted'`,
+		`DELETE FROM conversation_states WHERE user_id = $1`,
+		`DELETE FROM profile_picture WHERE user_id = $1`,
+		`DELETE FROM basic_info WHERE user_id = $1`,
+		`DELETE FROM locations WHERE user_id = $1`,
+		`DELETE FROM interests WHERE user_id = $1`,
+		`DELETE FROM onlines WHERE user_id = $1`,
+		`DELETE FROM blocks WHERE blocker_id = $1 OR blocked_id = $1`,
+		`DELETE FROM data_exports WHERE user_id = $1`,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	blobKeys := make([]string, 0)
+	err := u.execTx(ctx, func(q queryer) error {
+		res, err := q.ExecContext(ctx, anonymizeQ, userId, purgedAt)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "user is not pending deletion")
+		}
+		err = q.SelectContext(ctx, &blobKeys, blobQ, userId)
+		if err != nil {
+			return err
+		}
+		_, err = q.ExecContext(ctx, tombstoneQ, userId, purgedAt)
+		if err != nil {
+			return err
+		}
+		for _, query := range purgeQ {
+			_, err = q.ExecContext(ctx, query, userId)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, common.WrapError(err, common.ErrTooLongAccessingDB)
+		}
+		return nil, err
+	}
+	return blobKeys, nil
+}
+
+func (u *UserCon) execTx(ctx context.Context, q func(q queryer) error) error {
+	return execGeneric(u.conn, ctx, q, &sql.TxOptions{Isolation: sql.LevelReadCommitted, ReadOnly: false})
+}
@@ -2,6 +2,7 @@ package repository_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -108,26 +109,56 @@ func Test_CreateProfilePicture(t *testing.T) {
 	repo := repository.NewUser(testQuery)
 	t.Run("create valid pp", func(t *testing.T) {
 		usr := createNewAccount(t)
-		id, err := repo.CreateProfilePicture(usr.ID, util.RandomUUID()+".png", true)
+		id, err := repo.CreateProfilePicture(userEntity.ProfilePic{UserId: usr.ID, PictureLink: util.RandomUUID() + ".png", Selected: true})
 		require.NoError(t, err)
 		assert.NotEmpty(t, id)
 	})
 	t.Run("create valid pp but not false selected", func(t *testing.T) {
 		usr := createNewAccount(t)
-		id, err := repo.CreateProfilePicture(usr.ID, util.RandomUUID()+".png", false)
+		id, err := repo.CreateProfilePicture(userEntity.ProfilePic{UserId: usr.ID, PictureLink: util.RandomUUID() + ".png", Selected: false})
 		require.NoError(t, err)
 		assert.NotEmpty(t, id)
 	})
 	t.Run("create multiple profpic", func(t *testing.T) {
 		usr := createNewAccount(t)
 		for i := 0; i < 3; i++ {
-			id, err := repo.CreateProfilePicture(usr.ID, util.RandomUUID()+".png", false)
+			id, err := repo.CreateProfilePicture(userEntity.ProfilePic{UserId: usr.ID, PictureLink: util.RandomUUID() + ".png", Selected: false})
 			require.NoError(t, err)
 			assert.NotEmpty(t, id)
 		}
 	})
+	t.Run("create with renditions", func(t *testing.T) {
+		usr := createNewAccount(t)
+		profPic := userEntity.ProfilePic{
+			UserId:        usr.ID,
+			PictureLink:   "profile-picture/" + util.RandomUUID() + ".jpg",
+			MediumLink:    "profile-picture/" + util.RandomUUID() + ".jpg",
+			ThumbnailLink: "profile-picture/" + util.RandomUUID() + ".jpg",
+			Selected:      true,
+		}
+		id, err := repo.CreateProfilePicture(profPic)
+		require.NoError(t, err)
+		profPics, err := repo.SelectProfilePicture(usr.ID, nil)
+		require.NoError(t, err)
+		require.Len(t, profPics, 1)
+		assert.Equal(t, id, profPics[0].Id)
+		assert.Equal(t, profPic.PictureLink, profPics[0].PictureLink)
+		assert.Equal(t, profPic.MediumLink, profPics[0].MediumLink)
+		assert.Equal(t, profPic.ThumbnailLink, profPics[0].ThumbnailLink)
+	})
+	t.Run("without renditions fallback to the full picture", func(t *testing.T) {
+		usr := createNewAccount(t)
+		pictureRef := "profile-picture/" + util.RandomUUID() + ".png"
+		_, err := repo.CreateProfilePicture(userEntity.ProfilePic{UserId: usr.ID, PictureLink: pictureRef})
+		require.NoError(t, err)
+		profPics, err := repo.SelectProfilePicture(usr.ID, nil)
+		require.NoError(t, err)
+		require.Len(t, profPics, 1)
+		assert.Equal(t, pictureRef, profPics[0].MediumLink)
+		assert.Equal(t, pictureRef, profPics[0].ThumbnailLink)
+	})
 	t.Run("invalid userId", func(t *testing.T) {
-		id, err := repo.CreateProfilePicture(util.RandomUUID(), util.RandomUUID()+".png", false)
+		id, err := repo.CreateProfilePicture(userEntity.ProfilePic{UserId: util.RandomUUID(), PictureLink: util.RandomUUID() + ".png", Selected: false})
 		require.Error(t, err)
 		assert.Empty(t, id)
 		assert.ErrorIs(t, err, common.ErrRefNotFound23503)
@@ -140,7 +171,7 @@ func Test_SelectProfilePic(t *testing.T) {
 	setupFunc := func(t *testing.T) string {
 		usr := createNewAccount(t)
 		for i := 0; i < 3; i++ {
-			id, err := repo.CreateProfilePicture(usr.ID, util.RandomUUID()+".png", false)
+			id, err := repo.CreateProfilePicture(userEntity.ProfilePic{UserId: usr.ID, PictureLink: util.RandomUUID() + ".png", Selected: false})
 			require.NoError(t, err)
 			assert.NotEmpty(t, id)
 		}
@@ -166,7 +197,7 @@ func Test_SelectProfilePic(t *testing.T) {
 	})
 	t.Run("valid Select with Params > Return 1", func(t *testing.T) {
 		userId := setupFunc(t)
-		id, err := repo.CreateProfilePicture(userId, util.RandomUUID()+".png", true)
+		id, err := repo.CreateProfilePicture(userEntity.ProfilePic{UserId: userId, PictureLink: util.RandomUUID() + ".png", Selected: true})
 		require.NoError(t, err)
 		require.NotEmpty(t, id)
 		selected := true
@@ -182,7 +213,7 @@ func Test_ProfilePicSelectedToFalse(t *testing.T) {
 	repo := repository.NewUser(testQuery)
 	usr := createNewAccount(t)
 	for i := 0; i < 3; i++ {
-		id, err := repo.CreateProfilePicture(usr.ID, util.RandomUUID()+".png", true)
+		id, err := repo.CreateProfilePicture(userEntity.ProfilePic{UserId: usr.ID, PictureLink: util.RandomUUID() + ".png", Selected: true})
 		require.NoError(t, err)
 		assert.NotEmpty(t, id)
 	}
@@ -191,12 +222,12 @@ func Test_ProfilePicSelectedToFalse(t *testing.T) {
 		Selected: &selected,
 	})
 	require.NoError(t, err)
-	require.Equal(t, len(profpics), 3)
+	require.Equal(t, len(profpics), 1, "only the latest selected picture stay selected")
 	require.Equal(t, profpics[0].UserId, usr.ID)
 
 	row, err := repo.ProfilePicSelectedToFalse(usr.ID)
 	require.NoError(t, err)
-	require.Equal(t, row, int64(3))
+	require.Equal(t, row, int64(1))
 
 	actualProfPic, err := repo.SelectProfilePicture(usr.ID, &user.ProfilePicQuery{
 		Selected: &selected,
@@ -206,6 +237,143 @@ func Test_ProfilePicSelectedToFalse(t *testing.T) {
 
 }
 
+func Test_ProfilePicturePosition(t *testing.T) {
+	repo := repository.NewUser(testQuery)
+	setupFunc := func(t *testing.T) (string, []string) {
+		usr := createNewAccount(t)
+		ids := make([]string, 0, 3)
+		for i := 0; i < 3; i++ {
+			id, err := repo.CreateProfilePicture(userEntity.ProfilePic{UserId: usr.ID, PictureLink: util.RandomUUID() + ".png", Selected: i == 0})
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+		return usr.ID, ids
+	}
+	positionOf := func(t *testing.T, userId string) []string {
+		profPics, err := repo.SelectProfilePicture(userId, nil)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(profPics))
+		for i, profPic := range profPics {
+			assert.Equal(t, i, profPic.Position)
+			ids = append(ids, profPic.Id)
+		}
+		return ids
+	}
+	selectedOf := func(t *testing.T, userId string) string {
+		selected := true
+		profPics, err := repo.SelectProfilePicture(userId, &user.ProfilePicQuery{
+			Selected: &selected,
+		})
+		require.NoError(t, err)
+		require.Len(t, profPics, 1)
+		return profPics[0].Id
+	}
+
+	t.Run("appended in upload order", func(t *testing.T) {
+		userId, ids := setupFunc(t)
+		assert.Equal(t, ids, positionOf(t, userId))
+	})
+	t.Run("select", func(t *testing.T) {
+		userId, ids := setupFunc(t)
+		err := repo.UpdateSelectedProfilePicture(userId, ids[2])
+		require.NoError(t, err)
+		assert.Equal(t, ids[2], selectedOf(t, userId))
+	})
+	t.Run("select other user picture", func(t *testing.T) {
+		userId, ids := setupFunc(t)
+		other, _ := setupFunc(t)
+		err := repo.UpdateSelectedProfilePicture(other, ids[2])
+		require.Error(t, err)
+		apiErr, ok := err.(common.APIError)
+		require.True(t, ok)
+		status, msg := apiErr.APIError()
+		assert.Equal(t, 404, status)
+		assert.Equal(t, "profile picture not found", msg)
+		assert.Equal(t, ids[0], selectedOf(t, userId))
+	})
+	t.Run("reorder", func(t *testing.T) {
+		userId, ids := setupFunc(t)
+		reordered := []string{ids[2], ids[0], ids[1]}
+		err := repo.UpdateProfilePicturePosition(userId, reordered)
+		require.NoError(t, err)
+		assert.Equal(t, reordered, positionOf(t, userId))
+	})
+	t.Run("reorder with stale ids", func(t *testing.T) {
+		userId, ids := setupFunc(t)
+		err := repo.UpdateProfilePicturePosition(userId, []string{ids[1], ids[0], "0"})
+		require.Error(t, err)
+		assert.Equal(t, ids, positionOf(t, userId))
+	})
+	t.Run("delete compact the position and keep a selected picture", func(t *testing.T) {
+		userId, ids := setupFunc(t)
+		deleted, err := repo.DeleteProfilePicture(userId, ids[0])
+		require.NoError(t, err)
+		assert.Equal(t, ids[0], deleted.Id)
+		assert.NotEmpty(t, deleted.PictureLink)
+		assert.Equal(t, ids[1:], positionOf(t, userId))
+		assert.Equal(t, ids[1], selectedOf(t, userId))
+	})
+	t.Run("delete not found", func(t *testing.T) {
+		userId, _ := setupFunc(t)
+		_, err := repo.DeleteProfilePicture(userId, "0")
+		require.Error(t, err)
+	})
+}
+
+func Test_AccountDeletion(t *testing.T) {
+	repo := repository.NewUser(testQuery)
+
+	t.Run("snooze", func(t *testing.T) {
+		user := createNewAccount(t)
+		snoozedAt := time.Now()
+		err := repo.UpdateSnooze(user.ID, &snoozedAt)
+		require.NoError(t, err)
+		got, err := repo.GetUserById(user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got.SnoozedAt)
+
+		err = repo.UpdateSnooze(user.ID, nil)
+		require.NoError(t, err)
+		got, err = repo.GetUserById(user.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.SnoozedAt)
+
+		err = repo.UpdateSnooze(util.RandomUUID(), &snoozedAt)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+	t.Run("only the pending account is purged", func(t *testing.T) {
+		user := createNewAccount(t)
+		_, err := repo.PurgeUser(user.ID, time.Now())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+	t.Run("purge leave an anonymous tombstone", func(t *testing.T) {
+		user := createNewAccount(t)
+		requestedAt := time.Now().Add(-time.Hour)
+		err := repo.UpdateDeletionRequest(user.ID, &requestedAt)
+		require.NoError(t, err)
+
+		userIds, err := repo.SelectPurgeable(time.Now(), 1000)
+		require.NoError(t, err)
+		assert.Contains(t, userIds, user.ID)
+
+		_, err = repo.PurgeUser(user.ID, time.Now())
+		require.NoError(t, err)
+
+		_, err = repo.GetUserById(user.ID)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+		_, err = repo.GetUserByEmail(user.Email)
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+
+		userIds, err = repo.SelectPurgeable(time.Now(), 1000)
+		require.NoError(t, err)
+		assert.NotContains(t, userIds, user.ID)
+
+		_, err = repo.PurgeUser(user.ID, time.Now())
+		assert.ErrorIs(t, err, common.ErrResourceNotFound)
+	})
+}
+
 func createNewAccount(t *testing.T) userEntity.FullDTO {
 	repo := repository.NewUser(testQuery)
 	hashed, err := bcrypt.GenerateFromPassword([]byte(util.RandomString(12)), 12)
@@ -14,10 +14,9 @@ import (
 )
 
 type Config struct {
-	Port       int
-	Env        string
-	BucketName string
-	DbConf     struct {
+	Port   int
+	Env    string
+	DbConf struct {
 		Dsn          string
 		MaxOpenConns int
 		MaxIdleConns int
@@ -29,6 +28,83 @@ type Config struct {
 		AccessExpires  string
 		RefreshExpires string
 	}
+	Presence struct {
+		HeartbeatTimeout time.Duration
+		// SweepInterval of the stale online sweeper, zero disable it
+		SweepInterval time.Duration
+	}
+	Ws struct {
+		// HistoryTtl is how long the event is kept for resume after the user disconnect
+		HistoryTtl time.Duration
+		// HistorySweepInterval of the history sweeper, zero disable it
+		HistorySweepInterval time.Duration
+	}
+	Cors struct {
+		TrustedOrigins   []string
+		AllowCredentials bool
+		MaxAge           time.Duration
+	}
+	Admin struct {
+		UserIds []string
+	}
+	Attachment struct {
+		// Driver is s3, local or memory
+		Driver     string
+		UrlExpires time.Duration
+		S3         struct {
+			Bucket    string
+			Region    string
+			Endpoint  string
+			PathStyle bool
+		}
+		Local struct {
+			Dir     string
+			BaseUrl string
+		}
+		// SigningSecret sign the url of local and memory driver
+		SigningSecret string
+	}
+	BlobGc struct {
+		// Interval of the orphaned blob collector, zero disable it
+		Interval time.Duration
+		Grace    time.Duration
+		DryRun   bool
+		// Report print the orphaned blob once and exit
+		Report bool
+	}
+	Account struct {
+		// DeletionGrace is the delay before the account requested for deletion is purged
+		DeletionGrace time.Duration
+		// PurgeInterval of the account purger, zero disable it
+		PurgeInterval time.Duration
+	}
+	Export struct {
+		// Interval of the exporter, zero disable it
+		Interval time.Duration
+		// Ttl is how long the archive is downloadable before it is deleted
+		Ttl time.Duration
+	}
+	Reveal struct {
+		// Ttl is how long the reveal request wait for the answer, zero never expire it
+		Ttl time.Duration
+		// Mutual require both participants to request the reveal
+		Mutual bool
+		// ExpiryInterval of the reveal expirer, zero disable it
+		ExpiryInterval time.Duration
+	}
+	Media struct {
+		MaxAudioBytes    int64
+		MaxImageBytes    int64
+		MaxVideoBytes    int64
+		MaxDimension     int
+		MaxVoiceDuration time.Duration
+		MaxVideoDuration time.Duration
+		ThumbnailSize    int
+
+		ProfileFullSize      int
+		ProfileMediumSize    int
+		ProfileThumbnailSize int
+	}
 }
 
 func (cfg *Config) NewServer(route api.Route) error {
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+)
+
+type accountSvc interface {
+	RequestDeletion(userId string, input userEntity.DeleteAccount) (time.Time, error)
+	Snooze(userId string) (time.Time, error)
+	Unsnooze(userId string) error
+}
+
+func NewAccount(accountSvc accountSvc) *Account {
+	return &Account{
+		accountSvc: accountSvc,
+	}
+}
+
+type Account struct {
+	accountSvc accountSvc
+}
+
+func (a *Account) deleteAccountHandler(c *gin.Context) {
+	var input userEntity.DeleteAccount
+	if err := c.ShouldBindJSON(&input); err != nil {
+		if jsonErr := jsonBindingErrResp(err, c, map[string]string{
+			"password": "required to confirm the deletion",
+		}); jsonErr != nil {
+			errServerResp(c, jsonErr)
+			return
+		}
+		return
+	}
+	purgeAt, err := a.accountSvc.RequestDeletion(c.GetString(keyUserId), input)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":  "success",
+		"message": "account is scheduled for deletion, login before it is purged to restore it",
+		"data": gin.H{
+			"purgeAt": purgeAt,
+		},
+	})
+}
+
+func (a *Account) putSnoozeHandler(c *gin.Context) {
+	snoozedAt, err := a.accountSvc.Snooze(c.GetString(keyUserId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"snoozedAt": snoozedAt,
+		},
+	})
+}
+
+func (a *Account) deleteSnoozeHandler(c *gin.Context) {
+	err := a.accountSvc.Unsnooze(c.GetString(keyUserId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "account is visible on discovery",
+	})
+}
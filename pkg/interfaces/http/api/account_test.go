@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/applications/service"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func Test_deleteAccountHandler(t *testing.T) {
+	grace := 30 * 24 * time.Hour
+	validUser := createNewUser(t)
+	hashed, err := bcrypt.GenerateFromPassword([]byte("pa55word"), 12)
+	require.NoError(t, err)
+	validUser.Password = string(hashed)
+
+	tests := []struct {
+		name      string
+		reqBody   string
+		setupFunc func(userRepo *mockrepo.MockUser)
+		wantCode  int
+		respFunc  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "schedule the deletion",
+			reqBody: `{"password":"pa55word"}`,
+			setupFunc: func(userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Eq(validUser.ID)).Times(1).Return(validUser, nil)
+				userRepo.EXPECT().UpdateDeletionRequest(gomock.Eq(validUser.ID), gomock.Not(gomock.Nil())).Times(1).Return(nil)
+			},
+			wantCode: http.StatusAccepted,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result struct {
+					Data struct {
+						PurgeAt time.Time `json:"purgeAt"`
+					} `json:"data"`
+				}
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				require.NoError(t, err)
+				assert.WithinDuration(t, time.Now().Add(grace), result.Data.PurgeAt, time.Minute)
+			},
+		},
+		{
+			name:    "already scheduled",
+			reqBody: `{"password":"pa55word"}`,
+			setupFunc: func(userRepo *mockrepo.MockUser) {
+				requestedAt := time.Now().Add(-24 * time.Hour)
+				scheduled := validUser
+				scheduled.DeletionRequestedAt = &requestedAt
+				userRepo.EXPECT().GetUserById(gomock.Eq(validUser.ID)).Times(1).Return(scheduled, nil)
+				userRepo.EXPECT().UpdateDeletionRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusAccepted,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result struct {
+					Data struct {
+						PurgeAt time.Time `json:"purgeAt"`
+					} `json:"data"`
+				}
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				require.NoError(t, err)
+				assert.WithinDuration(t, time.Now().Add(grace-24*time.Hour), result.Data.PurgeAt, time.Minute)
+			},
+		},
+		{
+			name:    "wrong password",
+			reqBody: `{"password":"wrong-password"}`,
+			setupFunc: func(userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Eq(validUser.ID)).Times(1).Return(validUser, nil)
+				userRepo.EXPECT().UpdateDeletionRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnauthorized,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"status":"fail","message":"invalid credentials"}`, rr.Body.String())
+			},
+		},
+		{
+			name:    "missing password",
+			reqBody: `{}`,
+			setupFunc: func(userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result map[string]any
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				require.NoError(t, err)
+				errs, ok := result["errors"].(map[string]any)
+				require.True(t, ok)
+				assert.Contains(t, errs, "password")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo := mockrepo.NewMockUser(ctrl)
+			tt.setupFunc(userRepo)
+			accountH := NewAccount(service.NewAccount(userRepo, nil, grace))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/users/"+validUser.ID, strings.NewReader(tt.reqBody))
+			c.Set(keyUserId, validUser.ID)
+
+			accountH.deleteAccountHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			tt.respFunc(t, rr)
+		})
+	}
+}
+
+func Test_snoozeHandler(t *testing.T) {
+	userId := createNewUser(t).ID
+	t.Run("snooze", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		userRepo := mockrepo.NewMockUser(ctrl)
+		userRepo.EXPECT().UpdateSnooze(gomock.Eq(userId), gomock.Not(gomock.Nil())).Times(1).Return(nil)
+		accountH := NewAccount(service.NewAccount(userRepo, nil, time.Hour))
+
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/users/"+userId+"/snooze", nil)
+		c.Set(keyUserId, userId)
+
+		accountH.putSnoozeHandler(c)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+	t.Run("unsnooze", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		userRepo := mockrepo.NewMockUser(ctrl)
+		userRepo.EXPECT().UpdateSnooze(gomock.Eq(userId), gomock.Nil()).Times(1).Return(nil)
+		accountH := NewAccount(service.NewAccount(userRepo, nil, time.Hour))
+
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/users/"+userId+"/snooze", nil)
+		c.Set(keyUserId, userId)
+
+		accountH.deleteSnoozeHandler(c)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	authEntity "github.com/xyedo/blindate/pkg/domain/authentication/entities"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
 )
 
 type authSvc interface {
@@ -12,11 +13,14 @@ type authSvc interface {
 	RevalidateRefreshToken(refreshToken string) (string, error)
 	Logout(refreshToken string) error
 }
+type accessSvc interface {
+	CheckAccess(userId string) (userEntity.Role, error)
+}
 type jwtSvc interface {
-	GenerateAccessToken(id string) (string, error)
+	GenerateAccessToken(id string, role userEntity.Role) (string, error)
 	GenerateRefreshToken(id string) (string, error)
 	ValidateRefreshToken(token string) (string, error)
-	ValidateAccessToken(token string) (string, error)
+	ValidateAccessToken(token string) (string, userEntity.Role, error)
 }
 
 func NewAuth(authService authSvc) *Auth {
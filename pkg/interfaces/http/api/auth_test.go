@@ -8,12 +8,14 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/xyedo/blindate/pkg/applications/service"
 	"github.com/xyedo/blindate/pkg/common"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
 	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
 	"github.com/xyedo/blindate/pkg/util"
 	"golang.org/x/crypto/bcrypt"
@@ -64,6 +66,70 @@ func Test_postAuthHandler(t *testing.T) {
 				assert.NotZero(t, data["accessToken"])
 			},
 		},
+		{
+			name: "Restore Account Pending Deletion",
+			reqBody: `{
+				"email":"uncleBob23@cool.com",
+				"password":"pa55word"
+			}`,
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Auth {
+				authRepo := mockrepo.NewMockAuth(ctrl)
+				userRepo := mockrepo.NewMockUser(ctrl)
+
+				pendingUser := createNewUser(t)
+				hashed, err := bcrypt.GenerateFromPassword([]byte("pa55word"), 12)
+				assert.NoError(t, err)
+				pendingUser.Password = string(hashed)
+				requestedAt := time.Now().Add(-time.Hour)
+				pendingUser.DeletionRequestedAt = &requestedAt
+
+				userRepo.EXPECT().GetUserByEmail(gomock.Eq("uncleBob23@cool.com")).Times(1).Return(pendingUser, nil)
+				userRepo.EXPECT().UpdateDeletionRequest(gomock.Eq(pendingUser.ID), gomock.Nil()).Times(1).Return(nil)
+				authRepo.EXPECT().AddRefreshToken(gomock.Any()).Times(1).Return(nil)
+
+				authSvc := service.NewAuth(authRepo, userRepo, jwt)
+				return NewAuth(authSvc)
+			},
+			wantCode: http.StatusCreated,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result map[string]any
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				assert.NoError(t, err)
+				assert.Equal(t, "success", result["status"])
+			},
+		},
+		{
+			name: "Banned User",
+			reqBody: `{
+				"email":"uncleBob23@cool.com",
+				"password":"pa55word"
+			}`,
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Auth {
+				authRepo := mockrepo.NewMockAuth(ctrl)
+				userRepo := mockrepo.NewMockUser(ctrl)
+
+				bannedUser := createNewUser(t)
+				hashed, err := bcrypt.GenerateFromPassword([]byte("pa55word"), 12)
+				assert.NoError(t, err)
+				bannedUser.Password = string(hashed)
+				bannedUser.Banned = true
+
+				userRepo.EXPECT().GetUserByEmail(gomock.Eq("uncleBob23@cool.com")).Times(1).Return(bannedUser, nil)
+				authRepo.EXPECT().AddRefreshToken(gomock.Any()).Times(0)
+
+				authSvc := service.NewAuth(authRepo, userRepo, jwt)
+				return NewAuth(authSvc)
+			},
+			wantCode: http.StatusForbidden,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				respBody, err := json.Marshal(map[string]any{
+					"status":  "fail",
+					"message": "account is banned",
+				})
+				assert.NoError(t, err)
+				assert.JSONEq(t, string(respBody), rr.Body.String())
+			},
+		},
 		{
 			name: "Invalid Type Req Body",
 			reqBody: `{
@@ -185,6 +251,8 @@ func Test_putAuthHandler(t *testing.T) {
 				token, err := jwt.GenerateRefreshToken(id)
 				assert.NoError(t, err)
 				authRepo.EXPECT().VerifyRefreshToken(gomock.Eq(token)).Times(1).Return(nil)
+				userRepo.EXPECT().GetUserById(gomock.Eq(id)).Times(1).
+					Return(userEntity.FullDTO{ID: id, Role: userEntity.RoleModerator}, nil)
 
 				authSvc := service.NewAuth(authRepo, userRepo, jwt)
 				return NewAuth(authSvc), token
@@ -198,7 +266,56 @@ func Test_putAuthHandler(t *testing.T) {
 				assert.Equal(t, "success", result["status"])
 				data, ok := result["data"].(map[string]any)
 				assert.True(t, ok)
-				assert.NotZero(t, data["accessToken"])
+				accessToken, ok := data["accessToken"].(string)
+				assert.True(t, ok)
+				_, role, err := jwt.ValidateAccessToken(accessToken)
+				assert.NoError(t, err)
+				assert.Equal(t, userEntity.RoleModerator, role)
+			},
+		},
+		{
+			name: "Suspended User",
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) (*Auth, string) {
+				authRepo := mockrepo.NewMockAuth(ctrl)
+				userRepo := mockrepo.NewMockUser(ctrl)
+				id := util.RandomUUID()
+				token, err := jwt.GenerateRefreshToken(id)
+				assert.NoError(t, err)
+				until := time.Now().Add(24 * time.Hour)
+				authRepo.EXPECT().VerifyRefreshToken(gomock.Eq(token)).Times(1).Return(nil)
+				userRepo.EXPECT().GetUserById(gomock.Eq(id)).Times(1).
+					Return(userEntity.FullDTO{ID: id, Role: userEntity.RoleUser, SuspendedUntil: &until}, nil)
+
+				authSvc := service.NewAuth(authRepo, userRepo, jwt)
+				return NewAuth(authSvc), token
+			},
+			wantCode: http.StatusForbidden,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result map[string]any
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				assert.NoError(t, err)
+				assert.Contains(t, result["message"], "account is suspended until")
+			},
+		},
+		{
+			name: "Account Pending Deletion",
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) (*Auth, string) {
+				authRepo := mockrepo.NewMockAuth(ctrl)
+				userRepo := mockrepo.NewMockUser(ctrl)
+				id := util.RandomUUID()
+				token, err := jwt.GenerateRefreshToken(id)
+				assert.NoError(t, err)
+				requestedAt := time.Now().Add(-time.Hour)
+				authRepo.EXPECT().VerifyRefreshToken(gomock.Eq(token)).Times(1).Return(nil)
+				userRepo.EXPECT().GetUserById(gomock.Eq(id)).Times(1).
+					Return(userEntity.FullDTO{ID: id, Role: userEntity.RoleUser, DeletionRequestedAt: &requestedAt}, nil)
+
+				authSvc := service.NewAuth(authRepo, userRepo, jwt)
+				return NewAuth(authSvc), token
+			},
+			wantCode: http.StatusForbidden,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"status":"fail","message":"account is scheduled for deletion, login to restore it"}`, rr.Body.String())
 			},
 		},
 		{
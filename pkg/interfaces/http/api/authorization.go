@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyedo/blindate/pkg/common"
+	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+)
+
+type participantSvc interface {
+	GetMatchById(matchId string) (matchEntity.MatchDAO, error)
+}
+
+type chatOwnerSvc interface {
+	GetMessageById(chatId string) (chatEntity.DTO, error)
+}
+
+func NewAuthorizer(participantSvc participantSvc, chatOwnerSvc chatOwnerSvc) *Authorizer {
+	return &Authorizer{
+		participantSvc: participantSvc,
+		chatOwnerSvc:   chatOwnerSvc,
+	}
+}
+
+// Authorizer load the resource of the url once per request and evaluate the route policies against it.
+// missing resource is answered with 404, denied policy with 403
+type Authorizer struct {
+	participantSvc participantSvc
+	chatOwnerSvc   chatOwnerSvc
+}
+
+// resource is what the policies evaluated against
+type resource struct {
+	UserId       string
+	Participants matchEntity.MatchDAO
+	Chat         *chatEntity.DTO
+}
+
+// policy return the reason the user is denied, or empty string when allowed
+type policy interface {
+	deny(res resource) string
+}
+
+type policyFunc func(res resource) string
+
+func (f policyFunc) deny(res resource) string {
+	return f(res)
+}
+
+var isParticipant policyFunc = func(res resource) string {
+	if res.UserId == res.Participants.RequestFrom || res.UserId == res.Participants.RequestTo {
+		return ""
+	}
+	return "you are not part of this conversation"
+}
+
+var isChatAuthor policyFunc = func(res resource) string {
+	if res.Chat != nil && res.Chat.Author == res.UserId {
+		return ""
+	}
+	return "only the author can modify this chat"
+}
+
+// conversation must be placed after validateConversation, the participants is cached for the next middleware
+func (a *Authorizer) conversation(policies ...policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		participants, ok := a.participants(c)
+		if !ok {
+			return
+		}
+		res := resource{
+			UserId:       c.GetString(keyUserId),
+			Participants: participants,
+		}
+		if !evaluate(c, res, policies) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// chat must be placed after validateChat inside conversation group, chat from another conversation is not found
+func (a *Authorizer) chat(policies ...policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		participants, ok := a.participants(c)
+		if !ok {
+			return
+		}
+		chat, err := a.chatOwnerSvc.GetMessageById(c.GetString(keyChatId))
+		if err != nil {
+			if errors.Is(err, common.ErrResourceNotFound) {
+				errNotFoundResp(c, "chat not found")
+				return
+			}
+			jsonHandleError(c, err)
+			return
+		}
+		if chat.ConversationId != c.GetString(keyConvId) {
+			errNotFoundResp(c, "chat not found")
+			return
+		}
+		res := resource{
+			UserId:       c.GetString(keyUserId),
+			Participants: participants,
+			Chat:         &chat,
+		}
+		if !evaluate(c, res, policies) {
+			return
+		}
+		c.Next()
+	}
+}
+
+func (a *Authorizer) participants(c *gin.Context) (matchEntity.MatchDAO, bool) {
+	if cached, ok := c.Get(keyParticipants); ok {
+		return cached.(matchEntity.MatchDAO), true
+	}
+	participants, err := a.participantSvc.GetMatchById(c.GetString(keyConvId))
+	if err != nil {
+		if errors.Is(err, common.ErrResourceNotFound) {
+			errNotFoundResp(c, "conversation not found")
+			return matchEntity.MatchDAO{}, false
+		}
+		jsonHandleError(c, err)
+		return matchEntity.MatchDAO{}, false
+	}
+	c.Set(keyParticipants, participants)
+	return participants, true
+}
+
+func evaluate(c *gin.Context, res resource, policies []policy) bool {
+	for _, p := range policies {
+		if reason := p.deny(res); reason != "" {
+			errForbiddenResp(c, reason)
+			return false
+		}
+	}
+	return true
+}
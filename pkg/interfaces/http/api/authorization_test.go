@@ -0,0 +1,223 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/applications/service"
+	"github.com/xyedo/blindate/pkg/common"
+	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_Authorizer(t *testing.T) {
+	validConvId := util.RandomUUID()
+	validUserId := util.RandomUUID()
+	partnerId := util.RandomUUID()
+	validChatId := util.RandomUUID()
+	validMatch := matchEntity.MatchDAO{
+		Id:            validConvId,
+		RequestFrom:   validUserId,
+		RequestTo:     partnerId,
+		RequestStatus: string(matchEntity.Accepted),
+	}
+	chatBy := func(author, convId string) chatEntity.DAO {
+		return chatEntity.DAO{
+			Id:             validChatId,
+			ConversationId: convId,
+			Author:         author,
+			Messages:       util.RandomString(12),
+			SentAt:         time.Now(),
+		}
+	}
+
+	tests := []struct {
+		name      string
+		userId    string
+		url       string
+		setupFunc func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:   "participant access conversation",
+			userId: validUserId,
+			url:    fmt.Sprintf("/%s/", validConvId),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().SelectChatById(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:   "partner access conversation",
+			userId: partnerId,
+			url:    fmt.Sprintf("/%s/", validConvId),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().SelectChatById(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:   "conversationId not uuid",
+			userId: validUserId,
+			url:    fmt.Sprintf("/%s/", util.RandomString(12)),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Any()).Times(0)
+				chatRepo.EXPECT().SelectChatById(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:   "conversation not found",
+			userId: validUserId,
+			url:    fmt.Sprintf("/%s/", validConvId),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).
+					Return(matchEntity.MatchDAO{}, common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound))
+				chatRepo.EXPECT().SelectChatById(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "conversation not found",
+			},
+		},
+		{
+			name:   "not participant of conversation",
+			userId: util.RandomUUID(),
+			url:    fmt.Sprintf("/%s/", validConvId),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().SelectChatById(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "you are not part of this conversation",
+			},
+		},
+		{
+			name:   "author access chat with participants loaded once",
+			userId: validUserId,
+			url:    fmt.Sprintf("/%s/chat/%s", validConvId, validChatId),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(validChatId)).Times(1).Return(chatBy(validUserId, validConvId), nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:   "chatId not uuid",
+			userId: validUserId,
+			url:    fmt.Sprintf("/%s/chat/%s", validConvId, util.RandomString(12)),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().SelectChatById(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:   "chat not found",
+			userId: validUserId,
+			url:    fmt.Sprintf("/%s/chat/%s", validConvId, validChatId),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(validChatId)).Times(1).
+					Return(chatEntity.DAO{}, common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound))
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "chat not found",
+			},
+		},
+		{
+			name:   "chat from another conversation",
+			userId: validUserId,
+			url:    fmt.Sprintf("/%s/chat/%s", validConvId, validChatId),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(validChatId)).Times(1).Return(chatBy(validUserId, util.RandomUUID()), nil)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "chat not found",
+			},
+		},
+		{
+			name:   "partner cannot modify chat",
+			userId: partnerId,
+			url:    fmt.Sprintf("/%s/chat/%s", validConvId, validChatId),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(validChatId)).Times(1).Return(chatBy(validUserId, validConvId), nil)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "only the author can modify this chat",
+			},
+		},
+		{
+			name:   "outsider denied before chat is loaded",
+			userId: util.RandomUUID(),
+			url:    fmt.Sprintf("/%s/chat/%s", validConvId, validChatId),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().SelectChatById(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "you are not part of this conversation",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			chatRepo := mockrepo.NewMockChat(ctrl)
+			tt.setupFunc(t, matchRepo, chatRepo)
+			authz := NewAuthorizer(service.NewMatch(matchRepo, nil, nil, matchEntity.RevealPolicy{}), service.NewChat(chatRepo, matchRepo, testBlobUrl))
+
+			rr := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(rr)
+			setUser := func(c *gin.Context) {
+				c.Set(keyUserId, tt.userId)
+			}
+			ok := func(c *gin.Context) {
+				c.JSON(http.StatusOK, nil)
+			}
+			conv := r.Group("/:conversationId", setUser, validateConversation(), authz.conversation(isParticipant))
+			conv.GET("/", ok)
+			conv.GET("/chat/:chatId", validateChat(), authz.chat(isChatAuthor), ok)
+
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			require.NoError(t, err)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.wantResp != nil {
+				expResBody, err := json.Marshal(tt.wantResp)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+			}
+		})
+	}
+}
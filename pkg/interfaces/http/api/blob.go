@@ -0,0 +1,50 @@
+package api
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type blobStore interface {
+	VerifyUrl(key, expires, signature string) error
+	OpenBlob(key string) (io.ReadSeekCloser, error)
+}
+
+// NewBlob serve the blob of the attachment driver without its own download url, eg: local disk
+func NewBlob(blobStore blobStore) *Blob {
+	return &Blob{
+		blobStore: blobStore,
+	}
+}
+
+type Blob struct {
+	blobStore blobStore
+}
+
+func (b *Blob) getBlobHandler(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	err := b.blobStore.VerifyUrl(key, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	blob, err := b.blobStore.OpenBlob(key)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	defer func(blob io.ReadSeekCloser) {
+		err := blob.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(blob)
+	// the url is signed per key so the blob never change behind it
+	c.Header("Cache-Control", "private, max-age=300, immutable")
+	http.ServeContent(c.Writer, c.Request, key, time.Time{}, blob)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/applications/service"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
+)
+
+func Test_getBlobHandler(t *testing.T) {
+	memory := service.NewMemoryAttachment(service.NewHmacUrl("https://api.test/api/v1/blobs", []byte("secret"), time.Minute))
+	key, err := memory.UploadBlob(strings.NewReader("\x89PNG\r\n\x1a\n"), attachmentEntity.Uploader{Prefix: "sticker", Ext: ".png"})
+	require.NoError(t, err)
+	deletedKey, err := memory.UploadBlob(strings.NewReader("deleted"), attachmentEntity.Uploader{Prefix: "sticker", Ext: ".png"})
+	require.NoError(t, err)
+	signedUrl := func(key string) url.Values {
+		signed, err := memory.GetPresignedUrl(key)
+		require.NoError(t, err)
+		u, err := url.Parse(signed)
+		require.NoError(t, err)
+		return u.Query()
+	}
+	validQuery := signedUrl(key)
+	deletedQuery := signedUrl(deletedKey)
+	require.NoError(t, memory.DeleteBlob(deletedKey))
+	tamperedQuery := signedUrl(key)
+	tamperedQuery.Set("expires", "9999999999")
+
+	tests := []struct {
+		name     string
+		key      string
+		query    url.Values
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "valid signed url",
+			key:      key,
+			query:    validQuery,
+			wantCode: http.StatusOK,
+			wantBody: "\x89PNG\r\n\x1a\n",
+		},
+		{
+			name:     "signed for the other key",
+			key:      deletedKey,
+			query:    validQuery,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "tampered expires",
+			key:      key,
+			query:    tamperedQuery,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "without signature",
+			key:      key,
+			query:    url.Values{},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "deleted blob",
+			key:      deletedKey,
+			query:    deletedQuery,
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blobH := NewBlob(memory)
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Params = gin.Params{{Key: "key", Value: "/" + tt.key}}
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/blobs/"+tt.key+"?"+tt.query.Encode(), nil)
+
+			blobH.getBlobHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rr.Body.String())
+				assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
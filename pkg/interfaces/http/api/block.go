@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+)
+
+type blockSvc interface {
+	BlockUser(blockerId, blockedId string) error
+	UnblockUser(blockerId, blockedId string) error
+	GetBlockedUsers(blockerId string) ([]blockEntity.Block, error)
+	Report(reporterId string, newReport blockEntity.NewReport) (blockEntity.Report, error)
+}
+
+func NewBlock(blockSvc blockSvc) *Block {
+	return &Block{
+		blockSvc: blockSvc,
+	}
+}
+
+type Block struct {
+	blockSvc blockSvc
+}
+
+func (b *Block) postBlockHandler(c *gin.Context) {
+	err := b.blockSvc.BlockUser(c.GetString(keyUserId), c.GetString(keyTargetUserId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "user has been blocked",
+	})
+}
+
+func (b *Block) deleteBlockHandler(c *gin.Context) {
+	err := b.blockSvc.UnblockUser(c.GetString(keyUserId), c.GetString(keyTargetUserId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "user has been unblocked",
+	})
+}
+
+func (b *Block) getBlocksHandler(c *gin.Context) {
+	blocks, err := b.blockSvc.GetBlockedUsers(c.GetString(keyUserId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"blocks": blocks,
+		},
+	})
+}
+
+func (b *Block) postReportHandler(c *gin.Context) {
+	var input blockEntity.NewReport
+	if err := c.ShouldBindJSON(&input); err != nil {
+		if jsonErr := jsonBindingErrResp(err, c, map[string]string{
+			"reportedId": "required and must be valid uuid",
+			"reason":     "required and the value must be one of `spam`, `harassment`, `inappropriate`, `fake_profile`, `underage` or `other`",
+			"chatId":     "must be valid uuid",
+			"details":    "must less than 1000 character",
+		}); jsonErr != nil {
+			errServerResp(c, jsonErr)
+			return
+		}
+		return
+	}
+	report, err := b.blockSvc.Report(c.GetString(keyUserId), input)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"report": report,
+		},
+	})
+}
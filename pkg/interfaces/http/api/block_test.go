@@ -0,0 +1,303 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/applications/service"
+	"github.com/xyedo/blindate/pkg/common"
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_postBlockHandler(t *testing.T) {
+	userId := util.RandomUUID()
+	targetId := util.RandomUUID()
+	tests := []struct {
+		name      string
+		targetId  string
+		setupFunc func(blockRepo *mockrepo.MockBlock)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:     "valid block",
+			targetId: targetId,
+			setupFunc: func(blockRepo *mockrepo.MockBlock) {
+				blockRepo.EXPECT().InsertBlock(gomock.Eq(userId), gomock.Eq(targetId)).Times(1).Return(nil)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: map[string]any{
+				"status":  "success",
+				"message": "user has been blocked",
+			},
+		},
+		{
+			name:     "block yourself",
+			targetId: userId,
+			setupFunc: func(blockRepo *mockrepo.MockBlock) {
+				blockRepo.EXPECT().InsertBlock(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "could not block yourself",
+			},
+		},
+		{
+			name:     "unknown user",
+			targetId: targetId,
+			setupFunc: func(blockRepo *mockrepo.MockBlock) {
+				blockRepo.EXPECT().InsertBlock(gomock.Eq(userId), gomock.Eq(targetId)).Times(1).
+					Return(common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrRefNotFound23503, "user is not found"))
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "user is not found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			blockRepo := mockrepo.NewMockBlock(ctrl)
+			tt.setupFunc(blockRepo)
+			blockH := NewBlock(service.NewBlock(blockRepo, nil, nil))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/users/"+tt.targetId+"/block", nil)
+			c.Set(keyUserId, userId)
+			c.Set(keyTargetUserId, tt.targetId)
+
+			blockH.postBlockHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			expResBody, err := json.Marshal(tt.wantResp)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expResBody), rr.Body.String())
+		})
+	}
+}
+
+func Test_deleteBlockHandler(t *testing.T) {
+	userId := util.RandomUUID()
+	targetId := util.RandomUUID()
+	t.Run("valid unblock", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		blockRepo := mockrepo.NewMockBlock(ctrl)
+		blockRepo.EXPECT().DeleteBlock(gomock.Eq(userId), gomock.Eq(targetId)).Times(1).Return(nil)
+		blockH := NewBlock(service.NewBlock(blockRepo, nil, nil))
+
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/users/"+targetId+"/block", nil)
+		c.Set(keyUserId, userId)
+		c.Set(keyTargetUserId, targetId)
+
+		blockH.deleteBlockHandler(c)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+	t.Run("not blocked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		blockRepo := mockrepo.NewMockBlock(ctrl)
+		blockRepo.EXPECT().DeleteBlock(gomock.Eq(userId), gomock.Eq(targetId)).Times(1).
+			Return(common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound))
+		blockH := NewBlock(service.NewBlock(blockRepo, nil, nil))
+
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/users/"+targetId+"/block", nil)
+		c.Set(keyUserId, userId)
+		c.Set(keyTargetUserId, targetId)
+
+		blockH.deleteBlockHandler(c)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func Test_getBlocksHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userId := util.RandomUUID()
+	blockedAt := time.Now().UTC().Truncate(time.Second)
+	blocked := blockEntity.Block{UserId: util.RandomUUID(), Alias: "alias", BlockedAt: blockedAt}
+	blockRepo := mockrepo.NewMockBlock(ctrl)
+	blockRepo.EXPECT().SelectBlocked(gomock.Eq(userId)).Times(1).Return([]blockEntity.Block{blocked}, nil)
+	blockH := NewBlock(service.NewBlock(blockRepo, nil, nil))
+
+	rr := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rr)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/blocks", nil)
+	c.Set(keyUserId, userId)
+
+	blockH.getBlocksHandler(c)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	expResBody, err := json.Marshal(map[string]any{
+		"status": "success",
+		"data": map[string]any{
+			"blocks": []map[string]any{
+				{
+					"userId":    blocked.UserId,
+					"alias":     "alias",
+					"blockedAt": blockedAt,
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expResBody), rr.Body.String())
+}
+
+func Test_postReportHandler(t *testing.T) {
+	reporterId := util.RandomUUID()
+	reportedId := util.RandomUUID()
+	convId := util.RandomUUID()
+	chatId := util.RandomUUID()
+	tests := []struct {
+		name      string
+		reqBody   string
+		setupFunc func(t *testing.T, blockRepo *mockrepo.MockBlock, chatRepo *mockrepo.MockChat, matchRepo *mockrepo.MockMatch)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:    "valid report with chat",
+			reqBody: `{"reportedId":"` + reportedId + `","reason":"harassment","chatId":"` + chatId + `","details":"  rude  "}`,
+			setupFunc: func(t *testing.T, blockRepo *mockrepo.MockBlock, chatRepo *mockrepo.MockChat, matchRepo *mockrepo.MockMatch) {
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(chatId)).Times(1).
+					Return(chatEntity.DAO{Id: chatId, ConversationId: convId, Author: reportedId}, nil)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(convId)).Times(1).
+					Return(matchEntity.MatchDAO{Id: convId, RequestFrom: reportedId, RequestTo: reporterId}, nil)
+				blockRepo.EXPECT().InsertReport(gomock.Any()).Times(1).
+					DoAndReturn(func(report *blockEntity.Report) error {
+						assert.Equal(t, reporterId, report.ReporterId)
+						assert.Equal(t, reportedId, report.ReportedId)
+						assert.Equal(t, blockEntity.Harassment, report.Reason)
+						require.NotNil(t, report.ChatId)
+						assert.Equal(t, chatId, *report.ChatId)
+						require.NotNil(t, report.Details)
+						assert.Equal(t, "rude", *report.Details)
+						report.Id = "report-1"
+						return nil
+					})
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:    "valid report without chat",
+			reqBody: `{"reportedId":"` + reportedId + `","reason":"fake_profile","details":"  "}`,
+			setupFunc: func(t *testing.T, blockRepo *mockrepo.MockBlock, chatRepo *mockrepo.MockChat, matchRepo *mockrepo.MockMatch) {
+				blockRepo.EXPECT().InsertReport(gomock.Any()).Times(1).
+					DoAndReturn(func(report *blockEntity.Report) error {
+						assert.Nil(t, report.ChatId)
+						assert.Nil(t, report.Details)
+						return nil
+					})
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:    "invalid reason",
+			reqBody: `{"reportedId":"` + reportedId + `","reason":"boring"}`,
+			setupFunc: func(t *testing.T, blockRepo *mockrepo.MockBlock, chatRepo *mockrepo.MockChat, matchRepo *mockrepo.MockMatch) {
+				blockRepo.EXPECT().InsertReport(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "details too long",
+			reqBody: `{"reportedId":"` + reportedId + `","reason":"other","details":"` + util.RandomString(1001) + `"}`,
+			setupFunc: func(t *testing.T, blockRepo *mockrepo.MockBlock, chatRepo *mockrepo.MockChat, matchRepo *mockrepo.MockMatch) {
+				blockRepo.EXPECT().InsertReport(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "report yourself",
+			reqBody: `{"reportedId":"` + reporterId + `","reason":"spam"}`,
+			setupFunc: func(t *testing.T, blockRepo *mockrepo.MockBlock, chatRepo *mockrepo.MockChat, matchRepo *mockrepo.MockMatch) {
+				blockRepo.EXPECT().InsertReport(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "could not report yourself",
+			},
+		},
+		{
+			name:    "chat not found",
+			reqBody: `{"reportedId":"` + reportedId + `","reason":"spam","chatId":"` + chatId + `"}`,
+			setupFunc: func(t *testing.T, blockRepo *mockrepo.MockBlock, chatRepo *mockrepo.MockChat, matchRepo *mockrepo.MockMatch) {
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(chatId)).Times(1).
+					Return(chatEntity.DAO{}, common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound))
+				blockRepo.EXPECT().InsertReport(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "chat is not found",
+			},
+		},
+		{
+			name:    "chat from another conversation",
+			reqBody: `{"reportedId":"` + reportedId + `","reason":"spam","chatId":"` + chatId + `"}`,
+			setupFunc: func(t *testing.T, blockRepo *mockrepo.MockBlock, chatRepo *mockrepo.MockChat, matchRepo *mockrepo.MockMatch) {
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(chatId)).Times(1).
+					Return(chatEntity.DAO{Id: chatId, ConversationId: convId, Author: reportedId}, nil)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(convId)).Times(1).
+					Return(matchEntity.MatchDAO{Id: convId, RequestFrom: reportedId, RequestTo: util.RandomUUID()}, nil)
+				blockRepo.EXPECT().InsertReport(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "chat is not part of your conversation with the reported user",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			blockRepo := mockrepo.NewMockBlock(ctrl)
+			chatRepo := mockrepo.NewMockChat(ctrl)
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			tt.setupFunc(t, blockRepo, chatRepo, matchRepo)
+			blockH := NewBlock(service.NewBlock(blockRepo, chatRepo, matchRepo))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/reports", strings.NewReader(tt.reqBody))
+			c.Set(keyUserId, reporterId)
+
+			blockH.postReportHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.wantResp != nil {
+				expResBody, err := json.Marshal(tt.wantResp)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+			}
+		})
+	}
+}
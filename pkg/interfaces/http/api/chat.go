@@ -1,35 +1,49 @@
 package api
 
 import (
+	"bytes"
 	"errors"
+	"io"
+	"log"
+	"mime/multipart"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xyedo/blindate/pkg/common"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
 	"github.com/xyedo/blindate/pkg/domain/chat"
 	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
 	"github.com/xyedo/blindate/pkg/util"
 )
 
-// TODO: Create chat_test.go
 type chatSvc interface {
 	CreateNewChat(content *chatEntity.DTO) error
-	UpdateSeenChat(convId, userId string) error
-	GetMessages(convoId string, filter chat.Filter) ([]chatEntity.DTO, error)
-	DeleteMessagesById(chatId string) error
+	UpdateSeenChat(convId, userId, upToChatId string) error
+	GetMessages(convoId, viewerId string, filter chat.Filter) ([]chatEntity.DTO, error)
+	EditMessage(convId, chatId, message string) (chatEntity.DTO, error)
+	GetMessageHistory(chatId string) ([]chatEntity.History, error)
+	DeleteMessagesById(convId, chatId string) error
+	AddReaction(convId, chatId, userId, emoji string) error
+	RemoveReaction(convId, chatId, userId, emoji string) error
 }
 
-func NewChat(chatSvc chatSvc, attachSvc attachmentManager) *Chat {
+type mediaSvc interface {
+	Process(file io.Reader, size int64, mediaType string) (attachmentEntity.Processed, error)
+}
+
+func NewChat(chatSvc chatSvc, attachSvc attachmentManager, mediaSvc mediaSvc) *Chat {
 	return &Chat{
 		chatSvc:   chatSvc,
 		attachSvc: attachSvc,
+		mediaSvc:  mediaSvc,
 	}
 }
 
 type Chat struct {
 	chatSvc   chatSvc
 	attachSvc attachmentManager
+	mediaSvc  mediaSvc
 }
 
 func (cha *Chat) postChatHandler(c *gin.Context) {
@@ -70,20 +84,9 @@ func (cha *Chat) postChatHandler(c *gin.Context) {
 }
 
 func (cha *Chat) postChatMediaHandler(c *gin.Context) {
-	var validAudioTypes = []string{
-		"application/ogg",
-		"audio/mpeg",
-	}
-	key, mediaType := uploadFile(c, cha.attachSvc, validAudioTypes, "chat-attachment")
-	if key == "" {
-		return
-	}
 	var input struct {
 		ReplyTo *string `form:"replyTo" binding:"omitempty,uuid"`
 	}
-
-	userId := c.GetString("userId")
-	convoId := c.GetString("convId")
 	if err := c.ShouldBindQuery(&input); err != nil {
 		if errMap := util.ReadValidationErr(err, map[string]string{
 			"ReplyTo": "if provided, it should be in uuid format",
@@ -98,18 +101,37 @@ func (cha *Chat) postChatMediaHandler(c *gin.Context) {
 		errServerResp(c, err)
 		return
 	}
+	validMediaTypes := make([]string, 0, len(attachmentEntity.AudioTypes)+len(attachmentEntity.ImageTypes)+len(attachmentEntity.VideoTypes))
+	validMediaTypes = append(validMediaTypes, attachmentEntity.AudioTypes...)
+	validMediaTypes = append(validMediaTypes, attachmentEntity.ImageTypes...)
+	validMediaTypes = append(validMediaTypes, attachmentEntity.VideoTypes...)
+	file, fileHeader, mediaType := openFormFile(c, validMediaTypes)
+	if file == nil {
+		return
+	}
+	defer func(file multipart.File) {
+		err := file.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(file)
+
+	attachment, err := cha.uploadMedia(file, fileHeader.Size, mediaType)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+
 	dtoChat := chatEntity.DTO{
-		ConversationId: convoId,
-		Author:         userId,
+		ConversationId: c.GetString(keyConvId),
+		Author:         c.GetString(keyUserId),
 		Messages:       "",
 		ReplyTo:        input.ReplyTo,
 		SentAt:         time.Now(),
-		Attachment: &chatEntity.Attachment{
-			BlobLink:  key,
-			MediaType: mediaType,
-		},
+		Attachment:     attachment,
 	}
 	if err := cha.chatSvc.CreateNewChat(&dtoChat); err != nil {
+		cha.deleteAttachmentBlobs(attachment)
 		jsonHandleError(c, err)
 		return
 	}
@@ -124,6 +146,97 @@ func (cha *Chat) postChatMediaHandler(c *gin.Context) {
 	})
 }
 
+// uploadMedia validate and sanitize the media before it is uploaded along with its thumbnail
+func (cha *Chat) uploadMedia(file io.Reader, size int64, mediaType string) (*chatEntity.Attachment, error) {
+	processed, err := cha.mediaSvc.Process(file, size, mediaType)
+	if err != nil {
+		return nil, err
+	}
+	key, err := cha.attachSvc.UploadBlob(bytes.NewReader(processed.Content), attachmentEntity.Uploader{
+		Length:      int64(len(processed.Content)),
+		ContentType: processed.MediaType,
+		Prefix:      "chat-attachment",
+		Ext:         processed.Ext,
+	})
+	if err != nil {
+		return nil, err
+	}
+	attachment := &chatEntity.Attachment{
+		BlobLink:  key,
+		MediaType: processed.MediaType,
+		Waveform:  processed.Waveform,
+	}
+	if processed.Width > 0 && processed.Height > 0 {
+		attachment.Width = &processed.Width
+		attachment.Height = &processed.Height
+	}
+	if processed.Duration > 0 {
+		durationMs := int(processed.Duration.Milliseconds())
+		attachment.DurationMs = &durationMs
+	}
+	if processed.Thumbnail != nil {
+		thumbnailKey, err := cha.attachSvc.UploadBlob(bytes.NewReader(processed.Thumbnail), attachmentEntity.Uploader{
+			Length:      int64(len(processed.Thumbnail)),
+			ContentType: "image/jpeg",
+			Prefix:      "chat-thumbnail",
+			Ext:         ".jpg",
+		})
+		if err != nil {
+			cha.deleteAttachmentBlobs(attachment)
+			return nil, err
+		}
+		attachment.ThumbnailLink = &thumbnailKey
+	}
+	return attachment, nil
+}
+
+func (cha *Chat) deleteAttachmentBlobs(attachment *chatEntity.Attachment) {
+	if err := cha.attachSvc.DeleteBlob(attachment.BlobLink); err != nil {
+		log.Println(err)
+	}
+	if attachment.ThumbnailLink != nil {
+		if err := cha.attachSvc.DeleteBlob(*attachment.ThumbnailLink); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func (cha *Chat) postChatStickerHandler(c *gin.Context) {
+	var input chatEntity.NewSticker
+	if err := c.ShouldBindJSON(&input); err != nil {
+		if jsonErr := jsonBindingErrResp(err, c, map[string]string{
+			"stickerId": "must be valid uuid",
+			"replyTo":   "if specified, must be valid uuid",
+		}); jsonErr != nil {
+			errServerResp(c, err)
+			return
+		}
+		return
+	}
+	dtoChat := chatEntity.DTO{
+		ConversationId: c.GetString(keyConvId),
+		Author:         c.GetString(keyUserId),
+		ReplyTo:        input.ReplyTo,
+		SentAt:         time.Now(),
+		Sticker: &chatEntity.Sticker{
+			Id: input.StickerId,
+		},
+	}
+	if err := cha.chatSvc.CreateNewChat(&dtoChat); err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "sticker sent",
+		"data": gin.H{
+			"chat": gin.H{
+				"id": dtoChat.Id,
+			},
+		},
+	})
+}
+
 func (cha *Chat) getMessagesHandler(c *gin.Context) {
 	var query struct {
 		Limit  *int       `form:"limit" binding:"omitempty,min=30,max=90"`
@@ -161,7 +274,8 @@ func (cha *Chat) getMessagesHandler(c *gin.Context) {
 		chatQueryFilter.Cursor.After = *query.After
 	}
 	convoId := c.GetString("convId")
-	dtoChats, err := cha.chatSvc.GetMessages(convoId, chatQueryFilter)
+	userId := c.GetString(keyUserId)
+	dtoChats, err := cha.chatSvc.GetMessages(convoId, userId, chatQueryFilter)
 	if err != nil {
 		if errors.Is(err, common.ErrTooLongAccessingDB) {
 			errResourceConflictResp(c)
@@ -178,9 +292,19 @@ func (cha *Chat) getMessagesHandler(c *gin.Context) {
 	})
 }
 func (chat *Chat) putSeenAtHandler(c *gin.Context) {
+	var input chatEntity.SeenWatermark
+	if err := c.ShouldBindJSON(&input); err != nil {
+		if jsonErr := jsonBindingErrResp(err, c, map[string]string{
+			"upTo": "must be required and must be valid uuid",
+		}); jsonErr != nil {
+			errServerResp(c, err)
+			return
+		}
+		return
+	}
 	convoId := c.GetString("convId")
 	userId := c.GetString("userId")
-	err := chat.chatSvc.UpdateSeenChat(convoId, userId)
+	err := chat.chatSvc.UpdateSeenChat(convoId, userId, input.UpTo)
 	if err != nil {
 		jsonHandleError(c, err)
 		return
@@ -190,9 +314,49 @@ func (chat *Chat) putSeenAtHandler(c *gin.Context) {
 		"message": "seenAt updated",
 	})
 }
+func (chat *Chat) patchMessageHandler(c *gin.Context) {
+	var input chatEntity.Edit
+	if err := c.ShouldBindJSON(&input); err != nil {
+		if jsonErr := jsonBindingErrResp(err, c, map[string]string{
+			"message": "must not empty and max characters is 4096",
+		}); jsonErr != nil {
+			errServerResp(c, err)
+			return
+		}
+		return
+	}
+	convoId := c.GetString(keyConvId)
+	chatId := c.GetString(keyChatId)
+	edited, err := chat.chatSvc.EditMessage(convoId, chatId, input.Message)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"chat": edited,
+		},
+	})
+}
+func (chat *Chat) getMessageHistoryHandler(c *gin.Context) {
+	chatId := c.GetString(keyChatId)
+	history, err := chat.chatSvc.GetMessageHistory(chatId)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"history": history,
+		},
+	})
+}
 func (chat *Chat) deleteMessagesByIdHandler(c *gin.Context) {
-	chatId := c.GetString("chatId")
-	if err := chat.chatSvc.DeleteMessagesById(chatId); err != nil {
+	convoId := c.GetString(keyConvId)
+	chatId := c.GetString(keyChatId)
+	if err := chat.chatSvc.DeleteMessagesById(convoId, chatId); err != nil {
 		switch {
 		case errors.Is(err, common.ErrRefNotFound23503):
 			errNotFoundResp(c, "provided chatId in url is not found!")
@@ -209,3 +373,45 @@ func (chat *Chat) deleteMessagesByIdHandler(c *gin.Context) {
 	})
 
 }
+
+func (chat *Chat) postReactionHandler(c *gin.Context) {
+	var input chatEntity.NewReaction
+	if err := c.ShouldBindJSON(&input); err != nil {
+		if jsonErr := jsonBindingErrResp(err, c, map[string]string{
+			"emoji": "must be required and must be a single emoji",
+		}); jsonErr != nil {
+			errServerResp(c, err)
+			return
+		}
+		return
+	}
+	convoId := c.GetString(keyConvId)
+	chatId := c.GetString(keyChatId)
+	userId := c.GetString(keyUserId)
+	if err := chat.chatSvc.AddReaction(convoId, chatId, userId, input.Emoji); err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "reaction added",
+	})
+}
+func (chat *Chat) deleteReactionHandler(c *gin.Context) {
+	emoji := c.Param("emoji")
+	if !chatEntity.IsEmoji(emoji) {
+		errBadRequestResp(c, "emoji in uri must be a single emoji")
+		return
+	}
+	convoId := c.GetString(keyConvId)
+	chatId := c.GetString(keyChatId)
+	userId := c.GetString(keyUserId)
+	if err := chat.chatSvc.RemoveReaction(convoId, chatId, userId, emoji); err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "reaction removed",
+	})
+}
@@ -0,0 +1,1525 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/applications/service"
+	mocksvc "github.com/xyedo/blindate/pkg/applications/service/mock"
+	"github.com/xyedo/blindate/pkg/common"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
+	"github.com/xyedo/blindate/pkg/domain/chat"
+	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_putSeenAtHandler(t *testing.T) {
+	validConvId := util.RandomUUID()
+	validUserId := util.RandomUUID()
+	partnerId := util.RandomUUID()
+	validChatId := util.RandomUUID()
+	validMatch := matchEntity.MatchDAO{
+		Id:            validConvId,
+		RequestFrom:   validUserId,
+		RequestTo:     partnerId,
+		RequestStatus: string(matchEntity.Accepted),
+	}
+
+	tests := []struct {
+		name      string
+		userId    string
+		reqBody   map[string]string
+		setupFunc func(t *testing.T, ctrl *gomock.Controller) *Chat
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:   "valid seen up to watermark",
+			userId: validUserId,
+			reqBody: map[string]string{
+				"upTo": validChatId,
+			},
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				matchRepo := mockrepo.NewMockMatch(ctrl)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo := mockrepo.NewMockChat(ctrl)
+				chatRepo.EXPECT().UpdateSeenChat(gomock.Eq(validConvId), gomock.Eq(validUserId), gomock.Eq(validChatId), gomock.Any()).
+					Times(1).Return([]string{validChatId}, nil)
+				return NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), nil, nil)
+			},
+			wantCode: http.StatusOK,
+			wantResp: map[string]any{
+				"status":  "success",
+				"message": "seenAt updated",
+			},
+		},
+		{
+			name:   "upTo is required",
+			userId: validUserId,
+			reqBody: map[string]string{
+				"upTo": "",
+			},
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				matchRepo := mockrepo.NewMockMatch(ctrl)
+				matchRepo.EXPECT().GetMatchById(gomock.Any()).Times(0)
+				chatRepo := mockrepo.NewMockChat(ctrl)
+				chatRepo.EXPECT().UpdateSeenChat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				return NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), nil, nil)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]string{
+					"upTo": "must be required and must be valid uuid",
+				},
+			},
+		},
+		{
+			name:   "upTo must be uuid",
+			userId: validUserId,
+			reqBody: map[string]string{
+				"upTo": "latest",
+			},
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				matchRepo := mockrepo.NewMockMatch(ctrl)
+				matchRepo.EXPECT().GetMatchById(gomock.Any()).Times(0)
+				chatRepo := mockrepo.NewMockChat(ctrl)
+				chatRepo.EXPECT().UpdateSeenChat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				return NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), nil, nil)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]string{
+					"upTo": "must be required and must be valid uuid",
+				},
+			},
+		},
+		{
+			name:   "user not in conversation",
+			userId: util.RandomUUID(),
+			reqBody: map[string]string{
+				"upTo": validChatId,
+			},
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				matchRepo := mockrepo.NewMockMatch(ctrl)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo := mockrepo.NewMockChat(ctrl)
+				chatRepo.EXPECT().UpdateSeenChat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				return NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), nil, nil)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "users not in this conversation",
+			},
+		},
+		{
+			name:   "watermark not found",
+			userId: validUserId,
+			reqBody: map[string]string{
+				"upTo": validChatId,
+			},
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				matchRepo := mockrepo.NewMockMatch(ctrl)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo := mockrepo.NewMockChat(ctrl)
+				chatRepo.EXPECT().UpdateSeenChat(gomock.Eq(validConvId), gomock.Eq(validUserId), gomock.Eq(validChatId), gomock.Any()).
+					Times(1).Return(nil, common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "upTo chat is not found in this conversation"))
+				return NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), nil, nil)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "upTo chat is not found in this conversation",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			chatH := tt.setupFunc(t, ctrl)
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, tt.userId)
+			c.Set(keyConvId, validConvId)
+
+			reqJsonBody, err := json.Marshal(tt.reqBody)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/%s/chat/seenAt", validConvId), bytes.NewReader(reqJsonBody))
+			c.Request = req
+
+			chatH.putSeenAtHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			require.Contains(t, rr.Header().Get("Content-Type"), "application/json")
+
+			if tt.wantResp != nil {
+				expResBody, err := json.Marshal(tt.wantResp)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+			}
+		})
+	}
+}
+
+func Test_postChatHandler(t *testing.T) {
+	validConvId := util.RandomUUID()
+	validUserId := util.RandomUUID()
+	partnerId := util.RandomUUID()
+	validMatch := matchEntity.MatchDAO{
+		Id:            validConvId,
+		RequestFrom:   validUserId,
+		RequestTo:     partnerId,
+		RequestStatus: string(matchEntity.Accepted),
+	}
+
+	tests := []struct {
+		name      string
+		userId    string
+		reqBody   string
+		setupFunc func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:    "valid chat",
+			userId:  validUserId,
+			reqBody: `{"message":"hello there"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(1).
+					DoAndReturn(func(chat *chatEntity.DAO) error {
+						assert.Equal(t, validConvId, chat.ConversationId)
+						assert.Equal(t, validUserId, chat.Author)
+						assert.Equal(t, "hello there", chat.Messages)
+						return nil
+					})
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:    "message is required",
+			userId:  validUserId,
+			reqBody: `{"message":""}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Any()).Times(0)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "author not in conversation",
+			userId:  util.RandomUUID(),
+			reqBody: `{"message":"hello there"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "author not in this conversation",
+			},
+		},
+		{
+			name:    "blocked pair",
+			userId:  validUserId,
+			reqBody: `{"message":"hello there"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				blockedMatch := validMatch
+				blockedMatch.Blocked = true
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(blockedMatch, nil)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "could not send chat to this user",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			chatRepo := mockrepo.NewMockChat(ctrl)
+			tt.setupFunc(t, matchRepo, chatRepo)
+			chatH := NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), nil, nil)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, tt.userId)
+			c.Set(keyConvId, validConvId)
+			c.Request = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/%s/chat", validConvId), strings.NewReader(tt.reqBody))
+
+			chatH.postChatHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			require.Contains(t, rr.Header().Get("Content-Type"), "application/json")
+			if tt.wantResp != nil {
+				expResBody, err := json.Marshal(tt.wantResp)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+			}
+		})
+	}
+}
+
+func Test_postChatMediaHandler(t *testing.T) {
+	validConvId := util.RandomUUID()
+	validUserId := util.RandomUUID()
+	validMatch := matchEntity.MatchDAO{
+		Id:            validConvId,
+		RequestFrom:   validUserId,
+		RequestTo:     util.RandomUUID(),
+		RequestStatus: string(matchEntity.Accepted),
+		RevealStatus:  string(matchEntity.Accepted),
+	}
+	blindMatch := validMatch
+	blindMatch.RevealStatus = string(matchEntity.Requested)
+	mediaSvc := service.NewMedia(attachmentEntity.Limits{
+		MaxAudioBytes:    1 << 20,
+		MaxImageBytes:    1 << 20,
+		MaxVideoBytes:    1 << 20,
+		MaxDimension:     64,
+		MaxVoiceDuration: 10 * time.Second,
+		MaxVideoDuration: time.Minute,
+		ThumbnailSize:    16,
+	})
+	writeFile := func(name string, content []byte) func(writer *multipart.Writer) {
+		return func(writer *multipart.Writer) {
+			defer writer.Close()
+			part, err := writer.CreateFormFile("file", name)
+			require.NoError(t, err)
+			_, err = part.Write(content)
+			require.NoError(t, err)
+		}
+	}
+	gps := "+37.7749-122.4194/"
+
+	tests := []struct {
+		name      string
+		writeMime func(writer *multipart.Writer)
+		setupFunc func(t *testing.T, ctrl *gomock.Controller) *Chat
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:      "valid voice note",
+			writeMime: writeFile("voice.ogg", encodeTestOgg(t, 3*time.Second, 150)),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				validKey := "chat-attachment/" + util.RandomUUID() + ".ogg"
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(1).Return(validKey, nil)
+				matchRepo := mockrepo.NewMockMatch(ctrl)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo := mockrepo.NewMockChat(ctrl)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(1).
+					DoAndReturn(func(chat *chatEntity.DAO) error {
+						require.NotNil(t, chat.Attachment)
+						assert.Equal(t, validKey, chat.Attachment.BlobLink)
+						assert.Equal(t, "application/ogg", chat.Attachment.MediaType)
+						assert.Nil(t, chat.Attachment.ThumbnailLink)
+						assert.Nil(t, chat.Attachment.Width)
+						require.NotNil(t, chat.Attachment.DurationMs)
+						assert.Equal(t, 3000, *chat.Attachment.DurationMs)
+						assert.Len(t, chat.Attachment.Waveform, 64)
+						for _, peak := range chat.Attachment.Waveform {
+							assert.True(t, peak >= 0 && peak <= 100)
+						}
+						assert.Contains(t, chat.Attachment.Waveform, 0)
+						assert.Contains(t, chat.Attachment.Waveform, 100)
+						return nil
+					})
+				return NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:      "valid mp3 voice note",
+			writeMime: writeFile("voice.mp3", encodeTestMP3(40)),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(file io.Reader, attach attachmentEntity.Uploader) (string, error) {
+						assert.Equal(t, "audio/mpeg", attach.ContentType)
+						assert.Equal(t, ".mp3", attach.Ext)
+						return "chat-attachment/voice.mp3", nil
+					})
+				matchRepo := mockrepo.NewMockMatch(ctrl)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo := mockrepo.NewMockChat(ctrl)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(1).
+					DoAndReturn(func(chat *chatEntity.DAO) error {
+						require.NotNil(t, chat.Attachment)
+						require.NotNil(t, chat.Attachment.DurationMs)
+						// 40 frames of 1152 samples at 44.1kHz
+						assert.InDelta(t, 1044, *chat.Attachment.DurationMs, 1)
+						assert.Len(t, chat.Attachment.Waveform, 40)
+						assert.Equal(t, 0, chat.Attachment.Waveform[0])
+						assert.Equal(t, 100, chat.Attachment.Waveform[39])
+						return nil
+					})
+				return NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:      "voice note too long",
+			writeMime: writeFile("voice.ogg", encodeTestOgg(t, 20*time.Second, 50)),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
+				return NewChat(service.NewChat(mockrepo.NewMockChat(ctrl), mockrepo.NewMockMatch(ctrl), testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "voice note must not be longer than 10 seconds",
+			},
+		},
+		{
+			name:      "voice note with huge final granule is too long",
+			writeMime: writeFile("voice.ogg", withFinalGranule(encodeTestOgg(t, 3*time.Second, 10), 10_000_000_000_000)),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
+				return NewChat(service.NewChat(mockrepo.NewMockChat(ctrl), mockrepo.NewMockMatch(ctrl), testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "voice note must not be longer than 10 seconds",
+			},
+		},
+		{
+			name:      "voice note with overflowing final granule",
+			writeMime: writeFile("voice.ogg", withFinalGranule(encodeTestOgg(t, 3*time.Second, 10), 0x7000000000000000)),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
+				return NewChat(service.NewChat(mockrepo.NewMockChat(ctrl), mockrepo.NewMockMatch(ctrl), testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "voice note is malformed",
+			},
+		},
+		{
+			name:      "malformed voice note",
+			writeMime: writeFile("voice.ogg", append([]byte("OggS\x00"), make([]byte, 64)...)),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
+				return NewChat(service.NewChat(mockrepo.NewMockChat(ctrl), mockrepo.NewMockMatch(ctrl), testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "voice note is malformed",
+			},
+		},
+		{
+			name:      "valid image with thumbnail",
+			writeMime: writeFile("photo.png", encodeTestPNG(t, 40, 20)),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(file io.Reader, attach attachmentEntity.Uploader) (string, error) {
+						if attach.Prefix == "chat-thumbnail" {
+							assert.Equal(t, "image/jpeg", attach.ContentType)
+							return "chat-thumbnail/thumb.jpg", nil
+						}
+						assert.Equal(t, "image/png", attach.ContentType)
+						assert.Equal(t, ".png", attach.Ext)
+						return "chat-attachment/photo.png", nil
+					})
+				matchRepo := mockrepo.NewMockMatch(ctrl)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo := mockrepo.NewMockChat(ctrl)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(1).
+					DoAndReturn(func(chat *chatEntity.DAO) error {
+						require.NotNil(t, chat.Attachment)
+						assert.Equal(t, "chat-attachment/photo.png", chat.Attachment.BlobLink)
+						require.NotNil(t, chat.Attachment.Width)
+						require.NotNil(t, chat.Attachment.Height)
+						assert.Equal(t, 40, *chat.Attachment.Width)
+						assert.Equal(t, 20, *chat.Attachment.Height)
+						require.NotNil(t, chat.Attachment.ThumbnailLink)
+						assert.Equal(t, "chat-thumbnail/thumb.jpg", *chat.Attachment.ThumbnailLink)
+						return nil
+					})
+				return NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:      "exif is stripped and orientation applied",
+			writeMime: writeFile("photo.jpg", encodeTestJPEGWithExif(t, 40, 20, 6)),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(file io.Reader, attach attachmentEntity.Uploader) (string, error) {
+						content, err := io.ReadAll(file)
+						require.NoError(t, err)
+						assert.NotContains(t, string(content), "Exif")
+						return attach.Prefix + "/" + util.RandomUUID() + attach.Ext, nil
+					})
+				matchRepo := mockrepo.NewMockMatch(ctrl)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo := mockrepo.NewMockChat(ctrl)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(1).
+					DoAndReturn(func(chat *chatEntity.DAO) error {
+						require.NotNil(t, chat.Attachment)
+						assert.Equal(t, "image/jpeg", chat.Attachment.MediaType)
+						assert.Equal(t, 20, *chat.Attachment.Width)
+						assert.Equal(t, 40, *chat.Attachment.Height)
+						return nil
+					})
+				return NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:      "image on blind match",
+			writeMime: writeFile("photo.png", encodeTestPNG(t, 40, 20)),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(file io.Reader, attach attachmentEntity.Uploader) (string, error) {
+						return attach.Prefix + "/blob" + attach.Ext, nil
+					})
+				attachSvc.EXPECT().DeleteBlob(gomock.Eq("chat-attachment/blob.png")).Times(1).Return(nil)
+				attachSvc.EXPECT().DeleteBlob(gomock.Eq("chat-thumbnail/blob.jpg")).Times(1).Return(nil)
+				matchRepo := mockrepo.NewMockMatch(ctrl)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(blindMatch, nil)
+				chatRepo := mockrepo.NewMockChat(ctrl)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(0)
+				return NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "image and video could only be shared after reveal",
+			},
+		},
+		{
+			name:      "image dimension too large",
+			writeMime: writeFile("photo.png", encodeTestPNG(t, 65, 20)),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
+				return NewChat(service.NewChat(mockrepo.NewMockChat(ctrl), mockrepo.NewMockMatch(ctrl), testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "image dimension must not exceed 64px",
+			},
+		},
+		{
+			name:      "valid video without metadata",
+			writeMime: writeFile("clip.mp4", encodeTestMP4(t, 48, 32, 30*time.Second, gps)),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(file io.Reader, attach attachmentEntity.Uploader) (string, error) {
+						content, err := io.ReadAll(file)
+						require.NoError(t, err)
+						assert.NotContains(t, string(content), gps)
+						assert.Equal(t, "video/mp4", attach.ContentType)
+						return "chat-attachment/clip.mp4", nil
+					})
+				matchRepo := mockrepo.NewMockMatch(ctrl)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo := mockrepo.NewMockChat(ctrl)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(1).
+					DoAndReturn(func(chat *chatEntity.DAO) error {
+						require.NotNil(t, chat.Attachment)
+						assert.Equal(t, 48, *chat.Attachment.Width)
+						assert.Equal(t, 32, *chat.Attachment.Height)
+						require.NotNil(t, chat.Attachment.DurationMs)
+						assert.Equal(t, 30000, *chat.Attachment.DurationMs)
+						assert.Nil(t, chat.Attachment.ThumbnailLink)
+						return nil
+					})
+				return NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:      "video too long",
+			writeMime: writeFile("clip.mp4", encodeTestMP4(t, 48, 32, 2*time.Minute, gps)),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
+				return NewChat(service.NewChat(mockrepo.NewMockChat(ctrl), mockrepo.NewMockMatch(ctrl), testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "video must not be longer than 60 seconds",
+			},
+		},
+		{
+			name:      "not valid mime-type",
+			writeMime: writeFile("note.txt", []byte("just a plain text")),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Chat {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
+				return NewChat(service.NewChat(mockrepo.NewMockChat(ctrl), mockrepo.NewMockMatch(ctrl), testBlobUrl), attachSvc, mediaSvc)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "not valid mime-type",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			pr, pw := io.Pipe()
+			writer := multipart.NewWriter(pw)
+			go tt.writeMime(writer)
+
+			chatH := tt.setupFunc(t, ctrl)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, validUserId)
+			c.Set(keyConvId, validConvId)
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/%s/chat-media", validConvId), pr)
+			req.Header.Add("Content-Type", writer.FormDataContentType())
+			c.Request = req
+
+			chatH.postChatMediaHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			require.Contains(t, rr.Header().Get("Content-Type"), "application/json")
+			if tt.wantResp != nil {
+				expResBody, err := json.Marshal(tt.wantResp)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+			}
+		})
+	}
+}
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, util.CreateDefaultImage(width, height))
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+// encodeTestJPEGWithExif put an exif segment holding only the orientation right after the start of image
+func encodeTestJPEGWithExif(t *testing.T, width, height int, orientation uint16) []byte {
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, util.CreateDefaultImage(width, height), nil)
+	require.NoError(t, err)
+	exif := []byte("Exif\x00\x00MM\x00\x2A\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01")
+	exif = append(exif, byte(orientation>>8), byte(orientation), 0, 0, 0, 0, 0, 0)
+	segment := []byte{0xFF, 0xE1, byte((len(exif) + 2) >> 8), byte(len(exif) + 2)}
+	content := append([]byte{}, buf.Bytes()[:2]...)
+	content = append(content, segment...)
+	content = append(content, exif...)
+	return append(content, buf.Bytes()[2:]...)
+}
+
+// encodeTestMP4 build the box structure of a single video track, the udta hold the location
+func encodeTestMP4(t *testing.T, width, height int, duration time.Duration, location string) []byte {
+	box := func(typ string, payload ...[]byte) []byte {
+		content := bytes.Join(payload, nil)
+		b := make([]byte, 8, 8+len(content))
+		binary.BigEndian.PutUint32(b, uint32(8+len(content)))
+		copy(b[4:], typ)
+		return append(b, content...)
+	}
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], uint32(duration.Milliseconds()))
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[40:], 0x10000)
+	binary.BigEndian.PutUint32(tkhd[76:], uint32(width)<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], uint32(height)<<16)
+	hdlr := append(make([]byte, 8), []byte("vide\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")...)
+	return bytes.Join([][]byte{
+		box("ftyp", []byte("isom\x00\x00\x02\x00isommp41")),
+		box("moov",
+			box("mvhd", mvhd),
+			box("trak", box("tkhd", tkhd), box("mdia", box("hdlr", hdlr))),
+			box("udta", box("\xA9xyz", []byte(location))),
+		),
+		box("mdat", make([]byte, 64)),
+	}, nil)
+}
+
+// encodeTestOgg build a mono opus stream, one packet per page with growing size so the waveform has a range
+func encodeTestOgg(t *testing.T, duration time.Duration, packets int) []byte {
+	const preSkip = 312
+	page := func(seq uint32, granule int64, packet []byte) []byte {
+		require.Less(t, len(packet), 255)
+		header := make([]byte, 28)
+		copy(header, "OggS")
+		binary.LittleEndian.PutUint64(header[6:], uint64(granule))
+		binary.LittleEndian.PutUint32(header[14:], 0x1234)
+		binary.LittleEndian.PutUint32(header[18:], seq)
+		header[26] = 1
+		header[27] = byte(len(packet))
+		return append(header, packet...)
+	}
+	opusHead := []byte("OpusHead\x01\x01\x00\x00\x80\xBB\x00\x00\x00\x00\x00")
+	binary.LittleEndian.PutUint16(opusHead[10:], preSkip)
+	pages := [][]byte{
+		page(0, 0, opusHead),
+		page(1, 0, []byte("OpusTags\x00\x00\x00\x00\x00\x00\x00\x00")),
+	}
+	total := int64(duration/time.Millisecond) * 48
+	for i := 1; i <= packets; i++ {
+		granule := total*int64(i)/int64(packets) + preSkip
+		pages = append(pages, page(uint32(i+1), granule, make([]byte, 10+i%200)))
+	}
+	return bytes.Join(pages, nil)
+}
+
+// withFinalGranule overwrite the granule position of the last page, the packets is zero filled so the capture pattern is unique
+func withFinalGranule(ogg []byte, granule int64) []byte {
+	last := bytes.LastIndex(ogg, []byte("OggS"))
+	binary.LittleEndian.PutUint64(ogg[last+6:], uint64(granule))
+	return ogg
+}
+
+// encodeTestMP3 build mono mpeg1 layer III frames at 128kbps 44.1kHz, the global gain grow with the frame index
+func encodeTestMP3(frames int) []byte {
+	content := []byte("ID3\x04\x00\x00\x00\x00\x00\x00")
+	for i := 0; i < frames; i++ {
+		frame := make([]byte, 417)
+		copy(frame, []byte{0xFF, 0xFB, 0x90, 0xC0})
+		// big_values start at bit 30 and global_gain at bit 39 of the side info
+		bits := uint64(100)<<(64-30-9) | uint64(100+i)<<(64-39-8)
+		binary.BigEndian.PutUint64(frame[4:], bits)
+		content = append(content, frame...)
+	}
+	return content
+}
+
+func Test_postChatStickerHandler(t *testing.T) {
+	validConvId := util.RandomUUID()
+	validUserId := util.RandomUUID()
+	validStickerId := util.RandomUUID()
+	validMatch := matchEntity.MatchDAO{
+		Id:            validConvId,
+		RequestFrom:   validUserId,
+		RequestTo:     util.RandomUUID(),
+		RequestStatus: string(matchEntity.Accepted),
+	}
+
+	tests := []struct {
+		name      string
+		reqBody   string
+		setupFunc func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:    "valid sticker",
+			reqBody: fmt.Sprintf(`{"stickerId":"%s"}`, validStickerId),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(1).
+					DoAndReturn(func(chat *chatEntity.DAO) error {
+						require.NotNil(t, chat.Sticker)
+						assert.Equal(t, validStickerId, chat.Sticker.Id)
+						assert.Empty(t, chat.Messages)
+						assert.Nil(t, chat.Attachment)
+						return nil
+					})
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:    "stickerId not uuid",
+			reqBody: fmt.Sprintf(`{"stickerId":"%s"}`, util.RandomString(12)),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Any()).Times(0)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]any{
+					"stickerId": "must be valid uuid",
+				},
+			},
+		},
+		{
+			name:    "archived sticker",
+			reqBody: fmt.Sprintf(`{"stickerId":"%s"}`, validStickerId),
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().InsertNewChat(gomock.Any()).Times(1).
+					Return(common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrRefNotFound23503, "sticker is invalid"))
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "sticker is invalid",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			chatRepo := mockrepo.NewMockChat(ctrl)
+			tt.setupFunc(t, matchRepo, chatRepo)
+			chatH := NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), nil, nil)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, validUserId)
+			c.Set(keyConvId, validConvId)
+			c.Request = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/%s/chat-sticker", validConvId), strings.NewReader(tt.reqBody))
+
+			chatH.postChatStickerHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			require.Contains(t, rr.Header().Get("Content-Type"), "application/json")
+			if tt.wantResp != nil {
+				expResBody, err := json.Marshal(tt.wantResp)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+			}
+		})
+	}
+}
+
+func Test_getMessagesHandler(t *testing.T) {
+	validConvId := util.RandomUUID()
+	validUserId := util.RandomUUID()
+	validChatId := util.RandomUUID()
+	at := time.Now().UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		params    map[string]string
+		setupRepo func(chatRepo *mockrepo.MockChat)
+		wantCode  int
+		wantChats []map[string]any
+	}{
+		{
+			name: "valid without cursor",
+			setupRepo: func(chatRepo *mockrepo.MockChat) {
+				chatRepo.EXPECT().SelectChat(gomock.Eq(validConvId), gomock.Eq(validUserId), gomock.Eq(chat.Filter{})).Times(1).Return([]chatEntity.DAO{}, nil)
+			},
+			wantCode:  http.StatusOK,
+			wantChats: []map[string]any{},
+		},
+		{
+			name: "reactions is aggregated for the caller",
+			setupRepo: func(chatRepo *mockrepo.MockChat) {
+				chatRepo.EXPECT().SelectChat(gomock.Eq(validConvId), gomock.Eq(validUserId), gomock.Eq(chat.Filter{})).Times(1).Return([]chatEntity.DAO{
+					{
+						Id:             validChatId,
+						ConversationId: validConvId,
+						Author:         validUserId,
+						Messages:       "helo",
+						SentAt:         at,
+						Reactions: []chatEntity.Reaction{
+							{Emoji: "👍", Count: 2, Reacted: true},
+						},
+					},
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantChats: []map[string]any{
+				{
+					"id":             validChatId,
+					"conversationId": validConvId,
+					"author":         validUserId,
+					"messages":       "helo",
+					"replyTo":        nil,
+					"sentAt":         at,
+					"deliveredAt":    nil,
+					"seenAt":         nil,
+					"editedAt":       nil,
+					"deletedAt":      nil,
+					"attachment":     nil,
+					"sticker":        nil,
+					"kind":           "text",
+					"reactions": []map[string]any{
+						{"emoji": "👍", "count": 2, "reacted": true},
+					},
+				},
+			},
+		},
+		{
+			name: "blob key is signed",
+			setupRepo: func(chatRepo *mockrepo.MockChat) {
+				thumbnail := "chat-thumbnail/thumb.jpg"
+				width, height := 40, 20
+				chatRepo.EXPECT().SelectChat(gomock.Eq(validConvId), gomock.Eq(validUserId), gomock.Eq(chat.Filter{})).Times(1).Return([]chatEntity.DAO{
+					{
+						Id:             validChatId,
+						ConversationId: validConvId,
+						Author:         validUserId,
+						SentAt:         at,
+						Attachment: &chatEntity.Attachment{
+							ChatId:        validChatId,
+							BlobLink:      "chat-attachment/photo.png",
+							MediaType:     "image/png",
+							Width:         &width,
+							Height:        &height,
+							ThumbnailLink: &thumbnail,
+						},
+					},
+					{
+						Id:             validChatId,
+						ConversationId: validConvId,
+						Author:         validUserId,
+						SentAt:         at,
+						Sticker: &chatEntity.Sticker{
+							Id:        validChatId,
+							PackId:    validConvId,
+							BlobLink:  "sticker/wave.png",
+							MediaType: "image/png",
+						},
+					},
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantChats: []map[string]any{
+				{
+					"id":             validChatId,
+					"conversationId": validConvId,
+					"author":         validUserId,
+					"messages":       "",
+					"replyTo":        nil,
+					"sentAt":         at,
+					"deliveredAt":    nil,
+					"seenAt":         nil,
+					"editedAt":       nil,
+					"deletedAt":      nil,
+					"attachment": map[string]any{
+						"blobLink":      testBlobHost + "chat-attachment/photo.png",
+						"mediaType":     "image/png",
+						"width":         40,
+						"height":        20,
+						"thumbnailLink": testBlobHost + "chat-thumbnail/thumb.jpg",
+					},
+					"sticker": nil,
+					"kind":    "attachment",
+				},
+				{
+					"id":             validChatId,
+					"conversationId": validConvId,
+					"author":         validUserId,
+					"messages":       "",
+					"replyTo":        nil,
+					"sentAt":         at,
+					"deliveredAt":    nil,
+					"seenAt":         nil,
+					"editedAt":       nil,
+					"deletedAt":      nil,
+					"attachment":     nil,
+					"sticker": map[string]any{
+						"id":        validChatId,
+						"packId":    validConvId,
+						"blobLink":  testBlobHost + "sticker/wave.png",
+						"mediaType": "image/png",
+					},
+					"kind": "sticker",
+				},
+			},
+		},
+		{
+			name: "valid with cursor",
+			params: map[string]string{
+				"limit":  "30",
+				"at":     at.Format(time.RFC3339),
+				"chatId": validChatId,
+				"after":  "true",
+			},
+			setupRepo: func(chatRepo *mockrepo.MockChat) {
+				chatRepo.EXPECT().SelectChat(gomock.Eq(validConvId), gomock.Eq(validUserId), gomock.Any()).Times(1).
+					DoAndReturn(func(_, _ string, filter chat.Filter) ([]chatEntity.DAO, error) {
+						assert.Equal(t, 30, filter.Limit)
+						require.NotNil(t, filter.Cursor)
+						assert.True(t, at.Equal(filter.Cursor.At))
+						assert.Equal(t, validChatId, filter.Cursor.Id)
+						assert.True(t, filter.Cursor.After)
+						return []chatEntity.DAO{}, nil
+					})
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "limit out of range",
+			params: map[string]string{
+				"limit": "5",
+			},
+			setupRepo: func(chatRepo *mockrepo.MockChat) {
+				chatRepo.EXPECT().SelectChat(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			chatRepo := mockrepo.NewMockChat(ctrl)
+			tt.setupRepo(chatRepo)
+			chatH := NewChat(service.NewChat(chatRepo, mockrepo.NewMockMatch(ctrl), testBlobUrl), nil, nil)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, validUserId)
+			c.Set(keyConvId, validConvId)
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/%s/chat", validConvId), nil)
+			queryParams := req.URL.Query()
+			for k, v := range tt.params {
+				queryParams.Add(k, v)
+			}
+			req.URL.RawQuery = queryParams.Encode()
+			c.Request = req
+
+			chatH.getMessagesHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			require.Contains(t, rr.Header().Get("Content-Type"), "application/json")
+			if tt.wantChats != nil {
+				expResBody, err := json.Marshal(map[string]any{
+					"status": "success",
+					"data": map[string]any{
+						"chats": tt.wantChats,
+					},
+				})
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+			}
+		})
+	}
+}
+
+func Test_patchMessageHandler(t *testing.T) {
+	validConvId := util.RandomUUID()
+	validUserId := util.RandomUUID()
+	validChatId := util.RandomUUID()
+	validMatch := matchEntity.MatchDAO{
+		Id:            validConvId,
+		RequestFrom:   validUserId,
+		RequestTo:     util.RandomUUID(),
+		RequestStatus: string(matchEntity.Accepted),
+	}
+	chatSentAt := func(sentAt time.Time) chatEntity.DAO {
+		return chatEntity.DAO{
+			Id:             validChatId,
+			ConversationId: validConvId,
+			Author:         validUserId,
+			Messages:       "helo",
+			SentAt:         sentAt,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		reqBody   string
+		setupFunc func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:    "valid edit",
+			reqBody: `{"message":"  hello  "}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(validChatId)).Times(1).Return(chatSentAt(time.Now().Add(-time.Minute)), nil)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().UpdateChatMessage(gomock.Eq(validChatId), gomock.Eq("hello"), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_, messages string, editedAt, editableSince time.Time) (chatEntity.DAO, error) {
+						assert.Equal(t, chatEntity.EditWindow, editedAt.Sub(editableSince))
+						edited := chatSentAt(time.Now().Add(-time.Minute))
+						edited.Messages = messages
+						edited.EditedAt = sql.NullTime{Valid: true, Time: editedAt}
+						return edited, nil
+					})
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:    "message is required",
+			reqBody: `{"message":""}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				chatRepo.EXPECT().SelectChatById(gomock.Any()).Times(0)
+				chatRepo.EXPECT().UpdateChatMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]string{
+					"message": "must not empty and max characters is 4096",
+				},
+			},
+		},
+		{
+			name:    "blank message",
+			reqBody: `{"message":"   "}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				chatRepo.EXPECT().SelectChatById(gomock.Any()).Times(0)
+				chatRepo.EXPECT().UpdateChatMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "message must not be empty",
+			},
+		},
+		{
+			name:    "edit window has passed",
+			reqBody: `{"message":"hello"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(validChatId)).Times(1).
+					Return(chatSentAt(time.Now().Add(-chatEntity.EditWindow-time.Minute)), nil)
+				chatRepo.EXPECT().UpdateChatMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "edit window has passed",
+			},
+		},
+		{
+			name:    "deleted chat",
+			reqBody: `{"message":"hello"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				tombstone := chatSentAt(time.Now().Add(-time.Minute))
+				tombstone.Messages = ""
+				tombstone.DeletedAt = sql.NullTime{Valid: true, Time: time.Now()}
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(validChatId)).Times(1).Return(tombstone, nil)
+				chatRepo.EXPECT().UpdateChatMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusGone,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "chat is already deleted",
+			},
+		},
+		{
+			name:    "attachment chat",
+			reqBody: `{"message":"hello"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				media := chatSentAt(time.Now().Add(-time.Minute))
+				media.Messages = ""
+				media.Attachment = &chatEntity.Attachment{
+					ChatId:    validChatId,
+					BlobLink:  "chat-attachment/" + util.RandomUUID() + ".ogg",
+					MediaType: "application/ogg",
+				}
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(validChatId)).Times(1).Return(media, nil)
+				chatRepo.EXPECT().UpdateChatMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "only text chat could be edited",
+			},
+		},
+		{
+			name:    "unchanged message is not recorded",
+			reqBody: `{"message":"helo"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(validChatId)).Times(1).Return(chatSentAt(time.Now().Add(-time.Minute)), nil)
+				matchRepo.EXPECT().GetMatchById(gomock.Any()).Times(0)
+				chatRepo.EXPECT().UpdateChatMessage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:    "edited concurrently after the window",
+			reqBody: `{"message":"hello"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(validChatId)).Times(1).Return(chatSentAt(time.Now().Add(-time.Minute)), nil)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().UpdateChatMessage(gomock.Eq(validChatId), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(chatEntity.DAO{}, common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "chat is no longer editable"))
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "chat is no longer editable",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			chatRepo := mockrepo.NewMockChat(ctrl)
+			tt.setupFunc(t, matchRepo, chatRepo)
+			chatH := NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), nil, nil)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, validUserId)
+			c.Set(keyConvId, validConvId)
+			c.Set(keyChatId, validChatId)
+			c.Request = httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/api/v1/%s/chat/%s", validConvId, validChatId), strings.NewReader(tt.reqBody))
+
+			chatH.patchMessageHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			require.Contains(t, rr.Header().Get("Content-Type"), "application/json")
+			if tt.wantResp != nil {
+				expResBody, err := json.Marshal(tt.wantResp)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+			}
+		})
+	}
+}
+
+func Test_getMessageHistoryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	validChatId := util.RandomUUID()
+	editedAt := time.Now().UTC().Truncate(time.Second)
+
+	chatRepo := mockrepo.NewMockChat(ctrl)
+	chatRepo.EXPECT().SelectChatHistory(gomock.Eq(validChatId)).Times(1).Return([]chatEntity.History{
+		{Messages: "helo", EditedAt: editedAt},
+	}, nil)
+	chatH := NewChat(service.NewChat(chatRepo, mockrepo.NewMockMatch(ctrl), testBlobUrl), nil, nil)
+
+	rr := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rr)
+	c.Set(keyChatId, validChatId)
+	c.Request = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/%s/chat/%s/history", util.RandomUUID(), validChatId), nil)
+
+	chatH.getMessageHistoryHandler(c)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	expResBody, err := json.Marshal(map[string]any{
+		"status": "success",
+		"data": map[string]any{
+			"history": []map[string]any{
+				{"messages": "helo", "editedAt": editedAt},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expResBody), rr.Body.String())
+}
+
+func Test_deleteMessagesByIdHandler(t *testing.T) {
+	validConvId := util.RandomUUID()
+	validChatId := util.RandomUUID()
+	validMatch := matchEntity.MatchDAO{
+		Id:            validConvId,
+		RequestFrom:   util.RandomUUID(),
+		RequestTo:     util.RandomUUID(),
+		RequestStatus: string(matchEntity.Accepted),
+	}
+
+	tests := []struct {
+		name      string
+		setupRepo func(chatRepo *mockrepo.MockChat)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name: "valid delete",
+			setupRepo: func(chatRepo *mockrepo.MockChat) {
+				chatRepo.EXPECT().DeleteChatById(gomock.Eq(validChatId), gomock.Any()).Times(1).Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantResp: map[string]any{
+				"status":   "success",
+				"messages": "chat deleted",
+			},
+		},
+		{
+			name: "chat already deleted",
+			setupRepo: func(chatRepo *mockrepo.MockChat) {
+				chatRepo.EXPECT().DeleteChatById(gomock.Eq(validChatId), gomock.Any()).Times(1).
+					Return(common.WrapError(sql.ErrNoRows, common.ErrRefNotFound23503))
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "provided chatId in url is not found!",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			chatRepo := mockrepo.NewMockChat(ctrl)
+			tt.setupRepo(chatRepo)
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+			chatH := NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), nil, nil)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyConvId, validConvId)
+			c.Set(keyChatId, validChatId)
+			c.Request = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/%s/chat/%s", validConvId, validChatId), nil)
+
+			chatH.deleteMessagesByIdHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			expResBody, err := json.Marshal(tt.wantResp)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expResBody), rr.Body.String())
+		})
+	}
+}
+
+func Test_postReactionHandler(t *testing.T) {
+	validConvId := util.RandomUUID()
+	validUserId := util.RandomUUID()
+	validChatId := util.RandomUUID()
+	validMatch := matchEntity.MatchDAO{
+		Id:            validConvId,
+		RequestFrom:   util.RandomUUID(),
+		RequestTo:     validUserId,
+		RequestStatus: string(matchEntity.Accepted),
+	}
+
+	tests := []struct {
+		name      string
+		reqBody   string
+		setupFunc func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:    "valid reaction",
+			reqBody: `{"emoji":"👍🏽"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().InsertReaction(gomock.Eq(validChatId), gomock.Eq(validUserId), gomock.Eq("👍🏽")).Times(1).Return(true, nil)
+			},
+			wantCode: http.StatusOK,
+			wantResp: map[string]any{
+				"status":  "success",
+				"message": "reaction added",
+			},
+		},
+		{
+			name:    "already reacted",
+			reqBody: `{"emoji":"👍"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().InsertReaction(gomock.Eq(validChatId), gomock.Eq(validUserId), gomock.Eq("👍")).Times(1).Return(false, nil)
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(validChatId)).Times(1).Return(chatEntity.DAO{Id: validChatId, ConversationId: validConvId}, nil)
+			},
+			wantCode: http.StatusOK,
+			wantResp: map[string]any{
+				"status":  "success",
+				"message": "reaction added",
+			},
+		},
+		{
+			name:    "deleted chat",
+			reqBody: `{"emoji":"👍"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().InsertReaction(gomock.Eq(validChatId), gomock.Eq(validUserId), gomock.Eq("👍")).Times(1).Return(false, nil)
+				chatRepo.EXPECT().SelectChatById(gomock.Eq(validChatId)).Times(1).Return(chatEntity.DAO{
+					Id:             validChatId,
+					ConversationId: validConvId,
+					DeletedAt:      sql.NullTime{Valid: true, Time: time.Now()},
+				}, nil)
+			},
+			wantCode: http.StatusGone,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "chat is already deleted",
+			},
+		},
+		{
+			name:    "not an emoji",
+			reqBody: `{"emoji":"lol"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Any()).Times(0)
+				chatRepo.EXPECT().InsertReaction(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]string{
+					"emoji": "must be required and must be a single emoji",
+				},
+			},
+		},
+		{
+			name:    "more than one emoji",
+			reqBody: `{"emoji":"👍👍👍👍👍👍👍👍👍👍👍👍👍👍👍👍👍"}`,
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Any()).Times(0)
+				chatRepo.EXPECT().InsertReaction(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]string{
+					"emoji": "must be required and must be a single emoji",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			chatRepo := mockrepo.NewMockChat(ctrl)
+			tt.setupFunc(t, matchRepo, chatRepo)
+			chatH := NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), nil, nil)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, validUserId)
+			c.Set(keyConvId, validConvId)
+			c.Set(keyChatId, validChatId)
+			c.Request = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/%s/chat/%s/reactions", validConvId, validChatId), strings.NewReader(tt.reqBody))
+
+			chatH.postReactionHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			expResBody, err := json.Marshal(tt.wantResp)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expResBody), rr.Body.String())
+		})
+	}
+}
+
+func Test_deleteReactionHandler(t *testing.T) {
+	validConvId := util.RandomUUID()
+	validUserId := util.RandomUUID()
+	validChatId := util.RandomUUID()
+	validMatch := matchEntity.MatchDAO{
+		Id:            validConvId,
+		RequestFrom:   util.RandomUUID(),
+		RequestTo:     validUserId,
+		RequestStatus: string(matchEntity.Accepted),
+	}
+
+	tests := []struct {
+		name      string
+		emoji     string
+		setupFunc func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:  "valid remove",
+			emoji: "😂",
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().DeleteReaction(gomock.Eq(validChatId), gomock.Eq(validUserId), gomock.Eq("😂")).Times(1).Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantResp: map[string]any{
+				"status":  "success",
+				"message": "reaction removed",
+			},
+		},
+		{
+			name:  "reaction not found",
+			emoji: "😂",
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validConvId)).Times(1).Return(validMatch, nil)
+				chatRepo.EXPECT().DeleteReaction(gomock.Eq(validChatId), gomock.Eq(validUserId), gomock.Eq("😂")).Times(1).
+					Return(common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "reaction not found"))
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "reaction not found",
+			},
+		},
+		{
+			name:  "not an emoji",
+			emoji: "lol",
+			setupFunc: func(t *testing.T, matchRepo *mockrepo.MockMatch, chatRepo *mockrepo.MockChat) {
+				matchRepo.EXPECT().GetMatchById(gomock.Any()).Times(0)
+				chatRepo.EXPECT().DeleteReaction(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusBadRequest,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "emoji in uri must be a single emoji",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			chatRepo := mockrepo.NewMockChat(ctrl)
+			tt.setupFunc(t, matchRepo, chatRepo)
+			chatH := NewChat(service.NewChat(chatRepo, matchRepo, testBlobUrl), nil, nil)
+
+			rr := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(rr)
+			r.DELETE("/:conversationId/chat/:chatId/reactions/:emoji", func(c *gin.Context) {
+				c.Set(keyUserId, validUserId)
+				c.Set(keyConvId, validConvId)
+				c.Set(keyChatId, validChatId)
+			}, chatH.deleteReactionHandler)
+			req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/%s/chat/%s/reactions/%s", validConvId, validChatId, url.PathEscape(tt.emoji)), nil)
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			expResBody, err := json.Marshal(tt.wantResp)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expResBody), rr.Body.String())
+		})
+	}
+}
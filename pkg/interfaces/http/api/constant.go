@@ -2,8 +2,18 @@ package api
 
 const (
 	keyUserId     = "userId"
+	keyRole       = "role"
 	keyInterestId = "interestId"
 	keyMatchId    = "matchId"
 	keyConvId     = "convId"
 	keyChatId     = "chatId"
+	keyPackId     = "packId"
+	keyStickerId  = "stickerId"
+	keyPictureId  = "pictureId"
+	keyReportId   = "reportId"
+	keyExportId   = "exportId"
+
+	keyTargetUserId = "targetUserId"
+
+	keyParticipants = "participants"
 )
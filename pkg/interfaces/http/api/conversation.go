@@ -4,25 +4,34 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/xyedo/blindate/pkg/domain/conversation"
 	convEntity "github.com/xyedo/blindate/pkg/domain/conversation/entities"
+	onlineEntity "github.com/xyedo/blindate/pkg/domain/online/entities"
+	"github.com/xyedo/blindate/pkg/util"
 )
 
-// TODO: Create conversation_test.go
 type conversationSvc interface {
-	CreateConversation(matchId string) (string, error)
+	CreateConversation(matchId, userId string) (string, error)
 	FindConversationById(convoId string) (convEntity.DTO, error)
-	GetConversationByUserId(userId string) ([]convEntity.DTO, error)
+	GetConversationByUserId(userId string, filter *conversation.Filter) ([]convEntity.DTO, error)
 	DeleteConversationById(convoId string) error
+	PutState(convoId, userId string, newState convEntity.NewState) (convEntity.State, error)
 }
 
-func NewConvo(convSvc conversationSvc) *Conversation {
+type presenceSvc interface {
+	GetPresence(viewerId, userId string, isMatched bool) (onlineEntity.Presence, error)
+}
+
+func NewConvo(convSvc conversationSvc, presenceSvc presenceSvc) *Conversation {
 	return &Conversation{
-		convSvc: convSvc,
+		convSvc:     convSvc,
+		presenceSvc: presenceSvc,
 	}
 }
 
 type Conversation struct {
-	convSvc conversationSvc
+	convSvc     conversationSvc
+	presenceSvc presenceSvc
 }
 
 func (conv *Conversation) postConversationHandler(c *gin.Context) {
@@ -40,7 +49,8 @@ func (conv *Conversation) postConversationHandler(c *gin.Context) {
 		}
 		return
 	}
-	_, err = conv.convSvc.CreateConversation(input.MatchId)
+	userId := c.GetString(keyUserId)
+	_, err = conv.convSvc.CreateConversation(input.MatchId, userId)
 	if err != nil {
 		jsonHandleError(c, err)
 		return
@@ -54,31 +64,90 @@ func (conv *Conversation) postConversationHandler(c *gin.Context) {
 }
 
 func (conv *Conversation) getConversationByUserId(c *gin.Context) {
+	var query struct {
+		Cursor   *string `form:"cursor"`
+		Limit    *int    `form:"limit" binding:"omitempty,min=1,max=50"`
+		Archived *bool   `form:"archived"`
+		Unread   *bool   `form:"unread"`
+		Revealed *bool   `form:"revealed"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		if errMap := util.ReadValidationErr(err, map[string]string{
+			"Limit": "if provided, value must in between 1-50",
+		}); len(errMap) != 0 {
+			errValidationResp(c, errMap)
+			return
+		}
+		errBadRequestResp(c, "archived, unread and revealed must be boolean, limit must be number")
+		return
+	}
+	filter := conversation.Filter{Limit: 20}
+	if query.Cursor != nil {
+		cursor, err := conversation.DecodeCursor(*query.Cursor)
+		if err != nil {
+			errBadRequestResp(c, "cursor is invalid, use the nextCursor from previous response")
+			return
+		}
+		filter.Cursor = &cursor
+	}
+	if query.Limit != nil {
+		filter.Limit = *query.Limit
+	}
+	if query.Archived != nil {
+		filter.Archived = *query.Archived
+	}
+	if query.Unread != nil {
+		filter.UnreadOnly = *query.Unread
+	}
+	filter.Revealed = query.Revealed
 	userId := c.GetString(keyUserId)
-	convs, err := conv.convSvc.GetConversationByUserId(userId)
+	convs, err := conv.convSvc.GetConversationByUserId(userId, &filter)
 	if err != nil {
 		jsonHandleError(c, err)
 		return
 	}
+	data := gin.H{
+		"conversations": convs,
+	}
+	// full page means there might be more
+	if len(convs) == filter.Limit {
+		data["nextCursor"] = conversation.CursorOf(convs[len(convs)-1]).Encode()
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
-		"data": gin.H{
-			"conversations": convs,
-		},
+		"data":   data,
 	})
 }
 func (conv *Conversation) getConversationById(c *gin.Context) {
 	convId := c.GetString(keyConvId)
+	userId := c.GetString(keyUserId)
 	convRet, err := conv.convSvc.FindConversationById(convId)
 	if err != nil {
 		jsonHandleError(c, err)
 		return
 	}
+	data := gin.H{
+		"conversation": convRet,
+	}
+	// partner presence only for the member, conversation exist only after the match accepted
+	var partnerId string
+	switch userId {
+	case convRet.FromUser.ID:
+		partnerId = convRet.ToUser.ID
+	case convRet.ToUser.ID:
+		partnerId = convRet.FromUser.ID
+	}
+	if partnerId != "" {
+		presence, err := conv.presenceSvc.GetPresence(userId, partnerId, true)
+		if err != nil {
+			jsonHandleError(c, err)
+			return
+		}
+		data["partnerPresence"] = presence
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
-		"data": gin.H{
-			"conversation": convRet,
-		},
+		"data":   data,
 	})
 }
 func (conv *Conversation) deleteConversationById(c *gin.Context) {
@@ -92,3 +161,32 @@ func (conv *Conversation) deleteConversationById(c *gin.Context) {
 		"message": "deleting conversationId success",
 	})
 }
+
+func (conv *Conversation) putConversationStateHandler(c *gin.Context) {
+	var input convEntity.NewState
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		errjson := jsonBindingErrResp(err, c, map[string]string{
+			"pinned":   "required and must be boolean",
+			"archived": "required and must be boolean",
+		})
+		if errjson != nil {
+			errServerResp(c, err)
+			return
+		}
+		return
+	}
+	convId := c.GetString(keyConvId)
+	userId := c.GetString(keyUserId)
+	state, err := conv.convSvc.PutState(convId, userId, input)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"state": state,
+		},
+	})
+}
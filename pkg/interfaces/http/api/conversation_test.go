@@ -0,0 +1,588 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/applications/service"
+	"github.com/xyedo/blindate/pkg/common"
+	"github.com/xyedo/blindate/pkg/domain/conversation"
+	convEntity "github.com/xyedo/blindate/pkg/domain/conversation/entities"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+	onlineEntity "github.com/xyedo/blindate/pkg/domain/online/entities"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_getConversationById(t *testing.T) {
+	validConvId := util.RandomUUID()
+	validUserId := util.RandomUUID()
+	partnerId := util.RandomUUID()
+	lastOnline := time.Now().Add(-2 * 24 * time.Hour).UTC().Truncate(time.Second)
+
+	newConv := func() convEntity.DTO {
+		var conv convEntity.DTO
+		conv.Id = validConvId
+		conv.FromUser.ID = validUserId
+		conv.FromUser.Alias = "me"
+		conv.ToUser.ID = partnerId
+		conv.ToUser.Alias = "partner"
+		conv.RequestStatus = string(matchEntity.Accepted)
+		conv.RevealStatus = string(matchEntity.Requested)
+		return conv
+	}
+	tests := []struct {
+		name        string
+		userId      string
+		partner     onlineEntity.DTO
+		wantCode    int
+		wantPresent bool
+		wantPres    map[string]any
+	}{
+		{
+			name:   "partner with exact last seen",
+			userId: validUserId,
+			partner: onlineEntity.DTO{
+				UserId:            partnerId,
+				LastOnline:        lastOnline,
+				Visibility:        onlineEntity.VisibleToEveryone,
+				LastSeenPrecision: onlineEntity.LastSeenExact,
+			},
+			wantCode:    http.StatusOK,
+			wantPresent: true,
+			wantPres: map[string]any{
+				"isOnline":   false,
+				"lastSeen":   onlineEntity.LastSeenRecently,
+				"lastOnline": lastOnline,
+			},
+		},
+		{
+			name:   "partner with coarse last seen",
+			userId: validUserId,
+			partner: onlineEntity.DTO{
+				UserId:            partnerId,
+				LastOnline:        lastOnline.Add(-3 * 24 * time.Hour),
+				Visibility:        onlineEntity.VisibleToMatches,
+				LastSeenPrecision: onlineEntity.LastSeenCoarse,
+			},
+			wantCode:    http.StatusOK,
+			wantPresent: true,
+			wantPres: map[string]any{
+				"isOnline": false,
+				"lastSeen": onlineEntity.LastSeenWithinWeek,
+			},
+		},
+		{
+			name:   "partner online with coarse last seen",
+			userId: validUserId,
+			partner: onlineEntity.DTO{
+				UserId:            partnerId,
+				LastOnline:        lastOnline,
+				IsOnline:          true,
+				Visibility:        onlineEntity.VisibleToEveryone,
+				LastSeenPrecision: onlineEntity.LastSeenCoarse,
+			},
+			wantCode:    http.StatusOK,
+			wantPresent: true,
+			wantPres: map[string]any{
+				"isOnline": true,
+				"lastSeen": onlineEntity.LastSeenOnline,
+			},
+		},
+		{
+			name:   "partner hide presence",
+			userId: validUserId,
+			partner: onlineEntity.DTO{
+				UserId:            partnerId,
+				LastOnline:        lastOnline,
+				IsOnline:          true,
+				Visibility:        onlineEntity.VisibleToNobody,
+				LastSeenPrecision: onlineEntity.LastSeenExact,
+			},
+			wantCode:    http.StatusOK,
+			wantPresent: true,
+			wantPres: map[string]any{
+				"lastSeen": onlineEntity.LastSeenHidden,
+			},
+		},
+		{
+			name:        "not a member got no presence",
+			userId:      util.RandomUUID(),
+			wantCode:    http.StatusOK,
+			wantPresent: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			convRepo := mockrepo.NewMockConversation(ctrl)
+			convRepo.EXPECT().SelectConversationById(gomock.Eq(validConvId)).Times(1).Return(newConv(), nil)
+			onlineRepo := mockrepo.NewMockOnline(ctrl)
+			if tt.wantPresent {
+				onlineRepo.EXPECT().SelectOnline(gomock.Eq(partnerId)).Times(1).Return(tt.partner, nil)
+			} else {
+				onlineRepo.EXPECT().SelectOnline(gomock.Any()).Times(0)
+			}
+			convSvc := service.NewConversation(convRepo, mockrepo.NewMockMatch(ctrl), testBlobUrl)
+			convH := NewConvo(convSvc, service.NewOnline(onlineRepo))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, tt.userId)
+			c.Set(keyConvId, validConvId)
+			c.Request = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/%s/", validConvId), nil)
+
+			convH.getConversationById(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			var resp struct {
+				Data map[string]json.RawMessage `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Contains(t, resp.Data, "conversation")
+			presence, ok := resp.Data["partnerPresence"]
+			require.Equal(t, tt.wantPresent, ok)
+			if tt.wantPresent {
+				expPresence, err := json.Marshal(tt.wantPres)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expPresence), string(presence))
+			}
+		})
+	}
+}
+
+func Test_getConversationByUserId(t *testing.T) {
+	validUserId := util.RandomUUID()
+	revealed := true
+	validCursor := conversation.Cursor{
+		Pinned: true,
+		At:     time.Now().UTC().Truncate(time.Microsecond),
+		Id:     util.RandomUUID(),
+	}
+	fullPage := func(n int) []convEntity.DTO {
+		convs := make([]convEntity.DTO, 0, n)
+		for i := 0; i < n; i++ {
+			convs = append(convs, convEntity.DTO{
+				Id:    util.RandomUUID(),
+				State: &convEntity.State{LastActivityAt: time.Now().UTC()},
+			})
+		}
+		return convs
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		wantFilter     *conversation.Filter
+		repoResp       []convEntity.DTO
+		wantCode       int
+		wantNextCursor bool
+		// wantPictures is the from and to user picture of each conversation
+		wantPictures [][2]string
+		wantResp     map[string]any
+	}{
+		{
+			name:       "default list the inbox",
+			query:      "",
+			wantFilter: &conversation.Filter{Limit: 20},
+			repoResp:   fullPage(3),
+			wantCode:   http.StatusOK,
+		},
+		{
+			name:       "picture is signed only when revealed",
+			query:      "",
+			wantFilter: &conversation.Filter{Limit: 20},
+			repoResp: func() []convEntity.DTO {
+				convs := fullPage(2)
+				for i := range convs {
+					convs[i].FromUser.ProfilePic = "profile-picture/from.png"
+					convs[i].ToUser.ProfilePic = "profile-picture/to.png"
+				}
+				convs[0].RevealStatus = string(matchEntity.Accepted)
+				convs[1].RevealStatus = string(matchEntity.Requested)
+				return convs
+			}(),
+			wantCode: http.StatusOK,
+			wantPictures: [][2]string{
+				{testBlobHost + "profile-picture/from.png", testBlobHost + "profile-picture/to.png"},
+				{"", ""},
+			},
+		},
+		{
+			name:           "full page has next cursor",
+			query:          "?limit=2",
+			wantFilter:     &conversation.Filter{Limit: 2},
+			repoResp:       fullPage(2),
+			wantCode:       http.StatusOK,
+			wantNextCursor: true,
+		},
+		{
+			name:  "cursor and filters",
+			query: "?cursor=" + validCursor.Encode() + "&archived=true&unread=true&revealed=true",
+			wantFilter: &conversation.Filter{
+				Cursor:     &validCursor,
+				Limit:      20,
+				Archived:   true,
+				UnreadOnly: true,
+				Revealed:   &revealed,
+			},
+			repoResp: fullPage(0),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "invalid cursor",
+			query:    "?cursor=bm90LWEtY3Vyc29y",
+			wantCode: http.StatusBadRequest,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "cursor is invalid, use the nextCursor from previous response",
+			},
+		},
+		{
+			name:     "invalid boolean",
+			query:    "?revealed=maybe",
+			wantCode: http.StatusBadRequest,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "archived, unread and revealed must be boolean, limit must be number",
+			},
+		},
+		{
+			name:     "limit out of range",
+			query:    "?limit=100",
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]string{
+					"Limit": "if provided, value must in between 1-50",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			convRepo := mockrepo.NewMockConversation(ctrl)
+			if tt.wantFilter != nil {
+				convRepo.EXPECT().SelectConversationByUserId(gomock.Eq(validUserId), gomock.Eq(tt.wantFilter)).Times(1).
+					Return(tt.repoResp, nil)
+			} else {
+				convRepo.EXPECT().SelectConversationByUserId(gomock.Any(), gomock.Any()).Times(0)
+			}
+			convSvc := service.NewConversation(convRepo, mockrepo.NewMockMatch(ctrl), testBlobUrl)
+			convH := NewConvo(convSvc, service.NewOnline(mockrepo.NewMockOnline(ctrl)))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, validUserId)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/conversation"+tt.query, nil)
+
+			convH.getConversationByUserId(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.wantResp != nil {
+				expResBody, err := json.Marshal(tt.wantResp)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+				return
+			}
+			var resp struct {
+				Data struct {
+					Conversations []convEntity.DTO `json:"conversations"`
+					NextCursor    *string          `json:"nextCursor"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			assert.Len(t, resp.Data.Conversations, len(tt.repoResp))
+			for i, pictures := range tt.wantPictures {
+				assert.Equal(t, pictures[0], resp.Data.Conversations[i].FromUser.ProfilePic)
+				assert.Equal(t, pictures[1], resp.Data.Conversations[i].ToUser.ProfilePic)
+			}
+			if !tt.wantNextCursor {
+				assert.Nil(t, resp.Data.NextCursor)
+				return
+			}
+			require.NotNil(t, resp.Data.NextCursor)
+			cursor, err := conversation.DecodeCursor(*resp.Data.NextCursor)
+			require.NoError(t, err)
+			last := tt.repoResp[len(tt.repoResp)-1]
+			assert.Equal(t, last.Id, cursor.Id)
+			assert.True(t, last.State.LastActivityAt.Equal(cursor.At))
+		})
+	}
+}
+
+func Test_putConversationStateHandler(t *testing.T) {
+	validConvId := util.RandomUUID()
+	validUserId := util.RandomUUID()
+	mutedUntil := time.Now().Add(8 * time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		reqBody   string
+		setupRepo func(convRepo *mockrepo.MockConversation)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:    "mute and pin",
+			reqBody: fmt.Sprintf(`{"mutedUntil":%q,"pinned":true,"archived":false}`, mutedUntil.Format(time.RFC3339)),
+			setupRepo: func(convRepo *mockrepo.MockConversation) {
+				convRepo.EXPECT().UpdateState(gomock.Eq(validConvId), gomock.Eq(validUserId), gomock.Any()).Times(1).
+					DoAndReturn(func(_, _ string, state convEntity.State) (convEntity.State, error) {
+						require.NotNil(t, state.MutedUntil)
+						assert.True(t, mutedUntil.Equal(*state.MutedUntil))
+						assert.True(t, state.Pinned)
+						assert.False(t, state.Archived)
+						state.UnreadCount = 3
+						state.LastActivityAt = mutedUntil.Add(-time.Hour)
+						return state, nil
+					})
+			},
+			wantCode: http.StatusOK,
+			wantResp: map[string]any{
+				"status": "success",
+				"data": map[string]any{
+					"state": map[string]any{
+						"unreadCount":    3,
+						"mutedUntil":     mutedUntil,
+						"pinned":         true,
+						"archived":       false,
+						"lastActivityAt": mutedUntil.Add(-time.Hour),
+					},
+				},
+			},
+		},
+		{
+			name:    "archived is required",
+			reqBody: `{"pinned":false}`,
+			setupRepo: func(convRepo *mockrepo.MockConversation) {
+				convRepo.EXPECT().UpdateState(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]string{
+					"archived": "required and must be boolean",
+				},
+			},
+		},
+		{
+			name:    "not a member",
+			reqBody: `{"pinned":false,"archived":true}`,
+			setupRepo: func(convRepo *mockrepo.MockConversation) {
+				convRepo.EXPECT().UpdateState(gomock.Eq(validConvId), gomock.Eq(validUserId), gomock.Any()).Times(1).
+					Return(convEntity.State{}, common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound))
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "resource not found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			convRepo := mockrepo.NewMockConversation(ctrl)
+			tt.setupRepo(convRepo)
+			convSvc := service.NewConversation(convRepo, mockrepo.NewMockMatch(ctrl), testBlobUrl)
+			convH := NewConvo(convSvc, service.NewOnline(mockrepo.NewMockOnline(ctrl)))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, validUserId)
+			c.Set(keyConvId, validConvId)
+			c.Request = httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/%s/state", validConvId), strings.NewReader(tt.reqBody))
+
+			convH.putConversationStateHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			expResBody, err := json.Marshal(tt.wantResp)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expResBody), rr.Body.String())
+		})
+	}
+}
+
+func Test_postConversationHandler(t *testing.T) {
+	validMatchId := util.RandomUUID()
+	validUserId := util.RandomUUID()
+	partnerId := util.RandomUUID()
+	validMatch := matchEntity.MatchDAO{
+		Id:            validMatchId,
+		RequestFrom:   partnerId,
+		RequestTo:     validUserId,
+		RequestStatus: string(matchEntity.Accepted),
+	}
+
+	tests := []struct {
+		name      string
+		userId    string
+		reqBody   string
+		setupRepo func(convRepo *mockrepo.MockConversation, matchRepo *mockrepo.MockMatch)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:    "valid conversation",
+			userId:  validUserId,
+			reqBody: fmt.Sprintf(`{"matchId":%q}`, validMatchId),
+			setupRepo: func(convRepo *mockrepo.MockConversation, matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validMatchId)).Times(1).Return(validMatch, nil)
+				convRepo.EXPECT().InsertConversation(gomock.Eq(validMatchId)).Times(1).Return(validMatchId, nil)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: map[string]any{
+				"status": "success",
+				"data": map[string]any{
+					"conversationId": validMatchId,
+				},
+			},
+		},
+		{
+			name:    "matchId must be uuid",
+			userId:  validUserId,
+			reqBody: `{"matchId":"abc"}`,
+			setupRepo: func(convRepo *mockrepo.MockConversation, matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Any()).Times(0)
+				convRepo.EXPECT().InsertConversation(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]string{
+					"matchId": "must be required and must valid uuid",
+				},
+			},
+		},
+		{
+			name:    "match not found",
+			userId:  validUserId,
+			reqBody: fmt.Sprintf(`{"matchId":%q}`, validMatchId),
+			setupRepo: func(convRepo *mockrepo.MockConversation, matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validMatchId)).Times(1).
+					Return(matchEntity.MatchDAO{}, common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound))
+				convRepo.EXPECT().InsertConversation(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "resource not found",
+			},
+		},
+		{
+			name:    "user not in match",
+			userId:  util.RandomUUID(),
+			reqBody: fmt.Sprintf(`{"matchId":%q}`, validMatchId),
+			setupRepo: func(convRepo *mockrepo.MockConversation, matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(validMatchId)).Times(1).Return(validMatch, nil)
+				convRepo.EXPECT().InsertConversation(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "users not in this match",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			convRepo := mockrepo.NewMockConversation(ctrl)
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			tt.setupRepo(convRepo, matchRepo)
+			convH := NewConvo(service.NewConversation(convRepo, matchRepo, testBlobUrl), service.NewOnline(mockrepo.NewMockOnline(ctrl)))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, tt.userId)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/conversation", strings.NewReader(tt.reqBody))
+
+			convH.postConversationHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			expResBody, err := json.Marshal(tt.wantResp)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expResBody), rr.Body.String())
+		})
+	}
+}
+
+func Test_deleteConversationById(t *testing.T) {
+	validConvId := util.RandomUUID()
+
+	tests := []struct {
+		name      string
+		setupRepo func(convRepo *mockrepo.MockConversation)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name: "valid delete",
+			setupRepo: func(convRepo *mockrepo.MockConversation) {
+				convRepo.EXPECT().DeleteConversationById(gomock.Eq(validConvId)).Times(1).Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantResp: map[string]any{
+				"status":  "success",
+				"message": "deleting conversationId success",
+			},
+		},
+		{
+			name: "conversation already deleted",
+			setupRepo: func(convRepo *mockrepo.MockConversation) {
+				convRepo.EXPECT().DeleteConversationById(gomock.Eq(validConvId)).Times(1).
+					Return(common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound))
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "resource not found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			convRepo := mockrepo.NewMockConversation(ctrl)
+			tt.setupRepo(convRepo)
+			convH := NewConvo(service.NewConversation(convRepo, mockrepo.NewMockMatch(ctrl), testBlobUrl), service.NewOnline(mockrepo.NewMockOnline(ctrl)))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyConvId, validConvId)
+			c.Request = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/%s/", validConvId), nil)
+
+			convH.deleteConversationById(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			expResBody, err := json.Marshal(tt.wantResp)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expResBody), rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	corsAllowedMethods = []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+		http.MethodOptions,
+	}
+	corsAllowedHeaders = []string{
+		authorizationHeaderKey,
+		"Content-Type",
+		lastEventIdHeaderKey,
+	}
+)
+
+type Cors struct {
+	Origins          *Origins
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+func cors(cfg Cors) gin.HandlerFunc {
+	allowMethods := strings.Join(corsAllowedMethods, ", ")
+	allowHeaders := strings.Join(corsAllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Origin")
+		origin := c.GetHeader("Origin")
+		isPreflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if isPreflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+		if origin == "" {
+			c.Next()
+			return
+		}
+		if cfg.Origins == nil || !cfg.Origins.Allowed(origin) {
+			if isPreflight {
+				errForbiddenResp(c, "origin not allowed")
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// any origin is never trusted with credentials, otherwise every site could read the refreshed access token
+		if cfg.Origins.AllowAny() {
+			c.Header("Access-Control-Allow-Origin", anyOrigin)
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+			if cfg.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		}
+
+		if isPreflight {
+			c.Header("Access-Control-Allow-Methods", allowMethods)
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
+			if cfg.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// preflight only exists so OPTIONS request on /api/v1 are routed into the group middleware
+func preflight(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+}
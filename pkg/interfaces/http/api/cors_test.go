@@ -0,0 +1,376 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/applications/service"
+)
+
+func Test_NewOrigins(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		wantErr  bool
+	}{
+		{
+			name:     "valid exact",
+			patterns: []string{"https://blindate.com", "http://localhost:3000"},
+		},
+		{
+			name:     "valid wildcard",
+			patterns: []string{"https://*.blindate.com"},
+		},
+		{
+			name:     "valid regex",
+			patterns: []string{`regex:^https://[a-z0-9-]+\.vercel\.app$`},
+		},
+		{
+			name:     "invalid regex",
+			patterns: []string{"regex:^https://(.*$"},
+			wantErr:  true,
+		},
+		{
+			name:     "wildcard not in subdomain",
+			patterns: []string{"https://blindate.*"},
+			wantErr:  true,
+		},
+		{
+			name:     "double wildcard",
+			patterns: []string{"https://*.*.blindate.com"},
+			wantErr:  true,
+		},
+		{
+			name:     "exact without scheme",
+			patterns: []string{"blindate.com"},
+			wantErr:  true,
+		},
+		{
+			name:     "exact with path",
+			patterns: []string{"https://blindate.com/app"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewOrigins(tt.patterns)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_OriginsAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		origin   string
+		want     bool
+	}{
+		{
+			name:     "exact match",
+			patterns: []string{"https://blindate.com"},
+			origin:   "https://blindate.com",
+			want:     true,
+		},
+		{
+			name:     "exact match is case insensitive",
+			patterns: []string{"https://blindate.com"},
+			origin:   "HTTPS://BlinDate.com",
+			want:     true,
+		},
+		{
+			name:     "exact different scheme",
+			patterns: []string{"https://blindate.com"},
+			origin:   "http://blindate.com",
+			want:     false,
+		},
+		{
+			name:     "exact different port",
+			patterns: []string{"http://localhost:3000"},
+			origin:   "http://localhost:5173",
+			want:     false,
+		},
+		{
+			name:     "exact suffix attack",
+			patterns: []string{"https://blindate.com"},
+			origin:   "https://blindate.com.evil.io",
+			want:     false,
+		},
+		{
+			name:     "wildcard subdomain",
+			patterns: []string{"https://*.blindate.com"},
+			origin:   "https://app.blindate.com",
+			want:     true,
+		},
+		{
+			name:     "wildcard nested subdomain",
+			patterns: []string{"https://*.blindate.com"},
+			origin:   "https://staging.app.blindate.com",
+			want:     true,
+		},
+		{
+			name:     "wildcard does not match apex",
+			patterns: []string{"https://*.blindate.com"},
+			origin:   "https://blindate.com",
+			want:     false,
+		},
+		{
+			name:     "wildcard does not match lookalike",
+			patterns: []string{"https://*.blindate.com"},
+			origin:   "https://evilblindate.com",
+			want:     false,
+		},
+		{
+			name:     "wildcard different scheme",
+			patterns: []string{"https://*.blindate.com"},
+			origin:   "http://app.blindate.com",
+			want:     false,
+		},
+		{
+			name:     "wildcard with port",
+			patterns: []string{"http://*.blindate.test:8080"},
+			origin:   "http://app.blindate.test:8080",
+			want:     true,
+		},
+		{
+			name:     "regex match",
+			patterns: []string{`regex:^https://[a-z0-9-]+\.vercel\.app$`},
+			origin:   "https://blindate-pr-12.vercel.app",
+			want:     true,
+		},
+		{
+			name:     "regex no match",
+			patterns: []string{`regex:^https://[a-z0-9-]+\.vercel\.app$`},
+			origin:   "https://blindate.vercel.app.evil.io",
+			want:     false,
+		},
+		{
+			name:     "any origin",
+			patterns: []string{"*"},
+			origin:   "https://whatever.io",
+			want:     true,
+		},
+		{
+			name:     "empty origin",
+			patterns: []string{"*"},
+			origin:   "",
+			want:     false,
+		},
+		{
+			name:     "no pattern",
+			patterns: nil,
+			origin:   "https://blindate.com",
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origins, err := NewOrigins(tt.patterns)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, origins.Allowed(tt.origin))
+		})
+	}
+}
+
+func Test_CorsMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		patterns   []string
+		credential bool
+		method     string
+		headers    map[string]string
+		wantCode   int
+		wantHeader map[string]string
+	}{
+		{
+			name:       "simple request from allowed origin",
+			patterns:   []string{"https://blindate.com"},
+			credential: true,
+			method:     http.MethodGet,
+			headers: map[string]string{
+				"Origin": "https://blindate.com",
+			},
+			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":      "https://blindate.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "",
+			},
+		},
+		{
+			name:     "simple request from not allowed origin",
+			patterns: []string{"https://blindate.com"},
+			method:   http.MethodGet,
+			headers: map[string]string{
+				"Origin": "https://evil.io",
+			},
+			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":      "",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+		{
+			name:     "request without origin",
+			patterns: []string{"https://blindate.com"},
+			method:   http.MethodGet,
+			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:       "preflight from allowed wildcard origin",
+			patterns:   []string{"https://*.blindate.com"},
+			credential: true,
+			method:     http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://app.blindate.com",
+				"Access-Control-Request-Method":  http.MethodPatch,
+				"Access-Control-Request-Headers": "authorization,content-type",
+			},
+			wantCode: http.StatusNoContent,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.blindate.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+				"Access-Control-Allow-Headers":     "Authorization, Content-Type, Last-Event-ID",
+				"Access-Control-Max-Age":           "600",
+			},
+		},
+		{
+			name:     "preflight from not allowed origin",
+			patterns: []string{"https://*.blindate.com"},
+			method:   http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://evil.io",
+				"Access-Control-Request-Method": http.MethodPatch,
+			},
+			wantCode: http.StatusForbidden,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:     "plain options is not preflight",
+			patterns: []string{"https://blindate.com"},
+			method:   http.MethodOptions,
+			headers: map[string]string{
+				"Origin": "https://blindate.com",
+			},
+			wantCode: http.StatusNoContent,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":  "https://blindate.com",
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+		{
+			name:     "any origin without credentials",
+			patterns: []string{"*"},
+			method:   http.MethodGet,
+			headers: map[string]string{
+				"Origin": "https://whatever.io",
+			},
+			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":      "*",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+		{
+			name:       "any origin is never allowed credentials",
+			patterns:   []string{"*"},
+			credential: true,
+			method:     http.MethodGet,
+			headers: map[string]string{
+				"Origin": "https://whatever.io",
+			},
+			wantCode: http.StatusOK,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":      "*",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origins, err := NewOrigins(tt.patterns)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(rr)
+			v1 := r.Group("/api/v1", cors(Cors{
+				Origins:          origins,
+				AllowCredentials: tt.credential,
+				MaxAge:           10 * time.Minute,
+			}))
+			v1.OPTIONS("/*path", preflight)
+			v1.GET("/healthcheck", func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, nil)
+			})
+
+			req := httptest.NewRequest(tt.method, "/api/v1/healthcheck", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			for k, v := range tt.wantHeader {
+				assert.Equal(t, v, rr.Header().Get(k), k)
+			}
+			assert.Contains(t, rr.Header().Values("Vary"), "Origin")
+		})
+	}
+}
+
+func Test_WsCheckOrigin(t *testing.T) {
+	origins, err := NewOrigins([]string{"https://blindate.com", "https://*.blindate.com"})
+	require.NoError(t, err)
+	ws := NewWs(service.NewWs(nil), origins)
+
+	tests := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{
+			name:   "exact origin",
+			origin: "https://blindate.com",
+			want:   true,
+		},
+		{
+			name:   "wildcard origin",
+			origin: "https://m.blindate.com",
+			want:   true,
+		},
+		{
+			name:   "not allowed origin",
+			origin: "https://evil.io",
+			want:   false,
+		},
+		{
+			name:   "non browser client",
+			origin: "",
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/ws", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			assert.Equal(t, tt.want, ws.upgrader.CheckOrigin(req))
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	exportEntity "github.com/xyedo/blindate/pkg/domain/export/entities"
+)
+
+type exportSvc interface {
+	RequestExport(userId string) (exportEntity.Job, error)
+	GetExports(userId string) ([]exportEntity.Job, error)
+	GetExportById(userId, exportId string) (exportEntity.Job, error)
+}
+
+func NewExport(exportSvc exportSvc) *Export {
+	return &Export{
+		exportSvc: exportSvc,
+	}
+}
+
+type Export struct {
+	exportSvc exportSvc
+}
+
+func (e *Export) postExportHandler(c *gin.Context) {
+	job, err := e.exportSvc.RequestExport(c.GetString(keyUserId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":  "success",
+		"message": "export is queued, the download url is available once it is ready",
+		"data": gin.H{
+			"export": job,
+		},
+	})
+}
+
+func (e *Export) getExportsHandler(c *gin.Context) {
+	jobs, err := e.exportSvc.GetExports(c.GetString(keyUserId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"exports": jobs,
+		},
+	})
+}
+
+func (e *Export) getExportByIdHandler(c *gin.Context) {
+	job, err := e.exportSvc.GetExportById(c.GetString(keyUserId), c.GetString(keyExportId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"export": job,
+		},
+	})
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/applications/service"
+	mocksvc "github.com/xyedo/blindate/pkg/applications/service/mock"
+	"github.com/xyedo/blindate/pkg/common"
+	exportEntity "github.com/xyedo/blindate/pkg/domain/export/entities"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_postExportHandler(t *testing.T) {
+	userId := createNewUser(t).ID
+	tests := []struct {
+		name      string
+		setupFunc func(exportRepo *mockrepo.MockExport)
+		wantCode  int
+		respFunc  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name: "queue the export",
+			setupFunc: func(exportRepo *mockrepo.MockExport) {
+				exportRepo.EXPECT().InsertJob(gomock.Any()).Times(1).DoAndReturn(func(job *exportEntity.Job) error {
+					assert.Equal(t, userId, job.UserId)
+					job.Id = util.RandomUUID()
+					job.Status = exportEntity.StatusPending
+					job.CreatedAt = time.Now()
+					return nil
+				})
+			},
+			wantCode: http.StatusAccepted,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result struct {
+					Data struct {
+						Export exportEntity.Job `json:"export"`
+					} `json:"data"`
+				}
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				require.NoError(t, err)
+				assert.Equal(t, exportEntity.StatusPending, result.Data.Export.Status)
+				assert.Empty(t, result.Data.Export.DownloadUrl)
+			},
+		},
+		{
+			name: "export already in progress",
+			setupFunc: func(exportRepo *mockrepo.MockExport) {
+				exportRepo.EXPECT().InsertJob(gomock.Any()).Times(1).
+					Return(common.WrapWithNewError(errors.New("duplicate key"), http.StatusConflict, "an export is already in progress"))
+			},
+			wantCode: http.StatusConflict,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"status":"fail","message":"an export is already in progress"}`, rr.Body.String())
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			exportRepo := mockrepo.NewMockExport(ctrl)
+			tt.setupFunc(exportRepo)
+			exportH := NewExport(service.NewExport(exportRepo, nil, nil, nil, nil, nil, time.Hour))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/users/"+userId+"/export", nil)
+			c.Set(keyUserId, userId)
+
+			exportH.postExportHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			tt.respFunc(t, rr)
+		})
+	}
+}
+
+func Test_getExportByIdHandler(t *testing.T) {
+	userId := createNewUser(t).ID
+	exportId := util.RandomUUID()
+	key := "data-export/" + util.RandomUUID() + ".zip"
+	tests := []struct {
+		name      string
+		setupFunc func(exportRepo *mockrepo.MockExport, attachment *mocksvc.MockAttachment)
+		wantCode  int
+		respFunc  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ready export is signed",
+			setupFunc: func(exportRepo *mockrepo.MockExport, attachment *mocksvc.MockAttachment) {
+				exportRepo.EXPECT().SelectJobById(gomock.Eq(exportId)).Times(1).
+					Return(exportEntity.Job{Id: exportId, UserId: userId, Status: exportEntity.StatusReady, BlobKey: &key}, nil)
+				attachment.EXPECT().GetPresignedUrl(gomock.Eq(key)).Times(1).Return("https://blob.test/"+key, nil)
+			},
+			wantCode: http.StatusOK,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result struct {
+					Data struct {
+						Export map[string]any `json:"export"`
+					} `json:"data"`
+				}
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				require.NoError(t, err)
+				assert.Equal(t, "https://blob.test/"+key, result.Data.Export["downloadUrl"])
+				assert.NotContains(t, result.Data.Export, "blobKey")
+			},
+		},
+		{
+			name: "processing export is not signed",
+			setupFunc: func(exportRepo *mockrepo.MockExport, attachment *mocksvc.MockAttachment) {
+				exportRepo.EXPECT().SelectJobById(gomock.Eq(exportId)).Times(1).
+					Return(exportEntity.Job{Id: exportId, UserId: userId, Status: exportEntity.StatusProcessing}, nil)
+				attachment.EXPECT().GetPresignedUrl(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusOK,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.NotContains(t, rr.Body.String(), "downloadUrl")
+			},
+		},
+		{
+			name: "export of another user",
+			setupFunc: func(exportRepo *mockrepo.MockExport, attachment *mocksvc.MockAttachment) {
+				exportRepo.EXPECT().SelectJobById(gomock.Eq(exportId)).Times(1).
+					Return(exportEntity.Job{Id: exportId, UserId: util.RandomUUID(), Status: exportEntity.StatusReady, BlobKey: &key}, nil)
+				attachment.EXPECT().GetPresignedUrl(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusNotFound,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"status":"fail","message":"export not found"}`, rr.Body.String())
+			},
+		},
+		{
+			name: "unknown export",
+			setupFunc: func(exportRepo *mockrepo.MockExport, attachment *mocksvc.MockAttachment) {
+				exportRepo.EXPECT().SelectJobById(gomock.Eq(exportId)).Times(1).
+					Return(exportEntity.Job{}, common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "export not found"))
+			},
+			wantCode: http.StatusNotFound,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"status":"fail","message":"export not found"}`, rr.Body.String())
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			exportRepo := mockrepo.NewMockExport(ctrl)
+			attachment := mocksvc.NewMockAttachment(ctrl)
+			tt.setupFunc(exportRepo, attachment)
+			exportH := NewExport(service.NewExport(exportRepo, nil, nil, nil, nil, attachment, time.Hour))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/users/"+userId+"/exports/"+exportId, nil)
+			c.Set(keyUserId, userId)
+			c.Set(keyExportId, exportId)
+
+			exportH.getExportByIdHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			tt.respFunc(t, rr)
+		})
+	}
+}
@@ -42,71 +42,75 @@ func jsonHandleError(c *gin.Context, err error) {
 	}
 }
 func uploadFile(c *gin.Context, uploader attachmentManager, validMimeTypes []string, prefix string) (key string, mediaType string) {
+	file, fileHeader, mediaType := openFormFile(c, validMimeTypes)
+	if file == nil {
+		return "", ""
+	}
+	defer func(file multipart.File) {
+		err := file.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}(file)
+	key, err := uploader.UploadBlob(file, attachmentEntity.Uploader{
+		Length:      fileHeader.Size,
+		ContentType: mediaType,
+		Prefix:      prefix,
+		Ext:         filepath.Ext(fileHeader.Filename),
+	})
+	if err != nil {
+		errServerResp(c, err)
+		return "", ""
+	}
+	return key, mediaType
+}
+
+// openFormFile open the multipart file and sniff its mime-type, the caller must close the file.
+// nil file mean the response is already written
+func openFormFile(c *gin.Context, validMimeTypes []string) (file multipart.File, fileHeader *multipart.FileHeader, mediaType string) {
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
 		if errors.Is(err, http.ErrNotMultipart) || errors.Is(err, http.ErrMissingBoundary) {
 			errBadRequestResp(c, "content-Type header is not valid")
-			return "", ""
+			return nil, nil, ""
 		}
 		if errors.Is(err, http.ErrMissingFile) {
 			errBadRequestResp(c, "request did not contain a file")
-			return "", ""
+			return nil, nil, ""
 		}
 		if errors.Is(err, multipart.ErrMessageTooLarge) {
 			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
 				"status":  "fail",
 				"message": "max byte to upload is 8mB",
 			})
-			return "", ""
+			return nil, nil, ""
 		}
 		errServerResp(c, err)
-		return "", ""
+		return nil, nil, ""
 	}
-	file, err := fileHeader.Open()
+	file, err = fileHeader.Open()
 	if err != nil {
 		errServerResp(c, err)
-		return "", ""
+		return nil, nil, ""
 	}
-	defer func(file multipart.File) {
-		err := file.Close()
-		if err != nil {
-			log.Fatal(err)
-		}
-	}(file)
 	buff := make([]byte, 512)
 	_, err = file.Read(buff)
-	if err != nil {
-		errServerResp(c, err)
-		return "", ""
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
 	}
-	_, err = file.Seek(0, io.SeekStart)
 	if err != nil {
+		file.Close()
 		errServerResp(c, err)
-		return "", ""
+		return nil, nil, ""
 	}
 
-	var isValidType bool
 	contentType := http.DetectContentType(buff)
 	for _, validTypes := range validMimeTypes {
 		if contentType == validTypes {
-			isValidType = true
-			mediaType = contentType
-			break
+			return file, fileHeader, contentType
 		}
 	}
-	if !isValidType {
-		errUnprocessableEntityResp(c, "not valid mime-type")
-		return "", ""
-	}
-	key, err = uploader.UploadBlob(file, attachmentEntity.Uploader{
-		Length:      fileHeader.Size,
-		ContentType: contentType,
-		Prefix:      prefix,
-		Ext:         filepath.Ext(fileHeader.Filename),
-	})
-	if err != nil {
-		errServerResp(c, err)
-		return "", ""
-	}
-	return key, mediaType
+	file.Close()
+	errUnprocessableEntityResp(c, "not valid mime-type")
+	return nil, nil, ""
 }
@@ -3,14 +3,40 @@ package api
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/xyedo/blindate/pkg/applications/service"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
 )
 
+// testBlobUrl sign the blob key by prefixing testBlobHost
+var testBlobUrl = service.NewBlobUrl(testPresigner{}, 5*time.Minute)
+
+const testBlobHost = "https://blob.test/"
+
+// testProfileMedia keep the renditions small so the upload test stay fast
+var testProfileMedia = service.NewMedia(attachmentEntity.Limits{
+	MaxImageBytes: 1 << 20,
+	MaxDimension:  512,
+	Profile: attachmentEntity.ProfileSizes{
+		Full:      128,
+		Medium:    64,
+		Thumbnail: 16,
+	},
+})
+
+type testPresigner struct{}
+
+func (testPresigner) GetPresignedUrl(key string) (string, error) {
+	return testBlobHost + key, nil
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	registerTagName()
 	registerValidDObValidator()
 	registerValidEducationLevelFieldValidator()
+	registerValidEmojiValidator()
 	os.Exit(m.Run())
 }
@@ -4,16 +4,18 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/xyedo/blindate/pkg/domain/match"
 	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+	"github.com/xyedo/blindate/pkg/util"
 )
 
-// TODO: Create match_test.go
 type matchSvc interface {
 	FindUserToMatch(userId string) ([]matchEntity.UserDTO, error)
 	PostNewMatch(fromUserId, toUserId string, matchStatus matchEntity.Status) (string, error)
-	GetMatchReqToUserId(userId string) ([]matchEntity.FullUserDTO, error)
-	RequestChange(matchId string, matchStatus matchEntity.Status) error
-	RevealChange(matchId string, matchStatus matchEntity.Status) error
+	GetMatchesByUserId(userId string, filter match.Filter) ([]matchEntity.FullUserDTO, error)
+	RequestChange(userId, matchId string, matchStatus matchEntity.Status) error
+	RevealChange(userId, matchId string, matchStatus matchEntity.Status) (matchEntity.Status, error)
+	GetMatchEvents(userId, matchId string) ([]matchEntity.Event, error)
 }
 
 func NewMatch(matchSvc matchSvc) *Match {
@@ -72,27 +74,63 @@ func (m *Match) postNewMatchHandler(c *gin.Context) {
 
 }
 func (m *Match) getAllMatchRequestedHandler(c *gin.Context) {
+	var query struct {
+		Box    *string `form:"box" binding:"omitempty,oneof=received sent accepted declined"`
+		Cursor *string `form:"cursor"`
+		Limit  *int    `form:"limit" binding:"omitempty,min=1,max=50"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		if errMap := util.ReadValidationErr(err, map[string]string{
+			"Box":   "if provided, the values is one of `received`, `sent`, `accepted` or `declined`",
+			"Limit": "if provided, value must in between 1-50",
+		}); len(errMap) != 0 {
+			errValidationResp(c, errMap)
+			return
+		}
+		errBadRequestResp(c, "limit must be number")
+		return
+	}
+	filter := match.Filter{Box: match.BoxReceived, Limit: 20}
+	if query.Box != nil {
+		filter.Box = match.Box(*query.Box)
+	}
+	if query.Cursor != nil {
+		cursor, err := match.DecodeCursor(*query.Cursor)
+		if err != nil {
+			errBadRequestResp(c, "cursor is invalid, use the nextCursor from previous response")
+			return
+		}
+		filter.Cursor = &cursor
+	}
+	if query.Limit != nil {
+		filter.Limit = *query.Limit
+	}
 	userId := c.GetString(keyUserId)
-	matcheds, err := m.matchSvc.GetMatchReqToUserId(userId)
+	matcheds, err := m.matchSvc.GetMatchesByUserId(userId, filter)
 	if err != nil {
 		jsonHandleError(c, err)
 		return
 	}
+	data := gin.H{
+		"matchs": matcheds,
+	}
+	// full page means there might be more
+	if len(matcheds) == filter.Limit {
+		data["nextCursor"] = match.CursorOf(matcheds[len(matcheds)-1]).Encode()
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
-		"data": gin.H{
-			"matchs": matcheds,
-		},
+		"data":   data,
 	})
 }
 
 func (m *Match) putRequestHandler(c *gin.Context) {
 	var input struct {
-		Request string `form:"request" json:"request" binding:"required,oneof=declined accepted"`
+		Request string `form:"request" json:"request" binding:"required,oneof=declined accepted withdrawn requested"`
 	}
 	if err := c.ShouldBind(&input); err != nil {
 		if errjson := jsonBindingErrResp(err, c, map[string]string{
-			"request": "required and the value must be `accepted` or `declined`",
+			"request": "required and the value must be `accepted`, `declined`, `withdrawn` or `requested`",
 		}); errjson != nil {
 			errServerResp(c, err)
 			return
@@ -100,24 +138,25 @@ func (m *Match) putRequestHandler(c *gin.Context) {
 		return
 
 	}
+	userId := c.GetString(keyUserId)
 	matchId := c.GetString(keyMatchId)
-	err := m.matchSvc.RequestChange(matchId, matchEntity.Status(input.Request))
+	err := m.matchSvc.RequestChange(userId, matchId, matchEntity.Status(input.Request))
 	if err != nil {
 		jsonHandleError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
-		"message": "request has been accepted",
+		"message": "match request has been " + input.Request,
 	})
 }
 func (m *Match) putRevealHandler(c *gin.Context) {
 	var input struct {
-		Reveal string `form:"reveal" json:"reveal" binding:"required,oneof=requested accepted declined"`
+		Reveal string `form:"reveal" json:"reveal" binding:"required,oneof=requested accepted declined cancelled"`
 	}
 	if err := c.ShouldBind(&input); err != nil {
 		if errjson := jsonBindingErrResp(err, c, map[string]string{
-			"Request": "required and the value must be `requested` or `accepted` or `declined`",
+			"reveal": "required and the value must be `requested`, `accepted`, `declined` or `cancelled`",
 		}); errjson != nil {
 			errServerResp(c, err)
 			return
@@ -125,15 +164,35 @@ func (m *Match) putRevealHandler(c *gin.Context) {
 		return
 
 	}
+	userId := c.GetString(keyUserId)
 	matchId := c.GetString(keyMatchId)
 
-	err := m.matchSvc.RevealChange(matchId, matchEntity.Status(input.Reveal))
+	revealStatus, err := m.matchSvc.RevealChange(userId, matchId, matchEntity.Status(input.Reveal))
 	if err != nil {
 		jsonHandleError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
-		"message": "request has been accepted",
+		"message": "reveal has been " + string(revealStatus),
+		"data": gin.H{
+			"revealStatus": revealStatus,
+		},
+	})
+}
+
+func (m *Match) getMatchTimelineHandler(c *gin.Context) {
+	userId := c.GetString(keyUserId)
+	matchId := c.GetString(keyMatchId)
+	events, err := m.matchSvc.GetMatchEvents(userId, matchId)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"events": events,
+		},
 	})
 }
@@ -0,0 +1,503 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/applications/service"
+	"github.com/xyedo/blindate/pkg/domain/match"
+	matchEntity "github.com/xyedo/blindate/pkg/domain/match/entities"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_postNewMatchHandler(t *testing.T) {
+	userId := util.RandomUUID()
+	toUserId := util.RandomUUID()
+	matchId := util.RandomUUID()
+	tests := []struct {
+		name      string
+		reqBody   string
+		setupFunc func(matchRepo *mockrepo.MockMatch, blockRepo *mockrepo.MockBlock)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:    "valid match request",
+			reqBody: `{"toUserId":"` + toUserId + `","matchStatus":"requested"}`,
+			setupFunc: func(matchRepo *mockrepo.MockMatch, blockRepo *mockrepo.MockBlock) {
+				blockRepo.EXPECT().IsBlocked(gomock.Eq(userId), gomock.Eq(toUserId)).Times(1).Return(false, nil)
+				matchRepo.EXPECT().InsertNewMatch(gomock.Eq(userId), gomock.Eq(toUserId), gomock.Eq(matchEntity.Requested)).Times(1).Return(matchId, nil)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: map[string]any{
+				"status": "success",
+				"data": map[string]any{
+					"matchId": matchId,
+				},
+			},
+		},
+		{
+			name:    "blocked pair",
+			reqBody: `{"toUserId":"` + toUserId + `","matchStatus":"requested"}`,
+			setupFunc: func(matchRepo *mockrepo.MockMatch, blockRepo *mockrepo.MockBlock) {
+				blockRepo.EXPECT().IsBlocked(gomock.Eq(userId), gomock.Eq(toUserId)).Times(1).Return(true, nil)
+				matchRepo.EXPECT().InsertNewMatch(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "could not match with this user",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			blockRepo := mockrepo.NewMockBlock(ctrl)
+			tt.setupFunc(matchRepo, blockRepo)
+			matchH := NewMatch(service.NewMatch(matchRepo, nil, blockRepo, matchEntity.RevealPolicy{}))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/match", strings.NewReader(tt.reqBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(keyUserId, userId)
+
+			matchH.postNewMatchHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			expResBody, err := json.Marshal(tt.wantResp)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expResBody), rr.Body.String())
+		})
+	}
+}
+
+func Test_putRequestHandler(t *testing.T) {
+	fromUserId := util.RandomUUID()
+	toUserId := util.RandomUUID()
+	matchId := util.RandomUUID()
+	requested := matchEntity.MatchDAO{
+		Id:            matchId,
+		RequestFrom:   fromUserId,
+		RequestTo:     toUserId,
+		RequestStatus: string(matchEntity.Requested),
+		RevealStatus:  string(matchEntity.Unknown),
+	}
+	tests := []struct {
+		name      string
+		userId    string
+		reqBody   string
+		setupFunc func(matchRepo *mockrepo.MockMatch)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:    "accepted by the requested user",
+			userId:  toUserId,
+			reqBody: `{"request":"accepted"}`,
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(matchId)).Times(1).Return(requested, nil)
+				matchRepo.EXPECT().TransitionMatch(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(matchDAO matchEntity.MatchDAO, event *matchEntity.Event) error {
+					assert.Equal(t, string(matchEntity.Accepted), matchDAO.RequestStatus)
+					assert.True(t, matchDAO.AcceptedAt.Valid)
+					assert.Equal(t, matchEntity.KindRequest, event.Kind)
+					assert.Equal(t, matchEntity.Requested, event.FromStatus)
+					require.NotNil(t, event.ActorId)
+					assert.Equal(t, toUserId, *event.ActorId)
+					return nil
+				})
+			},
+			wantCode: http.StatusOK,
+			wantResp: map[string]any{
+				"status":  "success",
+				"message": "match request has been accepted",
+			},
+		},
+		{
+			name:    "accepted by the requester",
+			userId:  fromUserId,
+			reqBody: `{"request":"accepted"}`,
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(matchId)).Times(1).Return(requested, nil)
+				matchRepo.EXPECT().TransitionMatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "only the requested user could accept or decline the match",
+			},
+		},
+		{
+			name:    "withdrawn by the requester",
+			userId:  fromUserId,
+			reqBody: `{"request":"withdrawn"}`,
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(matchId)).Times(1).Return(requested, nil)
+				matchRepo.EXPECT().TransitionMatch(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(matchDAO matchEntity.MatchDAO, event *matchEntity.Event) error {
+					assert.Equal(t, string(matchEntity.Withdrawn), matchDAO.RequestStatus)
+					assert.Equal(t, matchEntity.Withdrawn, event.ToStatus)
+					return nil
+				})
+			},
+			wantCode: http.StatusOK,
+			wantResp: map[string]any{
+				"status":  "success",
+				"message": "match request has been withdrawn",
+			},
+		},
+		{
+			name:    "withdrawn by the requested user",
+			userId:  toUserId,
+			reqBody: `{"request":"withdrawn"}`,
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(matchId)).Times(1).Return(requested, nil)
+				matchRepo.EXPECT().TransitionMatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "only the requester could withdraw the match request",
+			},
+		},
+		{
+			name:    "withdrawn after accepted",
+			userId:  fromUserId,
+			reqBody: `{"request":"withdrawn"}`,
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				accepted := requested
+				accepted.RequestStatus = string(matchEntity.Accepted)
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(matchId)).Times(1).Return(accepted, nil)
+				matchRepo.EXPECT().TransitionMatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "only the pending match request could be withdrawn",
+			},
+		},
+		{
+			name:    "not a participant",
+			userId:  util.RandomUUID(),
+			reqBody: `{"request":"declined"}`,
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(matchId)).Times(1).Return(requested, nil)
+				matchRepo.EXPECT().TransitionMatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "match not found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			tt.setupFunc(matchRepo)
+			matchH := NewMatch(service.NewMatch(matchRepo, nil, nil, matchEntity.RevealPolicy{}))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/match/"+matchId+"/request", strings.NewReader(tt.reqBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(keyUserId, tt.userId)
+			c.Set(keyMatchId, matchId)
+
+			matchH.putRequestHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			expResBody, err := json.Marshal(tt.wantResp)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expResBody), rr.Body.String())
+		})
+	}
+}
+
+func Test_getAllMatchRequestedHandler(t *testing.T) {
+	userId := util.RandomUUID()
+	matchs := []matchEntity.FullUserDTO{
+		{MatchId: util.RandomUUID(), RequestStatus: matchEntity.Requested, Sent: true, CreatedAt: time.Now()},
+		{MatchId: util.RandomUUID(), RequestStatus: matchEntity.Requested, Sent: true, CreatedAt: time.Now()},
+	}
+	tests := []struct {
+		name      string
+		query     string
+		setupFunc func(matchRepo *mockrepo.MockMatch)
+		wantCode  int
+		respFunc  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "full page has next cursor",
+			query: "?box=sent&limit=2",
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().SelectMatchesByUserId(gomock.Eq(userId), gomock.Eq(match.Filter{Box: match.BoxSent, Limit: 2})).
+					Times(1).Return(matchs, nil)
+			},
+			wantCode: http.StatusOK,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result struct {
+					Data struct {
+						Matchs     []matchEntity.FullUserDTO `json:"matchs"`
+						NextCursor string                    `json:"nextCursor"`
+					} `json:"data"`
+				}
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				require.NoError(t, err)
+				require.Len(t, result.Data.Matchs, 2)
+				cursor, err := match.DecodeCursor(result.Data.NextCursor)
+				require.NoError(t, err)
+				assert.Equal(t, matchs[1].MatchId, cursor.Id)
+			},
+		},
+		{
+			name:  "received by default",
+			query: "",
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().SelectMatchesByUserId(gomock.Eq(userId), gomock.Eq(match.Filter{Box: match.BoxReceived, Limit: 20})).
+					Times(1).Return(matchs, nil)
+			},
+			wantCode: http.StatusOK,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.NotContains(t, rr.Body.String(), "nextCursor")
+			},
+		},
+		{
+			name:  "invalid box",
+			query: "?box=blocked",
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().SelectMatchesByUserId(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Contains(t, rr.Body.String(), `"Box"`)
+			},
+		},
+		{
+			name:  "invalid cursor",
+			query: "?cursor=garbage",
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().SelectMatchesByUserId(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusBadRequest,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"status":"fail","message":"cursor is invalid, use the nextCursor from previous response"}`, rr.Body.String())
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			tt.setupFunc(matchRepo)
+			matchH := NewMatch(service.NewMatch(matchRepo, nil, nil, matchEntity.RevealPolicy{}))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/match"+tt.query, nil)
+			c.Set(keyUserId, userId)
+
+			matchH.getAllMatchRequestedHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			tt.respFunc(t, rr)
+		})
+	}
+}
+
+func Test_getMatchTimelineHandler(t *testing.T) {
+	fromUserId := util.RandomUUID()
+	toUserId := util.RandomUUID()
+	matchId := util.RandomUUID()
+	matchDAO := matchEntity.MatchDAO{Id: matchId, RequestFrom: fromUserId, RequestTo: toUserId}
+	tests := []struct {
+		name      string
+		userId    string
+		setupFunc func(matchRepo *mockrepo.MockMatch)
+		wantCode  int
+		respFunc  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "participant see the timeline",
+			userId: toUserId,
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(matchId)).Times(1).Return(matchDAO, nil)
+				matchRepo.EXPECT().SelectEventsByMatchId(gomock.Eq(matchId)).Times(1).Return([]matchEntity.Event{
+					{Id: util.RandomUUID(), MatchId: matchId, ActorId: &fromUserId, Kind: matchEntity.KindRequest, FromStatus: matchEntity.Unknown, ToStatus: matchEntity.Requested},
+					{Id: util.RandomUUID(), MatchId: matchId, ActorId: &toUserId, Kind: matchEntity.KindRequest, FromStatus: matchEntity.Requested, ToStatus: matchEntity.Accepted},
+				}, nil)
+			},
+			wantCode: http.StatusOK,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result struct {
+					Data struct {
+						Events []matchEntity.Event `json:"events"`
+					} `json:"data"`
+				}
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				require.NoError(t, err)
+				require.Len(t, result.Data.Events, 2)
+				assert.Equal(t, matchEntity.Accepted, result.Data.Events[1].ToStatus)
+			},
+		},
+		{
+			name:   "not a participant",
+			userId: util.RandomUUID(),
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(matchId)).Times(1).Return(matchDAO, nil)
+				matchRepo.EXPECT().SelectEventsByMatchId(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusNotFound,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"status":"fail","message":"match not found"}`, rr.Body.String())
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			tt.setupFunc(matchRepo)
+			matchH := NewMatch(service.NewMatch(matchRepo, nil, nil, matchEntity.RevealPolicy{}))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/match/"+matchId+"/timeline", nil)
+			c.Set(keyUserId, tt.userId)
+			c.Set(keyMatchId, matchId)
+
+			matchH.getMatchTimelineHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			tt.respFunc(t, rr)
+		})
+	}
+}
+
+func Test_putRevealHandler(t *testing.T) {
+	fromUserId := util.RandomUUID()
+	toUserId := util.RandomUUID()
+	matchId := util.RandomUUID()
+	requested := matchEntity.MatchDAO{
+		Id:                matchId,
+		RequestFrom:       fromUserId,
+		RequestTo:         toUserId,
+		RequestStatus:     string(matchEntity.Accepted),
+		RevealStatus:      string(matchEntity.Requested),
+		RevealRequestedBy: sql.NullString{String: fromUserId, Valid: true},
+	}
+	tests := []struct {
+		name      string
+		policy    matchEntity.RevealPolicy
+		userId    string
+		reqBody   string
+		setupFunc func(matchRepo *mockrepo.MockMatch)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:    "accepted by the other participant",
+			userId:  toUserId,
+			reqBody: `{"reveal":"accepted"}`,
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(matchId)).Times(1).Return(requested, nil)
+				matchRepo.EXPECT().TransitionMatch(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantResp: map[string]any{
+				"status":  "success",
+				"message": "reveal has been accepted",
+				"data": map[string]any{
+					"revealStatus": "accepted",
+				},
+			},
+		},
+		{
+			name:    "accepted by the requester",
+			userId:  fromUserId,
+			reqBody: `{"reveal":"accepted"}`,
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(matchId)).Times(1).Return(requested, nil)
+				matchRepo.EXPECT().TransitionMatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "only the other participant could accept the reveal",
+			},
+		},
+		{
+			name:    "mutual reveal by the second request",
+			policy:  matchEntity.RevealPolicy{Mutual: true},
+			userId:  toUserId,
+			reqBody: `{"reveal":"requested"}`,
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Eq(matchId)).Times(1).Return(requested, nil)
+				matchRepo.EXPECT().TransitionMatch(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			wantCode: http.StatusOK,
+			wantResp: map[string]any{
+				"status":  "success",
+				"message": "reveal has been accepted",
+				"data": map[string]any{
+					"revealStatus": "accepted",
+				},
+			},
+		},
+		{
+			name:    "invalid reveal",
+			userId:  fromUserId,
+			reqBody: `{"reveal":"expired"}`,
+			setupFunc: func(matchRepo *mockrepo.MockMatch) {
+				matchRepo.EXPECT().GetMatchById(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]any{
+					"reveal": "required and the value must be `requested`, `accepted`, `declined` or `cancelled`",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			matchRepo := mockrepo.NewMockMatch(ctrl)
+			tt.setupFunc(matchRepo)
+			matchH := NewMatch(service.NewMatch(matchRepo, nil, nil, tt.policy))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/match/"+matchId+"/reveal", strings.NewReader(tt.reqBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set(keyUserId, tt.userId)
+			c.Set(keyMatchId, matchId)
+
+			matchH.putRevealHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			expResBody, err := json.Marshal(tt.wantResp)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expResBody), rr.Body.String())
+		})
+	}
+}
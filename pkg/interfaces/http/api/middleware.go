@@ -6,13 +6,16 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
 )
 
 const (
 	authorizationHeaderKey = "Authorization"
 )
 
-func authToken(jwtSvc jwtSvc) gin.HandlerFunc {
+// authToken validate the access token, the restriction and the role is checked against the current user
+// so the moderation take effect without waiting for the token to expire
+func authToken(jwtSvc jwtSvc, accessSvc accessSvc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authorizationHeader := c.GetHeader(authorizationHeaderKey)
 		fields := strings.Fields(authorizationHeader)
@@ -32,12 +35,18 @@ func authToken(jwtSvc jwtSvc) gin.HandlerFunc {
 		}
 
 		accessToken := fields[1]
-		id, err := jwtSvc.ValidateAccessToken(accessToken)
+		id, _, err := jwtSvc.ValidateAccessToken(accessToken)
+		if err != nil {
+			jsonHandleError(c, err)
+			return
+		}
+		role, err := accessSvc.CheckAccess(id)
 		if err != nil {
 			jsonHandleError(c, err)
 			return
 		}
 		c.Set(keyUserId, id)
+		c.Set(keyRole, string(role))
 	}
 }
 func validateUser() gin.HandlerFunc {
@@ -62,6 +71,25 @@ func validateUser() gin.HandlerFunc {
 	}
 }
 
+// validateTargetUser accept another user in the uri, unlike validateUser which only accept the user itself
+func validateTargetUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var url struct {
+			UserId string `uri:"userId" binding:"required,uuid"`
+		}
+		err := c.ShouldBindUri(&url)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"status":  "fail",
+				"message": "must have uuid in uri!",
+			})
+			return
+		}
+		c.Set(keyTargetUserId, url.UserId)
+		c.Next()
+	}
+}
+
 func validateInterest() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var url struct {
@@ -134,3 +162,107 @@ func validateMatch() gin.HandlerFunc {
 	}
 
 }
+
+func validateStickerPack() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var url struct {
+			PackId string `uri:"packId" binding:"required,uuid"`
+		}
+		err := c.ShouldBindUri(&url)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"status":  "fail",
+				"message": "required,must have uuid in uri!",
+			})
+			return
+		}
+		c.Set(keyPackId, url.PackId)
+		c.Next()
+	}
+}
+
+func validateSticker() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var url struct {
+			StickerId string `uri:"stickerId" binding:"required,uuid"`
+		}
+		err := c.ShouldBindUri(&url)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"status":  "fail",
+				"message": "required,must have uuid in uri!",
+			})
+			return
+		}
+		c.Set(keyStickerId, url.StickerId)
+		c.Next()
+	}
+}
+
+func validateProfilePicture() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var url struct {
+			PictureId string `uri:"pictureId" binding:"required,number"`
+		}
+		err := c.ShouldBindUri(&url)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"status":  "fail",
+				"message": "required,must have number in uri!",
+			})
+			return
+		}
+		c.Set(keyPictureId, url.PictureId)
+		c.Next()
+	}
+}
+
+// requireRole allow only the user which current role is one of roles, must be placed after authToken
+func requireRole(roles ...userEntity.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := userEntity.Role(c.GetString(keyRole))
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		errForbiddenResp(c, "you are not allowed to access this resources")
+	}
+}
+
+func validateReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var url struct {
+			ReportId string `uri:"reportId" binding:"required,uuid"`
+		}
+		err := c.ShouldBindUri(&url)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"status":  "fail",
+				"message": "required,must have uuid in uri!",
+			})
+			return
+		}
+		c.Set(keyReportId, url.ReportId)
+		c.Next()
+	}
+}
+
+func validateExport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var url struct {
+			ExportId string `uri:"exportId" binding:"required,uuid"`
+		}
+		err := c.ShouldBindUri(&url)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"status":  "fail",
+				"message": "required,must have uuid in uri!",
+			})
+			return
+		}
+		c.Set(keyExportId, url.ExportId)
+		c.Next()
+	}
+}
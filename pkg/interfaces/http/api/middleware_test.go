@@ -8,14 +8,17 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/xyedo/blindate/pkg/applications/service"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
 	"github.com/xyedo/blindate/pkg/util"
 )
 
 // TODO: extends this test to match new middleware
 func addAutho(t *testing.T, req *http.Request, tokennizer jwtSvc, id, typeAuth string) {
-	token, err := tokennizer.GenerateAccessToken(id)
+	token, err := tokennizer.GenerateAccessToken(id, userEntity.RoleUser)
 	assert.NoError(t, err)
 	authoHeader := fmt.Sprintf("%s %s", typeAuth, token)
 	req.Header.Set("Authorization", authoHeader)
@@ -107,9 +110,15 @@ func Test_AuthMiddleware(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo := mockrepo.NewMockUser(ctrl)
+			userRepo.EXPECT().GetUserById(gomock.Any()).AnyTimes().Return(userEntity.FullDTO{ID: validId, Role: userEntity.RoleUser}, nil)
+			accessSvc := service.NewAuth(nil, userRepo, jwt)
+
 			rr := httptest.NewRecorder()
 			c, r := gin.CreateTestContext(rr)
-			r.GET("/api/v1/users/:userId", authToken(jwt), validateUser(), func(ctx *gin.Context) {
+			r.GET("/api/v1/users/:userId", authToken(jwt, accessSvc), validateUser(), func(ctx *gin.Context) {
 				ctx.JSON(http.StatusOK, nil)
 			})
 			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/users/%s", tt.id), nil)
@@ -160,3 +169,95 @@ func Test_InterestMidleware(t *testing.T) {
 	}
 
 }
+
+func Test_RequireRoleMiddleware(t *testing.T) {
+	jwt := service.NewJwt("test-access-secret", "test-refresh-secret", "1m", "720h")
+	suspendedUntil := time.Now().Add(time.Hour)
+	tests := []struct {
+		name string
+		// tokenRole is the role carried by the access token, user is the current state
+		tokenRole    userEntity.Role
+		user         userEntity.FullDTO
+		allowed      []userEntity.Role
+		expectedCode int
+	}{
+		{
+			name:         "admin",
+			tokenRole:    userEntity.RoleAdmin,
+			user:         userEntity.FullDTO{Role: userEntity.RoleAdmin},
+			allowed:      []userEntity.Role{userEntity.RoleAdmin},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "moderator on staff route",
+			tokenRole:    userEntity.RoleModerator,
+			user:         userEntity.FullDTO{Role: userEntity.RoleModerator},
+			allowed:      []userEntity.Role{userEntity.RoleModerator, userEntity.RoleAdmin},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "moderator on admin route",
+			tokenRole:    userEntity.RoleModerator,
+			user:         userEntity.FullDTO{Role: userEntity.RoleModerator},
+			allowed:      []userEntity.Role{userEntity.RoleAdmin},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "user",
+			tokenRole:    userEntity.RoleUser,
+			user:         userEntity.FullDTO{Role: userEntity.RoleUser},
+			allowed:      []userEntity.Role{userEntity.RoleModerator, userEntity.RoleAdmin},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "demoted since the token is issued",
+			tokenRole:    userEntity.RoleAdmin,
+			user:         userEntity.FullDTO{Role: userEntity.RoleUser},
+			allowed:      []userEntity.Role{userEntity.RoleAdmin},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "promoted since the token is issued",
+			tokenRole:    userEntity.RoleUser,
+			user:         userEntity.FullDTO{Role: userEntity.RoleModerator},
+			allowed:      []userEntity.Role{userEntity.RoleModerator, userEntity.RoleAdmin},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "banned since the token is issued",
+			tokenRole:    userEntity.RoleAdmin,
+			user:         userEntity.FullDTO{Role: userEntity.RoleAdmin, Banned: true},
+			allowed:      []userEntity.Role{userEntity.RoleAdmin},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "suspended since the token is issued",
+			tokenRole:    userEntity.RoleModerator,
+			user:         userEntity.FullDTO{Role: userEntity.RoleModerator, SuspendedUntil: &suspendedUntil},
+			allowed:      []userEntity.Role{userEntity.RoleModerator, userEntity.RoleAdmin},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userId := util.RandomUUID()
+			userRepo := mockrepo.NewMockUser(ctrl)
+			userRepo.EXPECT().GetUserById(gomock.Eq(userId)).Times(1).Return(tt.user, nil)
+
+			rr := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(rr)
+			r.GET("/admin", authToken(jwt, service.NewAuth(nil, userRepo, jwt)), requireRole(tt.allowed...), func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, nil)
+			})
+			req, err := http.NewRequest(http.MethodGet, "/admin", nil)
+			assert.NoError(t, err)
+			token, err := jwt.GenerateAccessToken(userId, tt.tokenRole)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+			r.ServeHTTP(rr, req)
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
+	"github.com/xyedo/blindate/pkg/domain/moderation"
+	moderationEntity "github.com/xyedo/blindate/pkg/domain/moderation/entities"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+type moderationSvc interface {
+	GetReports(filter moderation.ReportFilter) ([]blockEntity.Report, error)
+	GetReportById(reportId string) (blockEntity.Report, error)
+	ReviewReport(moderator moderationEntity.Moderator, reportId string, review moderationEntity.ReviewReport) (blockEntity.Report, error)
+	GetReportContext(moderator moderationEntity.Moderator, reportId string) ([]chatEntity.DTO, error)
+	Suspend(moderator moderationEntity.Moderator, userId string, newSuspension moderationEntity.NewSuspension) (moderationEntity.Suspension, error)
+	LiftSuspension(moderator moderationEntity.Moderator, userId string) error
+	GetSuspensions(userId string) ([]moderationEntity.Suspension, error)
+	ChangeRole(moderator moderationEntity.Moderator, userId string, newRole moderationEntity.NewRole) error
+	GetAuditLogs(filter moderation.AuditFilter) ([]moderationEntity.AuditLog, error)
+}
+
+func NewModeration(moderationSvc moderationSvc) *Moderation {
+	return &Moderation{
+		moderationSvc: moderationSvc,
+	}
+}
+
+type Moderation struct {
+	moderationSvc moderationSvc
+}
+
+func (m *Moderation) getReportsHandler(c *gin.Context) {
+	var query struct {
+		Status     *string `form:"status" binding:"omitempty,oneof=open resolved dismissed"`
+		ReportedId *string `form:"reportedId" binding:"omitempty,uuid"`
+		Limit      *int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		if errMap := util.ReadValidationErr(err, map[string]string{
+			"Status":     "if provided, the value must be one of `open`, `resolved` or `dismissed`",
+			"ReportedId": "if provided, must be valid uuid",
+			"Limit":      "if provided, value must in between 1-100",
+		}); errMap != nil {
+			errValidationResp(c, errMap)
+			return
+		}
+		errServerResp(c, err)
+		return
+	}
+	filter := moderation.ReportFilter{}
+	if query.Status != nil {
+		filter.Status = blockEntity.ReportStatus(*query.Status)
+	}
+	if query.ReportedId != nil {
+		filter.ReportedId = *query.ReportedId
+	}
+	if query.Limit != nil {
+		filter.Limit = *query.Limit
+	}
+	reports, err := m.moderationSvc.GetReports(filter)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"reports": reports,
+		},
+	})
+}
+
+func (m *Moderation) getReportByIdHandler(c *gin.Context) {
+	report, err := m.moderationSvc.GetReportById(c.GetString(keyReportId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"report": report,
+		},
+	})
+}
+
+func (m *Moderation) getReportContextHandler(c *gin.Context) {
+	chats, err := m.moderationSvc.GetReportContext(moderatorFrom(c), c.GetString(keyReportId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"chats": chats,
+		},
+	})
+}
+
+func (m *Moderation) putReviewReportHandler(c *gin.Context) {
+	var input moderationEntity.ReviewReport
+	if err := c.ShouldBindJSON(&input); err != nil {
+		if jsonErr := jsonBindingErrResp(err, c, map[string]string{
+			"status":     "required and the value must be one of `resolved` or `dismissed`",
+			"resolution": "must less than 1000 character",
+		}); jsonErr != nil {
+			errServerResp(c, jsonErr)
+			return
+		}
+		return
+	}
+	report, err := m.moderationSvc.ReviewReport(moderatorFrom(c), c.GetString(keyReportId), input)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"report": report,
+		},
+	})
+}
+
+func (m *Moderation) postSuspensionHandler(c *gin.Context) {
+	var input moderationEntity.NewSuspension
+	if err := c.ShouldBindJSON(&input); err != nil {
+		if jsonErr := jsonBindingErrResp(err, c, map[string]string{
+			"reason": "required and must less than 500 character",
+		}); jsonErr != nil {
+			errServerResp(c, jsonErr)
+			return
+		}
+		return
+	}
+	suspension, err := m.moderationSvc.Suspend(moderatorFrom(c), c.GetString(keyTargetUserId), input)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"suspension": suspension,
+		},
+	})
+}
+
+func (m *Moderation) deleteSuspensionHandler(c *gin.Context) {
+	err := m.moderationSvc.LiftSuspension(moderatorFrom(c), c.GetString(keyTargetUserId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "suspension has been lifted",
+	})
+}
+
+func (m *Moderation) getSuspensionsHandler(c *gin.Context) {
+	suspensions, err := m.moderationSvc.GetSuspensions(c.GetString(keyTargetUserId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"suspensions": suspensions,
+		},
+	})
+}
+
+func (m *Moderation) putRoleHandler(c *gin.Context) {
+	var input moderationEntity.NewRole
+	if err := c.ShouldBindJSON(&input); err != nil {
+		if jsonErr := jsonBindingErrResp(err, c, map[string]string{
+			"role": "required and the value must be one of `user`, `moderator` or `admin`",
+		}); jsonErr != nil {
+			errServerResp(c, jsonErr)
+			return
+		}
+		return
+	}
+	err := m.moderationSvc.ChangeRole(moderatorFrom(c), c.GetString(keyTargetUserId), input)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "role has been changed",
+	})
+}
+
+func (m *Moderation) getAuditLogsHandler(c *gin.Context) {
+	var query struct {
+		ModeratorId  *string    `form:"moderatorId" binding:"omitempty,uuid"`
+		TargetUserId *string    `form:"targetUserId" binding:"omitempty,uuid"`
+		Before       *time.Time `form:"before" binding:"omitempty"`
+		Limit        *int       `form:"limit" binding:"omitempty,min=1,max=100"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		if errMap := util.ReadValidationErr(err, map[string]string{
+			"ModeratorId":  "if provided, must be valid uuid",
+			"TargetUserId": "if provided, must be valid uuid",
+			"Before":       "if provided, must be valid time",
+			"Limit":        "if provided, value must in between 1-100",
+		}); errMap != nil {
+			errValidationResp(c, errMap)
+			return
+		}
+		errServerResp(c, err)
+		return
+	}
+	filter := moderation.AuditFilter{
+		Before: query.Before,
+	}
+	if query.ModeratorId != nil {
+		filter.ModeratorId = *query.ModeratorId
+	}
+	if query.TargetUserId != nil {
+		filter.TargetUserId = *query.TargetUserId
+	}
+	if query.Limit != nil {
+		filter.Limit = *query.Limit
+	}
+	logs, err := m.moderationSvc.GetAuditLogs(filter)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"auditLogs": logs,
+		},
+	})
+}
+
+func moderatorFrom(c *gin.Context) moderationEntity.Moderator {
+	return moderationEntity.Moderator{
+		Id:   c.GetString(keyUserId),
+		Role: userEntity.Role(c.GetString(keyRole)),
+	}
+}
@@ -0,0 +1,627 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/applications/service"
+	"github.com/xyedo/blindate/pkg/common"
+	blockEntity "github.com/xyedo/blindate/pkg/domain/block/entities"
+	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
+	"github.com/xyedo/blindate/pkg/domain/moderation"
+	moderationEntity "github.com/xyedo/blindate/pkg/domain/moderation/entities"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_AdminRoutes(t *testing.T) {
+	jwt := service.NewJwt("test-access-secret", "test-refresh-secret", "1m", "720h")
+	tests := []struct {
+		name      string
+		role      userEntity.Role
+		path      string
+		setupFunc func(moderationRepo *mockrepo.MockModeration)
+		wantCode  int
+	}{
+		{
+			name: "user could not read the report queue",
+			role: userEntity.RoleUser,
+			path: "/api/v1/admin/reports",
+			setupFunc: func(moderationRepo *mockrepo.MockModeration) {
+				moderationRepo.EXPECT().SelectReports(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "moderator read the report queue",
+			role: userEntity.RoleModerator,
+			path: "/api/v1/admin/reports",
+			setupFunc: func(moderationRepo *mockrepo.MockModeration) {
+				moderationRepo.EXPECT().SelectReports(gomock.Eq(moderation.ReportFilter{Status: blockEntity.ReportOpen})).
+					Times(1).Return([]blockEntity.Report{}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "moderator could not read the audit log",
+			role: userEntity.RoleModerator,
+			path: "/api/v1/admin/audit-logs",
+			setupFunc: func(moderationRepo *mockrepo.MockModeration) {
+				moderationRepo.EXPECT().SelectAuditLogs(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "admin read the audit log",
+			role: userEntity.RoleAdmin,
+			path: "/api/v1/admin/audit-logs",
+			setupFunc: func(moderationRepo *mockrepo.MockModeration) {
+				moderationRepo.EXPECT().SelectAuditLogs(gomock.Any()).Times(1).Return([]moderationEntity.AuditLog{}, nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "moderator could not manage the sticker catalogue",
+			role: userEntity.RoleModerator,
+			path: "/api/v1/admin/sticker-packs",
+			setupFunc: func(moderationRepo *mockrepo.MockModeration) {
+			},
+			wantCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			moderationRepo := mockrepo.NewMockModeration(ctrl)
+			tt.setupFunc(moderationRepo)
+			userRepo := mockrepo.NewMockUser(ctrl)
+			userRepo.EXPECT().GetUserById(gomock.Any()).Times(1).Return(userEntity.FullDTO{Role: tt.role}, nil)
+			handler := Routes(Route{
+				Tokenizer:  jwt,
+				Access:     service.NewAuth(nil, userRepo, jwt),
+				Moderation: NewModeration(service.NewModeration(moderationRepo, nil, nil)),
+			})
+
+			token, err := jwt.GenerateAccessToken(util.RandomUUID(), tt.role)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+		})
+	}
+}
+
+func Test_putReviewReportHandler(t *testing.T) {
+	moderatorId := util.RandomUUID()
+	reportId := util.RandomUUID()
+	reportedId := util.RandomUUID()
+	openReport := blockEntity.Report{
+		Id:         reportId,
+		ReporterId: util.RandomUUID(),
+		ReportedId: reportedId,
+		Reason:     blockEntity.Spam,
+		Status:     blockEntity.ReportOpen,
+		CreatedAt:  time.Now(),
+	}
+	tests := []struct {
+		name      string
+		reqBody   string
+		setupFunc func(moderationRepo *mockrepo.MockModeration)
+		wantCode  int
+		respFunc  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name: "resolve the report",
+			reqBody: `{
+				"status":"resolved",
+				"resolution":"  user has been warned  "
+			}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration) {
+				moderationRepo.EXPECT().SelectReportById(gomock.Eq(reportId)).Times(1).Return(openReport, nil)
+				moderationRepo.EXPECT().UpdateReportReview(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(report blockEntity.Report, audit moderationEntity.AuditLog) error {
+						assert.Equal(t, blockEntity.ReportResolved, report.Status)
+						require.NotNil(t, report.ReviewedBy)
+						assert.Equal(t, moderatorId, *report.ReviewedBy)
+						require.NotNil(t, report.Resolution)
+						assert.Equal(t, "user has been warned", *report.Resolution)
+
+						assert.Equal(t, moderationEntity.ActionReportResolved, audit.Action)
+						require.NotNil(t, audit.ModeratorId)
+						assert.Equal(t, moderatorId, *audit.ModeratorId)
+						require.NotNil(t, audit.TargetUserId)
+						assert.Equal(t, reportedId, *audit.TargetUserId)
+						require.NotNil(t, audit.ReportId)
+						assert.Equal(t, reportId, *audit.ReportId)
+						return nil
+					})
+			},
+			wantCode: http.StatusOK,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result struct {
+					Data struct {
+						Report blockEntity.Report `json:"report"`
+					} `json:"data"`
+				}
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				require.NoError(t, err)
+				assert.Equal(t, blockEntity.ReportResolved, result.Data.Report.Status)
+			},
+		},
+		{
+			name: "already reviewed",
+			reqBody: `{
+				"status":"dismissed"
+			}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration) {
+				reviewed := openReport
+				reviewed.Status = blockEntity.ReportDismissed
+				moderationRepo.EXPECT().SelectReportById(gomock.Eq(reportId)).Times(1).Return(reviewed, nil)
+				moderationRepo.EXPECT().UpdateReportReview(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"status":"fail","message":"report is already reviewed"}`, rr.Body.String())
+			},
+		},
+		{
+			name: "invalid status",
+			reqBody: `{
+				"status":"open"
+			}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration) {
+				moderationRepo.EXPECT().SelectReportById(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result map[string]any
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				require.NoError(t, err)
+				errs, ok := result["errors"].(map[string]any)
+				require.True(t, ok)
+				assert.Contains(t, errs, "status")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			moderationRepo := mockrepo.NewMockModeration(ctrl)
+			tt.setupFunc(moderationRepo)
+			moderationH := NewModeration(service.NewModeration(moderationRepo, nil, nil))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/admin/reports/"+reportId+"/review", strings.NewReader(tt.reqBody))
+			c.Set(keyUserId, moderatorId)
+			c.Set(keyRole, string(userEntity.RoleModerator))
+			c.Set(keyReportId, reportId)
+
+			moderationH.putReviewReportHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			tt.respFunc(t, rr)
+		})
+	}
+}
+
+func Test_getReportContextHandler(t *testing.T) {
+	moderatorId := util.RandomUUID()
+	reportId := util.RandomUUID()
+	chatId := util.RandomUUID()
+	report := blockEntity.Report{
+		Id:         reportId,
+		ReporterId: util.RandomUUID(),
+		ReportedId: util.RandomUUID(),
+		Reason:     blockEntity.Harassment,
+		ChatId:     &chatId,
+		Status:     blockEntity.ReportOpen,
+	}
+	t.Run("list the chats around the reported chat", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		moderationRepo := mockrepo.NewMockModeration(ctrl)
+		chatRepo := mockrepo.NewMockChat(ctrl)
+		convoId := util.RandomUUID()
+		moderationRepo.EXPECT().SelectReportById(gomock.Eq(reportId)).Times(1).Return(report, nil)
+		chatRepo.EXPECT().SelectChatContext(gomock.Eq(chatId), gomock.Any()).Times(1).Return([]chatEntity.DAO{
+			{Id: util.RandomUUID(), ConversationId: convoId, Author: report.ReporterId, Messages: "hi", SentAt: time.Now().Add(-time.Minute)},
+			{Id: chatId, ConversationId: convoId, Author: report.ReportedId, Messages: "abusive", SentAt: time.Now()},
+		}, nil)
+		moderationRepo.EXPECT().InsertAuditLog(gomock.Any()).Times(1).
+			DoAndReturn(func(audit moderationEntity.AuditLog) error {
+				assert.Equal(t, moderationEntity.ActionReportContextRead, audit.Action)
+				require.NotNil(t, audit.ReportId)
+				assert.Equal(t, reportId, *audit.ReportId)
+				return nil
+			})
+		chatSvc := service.NewChat(chatRepo, nil, nil)
+		moderationH := NewModeration(service.NewModeration(moderationRepo, nil, chatSvc))
+
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/reports/"+reportId+"/context", nil)
+		c.Set(keyUserId, moderatorId)
+		c.Set(keyRole, string(userEntity.RoleModerator))
+		c.Set(keyReportId, reportId)
+
+		moderationH.getReportContextHandler(c)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var result struct {
+			Data struct {
+				Chats []chatEntity.DTO `json:"chats"`
+			} `json:"data"`
+		}
+		err := json.Unmarshal(rr.Body.Bytes(), &result)
+		require.NoError(t, err)
+		require.Len(t, result.Data.Chats, 2)
+		assert.Equal(t, chatId, result.Data.Chats[1].Id)
+	})
+	t.Run("report without chat", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		moderationRepo := mockrepo.NewMockModeration(ctrl)
+		withoutChat := report
+		withoutChat.ChatId = nil
+		moderationRepo.EXPECT().SelectReportById(gomock.Eq(reportId)).Times(1).Return(withoutChat, nil)
+		moderationRepo.EXPECT().InsertAuditLog(gomock.Any()).Times(0)
+		moderationH := NewModeration(service.NewModeration(moderationRepo, nil, nil))
+
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/reports/"+reportId+"/context", nil)
+		c.Set(keyUserId, moderatorId)
+		c.Set(keyRole, string(userEntity.RoleModerator))
+		c.Set(keyReportId, reportId)
+
+		moderationH.getReportContextHandler(c)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.JSONEq(t, `{"status":"fail","message":"report has no chat reference"}`, rr.Body.String())
+	})
+}
+
+func Test_postSuspensionHandler(t *testing.T) {
+	moderatorId := util.RandomUUID()
+	targetId := util.RandomUUID()
+	tests := []struct {
+		name      string
+		role      userEntity.Role
+		targetId  string
+		reqBody   string
+		setupFunc func(moderationRepo *mockrepo.MockModeration, userRepo *mockrepo.MockUser)
+		wantCode  int
+		respFunc  func(t *testing.T, rr *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "suspend for a week",
+			role:     userEntity.RoleModerator,
+			targetId: targetId,
+			reqBody: `{
+				"reason":"spamming",
+				"duration":"7d"
+			}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration, userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Eq(targetId)).Times(1).
+					Return(userEntity.FullDTO{ID: targetId, Role: userEntity.RoleUser}, nil)
+				moderationRepo.EXPECT().InsertSuspension(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(suspension *moderationEntity.Suspension, audit moderationEntity.AuditLog) error {
+						require.NotNil(t, suspension.EndsAt)
+						assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), *suspension.EndsAt, time.Minute)
+						assert.Equal(t, "spamming", suspension.Reason)
+						assert.Equal(t, moderationEntity.ActionUserSuspended, audit.Action)
+						require.NotNil(t, audit.TargetUserId)
+						assert.Equal(t, targetId, *audit.TargetUserId)
+						suspension.Id = util.RandomUUID()
+						suspension.CreatedAt = time.Now()
+						return nil
+					})
+			},
+			wantCode: http.StatusCreated,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result struct {
+					Data struct {
+						Suspension moderationEntity.Suspension `json:"suspension"`
+					} `json:"data"`
+				}
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				require.NoError(t, err)
+				assert.NotEmpty(t, result.Data.Suspension.Id)
+				assert.NotNil(t, result.Data.Suspension.EndsAt)
+			},
+		},
+		{
+			name:     "ban without duration",
+			role:     userEntity.RoleModerator,
+			targetId: targetId,
+			reqBody: `{
+				"reason":"underage"
+			}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration, userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Eq(targetId)).Times(1).
+					Return(userEntity.FullDTO{ID: targetId, Role: userEntity.RoleUser}, nil)
+				moderationRepo.EXPECT().InsertSuspension(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(suspension *moderationEntity.Suspension, audit moderationEntity.AuditLog) error {
+						assert.Nil(t, suspension.EndsAt)
+						assert.Equal(t, moderationEntity.ActionUserBanned, audit.Action)
+						return nil
+					})
+			},
+			wantCode: http.StatusCreated,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {},
+		},
+		{
+			name:     "invalid duration",
+			role:     userEntity.RoleModerator,
+			targetId: targetId,
+			reqBody: `{
+				"reason":"spamming",
+				"duration":"-1h"
+			}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration, userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Eq(targetId)).Times(1).
+					Return(userEntity.FullDTO{ID: targetId, Role: userEntity.RoleUser}, nil)
+				moderationRepo.EXPECT().InsertSuspension(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"status":"fail","message":"duration must be a positive duration e.g. 12h or 7d"}`, rr.Body.String())
+			},
+		},
+		{
+			name:     "moderator could not suspend a staff",
+			role:     userEntity.RoleModerator,
+			targetId: targetId,
+			reqBody: `{
+				"reason":"abuse of power",
+				"duration":"24h"
+			}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration, userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Eq(targetId)).Times(1).
+					Return(userEntity.FullDTO{ID: targetId, Role: userEntity.RoleModerator}, nil)
+				moderationRepo.EXPECT().InsertSuspension(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusForbidden,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"status":"fail","message":"only admin could suspend a staff"}`, rr.Body.String())
+			},
+		},
+		{
+			name:     "admin suspend a staff",
+			role:     userEntity.RoleAdmin,
+			targetId: targetId,
+			reqBody: `{
+				"reason":"abuse of power",
+				"duration":"24h"
+			}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration, userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Eq(targetId)).Times(1).
+					Return(userEntity.FullDTO{ID: targetId, Role: userEntity.RoleModerator}, nil)
+				moderationRepo.EXPECT().InsertSuspension(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			wantCode: http.StatusCreated,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {},
+		},
+		{
+			name:     "suspend yourself",
+			role:     userEntity.RoleAdmin,
+			targetId: moderatorId,
+			reqBody: `{
+				"reason":"testing"
+			}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration, userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Any()).Times(0)
+				moderationRepo.EXPECT().InsertSuspension(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.JSONEq(t, `{"status":"fail","message":"could not suspend yourself"}`, rr.Body.String())
+			},
+		},
+		{
+			name:     "missing reason",
+			role:     userEntity.RoleModerator,
+			targetId: targetId,
+			reqBody: `{
+				"duration":"24h"
+			}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration, userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			respFunc: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				var result map[string]any
+				err := json.Unmarshal(rr.Body.Bytes(), &result)
+				require.NoError(t, err)
+				errs, ok := result["errors"].(map[string]any)
+				require.True(t, ok)
+				assert.Contains(t, errs, "reason")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			moderationRepo := mockrepo.NewMockModeration(ctrl)
+			userRepo := mockrepo.NewMockUser(ctrl)
+			tt.setupFunc(moderationRepo, userRepo)
+			moderationH := NewModeration(service.NewModeration(moderationRepo, userRepo, nil))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/"+tt.targetId+"/suspensions", strings.NewReader(tt.reqBody))
+			c.Set(keyUserId, moderatorId)
+			c.Set(keyRole, string(tt.role))
+			c.Set(keyTargetUserId, tt.targetId)
+
+			moderationH.postSuspensionHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			tt.respFunc(t, rr)
+		})
+	}
+}
+
+func Test_deleteSuspensionHandler(t *testing.T) {
+	moderatorId := util.RandomUUID()
+	targetId := util.RandomUUID()
+	t.Run("lift the suspension", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		moderationRepo := mockrepo.NewMockModeration(ctrl)
+		moderationRepo.EXPECT().LiftSuspension(gomock.Eq(targetId), gomock.Eq(moderatorId), gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(userId, moderatorId string, at time.Time, audit moderationEntity.AuditLog) error {
+				assert.Equal(t, moderationEntity.ActionSuspensionLifted, audit.Action)
+				return nil
+			})
+		moderationH := NewModeration(service.NewModeration(moderationRepo, nil, nil))
+
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/admin/users/"+targetId+"/suspensions", nil)
+		c.Set(keyUserId, moderatorId)
+		c.Set(keyRole, string(userEntity.RoleModerator))
+		c.Set(keyTargetUserId, targetId)
+
+		moderationH.deleteSuspensionHandler(c)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+	t.Run("not suspended", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		moderationRepo := mockrepo.NewMockModeration(ctrl)
+		moderationRepo.EXPECT().LiftSuspension(gomock.Eq(targetId), gomock.Eq(moderatorId), gomock.Any(), gomock.Any()).Times(1).
+			Return(common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrResourceNotFound, "active suspension not found"))
+		moderationH := NewModeration(service.NewModeration(moderationRepo, nil, nil))
+
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/admin/users/"+targetId+"/suspensions", nil)
+		c.Set(keyUserId, moderatorId)
+		c.Set(keyRole, string(userEntity.RoleModerator))
+		c.Set(keyTargetUserId, targetId)
+
+		moderationH.deleteSuspensionHandler(c)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func Test_putRoleHandler(t *testing.T) {
+	adminId := util.RandomUUID()
+	targetId := util.RandomUUID()
+	tests := []struct {
+		name      string
+		targetId  string
+		reqBody   string
+		setupFunc func(moderationRepo *mockrepo.MockModeration, userRepo *mockrepo.MockUser)
+		wantCode  int
+	}{
+		{
+			name:     "promote to moderator",
+			targetId: targetId,
+			reqBody:  `{"role":"moderator"}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration, userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Eq(targetId)).Times(1).
+					Return(userEntity.FullDTO{ID: targetId, Role: userEntity.RoleUser}, nil)
+				moderationRepo.EXPECT().UpdateRole(gomock.Eq(targetId), gomock.Eq(userEntity.RoleModerator), gomock.Any()).Times(1).
+					DoAndReturn(func(userId string, role userEntity.Role, audit moderationEntity.AuditLog) error {
+						assert.Equal(t, moderationEntity.ActionRoleChanged, audit.Action)
+						require.NotNil(t, audit.Details)
+						assert.Equal(t, "role changed from user to moderator", *audit.Details)
+						return nil
+					})
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "change your own role",
+			targetId: adminId,
+			reqBody:  `{"role":"user"}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration, userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Any()).Times(0)
+				moderationRepo.EXPECT().UpdateRole(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "invalid role",
+			targetId: targetId,
+			reqBody:  `{"role":"owner"}`,
+			setupFunc: func(moderationRepo *mockrepo.MockModeration, userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().GetUserById(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			moderationRepo := mockrepo.NewMockModeration(ctrl)
+			userRepo := mockrepo.NewMockUser(ctrl)
+			tt.setupFunc(moderationRepo, userRepo)
+			moderationH := NewModeration(service.NewModeration(moderationRepo, userRepo, nil))
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/admin/users/"+tt.targetId+"/role", strings.NewReader(tt.reqBody))
+			c.Set(keyUserId, adminId)
+			c.Set(keyRole, string(userEntity.RoleAdmin))
+			c.Set(keyTargetUserId, tt.targetId)
+
+			moderationH.putRoleHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+		})
+	}
+}
+
+func Test_getAuditLogsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	moderatorId := util.RandomUUID()
+	moderationRepo := mockrepo.NewMockModeration(ctrl)
+	moderationRepo.EXPECT().SelectAuditLogs(gomock.Eq(moderation.AuditFilter{ModeratorId: moderatorId, Limit: 10})).Times(1).
+		Return([]moderationEntity.AuditLog{
+			{Id: util.RandomUUID(), ModeratorId: &moderatorId, Action: moderationEntity.ActionUserBanned, CreatedAt: time.Now()},
+		}, nil)
+	moderationH := NewModeration(service.NewModeration(moderationRepo, nil, nil))
+
+	rr := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rr)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit-logs?moderatorId="+moderatorId+"&limit=10", nil)
+	c.Set(keyUserId, util.RandomUUID())
+	c.Set(keyRole, string(userEntity.RoleAdmin))
+
+	moderationH.getAuditLogsHandler(c)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var result struct {
+		Data struct {
+			AuditLogs []moderationEntity.AuditLog `json:"auditLogs"`
+		} `json:"data"`
+	}
+	err := json.Unmarshal(rr.Body.Bytes(), &result)
+	require.NoError(t, err)
+	require.Len(t, result.Data.AuditLogs, 1)
+	assert.Equal(t, moderationEntity.ActionUserBanned, result.Data.AuditLogs[0].Action)
+}
@@ -11,6 +11,7 @@ type onlineSvc interface {
 	CreateNewOnline(userId string) error
 	PutOnline(userId string, online bool) error
 	GetOnline(userId string) (onlineEntity.DTO, error)
+	PutSettings(userId string, settings onlineEntity.Settings) error
 }
 
 func NewOnline(onlineSvc onlineSvc) *Online {
@@ -68,6 +69,11 @@ func (o *Online) putuserOnlineHandler(c *gin.Context) {
 		}
 		return
 	}
+	// presence is derived from the websocket heartbeat, client can only tell it's leaving
+	if *input.Online {
+		errUnprocessableEntityResp(c, "online can only be set to false, presence is derived from the realtime connection")
+		return
+	}
 	err = o.onlineSvc.PutOnline(userId, *input.Online)
 	if err != nil {
 		jsonHandleError(c, err)
@@ -78,3 +84,28 @@ func (o *Online) putuserOnlineHandler(c *gin.Context) {
 		"message": "user-online updated",
 	})
 }
+func (o *Online) putUserOnlineSettingsHandler(c *gin.Context) {
+	userId := c.GetString(keyUserId)
+	var input onlineEntity.Settings
+	err := c.ShouldBindJSON(&input)
+	if err != nil {
+		errjson := jsonBindingErrResp(err, c, map[string]string{
+			"visibility":        "required and must be one of everyone, matches or nobody",
+			"lastSeenPrecision": "required and must be one of exact or coarse",
+		})
+		if errjson != nil {
+			errServerResp(c, err)
+			return
+		}
+		return
+	}
+	err = o.onlineSvc.PutSettings(userId, input)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "user-online settings updated",
+	})
+}
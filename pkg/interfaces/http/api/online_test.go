@@ -234,22 +234,21 @@ func Test_putsUserOnlineHandler(t *testing.T) {
 		wantResp  map[string]any
 	}{
 		{
-			name:   "valid online put true",
+			name:   "online put true is not allowed",
 			userId: validUserId,
 			reqBody: map[string]bool{
 				"online": true,
 			},
 			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Online {
 				onlineRepo := mockrepo.NewMockOnline(ctrl)
-				onlineRepo.EXPECT().UpdateOnline(gomock.Eq(validUserId), gomock.Eq(true)).Times(1).
-					Return(nil)
+				onlineRepo.EXPECT().UpdateOnline(gomock.Any(), gomock.Any()).Times(0)
 				onlineSvc := service.NewOnline(onlineRepo)
 				return NewOnline(onlineSvc)
 			},
-			wantCode: http.StatusOK,
+			wantCode: http.StatusUnprocessableEntity,
 			wantResp: map[string]any{
-				"status":  "success",
-				"message": "user-online updated",
+				"status":  "fail",
+				"message": "online can only be set to false, presence is derived from the realtime connection",
 			},
 		},
 		{
@@ -275,11 +274,11 @@ func Test_putsUserOnlineHandler(t *testing.T) {
 			name:   "userId not Found",
 			userId: validUserId,
 			reqBody: map[string]bool{
-				"online": true,
+				"online": false,
 			},
 			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Online {
 				onlineRepo := mockrepo.NewMockOnline(ctrl)
-				onlineRepo.EXPECT().UpdateOnline(gomock.Eq(validUserId), gomock.Eq(true)).Times(1).
+				onlineRepo.EXPECT().UpdateOnline(gomock.Eq(validUserId), gomock.Eq(false)).Times(1).
 					Return(common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound))
 				onlineSvc := service.NewOnline(onlineRepo)
 				return NewOnline(onlineSvc)
@@ -320,3 +319,133 @@ func Test_putsUserOnlineHandler(t *testing.T) {
 		})
 	}
 }
+
+func Test_putUserOnlineSettingsHandler(t *testing.T) {
+	validUserId := util.RandomUUID()
+
+	tests := []struct {
+		name      string
+		userId    string
+		reqBody   map[string]string
+		setupFunc func(t *testing.T, ctrl *gomock.Controller) *Online
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:   "valid settings put",
+			userId: validUserId,
+			reqBody: map[string]string{
+				"visibility":        "matches",
+				"lastSeenPrecision": "coarse",
+			},
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Online {
+				onlineRepo := mockrepo.NewMockOnline(ctrl)
+				onlineRepo.EXPECT().SelectOnline(gomock.Eq(validUserId)).Times(1).
+					Return(onlineEntity.DTO{UserId: validUserId, LastOnline: time.Now()}, nil)
+				onlineRepo.EXPECT().UpdateSettings(gomock.Eq(validUserId), gomock.Eq(onlineEntity.Settings{
+					Visibility:        onlineEntity.VisibleToMatches,
+					LastSeenPrecision: onlineEntity.LastSeenCoarse,
+				})).Times(1).Return(nil)
+				onlineSvc := service.NewOnline(onlineRepo)
+				return NewOnline(onlineSvc)
+			},
+			wantCode: http.StatusOK,
+			wantResp: map[string]any{
+				"status":  "success",
+				"message": "user-online settings updated",
+			},
+		},
+		{
+			name:   "invalid visibility",
+			userId: validUserId,
+			reqBody: map[string]string{
+				"visibility":        "friends",
+				"lastSeenPrecision": "exact",
+			},
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Online {
+				onlineRepo := mockrepo.NewMockOnline(ctrl)
+				onlineRepo.EXPECT().UpdateSettings(gomock.Any(), gomock.Any()).Times(0)
+				onlineSvc := service.NewOnline(onlineRepo)
+				return NewOnline(onlineSvc)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]string{
+					"visibility": "required and must be one of everyone, matches or nobody",
+				},
+			},
+		},
+		{
+			name:   "missing lastSeenPrecision",
+			userId: validUserId,
+			reqBody: map[string]string{
+				"visibility": "nobody",
+			},
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Online {
+				onlineRepo := mockrepo.NewMockOnline(ctrl)
+				onlineRepo.EXPECT().UpdateSettings(gomock.Any(), gomock.Any()).Times(0)
+				onlineSvc := service.NewOnline(onlineRepo)
+				return NewOnline(onlineSvc)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]string{
+					"lastSeenPrecision": "required and must be one of exact or coarse",
+				},
+			},
+		},
+		{
+			name:   "userId not Found",
+			userId: validUserId,
+			reqBody: map[string]string{
+				"visibility":        "nobody",
+				"lastSeenPrecision": "exact",
+			},
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Online {
+				onlineRepo := mockrepo.NewMockOnline(ctrl)
+				onlineRepo.EXPECT().SelectOnline(gomock.Eq(validUserId)).Times(1).
+					Return(onlineEntity.DTO{}, common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound))
+				onlineRepo.EXPECT().UpdateSettings(gomock.Any(), gomock.Any()).Times(0)
+				onlineSvc := service.NewOnline(onlineRepo)
+				return NewOnline(onlineSvc)
+			},
+			wantCode: http.StatusNotFound,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "resource not found",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			onlineH := tt.setupFunc(t, ctrl)
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set("userId", tt.userId)
+
+			reqJsonBody, err := json.Marshal(tt.reqBody)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/users/%s/online/settings", tt.userId), bytes.NewReader(reqJsonBody))
+			c.Request = req
+
+			onlineH.putUserOnlineSettingsHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			require.Contains(t, rr.Header().Get("Content-Type"), "application/json")
+
+			if tt.wantResp != nil {
+				expResBody, err := json.Marshal(tt.wantResp)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+			}
+		})
+	}
+}
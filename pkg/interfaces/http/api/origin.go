@@ -0,0 +1,137 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	anyOrigin         = "*"
+	regexOriginPrefix = "regex:"
+)
+
+// Origins is the origin policy shared by the websocket upgrader and the cors middleware.
+// each pattern is one of:
+//   - "*" to allow every origin
+//   - an exact origin, "https://blindate.com"
+//   - a wildcard subdomain, "https://*.blindate.com" (does not match the apex domain)
+//   - a regex prefixed with "regex:", "regex:^https://[a-z0-9-]+\.vercel\.app$"
+type Origins struct {
+	any       bool
+	exact     map[string]struct{}
+	wildcards []wildcardOrigin
+	regexes   []*regexp.Regexp
+}
+
+type wildcardOrigin struct {
+	scheme string
+	suffix string
+	port   string
+}
+
+func NewOrigins(patterns []string) (*Origins, error) {
+	origins := &Origins{
+		exact: make(map[string]struct{}),
+	}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		switch {
+		case pattern == "":
+			continue
+		case pattern == anyOrigin:
+			origins.any = true
+		case strings.HasPrefix(pattern, regexOriginPrefix):
+			re, err := regexp.Compile(strings.TrimPrefix(pattern, regexOriginPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("invalid origin regex %q: %w", pattern, err)
+			}
+			origins.regexes = append(origins.regexes, re)
+		case strings.Contains(pattern, "*"):
+			wildcard, err := parseWildcardOrigin(pattern)
+			if err != nil {
+				return nil, err
+			}
+			origins.wildcards = append(origins.wildcards, wildcard)
+		default:
+			origin, ok := normalizeOrigin(pattern)
+			if !ok {
+				return nil, fmt.Errorf("invalid origin %q", pattern)
+			}
+			origins.exact[origin] = struct{}{}
+		}
+	}
+	return origins, nil
+}
+
+// AllowAny reports whether the policy was configured with "*"
+func (o *Origins) AllowAny() bool {
+	return o.any
+}
+
+func (o *Origins) Allowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	if o.any {
+		return true
+	}
+	normalized, ok := normalizeOrigin(origin)
+	if !ok {
+		return false
+	}
+	if _, ok := o.exact[normalized]; ok {
+		return true
+	}
+	for _, wildcard := range o.wildcards {
+		if wildcard.match(normalized) {
+			return true
+		}
+	}
+	for _, re := range o.regexes {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w wildcardOrigin) match(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != w.scheme || u.Port() != w.port {
+		return false
+	}
+	host := u.Hostname()
+	return strings.HasSuffix(host, w.suffix) && len(host) > len(w.suffix)
+}
+
+func parseWildcardOrigin(pattern string) (wildcardOrigin, error) {
+	scheme, rest, ok := strings.Cut(pattern, "://")
+	if !ok || !strings.HasPrefix(rest, "*.") || strings.Count(rest, "*") != 1 {
+		return wildcardOrigin{}, fmt.Errorf("invalid wildcard origin %q, must be in scheme://*.domain format", pattern)
+	}
+	u, err := url.Parse(scheme + "://" + strings.TrimPrefix(rest, "*."))
+	if err != nil || u.Hostname() == "" {
+		return wildcardOrigin{}, fmt.Errorf("invalid wildcard origin %q", pattern)
+	}
+	return wildcardOrigin{
+		scheme: strings.ToLower(u.Scheme),
+		suffix: "." + strings.ToLower(u.Hostname()),
+		port:   u.Port(),
+	}, nil
+}
+
+func normalizeOrigin(origin string) (string, bool) {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", false
+	}
+	if u.Path != "" && u.Path != "/" {
+		return "", false
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host), true
+}
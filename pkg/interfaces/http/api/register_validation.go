@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	basicInfoEntity "github.com/xyedo/blindate/pkg/domain/basicinfo/entities"
+	chatEntity "github.com/xyedo/blindate/pkg/domain/chat/entities"
 	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
 )
 
@@ -30,6 +31,16 @@ func registerValidEducationLevelFieldValidator() {
 		panic("not ok validator")
 	}
 }
+func registerValidEmojiValidator() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		err := v.RegisterValidation("validemoji", chatEntity.ValidEmoji)
+		if err != nil {
+			panic(err)
+		}
+	} else {
+		panic("not ok validator")
+	}
+}
 func registerTagName() {
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterTagNameFunc(func(fld reflect.StructField) string {
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
 )
 
 type Route struct {
@@ -13,12 +14,21 @@ type Route struct {
 	Location       *Location
 	Authentication *Auth
 	Tokenizer      jwtSvc
+	Access         accessSvc
+	Authorizer     *Authorizer
 	Interest       *Interest
 	Online         *Online
 	Match          *Match
 	Convo          *Conversation
 	Chat           *Chat
+	Sticker        *Sticker
+	Blob           *Blob
+	Block          *Block
+	Moderation     *Moderation
+	Account        *Account
+	Export         *Export
 	Webscoket      *Ws
+	Cors           Cors
 }
 
 func Routes(route Route) http.Handler {
@@ -32,28 +42,61 @@ func Routes(route Route) http.Handler {
 	registerTagName()
 	registerValidDObValidator()
 	registerValidEducationLevelFieldValidator()
-	v1 := r.Group("/api/v1")
+	registerValidEmojiValidator()
+	v1 := r.Group("/api/v1", cors(route.Cors))
+	v1.OPTIONS("/*path", preflight)
 
 	rh := route.Healthcheck
 	v1.GET("/healthcheck", rh.healthCheckHandler)
 
+	// the blob url is signed, so it is served without the access token
+	if rblob := route.Blob; rblob != nil {
+		v1.GET("/blobs/*key", rblob.getBlobHandler)
+	}
+
 	ra := route.Authentication
 	v1.POST("/auth", ra.postAuthHandler)
 	v1.PUT("/auth", ra.putAuthHandler)
 	v1.DELETE("/auth", ra.deleteAuthHandler)
 	ru := route.User
 	v1.POST("/users", ru.postUserHandler)
-	auth := v1.Group("/", authToken(route.Tokenizer))
+	auth := v1.Group("/", authToken(route.Tokenizer, route.Access))
+	auth.GET("/avatars/:userId", ru.getAvatarHandler)
+
+	rblock := route.Block
+	auth.POST("/users/:userId/block", validateTargetUser(), rblock.postBlockHandler)
+	auth.DELETE("/users/:userId/block", validateTargetUser(), rblock.deleteBlockHandler)
+	auth.GET("/blocks", rblock.getBlocksHandler)
+	auth.POST("/reports", rblock.postReportHandler)
 	user := auth.Group("/users/:userId", validateUser())
 	{
 		user.GET("/", ru.getUserByIdHandler)
 		user.PATCH("/", ru.patchUserByIdHandler)
+
+		racc := route.Account
+		user.DELETE("/", racc.deleteAccountHandler)
+		user.PUT("/snooze", racc.putSnoozeHandler)
+		user.DELETE("/snooze", racc.deleteSnoozeHandler)
+
+		rexp := route.Export
+		user.POST("/export", rexp.postExportHandler)
+		user.GET("/exports", rexp.getExportsHandler)
+		user.GET("/exports/:exportId", validateExport(), rexp.getExportByIdHandler)
+
 		user.PUT("/profile-picture", ru.putUserImageProfileHandler)
+		user.GET("/profile-pictures", ru.getProfilePicturesHandler)
+		user.PUT("/profile-pictures/order", ru.putProfilePictureOrderHandler)
+		profPic := user.Group("/profile-pictures/:pictureId", validateProfilePicture())
+		{
+			profPic.PUT("/selected", ru.putSelectedProfilePictureHandler)
+			profPic.DELETE("/", ru.deleteProfilePictureHandler)
+		}
 
 		ro := route.Online
 		user.POST("/online", ro.postUserOnlineHandler)
 		user.GET("/online", ro.getUserOnlineHandler)
 		user.PUT("/online", ro.putuserOnlineHandler)
+		user.PUT("/online/settings", ro.putUserOnlineSettingsHandler)
 
 		rb := route.BasicInfo
 		user.POST("/basic-info", rb.postBasicInfoHandler)
@@ -91,6 +134,7 @@ func Routes(route Route) http.Handler {
 	}
 	rw := route.Webscoket
 	auth.GET("/ws", rw.wsEndPoint)
+	auth.GET("/events", rw.sseEndPoint)
 
 	rm := route.Match
 	auth.GET("/new-match", rm.getNewUserToMatchHandler)
@@ -101,22 +145,64 @@ func Routes(route Route) http.Handler {
 	{
 		match.PUT("/request", rm.putRequestHandler)
 		match.PUT("/reveal", rm.putRevealHandler)
+		match.GET("/timeline", rm.getMatchTimelineHandler)
+	}
+
+	rs := route.Sticker
+	auth.GET("/stickers", rs.getCatalogueHandler)
+	rmod := route.Moderation
+	staff := auth.Group("/admin", requireRole(userEntity.RoleModerator, userEntity.RoleAdmin))
+	{
+		staff.GET("/reports", rmod.getReportsHandler)
+		report := staff.Group("/reports/:reportId", validateReport())
+		{
+			report.GET("/", rmod.getReportByIdHandler)
+			report.GET("/context", rmod.getReportContextHandler)
+			report.PUT("/review", rmod.putReviewReportHandler)
+		}
+		staff.GET("/users/:userId/suspensions", validateTargetUser(), rmod.getSuspensionsHandler)
+		staff.POST("/users/:userId/suspensions", validateTargetUser(), rmod.postSuspensionHandler)
+		staff.DELETE("/users/:userId/suspensions", validateTargetUser(), rmod.deleteSuspensionHandler)
+
+		admin := staff.Group("/", requireRole(userEntity.RoleAdmin))
+		{
+			admin.GET("/audit-logs", rmod.getAuditLogsHandler)
+			admin.PUT("/users/:userId/role", validateTargetUser(), rmod.putRoleHandler)
+
+			admin.GET("/sticker-packs", rs.getPacksHandler)
+			admin.POST("/sticker-packs", rs.postPackHandler)
+
+			pack := admin.Group("/sticker-packs/:packId", validateStickerPack())
+			{
+				pack.GET("/", rs.getPackByIdHandler)
+				pack.PATCH("/", rs.patchPackHandler)
+				pack.POST("/stickers", rs.postStickerHandler)
+			}
+			admin.DELETE("/stickers/:stickerId", validateSticker(), rs.deleteStickerHandler)
+		}
 	}
 
 	rconv := route.Convo
 	auth.POST("/conversation", rconv.postConversationHandler)
 	auth.GET("/conversation", rconv.getConversationByUserId)
-	conv := auth.Group("/:conversationId", validateConversation())
+	authz := route.Authorizer
+	conv := auth.Group("/:conversationId", validateConversation(), authz.conversation(isParticipant))
 	{
 		conv.GET("/", rconv.getConversationById)
 		conv.DELETE("/", rconv.deleteConversationById)
+		conv.PUT("/state", rconv.putConversationStateHandler)
 		rchat := route.Chat
 		conv.POST("/chat", rchat.postChatHandler)
 		conv.POST("/chat-media", rchat.postChatMediaHandler)
+		conv.POST("/chat-sticker", rchat.postChatStickerHandler)
 		conv.GET("/chat", rchat.getMessagesHandler)
 		conv.PUT("/chat/seenAt", rchat.putSeenAtHandler)
 
-		conv.DELETE("/chat/:chatId", validateChat(), rchat.deleteMessagesByIdHandler)
+		conv.PATCH("/chat/:chatId", validateChat(), authz.chat(isChatAuthor), rchat.patchMessageHandler)
+		conv.GET("/chat/:chatId/history", validateChat(), authz.chat(), rchat.getMessageHistoryHandler)
+		conv.DELETE("/chat/:chatId", validateChat(), authz.chat(isChatAuthor), rchat.deleteMessagesByIdHandler)
+		conv.POST("/chat/:chatId/reactions", validateChat(), authz.chat(), rchat.postReactionHandler)
+		conv.DELETE("/chat/:chatId/reactions/:emoji", validateChat(), authz.chat(), rchat.deleteReactionHandler)
 	}
 
 	r.NoMethod(noMethod)
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	websocketEntity "github.com/xyedo/blindate/pkg/domain/ws"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+const (
+	lastEventIdHeaderKey = "Last-Event-ID"
+
+	sseBufferSize = 32
+	sseRetry      = 3 * time.Second
+	sseKeepAlive  = 10 * time.Second
+	// sseStreamDuration must be lower than the server WriteTimeout,
+	// the client reconnect with Last-Event-ID after the stream ended
+	sseStreamDuration = 25 * time.Second
+)
+
+func (ws *Ws) sseEndPoint(c *gin.Context) {
+	userId := c.GetString(keyUserId)
+	lastEventId, err := readLastEventId(c)
+	if err != nil {
+		errBadRequestResp(c, "Last-Event-ID must be a valid event id")
+		return
+	}
+
+	conn := websocketEntity.NewSseConn(util.RandomUUID(), sseBufferSize)
+	ws.wsSvc.Register(userId, conn)
+
+	var clientGone bool
+	defer func() {
+		_ = conn.Close()
+		if clientGone {
+			ws.wsSvc.Unregister(conn)
+			return
+		}
+		ws.wsSvc.Detach(conn)
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetry.Milliseconds()); err != nil {
+		return
+	}
+	for _, resp := range ws.wsSvc.Replay(userId, lastEventId) {
+		if err := writeSseEvent(c.Writer, resp); err != nil {
+			return
+		}
+		lastEventId = resp.Id
+	}
+	c.Writer.Flush()
+
+	streamTimer := time.NewTimer(sseStreamDuration)
+	defer streamTimer.Stop()
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			clientGone = true
+			return
+		case <-conn.Done():
+			return
+		case <-streamTimer.C:
+			return
+		case <-keepAlive.C:
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				clientGone = true
+				return
+			}
+			ws.wsSvc.Heartbeat(conn)
+		case resp := <-conn.Events():
+			// already sent on replay
+			if resp.Id != 0 && resp.Id <= lastEventId {
+				continue
+			}
+			if err := writeSseEvent(c.Writer, resp); err != nil {
+				clientGone = true
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func readLastEventId(c *gin.Context) (uint64, error) {
+	lastEventId := c.GetHeader(lastEventIdHeaderKey)
+	if lastEventId == "" {
+		lastEventId = c.Query("lastEventId")
+	}
+	if lastEventId == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(lastEventId, 10, 64)
+}
+
+func writeSseEvent(w io.Writer, resp websocketEntity.Response) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+	if resp.Id != 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", resp.Id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", resp.Action, data)
+	return err
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/applications/service"
+	websocketEntity "github.com/xyedo/blindate/pkg/domain/ws"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_sseEndPoint(t *testing.T) {
+	validUserId := util.RandomUUID()
+
+	setupServer := func(wsSvc *service.Ws) *httptest.Server {
+		wsH := NewWs(wsSvc, nil)
+
+		r := gin.New()
+		r.GET("/events", func(c *gin.Context) {
+			c.Set(keyUserId, validUserId)
+		}, wsH.sseEndPoint)
+		return httptest.NewServer(r)
+	}
+	readEvent := func(t *testing.T, scanner *bufio.Scanner) map[string]string {
+		event := make(map[string]string)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" {
+				if len(event) != 0 {
+					return event
+				}
+				continue
+			}
+			key, value, _ := strings.Cut(line, ": ")
+			event[key] = value
+		}
+		require.NoError(t, scanner.Err())
+		return event
+	}
+
+	t.Run("replay after Last-Event-ID then stream live event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		onlineRepo := mockrepo.NewMockOnline(ctrl)
+		onlineRepo.EXPECT().UpdateHeartbeat(gomock.Eq(validUserId), gomock.Any()).Times(1).Return(false, nil)
+		onlineRepo.EXPECT().UpdateOnline(gomock.Eq(validUserId), gomock.Eq(false)).Times(1).Return(nil)
+		wsSvc := service.NewWs(service.NewOnline(onlineRepo))
+		require.NoError(t, wsSvc.Send(validUserId, websocketEntity.Response{Action: "OnMessage"}))
+		require.NoError(t, wsSvc.Send(validUserId, websocketEntity.Response{Action: "update.chat.seenAt"}))
+		history := wsSvc.Replay(validUserId, 0)
+		require.Len(t, history, 2)
+
+		srv := setupServer(wsSvc)
+		defer srv.Close()
+
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/events", nil)
+		require.NoError(t, err)
+		req.Header.Set(lastEventIdHeaderKey, fmt.Sprint(history[0].Id))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		scanner := bufio.NewScanner(resp.Body)
+		retry := readEvent(t, scanner)
+		assert.Equal(t, "3000", retry["retry"])
+
+		replayed := readEvent(t, scanner)
+		assert.Equal(t, fmt.Sprint(history[1].Id), replayed["id"])
+		assert.Equal(t, "update.chat.seenAt", replayed["event"])
+
+		require.Eventually(t, func() bool {
+			_, ok := wsSvc.ReverseClient.Get(validUserId)
+			return ok
+		}, time.Second, 10*time.Millisecond)
+		require.NoError(t, wsSvc.Send(validUserId, websocketEntity.Response{
+			Action: "reveal.requested",
+			Data:   map[string]any{"matchId": "1"},
+		}))
+		require.NoError(t, wsSvc.SendTransient(validUserId, websocketEntity.Response{Action: "onTypingStart"}))
+
+		live := readEvent(t, scanner)
+		assert.Equal(t, "reveal.requested", live["event"])
+		assert.NotEmpty(t, live["id"])
+		assert.Contains(t, live["data"], `"matchId":"1"`)
+
+		transient := readEvent(t, scanner)
+		assert.Equal(t, "onTypingStart", transient["event"])
+		assert.Empty(t, transient["id"])
+
+		require.NoError(t, resp.Body.Close())
+		require.Eventually(t, func() bool {
+			_, ok := wsSvc.ReverseClient.Get(validUserId)
+			return !ok
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("invalid Last-Event-ID", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Set(keyUserId, validUserId)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events", nil)
+		req.Header.Set(lastEventIdHeaderKey, "not-a-number")
+		c.Request = req
+
+		wsH := NewWs(service.NewWs(nil), nil)
+		wsH.sseEndPoint(c)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.JSONEq(t, `{"status":"fail","message":"Last-Event-ID must be a valid event id"}`, rr.Body.String())
+	})
+}
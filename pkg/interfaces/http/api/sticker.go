@@ -0,0 +1,182 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	stickerEntity "github.com/xyedo/blindate/pkg/domain/sticker/entities"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+type stickerSvc interface {
+	CreatePack(newPack stickerEntity.NewPack) (string, error)
+	GetCatalogue() ([]stickerEntity.Pack, error)
+	GetPacks() ([]stickerEntity.Pack, error)
+	GetPackById(packId string) (stickerEntity.Pack, error)
+	UpdatePack(packId string, update stickerEntity.UpdatePack) (stickerEntity.Pack, error)
+	AddSticker(newSticker stickerEntity.Sticker) (string, error)
+	ArchiveSticker(stickerId string) error
+}
+
+func NewSticker(stickerSvc stickerSvc, attachSvc attachmentManager) *Sticker {
+	return &Sticker{
+		stickerSvc: stickerSvc,
+		attachSvc:  attachSvc,
+	}
+}
+
+type Sticker struct {
+	stickerSvc stickerSvc
+	attachSvc  attachmentManager
+}
+
+func (s *Sticker) getCatalogueHandler(c *gin.Context) {
+	packs, err := s.stickerSvc.GetCatalogue()
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"packs": packs,
+		},
+	})
+}
+
+func (s *Sticker) getPacksHandler(c *gin.Context) {
+	packs, err := s.stickerSvc.GetPacks()
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"packs": packs,
+		},
+	})
+}
+
+func (s *Sticker) getPackByIdHandler(c *gin.Context) {
+	pack, err := s.stickerSvc.GetPackById(c.GetString(keyPackId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"pack": pack,
+		},
+	})
+}
+
+func (s *Sticker) postPackHandler(c *gin.Context) {
+	var input stickerEntity.NewPack
+	if err := c.ShouldBindJSON(&input); err != nil {
+		if jsonErr := jsonBindingErrResp(err, c, map[string]string{
+			"name": "required and must less than 50 character",
+		}); jsonErr != nil {
+			errServerResp(c, err)
+			return
+		}
+		return
+	}
+	id, err := s.stickerSvc.CreatePack(input)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"pack": gin.H{
+				"id": id,
+			},
+		},
+	})
+}
+
+func (s *Sticker) patchPackHandler(c *gin.Context) {
+	var input stickerEntity.UpdatePack
+	if err := c.ShouldBindJSON(&input); err != nil {
+		if jsonErr := jsonBindingErrResp(err, c, map[string]string{
+			"name":     "if provided, must between 1-50 character",
+			"archived": "if provided, must be boolean",
+		}); jsonErr != nil {
+			errServerResp(c, err)
+			return
+		}
+		return
+	}
+	pack, err := s.stickerSvc.UpdatePack(c.GetString(keyPackId), input)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"pack": pack,
+		},
+	})
+}
+
+func (s *Sticker) postStickerHandler(c *gin.Context) {
+	var query struct {
+		Emoji *string `form:"emoji" binding:"omitempty,validemoji"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		if errMap := util.ReadValidationErr(err, map[string]string{
+			"Emoji": "if provided, must be a single emoji",
+		}); errMap != nil {
+			errValidationResp(c, errMap)
+			return
+		}
+		errServerResp(c, err)
+		return
+	}
+	var validStickerTypes = []string{
+		"image/png",
+		"image/webp",
+		"image/gif",
+	}
+	key, mediaType := uploadFile(c, s.attachSvc, validStickerTypes, "sticker")
+	if key == "" {
+		return
+	}
+	id, err := s.stickerSvc.AddSticker(stickerEntity.Sticker{
+		PackId:    c.GetString(keyPackId),
+		Emoji:     query.Emoji,
+		BlobLink:  key,
+		MediaType: mediaType,
+	})
+	if err != nil {
+		if delErr := s.attachSvc.DeleteBlob(key); delErr != nil {
+			log.Println(delErr)
+		}
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"sticker": gin.H{
+				"id": id,
+			},
+		},
+	})
+}
+
+func (s *Sticker) deleteStickerHandler(c *gin.Context) {
+	if err := s.stickerSvc.ArchiveSticker(c.GetString(keyStickerId)); err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "sticker archived",
+	})
+}
@@ -0,0 +1,365 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xyedo/blindate/pkg/applications/service"
+	mocksvc "github.com/xyedo/blindate/pkg/applications/service/mock"
+	"github.com/xyedo/blindate/pkg/common"
+	stickerEntity "github.com/xyedo/blindate/pkg/domain/sticker/entities"
+	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
+	"github.com/xyedo/blindate/pkg/util"
+)
+
+func Test_getCatalogueHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	stickerRepo := mockrepo.NewMockSticker(ctrl)
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	stickerRepo.EXPECT().SelectPacks(gomock.Eq(false)).Times(1).Return([]stickerEntity.Pack{
+		{
+			Id:        "pack-1",
+			Name:      "waves",
+			CreatedAt: createdAt,
+			Stickers: []stickerEntity.Sticker{
+				{Id: "sticker-1", PackId: "pack-1", BlobLink: "sticker/wave.png", MediaType: "image/png", CreatedAt: createdAt},
+			},
+		},
+	}, nil)
+	stickerH := NewSticker(service.NewSticker(stickerRepo, testBlobUrl), nil)
+
+	rr := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rr)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/stickers", nil)
+
+	stickerH.getCatalogueHandler(c)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	expResBody, err := json.Marshal(map[string]any{
+		"status": "success",
+		"data": map[string]any{
+			"packs": []map[string]any{
+				{
+					"id":         "pack-1",
+					"name":       "waves",
+					"createdAt":  createdAt,
+					"archivedAt": nil,
+					"stickers": []map[string]any{
+						{
+							"id":         "sticker-1",
+							"packId":     "pack-1",
+							"emoji":      nil,
+							"blobLink":   testBlobHost + "sticker/wave.png",
+							"mediaType":  "image/png",
+							"createdAt":  createdAt,
+							"archivedAt": nil,
+						},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expResBody), rr.Body.String())
+}
+
+func Test_postPackHandler(t *testing.T) {
+	validPackId := util.RandomUUID()
+	tests := []struct {
+		name      string
+		reqBody   string
+		setupFunc func(stickerRepo *mockrepo.MockSticker)
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:    "valid pack",
+			reqBody: `{"name":"cats"}`,
+			setupFunc: func(stickerRepo *mockrepo.MockSticker) {
+				stickerRepo.EXPECT().InsertPack(gomock.Eq("cats")).Times(1).Return(validPackId, nil)
+			},
+			wantCode: http.StatusCreated,
+			wantResp: map[string]any{
+				"status": "success",
+				"data": map[string]any{
+					"pack": map[string]any{
+						"id": validPackId,
+					},
+				},
+			},
+		},
+		{
+			name:    "name too long",
+			reqBody: fmt.Sprintf(`{"name":"%s"}`, util.RandomString(51)),
+			setupFunc: func(stickerRepo *mockrepo.MockSticker) {
+				stickerRepo.EXPECT().InsertPack(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "duplicate name",
+			reqBody: `{"name":"cats"}`,
+			setupFunc: func(stickerRepo *mockrepo.MockSticker) {
+				stickerRepo.EXPECT().InsertPack(gomock.Eq("cats")).Times(1).
+					Return("", common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrUniqueConstraint23505, "sticker pack with this name is already created"))
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "sticker pack with this name is already created",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			stickerRepo := mockrepo.NewMockSticker(ctrl)
+			tt.setupFunc(stickerRepo)
+			stickerH := NewSticker(service.NewSticker(stickerRepo, testBlobUrl), nil)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/sticker-packs", strings.NewReader(tt.reqBody))
+
+			stickerH.postPackHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.wantResp != nil {
+				expResBody, err := json.Marshal(tt.wantResp)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+			}
+		})
+	}
+}
+
+func Test_patchPackHandler(t *testing.T) {
+	validPackId := util.RandomUUID()
+	tests := []struct {
+		name      string
+		reqBody   string
+		setupFunc func(t *testing.T, stickerRepo *mockrepo.MockSticker)
+		wantCode  int
+	}{
+		{
+			name:    "archive pack",
+			reqBody: `{"archived":true}`,
+			setupFunc: func(t *testing.T, stickerRepo *mockrepo.MockSticker) {
+				stickerRepo.EXPECT().UpdatePack(gomock.Eq(validPackId), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(packId string, update stickerEntity.UpdatePack, at time.Time) (stickerEntity.Pack, error) {
+						assert.Nil(t, update.Name)
+						require.NotNil(t, update.Archived)
+						assert.True(t, *update.Archived)
+						return stickerEntity.Pack{Id: packId, ArchivedAt: &at}, nil
+					})
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:    "empty name",
+			reqBody: `{"name":""}`,
+			setupFunc: func(t *testing.T, stickerRepo *mockrepo.MockSticker) {
+				stickerRepo.EXPECT().UpdatePack(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "pack not found",
+			reqBody: `{"name":"dogs"}`,
+			setupFunc: func(t *testing.T, stickerRepo *mockrepo.MockSticker) {
+				stickerRepo.EXPECT().UpdatePack(gomock.Eq(validPackId), gomock.Any(), gomock.Any()).Times(1).
+					Return(stickerEntity.Pack{}, common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound))
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			stickerRepo := mockrepo.NewMockSticker(ctrl)
+			tt.setupFunc(t, stickerRepo)
+			stickerH := NewSticker(service.NewSticker(stickerRepo, testBlobUrl), nil)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyPackId, validPackId)
+			c.Request = httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/api/v1/admin/sticker-packs/%s", validPackId), strings.NewReader(tt.reqBody))
+
+			stickerH.patchPackHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+		})
+	}
+}
+
+func Test_postStickerHandler(t *testing.T) {
+	validPackId := util.RandomUUID()
+	writeFile := func(name string, content []byte) func(writer *multipart.Writer) {
+		return func(writer *multipart.Writer) {
+			defer writer.Close()
+			part, err := writer.CreateFormFile("file", name)
+			require.NoError(t, err)
+			_, err = part.Write(content)
+			require.NoError(t, err)
+		}
+	}
+	pngHeader := append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), make([]byte, 64)...)
+
+	tests := []struct {
+		name      string
+		query     string
+		writeMime func(writer *multipart.Writer)
+		setupFunc func(t *testing.T, ctrl *gomock.Controller) *Sticker
+		wantCode  int
+		wantResp  map[string]any
+	}{
+		{
+			name:      "valid sticker",
+			query:     "?emoji=%F0%9F%98%80",
+			writeMime: writeFile("grin.png", pngHeader),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Sticker {
+				validKey := "sticker/" + util.RandomUUID() + ".png"
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(1).Return(validKey, nil)
+				attachSvc.EXPECT().DeleteBlob(gomock.Any()).Times(0)
+				stickerRepo := mockrepo.NewMockSticker(ctrl)
+				stickerRepo.EXPECT().InsertSticker(gomock.Any()).Times(1).
+					DoAndReturn(func(sticker *stickerEntity.Sticker) error {
+						assert.Equal(t, validPackId, sticker.PackId)
+						assert.Equal(t, validKey, sticker.BlobLink)
+						assert.Equal(t, "image/png", sticker.MediaType)
+						require.NotNil(t, sticker.Emoji)
+						assert.Equal(t, "\U0001F600", *sticker.Emoji)
+						sticker.Id = util.RandomUUID()
+						return nil
+					})
+				return NewSticker(service.NewSticker(stickerRepo, testBlobUrl), attachSvc)
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:      "invalid emoji",
+			query:     "?emoji=abc",
+			writeMime: writeFile("grin.png", pngHeader),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Sticker {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
+				return NewSticker(service.NewSticker(mockrepo.NewMockSticker(ctrl), testBlobUrl), attachSvc)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:      "not valid mime-type",
+			writeMime: writeFile("note.txt", []byte("just a plain text")),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Sticker {
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
+				return NewSticker(service.NewSticker(mockrepo.NewMockSticker(ctrl), testBlobUrl), attachSvc)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "not valid mime-type",
+			},
+		},
+		{
+			name:      "pack not found delete the uploaded blob",
+			writeMime: writeFile("grin.png", pngHeader),
+			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *Sticker {
+				validKey := "sticker/" + util.RandomUUID() + ".png"
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(1).Return(validKey, nil)
+				attachSvc.EXPECT().DeleteBlob(gomock.Eq(validKey)).Times(1).Return(nil)
+				stickerRepo := mockrepo.NewMockSticker(ctrl)
+				stickerRepo.EXPECT().InsertSticker(gomock.Any()).Times(1).
+					Return(common.WrapErrorWithMsg(sql.ErrNoRows, common.ErrRefNotFound23503, "sticker pack is invalid"))
+				return NewSticker(service.NewSticker(stickerRepo, testBlobUrl), attachSvc)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "sticker pack is invalid",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			pr, pw := io.Pipe()
+			writer := multipart.NewWriter(pw)
+			go tt.writeMime(writer)
+
+			stickerH := tt.setupFunc(t, ctrl)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyPackId, validPackId)
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/admin/sticker-packs/%s/stickers%s", validPackId, tt.query), pr)
+			req.Header.Add("Content-Type", writer.FormDataContentType())
+			c.Request = req
+
+			stickerH.postStickerHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			require.Contains(t, rr.Header().Get("Content-Type"), "application/json")
+			if tt.wantResp != nil {
+				expResBody, err := json.Marshal(tt.wantResp)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+			}
+		})
+	}
+}
+
+func Test_deleteStickerHandler(t *testing.T) {
+	validStickerId := util.RandomUUID()
+	tests := []struct {
+		name     string
+		repoErr  error
+		wantCode int
+	}{
+		{
+			name:     "valid",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "already archived",
+			repoErr:  common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound),
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			stickerRepo := mockrepo.NewMockSticker(ctrl)
+			stickerRepo.EXPECT().ArchiveSticker(gomock.Eq(validStickerId), gomock.Any()).Times(1).Return(tt.repoErr)
+			stickerH := NewSticker(service.NewSticker(stickerRepo, testBlobUrl), nil)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyStickerId, validStickerId)
+			c.Request = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/admin/stickers/%s", validStickerId), nil)
+
+			stickerH.deleteStickerHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+		})
+	}
+}
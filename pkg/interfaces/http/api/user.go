@@ -1,7 +1,10 @@
 package api
 
 import (
+	"bytes"
 	"io"
+	"log"
+	"mime/multipart"
 	"net/http"
 	"strings"
 
@@ -15,22 +18,34 @@ type userSvc interface {
 	GetUserById(id string) (userEntity.FullDTO, error)
 	UpdateUser(userId string, updateUser userEntity.Update) error
 	CreateNewProfilePic(profPicParam userEntity.ProfilePic) (string, error)
+	GetProfilePics(userId string) ([]userEntity.ProfilePic, error)
+	SelectProfilePic(userId, id string) error
+	ReorderProfilePics(userId string, ids []string) ([]userEntity.ProfilePic, error)
+	DeleteProfilePic(userId, id string) (userEntity.ProfilePic, error)
+}
+
+type profileMediaSvc interface {
+	ProcessProfilePicture(file io.Reader, size int64, mediaType string) (attachmentEntity.ProfileRenditions, error)
+	PlaceholderAvatar(seed, rendition string) ([]byte, error)
 }
 
 type attachmentManager interface {
 	UploadBlob(file io.Reader, attach attachmentEntity.Uploader) (string, error)
+	DeleteBlob(key string) error
 }
 
-func NewUser(userSvc userSvc, attachmentSvc attachmentManager) *User {
+func NewUser(userSvc userSvc, attachmentSvc attachmentManager, mediaSvc profileMediaSvc) *User {
 	return &User{
 		userService:   userSvc,
 		attachmentSvc: attachmentSvc,
+		mediaSvc:      mediaSvc,
 	}
 }
 
 type User struct {
 	userService   userSvc
 	attachmentSvc attachmentManager
+	mediaSvc      profileMediaSvc
 }
 
 func (u *User) postUserHandler(c *gin.Context) {
@@ -69,24 +84,28 @@ func (u *User) putUserImageProfileHandler(c *gin.Context) {
 	selectedQ := c.Query("selected")
 	selected := strings.EqualFold(selectedQ, "true")
 	userId := c.GetString(keyUserId)
-	var validImageTypes = []string{
-		"image/avif",
-		"image/jpeg",
-		"image/png",
-		"image/webp",
-		"image/svg+xml",
-	}
-	key, _ := uploadFile(c, u.attachmentSvc, validImageTypes, "profile-picture")
-	if key == "" {
+	// svg, webp and avif is rejected since only the decodable raster could be re-encoded
+	file, fileHeader, mediaType := openFormFile(c, attachmentEntity.ImageTypes)
+	if file == nil {
 		return
 	}
-	newProfPic := userEntity.ProfilePic{
-		UserId:      userId,
-		Selected:    selected,
-		PictureLink: key,
+	defer func(file multipart.File) {
+		err := file.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(file)
+
+	newProfPic, err := u.uploadRenditions(file, fileHeader.Size, mediaType)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
 	}
+	newProfPic.UserId = userId
+	newProfPic.Selected = selected
 	id, err := u.userService.CreateNewProfilePic(newProfPic)
 	if err != nil {
+		u.deleteRenditions(newProfPic)
 		jsonHandleError(c, err)
 		return
 	}
@@ -100,6 +119,140 @@ func (u *User) putUserImageProfileHandler(c *gin.Context) {
 		},
 	})
 }
+
+// uploadRenditions re-encode the picture and upload each rendition, nothing is left uploaded on failure
+func (u *User) uploadRenditions(file io.Reader, size int64, mediaType string) (userEntity.ProfilePic, error) {
+	renditions, err := u.mediaSvc.ProcessProfilePicture(file, size, mediaType)
+	if err != nil {
+		return userEntity.ProfilePic{}, err
+	}
+	var profPic userEntity.ProfilePic
+	uploads := []struct {
+		content []byte
+		key     *string
+	}{
+		{renditions.Full, &profPic.PictureLink},
+		{renditions.Medium, &profPic.MediumLink},
+		{renditions.Thumbnail, &profPic.ThumbnailLink},
+	}
+	for _, upload := range uploads {
+		key, err := u.attachmentSvc.UploadBlob(bytes.NewReader(upload.content), attachmentEntity.Uploader{
+			Length:      int64(len(upload.content)),
+			ContentType: "image/jpeg",
+			Prefix:      "profile-picture",
+			Ext:         ".jpg",
+		})
+		if err != nil {
+			u.deleteRenditions(profPic)
+			return userEntity.ProfilePic{}, err
+		}
+		*upload.key = key
+	}
+	return profPic, nil
+}
+
+// deleteRenditions delete every rendition blob, the picture uploaded before the renditions share one key
+func (u *User) deleteRenditions(profPic userEntity.ProfilePic) {
+	deleted := make(map[string]bool, 3)
+	for _, key := range []string{profPic.PictureLink, profPic.MediumLink, profPic.ThumbnailLink} {
+		if key == "" || deleted[key] {
+			continue
+		}
+		deleted[key] = true
+		if err := u.attachmentSvc.DeleteBlob(key); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func (u *User) getProfilePicturesHandler(c *gin.Context) {
+	profPics, err := u.userService.GetProfilePics(c.GetString(keyUserId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"profilePictures": profPics,
+		},
+	})
+}
+
+func (u *User) putProfilePictureOrderHandler(c *gin.Context) {
+	var input userEntity.ReorderProfilePic
+	if err := c.ShouldBindJSON(&input); err != nil {
+		errjson := jsonBindingErrResp(err, c, map[string]string{
+			"ids": "must be required and have 1-5 profile picture id",
+		})
+		if errjson != nil {
+			errServerResp(c, err)
+			return
+		}
+		return
+	}
+	profPics, err := u.userService.ReorderProfilePics(c.GetString(keyUserId), input.Ids)
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "profile-picture reordered",
+		"data": gin.H{
+			"profilePictures": profPics,
+		},
+	})
+}
+
+func (u *User) putSelectedProfilePictureHandler(c *gin.Context) {
+	err := u.userService.SelectProfilePic(c.GetString(keyUserId), c.GetString(keyPictureId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "profile-picture selected",
+	})
+}
+
+func (u *User) deleteProfilePictureHandler(c *gin.Context) {
+	deleted, err := u.userService.DeleteProfilePic(c.GetString(keyUserId), c.GetString(keyPictureId))
+	if err != nil {
+		jsonHandleError(c, err)
+		return
+	}
+	u.deleteRenditions(deleted)
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "profile-picture deleted",
+	})
+}
+
+// getAvatarHandler serve the generated avatar, the client use it when the user has no visible picture
+func (u *User) getAvatarHandler(c *gin.Context) {
+	var input struct {
+		UserId string `uri:"userId" binding:"required,uuid"`
+		Size   string `form:"size" binding:"omitempty,oneof=full medium thumbnail"`
+	}
+	if err := c.ShouldBindUri(&input); err != nil {
+		errBadRequestResp(c, "must have uuid in uri!")
+		return
+	}
+	if err := c.ShouldBindQuery(&input); err != nil {
+		errBadRequestResp(c, "size must be one of full, medium or thumbnail")
+		return
+	}
+	avatar, err := u.mediaSvc.PlaceholderAvatar(input.UserId, input.Size)
+	if err != nil {
+		errServerResp(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.Data(http.StatusOK, "image/jpeg", avatar)
+}
+
 func (u *User) getUserByIdHandler(c *gin.Context) {
 	userId := c.GetString(keyUserId)
 	user, err := u.userService.GetUserById(userId)
@@ -6,12 +6,14 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
 	"image/png"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
-	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -26,6 +28,7 @@ import (
 	"github.com/xyedo/blindate/pkg/applications/service"
 	mocksvc "github.com/xyedo/blindate/pkg/applications/service/mock"
 	"github.com/xyedo/blindate/pkg/common"
+	attachmentEntity "github.com/xyedo/blindate/pkg/domain/attachment"
 	userEntity "github.com/xyedo/blindate/pkg/domain/user/entities"
 	mockrepo "github.com/xyedo/blindate/pkg/infra/repository/mock"
 	"github.com/xyedo/blindate/pkg/util"
@@ -61,9 +64,9 @@ func Test_PostUserHandler(t *testing.T) {
 				userRepo := mockrepo.NewMockUser(ctrl)
 
 				userRepo.EXPECT().InsertUser(gomock.Not(nil)).Times(1).Return(validUUID, nil)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusCreated,
 			wantHeader: map[string]string{
@@ -88,9 +91,9 @@ func Test_PostUserHandler(t *testing.T) {
 			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *User {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				userRepo.EXPECT().InsertUser(gomock.Any()).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusBadRequest,
 			wantHeader: map[string]string{
@@ -112,9 +115,9 @@ func Test_PostUserHandler(t *testing.T) {
 			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *User {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				userRepo.EXPECT().InsertUser(gomock.Any()).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusBadRequest,
 			wantHeader: map[string]string{
@@ -136,9 +139,9 @@ func Test_PostUserHandler(t *testing.T) {
 			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *User {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				userRepo.EXPECT().InsertUser(gomock.Any()).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusBadRequest,
 			wantHeader: map[string]string{
@@ -160,9 +163,9 @@ func Test_PostUserHandler(t *testing.T) {
 			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *User {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				userRepo.EXPECT().InsertUser(gomock.Any()).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusUnprocessableEntity,
 			wantHeader: map[string]string{
@@ -189,9 +192,9 @@ func Test_PostUserHandler(t *testing.T) {
 			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *User {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				userRepo.EXPECT().InsertUser(gomock.Any()).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusUnprocessableEntity,
 			wantHeader: map[string]string{
@@ -212,9 +215,9 @@ func Test_PostUserHandler(t *testing.T) {
 				userRepo := mockrepo.NewMockUser(ctrl)
 
 				userRepo.EXPECT().InsertUser(gomock.Not(nil)).Times(1).Return(validUUID, nil)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusCreated,
 			wantHeader: map[string]string{
@@ -245,9 +248,9 @@ func Test_PostUserHandler(t *testing.T) {
 				}
 				userRepo.EXPECT().InsertUser(gomock.Not(nil)).Times(1).
 					Return("", common.WrapErrorWithMsg(&pqErr, common.ErrUniqueConstraint23505, "email already taken"))
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusUnprocessableEntity,
 			wantHeader: map[string]string{
@@ -260,9 +263,9 @@ func Test_PostUserHandler(t *testing.T) {
 			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *User {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				userRepo.EXPECT().InsertUser(gomock.Any()).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusUnprocessableEntity,
 			wantHeader: map[string]string{
@@ -282,9 +285,9 @@ func Test_PostUserHandler(t *testing.T) {
 			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *User {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				userRepo.EXPECT().InsertUser(gomock.Any()).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusUnprocessableEntity,
 			wantHeader: map[string]string{
@@ -303,9 +306,9 @@ func Test_PostUserHandler(t *testing.T) {
 			setupFunc: func(t *testing.T, ctrl *gomock.Controller) *User {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				userRepo.EXPECT().InsertUser(gomock.Any()).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusUnprocessableEntity,
 			wantHeader: map[string]string{
@@ -326,9 +329,9 @@ func Test_PostUserHandler(t *testing.T) {
 
 				userRepo.EXPECT().InsertUser(gomock.Not(nil)).Times(1).
 					Return("", common.WrapError(context.Canceled, common.ErrTooLongAccessingDB))
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusConflict,
 			wantHeader: map[string]string{
@@ -378,7 +381,7 @@ func Test_GetUserByIdHandler(t *testing.T) {
 				userRepo.EXPECT().GetUserById(gomock.Eq("8c540e20-75d1-4513-a8e3-72dc4bc68619")).Times(1).Return(users, nil)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
 
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				return userService, attachSvc, users
 			},
 			respFunc: func(t *testing.T, user userEntity.FullDTO, resp *httptest.ResponseRecorder) {
@@ -404,7 +407,7 @@ func Test_GetUserByIdHandler(t *testing.T) {
 				userRepo.EXPECT().GetUserById("d3aa0883-4a29-4a39-8f0e-2413c169bd9d").Times(1).
 					Return(userEntity.FullDTO{}, common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound))
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				return userService, attachSvc, users
 			},
 			respFunc: func(t *testing.T, user userEntity.FullDTO, resp *httptest.ResponseRecorder) {
@@ -426,7 +429,7 @@ func Test_GetUserByIdHandler(t *testing.T) {
 			defer ctrl.Finish()
 			userSvc, attachSvc, user := tt.setupFunc(t, ctrl)
 
-			userH := NewUser(userSvc, attachSvc)
+			userH := NewUser(userSvc, attachSvc, testProfileMedia)
 			rr := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(rr)
 			c.Set("userId", tt.id)
@@ -458,9 +461,9 @@ func Test_PatchUserById(t *testing.T) {
 				userRepo.EXPECT().GetUserById(gomock.Eq("8c540e20-75d1-4513-a8e3-72dc4bc68619")).Times(1).Return(user, nil)
 				user.FullName = "Bob Martin"
 				userRepo.EXPECT().UpdateUser(gomock.Eq(user)).Times(1).Return(nil)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusOK,
 			wantResp: map[string]any{
@@ -478,9 +481,9 @@ func Test_PatchUserById(t *testing.T) {
 				userRepo.EXPECT().GetUserById(gomock.Eq("8c540e20-75d1-4513-a8e3-72dc4bc68619")).Times(1).Return(user, nil)
 				user.Email = "bob@martin.com"
 				userRepo.EXPECT().UpdateUser(gomock.Eq(user)).Times(1).Return(nil)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusOK,
 			wantResp: map[string]any{
@@ -502,9 +505,9 @@ func Test_PatchUserById(t *testing.T) {
 				userRepo.EXPECT().GetUserById(gomock.Eq("8c540e20-75d1-4513-a8e3-72dc4bc68619")).Times(1).Return(user, nil)
 				user.Password = "newPa55word"
 				userRepo.EXPECT().UpdateUser(gomock.Not(nil)).Times(1).Return(nil)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantResp: map[string]any{
 				"status":  "success",
@@ -521,9 +524,9 @@ func Test_PatchUserById(t *testing.T) {
 				user := createNewUser(t)
 				userRepo.EXPECT().GetUserById(gomock.Eq("8c540e20-75d1-4513-a8e3-72dc4bc68619")).Times(1).Return(user, nil)
 				userRepo.EXPECT().UpdateUser(gomock.Not(nil)).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantResp: map[string]any{
 				"status":  "fail",
@@ -538,9 +541,9 @@ func Test_PatchUserById(t *testing.T) {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				userRepo.EXPECT().GetUserById(gomock.Eq("8c540e20-75d1-4513-a8e3-72dc4bc68619")).Times(0)
 				userRepo.EXPECT().UpdateUser(gomock.Any()).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusUnprocessableEntity,
 			wantResp: map[string]any{
@@ -561,9 +564,9 @@ func Test_PatchUserById(t *testing.T) {
 				userRepo.EXPECT().GetUserById(gomock.Eq("8c540e20-75d1-4513-a8e3-72dc4bc68619")).Times(0)
 				userRepo.EXPECT().GetUserByEmail(gomock.Any()).Times(0)
 				userRepo.EXPECT().UpdateUser(gomock.Any()).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantResp: map[string]any{
 				"status":  "fail",
@@ -582,9 +585,9 @@ func Test_PatchUserById(t *testing.T) {
 				userRepo.EXPECT().GetUserById(gomock.Eq("d3aa0883-4a29-4a39-8f0e-2413c169bd9d")).Times(1).
 					Return(userEntity.FullDTO{}, common.WrapError(sql.ErrNoRows, common.ErrResourceNotFound))
 				userRepo.EXPECT().UpdateUser(gomock.Any()).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusNotFound,
 			wantResp: map[string]any{
@@ -600,9 +603,9 @@ func Test_PatchUserById(t *testing.T) {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				userRepo.EXPECT().GetUserById(gomock.Eq("d3aa0883-4a29-4a39-8f0e-2413c169bd9d")).Times(0)
 				userRepo.EXPECT().UpdateUser(gomock.Any()).Times(0)
-				userService := service.NewUser(userRepo)
+				userService := service.NewUser(userRepo, testBlobUrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				return NewUser(userService, attachSvc)
+				return NewUser(userService, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusBadRequest,
 			wantResp: map[string]any{
@@ -649,6 +652,22 @@ func (u *uploadTestMatcher) String() string {
 func uploadValid() *uploadTestMatcher {
 	return &uploadTestMatcher{}
 }
+
+// expectRenditionUpload expect the full, medium and thumbnail rendition to be uploaded in order as jpeg
+func expectRenditionUpload(attachSvc *mocksvc.MockAttachment) userEntity.ProfilePic {
+	renditions := userEntity.ProfilePic{
+		PictureLink:   "profile-picture/" + util.RandomUUID() + ".jpg",
+		MediumLink:    "profile-picture/" + util.RandomUUID() + ".jpg",
+		ThumbnailLink: "profile-picture/" + util.RandomUUID() + ".jpg",
+	}
+	jpegUpload := gomock.AssignableToTypeOf(attachmentEntity.Uploader{})
+	gomock.InOrder(
+		attachSvc.EXPECT().UploadBlob(uploadValid(), jpegUpload).Return(renditions.PictureLink, nil),
+		attachSvc.EXPECT().UploadBlob(uploadValid(), jpegUpload).Return(renditions.MediumLink, nil),
+		attachSvc.EXPECT().UploadBlob(uploadValid(), jpegUpload).Return(renditions.ThumbnailLink, nil),
+	)
+	return renditions
+}
 func Test_PutUserImageProfile(t *testing.T) {
 	validUserId := util.RandomUUID()
 	validProfPicId := util.RandomUUID()
@@ -679,10 +698,9 @@ func Test_PutUserImageProfile(t *testing.T) {
 			stubFunc: func(t *testing.T, ctrl *gomock.Controller, pr *io.PipeReader) *User {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				validKey := "profile-picture/" + util.RandomUUID() + ".png"
 				user := createNewUser(t)
 				user.ID = validUserId
-				attachSvc.EXPECT().UploadBlob(uploadValid(), gomock.Any()).Return(validKey, nil).Times(1)
+				renditions := expectRenditionUpload(attachSvc)
 
 				profPic := make([]userEntity.ProfilePic, 0, 4)
 				for i := 0; i < 3; i++ {
@@ -690,18 +708,20 @@ func Test_PutUserImageProfile(t *testing.T) {
 				}
 
 				userRepo.EXPECT().SelectProfilePicture(gomock.Eq(user.ID), gomock.Nil()).Return(profPic, nil).Times(1)
-				userRepo.EXPECT().ProfilePicSelectedToFalse(gomock.Eq(user.ID)).Return(int64(3), nil).Times(1)
+				userRepo.EXPECT().ProfilePicSelectedToFalse(gomock.Any()).Times(0)
 				userRepo.
 					EXPECT().
-					CreateProfilePicture(
-						gomock.Eq(user.ID),
-						gomock.Eq(filepath.Base(validKey)),
-						gomock.Eq(true),
-					).
+					CreateProfilePicture(gomock.Eq(userEntity.ProfilePic{
+						UserId:        user.ID,
+						Selected:      true,
+						PictureLink:   renditions.PictureLink,
+						MediumLink:    renditions.MediumLink,
+						ThumbnailLink: renditions.ThumbnailLink,
+					})).
 					Return(validProfPicId, nil).
 					Times(1)
-				userSvc := service.NewUser(userRepo)
-				return NewUser(userSvc, attachSvc)
+				userSvc := service.NewUser(userRepo, testBlobUrl)
+				return NewUser(userSvc, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusOK,
 			wantResp: map[string]any{
@@ -721,10 +741,9 @@ func Test_PutUserImageProfile(t *testing.T) {
 			stubFunc: func(t *testing.T, ctrl *gomock.Controller, pr *io.PipeReader) *User {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				validKey := "profile-picture/" + util.RandomUUID() + ".png"
 				user := createNewUser(t)
 				user.ID = validUserId
-				attachSvc.EXPECT().UploadBlob(uploadValid(), gomock.Any()).Return(validKey, nil).Times(1)
+				renditions := expectRenditionUpload(attachSvc)
 
 				profPic := make([]userEntity.ProfilePic, 0, 4)
 				for i := 0; i < 3; i++ {
@@ -735,15 +754,17 @@ func Test_PutUserImageProfile(t *testing.T) {
 				userRepo.EXPECT().ProfilePicSelectedToFalse(gomock.Any()).Times(0)
 				userRepo.
 					EXPECT().
-					CreateProfilePicture(
-						gomock.Eq(user.ID),
-						gomock.Eq(filepath.Base(validKey)),
-						gomock.Eq(false),
-					).
+					CreateProfilePicture(gomock.Eq(userEntity.ProfilePic{
+						UserId:        user.ID,
+						Selected:      false,
+						PictureLink:   renditions.PictureLink,
+						MediumLink:    renditions.MediumLink,
+						ThumbnailLink: renditions.ThumbnailLink,
+					})).
 					Return(validProfPicId, nil).
 					Times(1)
-				userSvc := service.NewUser(userRepo)
-				return NewUser(userSvc, attachSvc)
+				userSvc := service.NewUser(userRepo, testBlobUrl)
+				return NewUser(userSvc, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusOK,
 			wantResp: map[string]any{
@@ -774,7 +795,6 @@ func Test_PutUserImageProfile(t *testing.T) {
 			stubFunc: func(t *testing.T, ctrl *gomock.Controller, pr *io.PipeReader) *User {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				validKey := "profile-picture/" + util.RandomUUID() + ".png"
 				user := createNewUser(t)
 				user.ID = validUserId
 				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
@@ -783,14 +803,10 @@ func Test_PutUserImageProfile(t *testing.T) {
 				userRepo.EXPECT().ProfilePicSelectedToFalse(gomock.Any()).Times(0)
 				userRepo.
 					EXPECT().
-					CreateProfilePicture(
-						gomock.Eq(user.ID),
-						gomock.Eq(filepath.Base(validKey)),
-						gomock.Eq(false),
-					).
+					CreateProfilePicture(gomock.Any()).
 					Times(0)
-				userSvc := service.NewUser(userRepo)
-				return NewUser(userSvc, attachSvc)
+				userSvc := service.NewUser(userRepo, testBlobUrl)
+				return NewUser(userSvc, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusUnprocessableEntity,
 			wantResp: map[string]any{
@@ -798,6 +814,52 @@ func Test_PutUserImageProfile(t *testing.T) {
 				"message": "not valid mime-type",
 			},
 		},
+		{
+			name: "svg is rejected",
+			id:   validUserId,
+			writoMime: func(writer *multipart.Writer) {
+				defer writer.Close()
+				part, err := writer.CreateFormFile("file", "avatar.svg")
+				require.NoError(t, err)
+				_, err = io.WriteString(part, `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`)
+				require.NoError(t, err)
+			},
+			stubFunc: func(t *testing.T, ctrl *gomock.Controller, pr *io.PipeReader) *User {
+				userRepo := mockrepo.NewMockUser(ctrl)
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
+				userRepo.EXPECT().CreateProfilePicture(gomock.Any()).Times(0)
+				return NewUser(service.NewUser(userRepo, testBlobUrl), attachSvc, testProfileMedia)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "not valid mime-type",
+			},
+		},
+		{
+			name: "dimension too large",
+			id:   validUserId,
+			writoMime: func(writer *multipart.Writer) {
+				defer writer.Close()
+				part, err := writer.CreateFormFile("file", "huge.png")
+				require.NoError(t, err)
+				_, err = part.Write(encodeTestPNG(t, 1024, 8))
+				require.NoError(t, err)
+			},
+			stubFunc: func(t *testing.T, ctrl *gomock.Controller, pr *io.PipeReader) *User {
+				userRepo := mockrepo.NewMockUser(ctrl)
+				attachSvc := mocksvc.NewMockAttachment(ctrl)
+				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
+				userRepo.EXPECT().CreateProfilePicture(gomock.Any()).Times(0)
+				return NewUser(service.NewUser(userRepo, testBlobUrl), attachSvc, testProfileMedia)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "image dimension must not exceed 512px",
+			},
+		},
 		{
 			name: "not having a file",
 			id:   validUserId,
@@ -816,7 +878,6 @@ func Test_PutUserImageProfile(t *testing.T) {
 			stubFunc: func(t *testing.T, ctrl *gomock.Controller, pr *io.PipeReader) *User {
 				userRepo := mockrepo.NewMockUser(ctrl)
 				attachSvc := mocksvc.NewMockAttachment(ctrl)
-				validKey := "profile-picture/" + util.RandomUUID() + ".png"
 				user := createNewUser(t)
 				user.ID = validUserId
 				attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
@@ -825,14 +886,10 @@ func Test_PutUserImageProfile(t *testing.T) {
 				userRepo.EXPECT().ProfilePicSelectedToFalse(gomock.Any()).Times(0)
 				userRepo.
 					EXPECT().
-					CreateProfilePicture(
-						gomock.Eq(user.ID),
-						gomock.Eq(filepath.Base(validKey)),
-						gomock.Eq(false),
-					).
+					CreateProfilePicture(gomock.Any()).
 					Times(0)
-				userSvc := service.NewUser(userRepo)
-				return NewUser(userSvc, attachSvc)
+				userSvc := service.NewUser(userRepo, testBlobUrl)
+				return NewUser(userSvc, attachSvc, testProfileMedia)
 			},
 			wantCode: http.StatusBadRequest,
 			wantResp: map[string]any{
@@ -881,7 +938,6 @@ func Test_PutUserImageProfile(t *testing.T) {
 		defer ctrl.Finish()
 		userRepo := mockrepo.NewMockUser(ctrl)
 		attachSvc := mocksvc.NewMockAttachment(ctrl)
-		validKey := "profile-picture/" + util.RandomUUID() + ".png"
 		user := createNewUser(t)
 		user.ID = validUserId
 		attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Times(0)
@@ -890,14 +946,10 @@ func Test_PutUserImageProfile(t *testing.T) {
 		userRepo.EXPECT().ProfilePicSelectedToFalse(gomock.Any()).Times(0)
 		userRepo.
 			EXPECT().
-			CreateProfilePicture(
-				gomock.Eq(user.ID),
-				gomock.Eq(filepath.Base(validKey)),
-				gomock.Eq(false),
-			).
+			CreateProfilePicture(gomock.Any()).
 			Times(0)
-		userSvc := service.NewUser(userRepo)
-		userApi := NewUser(userSvc, attachSvc)
+		userSvc := service.NewUser(userRepo, testBlobUrl)
+		userApi := NewUser(userSvc, attachSvc, testProfileMedia)
 		rr := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(rr)
 		c.Set("userId", validUserId)
@@ -912,6 +964,382 @@ func Test_PutUserImageProfile(t *testing.T) {
 	})
 }
 
+func Test_GetProfilePictures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userId := util.RandomUUID()
+	profPics := []userEntity.ProfilePic{
+		{Id: "1", UserId: userId, PictureLink: "profile-picture/a.png", Selected: true, Position: 0},
+		{Id: "2", UserId: userId, PictureLink: "profile-picture/b.png", Position: 1},
+	}
+	userRepo := mockrepo.NewMockUser(ctrl)
+	userRepo.EXPECT().SelectProfilePicture(gomock.Eq(userId), gomock.Nil()).Return(profPics, nil).Times(1)
+	userH := NewUser(service.NewUser(userRepo, testBlobUrl), mocksvc.NewMockAttachment(ctrl), testProfileMedia)
+
+	rr := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rr)
+	c.Set(keyUserId, userId)
+	c.Request = httptest.NewRequest(http.MethodGet, "/users/"+userId+"/profile-pictures", nil)
+	userH.getProfilePicturesHandler(c)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp struct {
+		Data struct {
+			ProfilePictures []userEntity.ProfilePic `json:"profilePictures"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Data.ProfilePictures, 2)
+	assert.True(t, strings.HasPrefix(resp.Data.ProfilePictures[0].PictureLink, testBlobHost+"profile-picture/a.png"))
+	assert.Equal(t, 1, resp.Data.ProfilePictures[1].Position)
+}
+
+func Test_PutProfilePictureOrder(t *testing.T) {
+	userId := util.RandomUUID()
+	owned := []userEntity.ProfilePic{
+		{Id: "1", UserId: userId, PictureLink: "profile-picture/a.png", Selected: true, Position: 0},
+		{Id: "2", UserId: userId, PictureLink: "profile-picture/b.png", Position: 1},
+	}
+	tests := []struct {
+		name     string
+		body     string
+		stubFunc func(userRepo *mockrepo.MockUser)
+		wantCode int
+		wantResp map[string]any
+	}{
+		{
+			name: "valid",
+			body: `{"ids":["2","1"]}`,
+			stubFunc: func(userRepo *mockrepo.MockUser) {
+				reordered := []userEntity.ProfilePic{
+					{Id: "2", UserId: userId, PictureLink: "profile-picture/b.png", Position: 0},
+					{Id: "1", UserId: userId, PictureLink: "profile-picture/a.png", Selected: true, Position: 1},
+				}
+				gomock.InOrder(
+					userRepo.EXPECT().SelectProfilePicture(gomock.Eq(userId), gomock.Nil()).Return(owned, nil),
+					userRepo.EXPECT().UpdateProfilePicturePosition(gomock.Eq(userId), gomock.Eq([]string{"2", "1"})).Return(nil),
+					userRepo.EXPECT().SelectProfilePicture(gomock.Eq(userId), gomock.Nil()).Return(reordered, nil),
+				)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "missing picture",
+			body: `{"ids":["2"]}`,
+			stubFunc: func(userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().SelectProfilePicture(gomock.Eq(userId), gomock.Nil()).Return(owned, nil).Times(1)
+				userRepo.EXPECT().UpdateProfilePicturePosition(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "ids must contain every profile picture exactly once",
+			},
+		},
+		{
+			name: "duplicate picture",
+			body: `{"ids":["1","1"]}`,
+			stubFunc: func(userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().SelectProfilePicture(gomock.Eq(userId), gomock.Nil()).Return(owned, nil).Times(1)
+				userRepo.EXPECT().UpdateProfilePicturePosition(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "ids must contain every profile picture exactly once",
+			},
+		},
+		{
+			name: "not owned picture",
+			body: `{"ids":["2","1","3"]}`,
+			stubFunc: func(userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().SelectProfilePicture(gomock.Eq(userId), gomock.Nil()).Return(owned, nil).Times(1)
+				userRepo.EXPECT().UpdateProfilePicturePosition(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "empty ids",
+			body: `{"ids":[]}`,
+			stubFunc: func(userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().SelectProfilePicture(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+			wantResp: map[string]any{
+				"status":  "fail",
+				"message": "please refer to the documentation",
+				"errors": map[string]string{
+					"ids": "must be required and have 1-5 profile picture id",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo := mockrepo.NewMockUser(ctrl)
+			tt.stubFunc(userRepo)
+			userH := NewUser(service.NewUser(userRepo, testBlobUrl), mocksvc.NewMockAttachment(ctrl), testProfileMedia)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, userId)
+			c.Request = httptest.NewRequest(http.MethodPut, "/users/"+userId+"/profile-pictures/order", strings.NewReader(tt.body))
+			userH.putProfilePictureOrderHandler(c)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.wantResp != nil {
+				expResBody, err := json.Marshal(tt.wantResp)
+				require.NoError(t, err)
+				assert.JSONEq(t, string(expResBody), rr.Body.String())
+			}
+		})
+	}
+}
+
+func Test_PutSelectedProfilePicture(t *testing.T) {
+	userId := util.RandomUUID()
+	tests := []struct {
+		name     string
+		stubFunc func(userRepo *mockrepo.MockUser)
+		wantCode int
+	}{
+		{
+			name: "valid",
+			stubFunc: func(userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().UpdateSelectedProfilePicture(gomock.Eq(userId), gomock.Eq("7")).Return(nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "not found",
+			stubFunc: func(userRepo *mockrepo.MockUser) {
+				userRepo.EXPECT().UpdateSelectedProfilePicture(gomock.Eq(userId), gomock.Eq("7")).
+					Return(common.WrapWithNewError(sql.ErrNoRows, http.StatusNotFound, "profile picture not found")).Times(1)
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo := mockrepo.NewMockUser(ctrl)
+			tt.stubFunc(userRepo)
+			userH := NewUser(service.NewUser(userRepo, testBlobUrl), mocksvc.NewMockAttachment(ctrl), testProfileMedia)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, userId)
+			c.Set(keyPictureId, "7")
+			c.Request = httptest.NewRequest(http.MethodPut, "/users/"+userId+"/profile-pictures/7/selected", nil)
+			userH.putSelectedProfilePictureHandler(c)
+			assert.Equal(t, tt.wantCode, rr.Code)
+		})
+	}
+}
+
+func Test_DeleteProfilePicture(t *testing.T) {
+	userId := util.RandomUUID()
+	tests := []struct {
+		name     string
+		stubFunc func(userRepo *mockrepo.MockUser, attachSvc *mocksvc.MockAttachment)
+		wantCode int
+	}{
+		{
+			name: "valid",
+			stubFunc: func(userRepo *mockrepo.MockUser, attachSvc *mocksvc.MockAttachment) {
+				deleted := userEntity.ProfilePic{Id: "7", UserId: userId, PictureLink: "profile-picture/a.png"}
+				userRepo.EXPECT().DeleteProfilePicture(gomock.Eq(userId), gomock.Eq("7")).Return(deleted, nil).Times(1)
+				attachSvc.EXPECT().DeleteBlob(gomock.Eq("profile-picture/a.png")).Return(nil).Times(1)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "blob cleanup failed",
+			stubFunc: func(userRepo *mockrepo.MockUser, attachSvc *mocksvc.MockAttachment) {
+				deleted := userEntity.ProfilePic{Id: "7", UserId: userId, PictureLink: "profile-picture/a.png"}
+				userRepo.EXPECT().DeleteProfilePicture(gomock.Eq(userId), gomock.Eq("7")).Return(deleted, nil).Times(1)
+				attachSvc.EXPECT().DeleteBlob(gomock.Eq("profile-picture/a.png")).Return(io.ErrUnexpectedEOF).Times(1)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "not found",
+			stubFunc: func(userRepo *mockrepo.MockUser, attachSvc *mocksvc.MockAttachment) {
+				userRepo.EXPECT().DeleteProfilePicture(gomock.Eq(userId), gomock.Eq("7")).
+					Return(userEntity.ProfilePic{}, common.WrapWithNewError(sql.ErrNoRows, http.StatusNotFound, "profile picture not found")).Times(1)
+				attachSvc.EXPECT().DeleteBlob(gomock.Any()).Times(0)
+			},
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo := mockrepo.NewMockUser(ctrl)
+			attachSvc := mocksvc.NewMockAttachment(ctrl)
+			tt.stubFunc(userRepo, attachSvc)
+			userH := NewUser(service.NewUser(userRepo, testBlobUrl), attachSvc, testProfileMedia)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			c.Set(keyUserId, userId)
+			c.Set(keyPictureId, "7")
+			c.Request = httptest.NewRequest(http.MethodDelete, "/users/"+userId+"/profile-pictures/7", nil)
+			userH.deleteProfilePictureHandler(c)
+			assert.Equal(t, tt.wantCode, rr.Code)
+		})
+	}
+}
+
+func Test_PutUserImageProfileRenditions(t *testing.T) {
+	upload := func(t *testing.T, userH *User, userId, filename string, content []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("file", filename)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Set(keyUserId, userId)
+		c.Request = httptest.NewRequest(http.MethodPut, "/users/"+userId+"/profile-picture", &body)
+		c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+		userH.putUserImageProfileHandler(c)
+		return rr
+	}
+
+	t.Run("oriented, stripped and capped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		userId := util.RandomUUID()
+		userRepo := mockrepo.NewMockUser(ctrl)
+		attachSvc := mocksvc.NewMockAttachment(ctrl)
+		uploaded := make([][]byte, 0, 3)
+		attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(file io.Reader, attach attachmentEntity.Uploader) (string, error) {
+				content, err := io.ReadAll(file)
+				require.NoError(t, err)
+				assert.Equal(t, "image/jpeg", attach.ContentType)
+				assert.Equal(t, "profile-picture", attach.Prefix)
+				assert.Equal(t, ".jpg", attach.Ext)
+				assert.Equal(t, int64(len(content)), attach.Length)
+				uploaded = append(uploaded, content)
+				return "profile-picture/" + util.RandomUUID() + ".jpg", nil
+			}).Times(3)
+		userRepo.EXPECT().SelectProfilePicture(gomock.Eq(userId), gomock.Nil()).Return(nil, nil).Times(1)
+		userRepo.EXPECT().CreateProfilePicture(gomock.Any()).Return("1", nil).Times(1)
+		userH := NewUser(service.NewUser(userRepo, testBlobUrl), attachSvc, testProfileMedia)
+
+		// orientation 6 rotate the 400x200 picture to 200x400
+		rr := upload(t, userH, userId, "photo.jpg", encodeTestJPEGWithExif(t, 400, 200, 6))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Len(t, uploaded, 3)
+		for i, wantHeight := range []int{128, 64, 16} {
+			config, format, err := image.DecodeConfig(bytes.NewReader(uploaded[i]))
+			require.NoError(t, err)
+			assert.Equal(t, "jpeg", format)
+			assert.Equal(t, wantHeight/2, config.Width)
+			assert.Equal(t, wantHeight, config.Height)
+			assert.NotContains(t, string(uploaded[i]), "Exif")
+		}
+	})
+	t.Run("gif is rasterized", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		userId := util.RandomUUID()
+		userRepo := mockrepo.NewMockUser(ctrl)
+		attachSvc := mocksvc.NewMockAttachment(ctrl)
+		renditions := expectRenditionUpload(attachSvc)
+		userRepo.EXPECT().SelectProfilePicture(gomock.Eq(userId), gomock.Nil()).Return(nil, nil).Times(1)
+		userRepo.EXPECT().CreateProfilePicture(gomock.Eq(userEntity.ProfilePic{
+			UserId:        userId,
+			PictureLink:   renditions.PictureLink,
+			MediumLink:    renditions.MediumLink,
+			ThumbnailLink: renditions.ThumbnailLink,
+		})).Return("1", nil).Times(1)
+		userH := NewUser(service.NewUser(userRepo, testBlobUrl), attachSvc, testProfileMedia)
+
+		var buf bytes.Buffer
+		require.NoError(t, gif.Encode(&buf, util.CreateDefaultImage(32, 32), nil))
+		rr := upload(t, userH, userId, "wave.gif", buf.Bytes())
+		assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	})
+	t.Run("every rendition is deleted when the picture is not saved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		userId := util.RandomUUID()
+		userRepo := mockrepo.NewMockUser(ctrl)
+		attachSvc := mocksvc.NewMockAttachment(ctrl)
+		renditions := expectRenditionUpload(attachSvc)
+		profPics := make([]userEntity.ProfilePic, 0, 5)
+		for i := 0; i < 5; i++ {
+			profPics = append(profPics, createRandomProfPic(userId))
+		}
+		userRepo.EXPECT().SelectProfilePicture(gomock.Eq(userId), gomock.Nil()).Return(profPics, nil).Times(1)
+		userRepo.EXPECT().CreateProfilePicture(gomock.Any()).Times(0)
+		for _, key := range []string{renditions.PictureLink, renditions.MediumLink, renditions.ThumbnailLink} {
+			attachSvc.EXPECT().DeleteBlob(gomock.Eq(key)).Return(nil).Times(1)
+		}
+		userH := NewUser(service.NewUser(userRepo, testBlobUrl), attachSvc, testProfileMedia)
+
+		rr := upload(t, userH, userId, "photo.png", encodeTestPNG(t, 32, 32))
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+	t.Run("uploaded rendition is deleted when the next upload failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		userRepo := mockrepo.NewMockUser(ctrl)
+		attachSvc := mocksvc.NewMockAttachment(ctrl)
+		fullKey := "profile-picture/" + util.RandomUUID() + ".jpg"
+		gomock.InOrder(
+			attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Return(fullKey, nil),
+			attachSvc.EXPECT().UploadBlob(gomock.Any(), gomock.Any()).Return("", io.ErrUnexpectedEOF),
+		)
+		attachSvc.EXPECT().DeleteBlob(gomock.Eq(fullKey)).Return(nil).Times(1)
+		userRepo.EXPECT().CreateProfilePicture(gomock.Any()).Times(0)
+		userH := NewUser(service.NewUser(userRepo, testBlobUrl), attachSvc, testProfileMedia)
+
+		rr := upload(t, userH, util.RandomUUID(), "photo.png", encodeTestPNG(t, 32, 32))
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func Test_GetAvatarHandler(t *testing.T) {
+	userH := NewUser(nil, nil, testProfileMedia)
+	get := func(userId, size string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rr)
+		c.Params = gin.Params{{Key: "userId", Value: userId}}
+		c.Request = httptest.NewRequest(http.MethodGet, "/avatars/"+userId+"?size="+size, nil)
+		userH.getAvatarHandler(c)
+		return rr
+	}
+	userId := util.RandomUUID()
+
+	rr := get(userId, "thumbnail")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "image/jpeg", rr.Header().Get("Content-Type"))
+	assert.NotEmpty(t, rr.Header().Get("Cache-Control"))
+	config, err := jpeg.DecodeConfig(bytes.NewReader(rr.Body.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 16, config.Width)
+	assert.Equal(t, rr.Body.Bytes(), get(userId, "thumbnail").Body.Bytes(), "the same user get the same avatar")
+	assert.NotEqual(t, rr.Body.Bytes(), get(util.RandomUUID(), "thumbnail").Body.Bytes())
+
+	config, err = jpeg.DecodeConfig(bytes.NewReader(get(userId, "").Body.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 64, config.Width, "medium is the default")
+
+	assert.Equal(t, http.StatusBadRequest, get(userId, "huge").Code)
+	assert.Equal(t, http.StatusBadRequest, get("not-uuid", "").Code)
+}
+
 func createNewUser(t *testing.T) userEntity.FullDTO {
 	pass := util.RandomString(10)
 	hashed, err := bcrypt.GenerateFromPassword([]byte(pass), 12)
@@ -3,56 +3,47 @@ package api
 import (
 	"log"
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/xyedo/blindate/pkg/applications/service"
 	websocketEntity "github.com/xyedo/blindate/pkg/domain/ws"
+	"github.com/xyedo/blindate/pkg/util"
 )
 
-func NewWs(wsSvc *service.Ws, onlineSvc onlineSvc) *Ws {
+func NewWs(wsSvc *service.Ws, origins *Origins) *Ws {
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		CheckOrigin: func(r *http.Request) bool {
-			allowedOrigins := []string{"https://blindate.com"}
-			if os.Getenv("env") == "development" {
-				allowedOrigins = append(allowedOrigins, "http://localhost:3000")
-			}
 			reqOrigin := r.Header.Get("Origin")
-			for _, allallowedOrigin := range allowedOrigins {
-				if allallowedOrigin == reqOrigin {
-					return true
-				}
+			// non-browser clients does not send origin
+			if reqOrigin == "" {
+				return true
 			}
-			return false
+			return origins.Allowed(reqOrigin)
 		},
 	}
 	return &Ws{
-		wsSvc:     wsSvc,
-		upgrader:  &upgrader,
-		onlineSvc: onlineSvc,
+		wsSvc:    wsSvc,
+		upgrader: &upgrader,
 	}
 }
 
 type Ws struct {
-	wsSvc     *service.Ws
-	upgrader  *websocket.Upgrader
-	onlineSvc onlineSvc
+	wsSvc    *service.Ws
+	upgrader *websocket.Upgrader
 }
 
 func (ws *Ws) wsEndPoint(c *gin.Context) {
-	userId := c.GetString("userId")
+	userId := c.GetString(keyUserId)
 	wsConn, err := ws.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
-	ws.onlineSvc.PutOnline(userId, true)
-	conn := websocketEntity.Conn{Conn: wsConn}
-	ws.wsSvc.Clients.Set(conn, userId)
-	ws.wsSvc.ReverseClient.Set(userId, conn)
+	conn := websocketEntity.Conn{Conn: wsConn, Id: util.RandomUUID()}
+	ws.wsSvc.Register(userId, conn)
 
 	go ws.wsSvc.ListenForWsPayload(&conn)
 	go ws.wsSvc.PingTicker(&conn)
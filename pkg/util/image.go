@@ -1,8 +1,10 @@
 package util
 
 import (
+	"hash/fnv"
 	"image"
 	"image/color"
+	"image/draw"
 )
 
 func CreateDefaultImage(width, height int) *image.RGBA {
@@ -29,3 +31,43 @@ func CreateDefaultImage(width, height int) *image.RGBA {
 	}
 	return img
 }
+
+// identiconGrid is the cell count per side of the placeholder avatar
+const identiconGrid = 5
+
+// CreatePlaceholderAvatar generate the avatar of the user without photo, the same seed always give the same avatar.
+// the cell is mirrored on the vertical axis like the usual identicon
+func CreatePlaceholderAvatar(seed string, size int) *image.RGBA {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	sum := h.Sum64()
+
+	fg := color.RGBA{
+		R: 80 + uint8(sum>>56)%128,
+		G: 80 + uint8(sum>>48)%128,
+		B: 80 + uint8(sum>>40)%128,
+		A: 0xff,
+	}
+	bg := color.RGBA{240, 240, 240, 0xff}
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{bg}, image.Point{}, draw.Src)
+
+	cell := size / (identiconGrid + 1)
+	if cell < 1 {
+		return img
+	}
+	margin := (size - cell*identiconGrid) / 2
+	half := (identiconGrid + 1) / 2
+	for row := 0; row < identiconGrid; row++ {
+		for col := 0; col < half; col++ {
+			if sum>>(row*half+col)&1 == 0 {
+				continue
+			}
+			for _, c := range []int{col, identiconGrid - 1 - col} {
+				rect := image.Rect(margin+c*cell, margin+row*cell, margin+(c+1)*cell, margin+(row+1)*cell)
+				draw.Draw(img, rect, &image.Uniform{fg}, image.Point{}, draw.Src)
+			}
+		}
+	}
+	return img
+}